@@ -0,0 +1,299 @@
+// Package archlint checks a project's imports against the layering the
+// domain scaffold sets up: handlers call services, services and
+// repositories depend on the domain, and only a domain's own container
+// reaches into its infrastructure.
+package archlint
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// Layer is the role of a package within a scaffolded domain. Where each
+// layer lives depends on the domain's naming convention; the comments give
+// the suffix convention's directories.
+type Layer string
+
+const (
+	LayerDomain    Layer = "domain"    // <path>: entity, port, errors, policy
+	LayerService   Layer = "srv"       // <path>/<pkg>srv
+	LayerAPI       Layer = "api"       // <path>/<pkg>api and <path>/<pkg>grpc
+	LayerInfra     Layer = "infra"     // <path>/<pkg>infra
+	LayerContainer Layer = "container" // <path>/<pkg>container
+)
+
+// Layers lists every layer, in dependency order.
+var Layers = []Layer{LayerAPI, LayerService, LayerDomain, LayerInfra, LayerContainer}
+
+// allowed lists the layers of its own domain each layer may import.
+var allowed = map[Layer][]Layer{
+	LayerDomain:    {LayerDomain},
+	LayerService:   {LayerDomain, LayerService},
+	LayerInfra:     {LayerDomain, LayerInfra},
+	LayerAPI:       {LayerDomain, LayerService, LayerAPI},
+	LayerContainer: Layers,
+}
+
+// shared lists the layers one domain may import from another, as far as
+// allowed permits: its model and its service, never its handlers, its
+// repositories or its wiring.
+var shared = []Layer{LayerDomain, LayerService}
+
+// DefaultOutside lists the domain layers code outside the domains, such
+// as cmd/, may import when the manifest doesn't say otherwise.
+var DefaultOutside = []Layer{LayerDomain, LayerService, LayerContainer}
+
+// Domain is a scaffolded domain, by its path relative to the module root
+// and the directories of its layers.
+type Domain struct {
+	Path   string // e.g. "pkg/billing/invoice"
+	Layout config.DomainLayout
+}
+
+// Violation is an import that breaks the layering.
+type Violation struct {
+	File   string // Slash path relative to the project root
+	Line   int
+	From   string // Importing package, relative to the module root
+	To     string // Imported package, relative to the module root
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d: imports %s: %s", v.File, v.Line, v.To, v.Reason)
+}
+
+// Linter checks the imports of a project.
+type Linter struct {
+	goModule string
+	domains  []Domain
+	allow    []rule
+	deny     []rule
+	outside  []Layer
+}
+
+type rule struct {
+	from, to *regexp.Regexp
+}
+
+// New returns a Linter for the module goModule with the given tracked
+// domains, applying the exceptions in cfg.
+func New(goModule string, domains []Domain, cfg config.ArchConfig) (*Linter, error) {
+	l := &Linter{goModule: goModule, domains: domains, outside: DefaultOutside}
+	var err error
+	if l.allow, err = compileRules(cfg.Allow, "arch.allow"); err != nil {
+		return nil, err
+	}
+	if l.deny, err = compileRules(cfg.Deny, "arch.deny"); err != nil {
+		return nil, err
+	}
+	if len(cfg.Outside) > 0 {
+		l.outside = nil
+		for _, name := range cfg.Outside {
+			layer := Layer(name)
+			if !slices.Contains(Layers, layer) {
+				return nil, fmt.Errorf("arch.outside: unknown layer %q (want one of %s)", name, layerList(Layers))
+			}
+			l.outside = append(l.outside, layer)
+		}
+	}
+	return l, nil
+}
+
+func compileRules(rules []config.ImportRule, what string) ([]rule, error) {
+	out := make([]rule, 0, len(rules))
+	for i, r := range rules {
+		if r.From == "" || r.To == "" {
+			return nil, fmt.Errorf("%s[%d]: needs both from and to", what, i)
+		}
+		out = append(out, rule{from: patternRegexp(r.From), to: patternRegexp(r.To)})
+	}
+	return out, nil
+}
+
+// patternRegexp compiles a package pattern in which "..." matches any
+// string; a trailing "/..." also matches the package itself, as in go list.
+func patternRegexp(pattern string) *regexp.Regexp {
+	pattern = strings.Trim(pattern, "/")
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	return regexp.MustCompile(`^` + re + `$`)
+}
+
+func (r rule) matches(from, to string) bool {
+	return r.from.MatchString(from) && r.to.MatchString(to)
+}
+
+// Check parses the imports of every package under projectRoot and
+// returns the violations, sorted by file and line. Test files, vendor/,
+// testdata/ and nested modules aren't checked.
+func (l *Linter) Check(projectRoot string) ([]Violation, error) {
+	var violations []Violation
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(projectRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == projectRoot {
+				return nil
+			}
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(projectRoot, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", rel, err)
+		}
+		from := path.Dir(rel)
+		for _, spec := range f.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			to, ok := l.internal(imp)
+			if !ok {
+				continue
+			}
+			if reason := l.reason(from, to); reason != "" {
+				violations = append(violations, Violation{
+					File:   rel,
+					Line:   fset.Position(spec.Pos()).Line,
+					From:   from,
+					To:     to,
+					Reason: reason,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Line < violations[j].Line
+	})
+	return violations, nil
+}
+
+// internal returns imp relative to the module root, if it is part of the
+// module.
+func (l *Linter) internal(imp string) (string, bool) {
+	if imp == l.goModule {
+		return ".", true
+	}
+	rel, ok := strings.CutPrefix(imp, l.goModule+"/")
+	return rel, ok
+}
+
+// reason explains why the package from may not import to, or returns ""
+// when it may.
+func (l *Linter) reason(from, to string) string {
+	for _, r := range l.allow {
+		if r.matches(from, to) {
+			return ""
+		}
+	}
+	for _, r := range l.deny {
+		if r.matches(from, to) {
+			return "denied by arch.deny in " + config.ManifestoFile
+		}
+	}
+
+	toDomain, toLayer, ok := l.layerOf(to)
+	if !ok {
+		return ""
+	}
+	fromDomain, fromLayer, ok := l.layerOf(from)
+	switch {
+	case !ok:
+		if !slices.Contains(l.outside, toLayer) {
+			return fmt.Sprintf("code outside domains may not import the %s layer of %s (only %s)", toLayer, toDomain.Path, layerList(l.outside))
+		}
+	case fromDomain.Path == toDomain.Path:
+		if !slices.Contains(allowed[fromLayer], toLayer) {
+			return fmt.Sprintf("the %s layer may not import the %s layer", fromLayer, toLayer)
+		}
+	default:
+		if !slices.Contains(shared, toLayer) || !slices.Contains(allowed[fromLayer], toLayer) {
+			return fmt.Sprintf("the %s layer may not import the %s layer of another domain (%s)", fromLayer, toLayer, toDomain.Path)
+		}
+	}
+	return ""
+}
+
+// layerOf returns the tracked domain pkg belongs to and its layer there.
+// Packages nested in a layer share its layer; any other package under the
+// domain path counts as the domain layer, as does every package of a flat
+// domain.
+func (l *Linter) layerOf(pkg string) (Domain, Layer, bool) {
+	var best Domain
+	found := false
+	for _, d := range l.domains {
+		if (pkg == d.Path || strings.HasPrefix(pkg, d.Path+"/")) && (!found || len(d.Path) > len(best.Path)) {
+			best, found = d, true
+		}
+	}
+	if !found {
+		return Domain{}, "", false
+	}
+
+	sub, _ := strings.CutPrefix(pkg, best.Path)
+	first, _, _ := strings.Cut(strings.TrimPrefix(sub, "/"), "/")
+	if first == "" {
+		return best, LayerDomain, true
+	}
+	switch first {
+	case best.Layout.Service:
+		return best, LayerService, true
+	case best.Layout.API, best.Layout.GRPC:
+		return best, LayerAPI, true
+	case best.Layout.Infra:
+		return best, LayerInfra, true
+	case best.Layout.Container:
+		return best, LayerContainer, true
+	}
+	return best, LayerDomain, true
+}
+
+func layerList(layers []Layer) string {
+	names := make([]string, len(layers))
+	for i, l := range layers {
+		names[i] = string(l)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,145 @@
+package archlint
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// The projects under testdata break each layering rule at least once. An
+// import that must be reported ends with a comment
+//
+//	// want "regexp"
+//
+// matching the violation's reason; every other import must pass.
+
+var wantRe = regexp.MustCompile(`//\s*want\s+("(?:[^"\\]|\\.)*")`)
+
+// fixtureLinter returns a Linter for the fixture project at root, set up
+// from its manifest the way lint-arch does.
+func fixtureLinter(t *testing.T, root string) *Linter {
+	t.Helper()
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domains := make([]Domain, len(manifest.Domains))
+	for i, d := range manifest.Domains {
+		naming, err := manifest.DomainNaming(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		domains[i] = Domain{Path: d.Path, Layout: naming.Layout(path.Base(d.Path))}
+	}
+	linter, err := New(manifest.Project.GoModule, domains, manifest.Arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return linter
+}
+
+// fixtureWants returns the want comments of the non-test Go files under
+// root, by "file:line".
+func fixtureWants(t *testing.T, root string) map[string]*regexp.Regexp {
+	t.Helper()
+	wants := make(map[string]*regexp.Regexp)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return err
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				m := wantRe.FindStringSubmatch(c.Text)
+				if m == nil {
+					continue
+				}
+				pattern, err := strconv.Unquote(m[1])
+				if err != nil {
+					return fmt.Errorf("%s: bad want %s: %w", rel, m[1], err)
+				}
+				key := fmt.Sprintf("%s:%d", filepath.ToSlash(rel), fset.Position(c.Pos()).Line)
+				wants[key] = regexp.MustCompile(pattern)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wants
+}
+
+func TestFixtures(t *testing.T) {
+	for _, name := range []string{"suffix", "subdir", "config"} {
+		t.Run(name, func(t *testing.T) {
+			root := filepath.Join("testdata", name)
+			violations, err := fixtureLinter(t, root).Check(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wants := fixtureWants(t, root)
+			if len(wants) == 0 {
+				t.Fatal("fixture has no violations to find")
+			}
+			for _, v := range violations {
+				key := fmt.Sprintf("%s:%d", v.File, v.Line)
+				want, ok := wants[key]
+				switch {
+				case !ok:
+					t.Errorf("unexpected violation %s", v)
+				case !want.MatchString(v.Reason):
+					t.Errorf("%s: reason %q doesn't match %q", key, v.Reason, want)
+				}
+				delete(wants, key)
+			}
+			for key, want := range wants {
+				t.Errorf("%s: no violation reported, want %q", key, want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+	for name, cfg := range map[string]config.ArchConfig{
+		"rule without to": {Allow: []config.ImportRule{{From: "pkg/..."}}},
+		"unknown layer":   {Outside: []string{"handlers"}},
+	} {
+		if _, err := New("example.com/shop", nil, cfg); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestPatternRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, pkg string
+		want         bool
+	}{
+		{"pkg/...", "pkg", true},
+		{"pkg/...", "pkg/kernel", true},
+		{"pkg/...", "pkgs/kernel", false},
+		{"pkg/.../infra", "pkg/billing/invoice/infra", true},
+		{"pkg/kernel", "pkg/kernel/ids", false},
+		{"/pkg/kernel/", "pkg/kernel", true},
+	}
+	for _, tt := range tests {
+		if got := patternRegexp(tt.pattern).MatchString(tt.pkg); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.pkg, got, tt.want)
+		}
+	}
+}
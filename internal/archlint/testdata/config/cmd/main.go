@@ -0,0 +1,7 @@
+package main
+
+import (
+	"example.com/shop/pkg/invoice" // want "code outside domains may not import the domain layer of pkg/invoice \\(only container\\)"
+	"example.com/shop/pkg/invoice/invoicecontainer"
+	"example.com/shop/pkg/kernel"
+)
@@ -0,0 +1,6 @@
+package kernel
+
+import (
+	"example.com/shop/pkg/errx"    // want "denied by arch.deny in manifesto.yaml"
+	"example.com/shop/pkg/invoice" // want "denied by arch.deny in manifesto.yaml"
+)
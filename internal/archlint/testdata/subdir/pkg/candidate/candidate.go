@@ -0,0 +1,3 @@
+package candidate
+
+import "example.com/shop/pkg/candidate/repository" // want "the domain layer may not import the infra layer"
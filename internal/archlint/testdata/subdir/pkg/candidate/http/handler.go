@@ -0,0 +1,7 @@
+package http
+
+import (
+	"example.com/shop/pkg/candidate/repository" // want "the api layer may not import the infra layer"
+	"example.com/shop/pkg/candidate/service"
+	"example.com/shop/pkg/notes"
+)
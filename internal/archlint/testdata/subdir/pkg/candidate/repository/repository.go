@@ -0,0 +1,3 @@
+package repository
+
+import "example.com/shop/pkg/candidate"
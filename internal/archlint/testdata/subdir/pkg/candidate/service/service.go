@@ -0,0 +1,6 @@
+package service
+
+import (
+	"example.com/shop/pkg/candidate"
+	"example.com/shop/pkg/candidate/wire" // want "the srv layer may not import the container layer"
+)
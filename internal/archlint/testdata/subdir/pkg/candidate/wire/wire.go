@@ -0,0 +1,7 @@
+package wire
+
+import (
+	"example.com/shop/pkg/candidate/http"
+	"example.com/shop/pkg/candidate/repository"
+	"example.com/shop/pkg/candidate/service"
+)
@@ -0,0 +1,3 @@
+package notes
+
+import "example.com/shop/pkg/candidate/http" // want "the domain layer may not import the api layer of another domain"
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"example.com/shop/pkg/billing/invoice"
+	"example.com/shop/pkg/billing/invoice/invoicecontainer"
+	"example.com/shop/pkg/billing/invoice/invoicesrv"
+	"example.com/shop/pkg/sales/order/orderapi"   // want "code outside domains may not import the api layer of pkg/sales/order"
+	"example.com/shop/pkg/sales/order/orderinfra" // want "code outside domains may not import the infra layer of pkg/sales/order"
+)
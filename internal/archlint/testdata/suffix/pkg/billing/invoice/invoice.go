@@ -0,0 +1,9 @@
+package invoice
+
+import (
+	"fmt"
+
+	"example.com/shop/pkg/billing/invoice/invoicesrv" // want "the domain layer may not import the srv layer"
+	"example.com/shop/pkg/kernel"
+	"example.com/shop/pkg/sales/order"
+)
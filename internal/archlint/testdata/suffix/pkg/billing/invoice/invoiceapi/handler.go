@@ -0,0 +1,10 @@
+package invoiceapi
+
+import (
+	"example.com/shop/pkg/billing/invoice"
+	"example.com/shop/pkg/billing/invoice/invoiceinfra"     // want "the api layer may not import the infra layer"
+	"example.com/shop/pkg/billing/invoice/invoiceinfra/dto" // allowed by arch.allow
+	"example.com/shop/pkg/billing/invoice/invoicesrv"
+	"example.com/shop/pkg/sales/order/orderapi"       // want "the api layer may not import the api layer of another domain"
+	"example.com/shop/pkg/sales/order/ordercontainer" // want "the api layer may not import the container layer of another domain"
+)
@@ -0,0 +1,8 @@
+package invoicecontainer
+
+import (
+	"example.com/shop/pkg/billing/invoice/invoiceapi"
+	"example.com/shop/pkg/billing/invoice/invoiceinfra"
+	"example.com/shop/pkg/billing/invoice/invoicesrv"
+	"example.com/shop/pkg/sales/order/orderinfra" // want "the container layer may not import the infra layer of another domain"
+)
@@ -0,0 +1,6 @@
+package invoiceinfra
+
+import (
+	"example.com/shop/pkg/billing/invoice"
+	"example.com/shop/pkg/billing/invoice/invoicesrv" // want "the infra layer may not import the srv layer"
+)
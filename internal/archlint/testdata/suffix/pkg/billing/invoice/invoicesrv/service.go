@@ -0,0 +1,9 @@
+package invoicesrv
+
+import (
+	"example.com/shop/pkg/billing/invoice"
+	"example.com/shop/pkg/billing/invoice/invoiceapi"   // want "the srv layer may not import the api layer"
+	"example.com/shop/pkg/billing/invoice/invoiceinfra" // want "the srv layer may not import the infra layer"
+	"example.com/shop/pkg/sales/order/orderinfra"       // want "the srv layer may not import the infra layer of another domain \\(pkg/sales/order\\)"
+	"example.com/shop/pkg/sales/order/ordersrv"
+)
@@ -0,0 +1,3 @@
+package invoicesrv
+
+import "example.com/shop/pkg/billing/invoice/invoiceinfra"
@@ -1,118 +1,826 @@
 package cli
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
 	"github.com/Abraxas-365/manifesto-cli/internal/remote"
 	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
 	"github.com/Abraxas-365/manifesto-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var addCmd = &cobra.Command{
-	Use:   "add <module-or-domain-path>",
+	Use:   "add <module-or-domain-path>...",
 	Short: "Wire a module or scaffold a DDD domain package",
 	Long: `Add a module to the project or scaffold a full domain package.
 
 Module wiring (downloads source + injects into container/server):
-  manifesto add fsx
+  manifesto add fsx                        # asks where files are stored
+  manifesto add fsx --storage s3           # or local, both (STORAGE_MODE at runtime)
   manifesto add asyncx
   manifesto add ai
   manifesto add jobx
-  manifesto add notifx
+  manifesto add notifx                     # asks which provider to use
+  manifesto add notifx --provider smtp     # or ses, console
+  manifesto add redis
   manifesto add iam
+  manifesto add metrics
+  manifesto add health
+  manifesto add otel
+  manifesto add cronx
+  manifesto add swagger
+  manifesto add jobx notifx   # several modules, downloaded together
+  manifesto add --all         # every module the project can host
 
 Domain scaffolding (creates entity, repo, service, handler layers):
   manifesto add pkg/recruitment/candidate
-  manifesto add pkg/billing/invoice`,
-	Args: cobra.ExactArgs(1),
+  manifesto add pkg/billing/invoice
+  manifesto add pkg/billing/invoice --with-policy
+  manifesto add pkg/billing/invoice --with-events
+  manifesto add pkg/docs/report --with-uploads   # project with fsx wired
+  manifesto add pkg/billing/invoice --fields "amount:decimal,currency:string,due_date:time,paid:bool"
+  manifesto add pkg/catalog/product --repo memory
+  manifesto add pkg/catalog/product --no-tests
+  manifesto add pkg/catalog/product --no-mocks
+  manifesto add pkg/catalog/product --layers entity,port,service,infra,container
+  manifesto add pkg/billing/reconcile --kind worker
+  manifesto add pkg/billing/payment --transport grpc   # project created with init --grpc
+  manifesto add pkg/catalog/tag --naming flat   # differs from the project's convention
+  manifesto add pkg/hr/person --plural people
+  manifesto add pkg/billing/invoice --table billing_invoices
+
+Cron jobs (generated in pkg/cronjobs, registered with the cronx scheduler):
+  manifesto add cron nightly-report
+  manifesto add cron cleanup-sessions --schedule "*/15 * * * *"
+
+Job handlers (generated in the domain's <pkg>jobs, registered with jobx):
+  manifesto add job send-invoice --domain pkg/billing/invoice
+
+Module settings go where the project's env_target in manifesto.yaml says:
+the Makefile (default), .env.example and .env (dotenv), or both.
+--env-target overrides it for one run:
+  manifesto add jobx --env-target dotenv
+
+Afterwards add runs go mod tidy, then go build ./... to check the project
+still compiles; if it doesn't, add shows the errors and offers to revert the
+wiring. --no-verify skips the build and --skip-tidy skips both.
+
+Preview changes without writing anything:
+  manifesto add jobx --dry-run
+
+Check in CI that the project matches what add would generate, without
+writing anything:
+  manifesto add jobx --check
+  manifesto add pkg/billing/invoice --check
+
+--check exits 0 when add would change nothing, 1 when changes are pending
+(listed one per line), and 2 when the check itself fails or an injection
+can't be applied. A tracked domain is checked against the options in
+manifesto.yaml, so hand edits to its generated files count as pending.
+
+Pin one module to another manifesto version; the rest of the project stays
+on the version recorded for each module:
+  manifesto add ai --ref v1.4.0
+
+Copy a module from a manifesto checkout instead, to work on both at once;
+it is recorded with local:<dir> as its version:
+  manifesto add jobx --local ../manifesto
+
+Module source comes without its _test.go files and testdata;
+--with-tests downloads them too, and records in manifesto.yaml that later
+downloads should:
+  manifesto add ai --with-tests
+
+Modules come from the manifesto fork recorded in manifesto.yaml (set with
+'manifesto init --repo'); --source overrides it for one run:
+  manifesto add jobx --source acme/manifesto
+  manifesto add pkg/billing/invoice --dry-run`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if addAll {
+			if len(args) > 0 {
+				return fmt.Errorf("--all wires every module the project can host; it takes no arguments")
+			}
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: runAdd,
 }
 
+var (
+	addAll       bool
+	addDryRun    bool
+	addCheck     bool
+	addRef       string
+	addSource    string
+	addSchedule  string
+	addJobDomain string
+	addProvider  string
+	addStorage   string
+	addEnvTarget string
+	addDomainF   domainFlags
+)
+
+func init() {
+	addDomainF.register(addCmd)
+	addCmd.Flags().BoolVar(&addAll, "all", false, "Wire every module the project can host that isn't wired yet")
+	addCmd.Flags().BoolVar(&addDryRun, "dry-run", false, "Print a diff of the changes without writing files or the manifest")
+	addCmd.Flags().BoolVar(&addCheck, "check", false, "Exit 1 if add would change the project, 0 if not, without writing anything")
+	addCmd.MarkFlagsMutuallyExclusive("dry-run", "check")
+	addCmd.Flags().StringVar(&addRef, "ref", "", "Download the module at this manifesto version and record it for the module only (default: project version)")
+	addCmd.MarkFlagsMutuallyExclusive("ref", "dry-run")
+	addCmd.MarkFlagsMutuallyExclusive("ref", "check")
+	addCmd.MarkFlagsMutuallyExclusive("all", "ref")
+	addCmd.MarkFlagsMutuallyExclusive("all", "dry-run")
+	addCmd.MarkFlagsMutuallyExclusive("all", "check")
+	registerModifiedFlags(addCmd)
+	registerTidyFlag(addCmd)
+	registerVerifyFlag(addCmd)
+	registerTestsFlag(addCmd)
+	registerLocalFlag(addCmd)
+	addCmd.Flags().StringVar(&addSource, "source", "", "Fetch modules from this manifesto fork (owner/name); default: the project's repo")
+	addCmd.MarkFlagsMutuallyExclusive("local", "ref")
+	addCmd.MarkFlagsMutuallyExclusive("local", "source")
+	addCmd.MarkFlagsMutuallyExclusive("local", "dry-run")
+	addCmd.MarkFlagsMutuallyExclusive("local", "check")
+	addCmd.MarkFlagsMutuallyExclusive("all", "local")
+	addCmd.Flags().StringVar(&addProvider, "provider", "", "Provider for a module that has several, e.g. notifx: console, ses, smtp (default: ask, or the module's default)")
+	addCmd.Flags().StringVar(&addStorage, "storage", "", "Storage backend for fsx: local, s3, both (the fsx name for --provider)")
+	addCmd.MarkFlagsMutuallyExclusive("provider", "storage")
+	addCmd.Flags().StringVar(&addEnvTarget, "env-target", "", "Write module env variables to the Makefile, to .env.example and .env (dotenv), or both (default: the project's env_target, else makefile)")
+	addCmd.Flags().StringVar(&addSchedule, "schedule", scaffold.DefaultCronSchedule, "Cron expression for 'add cron <name>' (cron jobs only)")
+	addCmd.Flags().StringVar(&addJobDomain, "domain", "", "Domain path a job from 'add job <name>' belongs to, e.g. pkg/billing/invoice (jobs only)")
+}
+
 func runAdd(cmd *cobra.Command, args []string) error {
-	arg := args[0]
+	applyModifiedFlags()
+	if addCheck {
+		return checkExit(add(cmd, args))
+	}
+	return add(cmd, args)
+}
 
+func add(cmd *cobra.Command, args []string) error {
 	projectRoot, err := findProjectRoot()
 	if err != nil {
 		return err
 	}
 
-	manifest, err := config.LoadManifest(projectRoot)
+	manifest, err := loadManifest(projectRoot)
 	if err != nil {
-		return fmt.Errorf("not a manifesto project (no manifesto.yaml found)")
+		return err
+	}
+	profile, err := manifest.Profile()
+	if err != nil {
+		return err
 	}
 
+	providerFlag := providerFlagName(cmd)
+	if !addAll && args[0] == "cron" {
+		if providerFlag != "" {
+			return fmt.Errorf("--%s doesn't apply to cron jobs", providerFlag)
+		}
+		for _, flag := range []string{"env-target", "with-tests", "include-tests", "local"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--%s doesn't apply to cron jobs", flag)
+			}
+		}
+		return runAddCron(cmd, projectRoot, manifest, args[1:])
+	}
+	if !addAll && args[0] == "job" {
+		if providerFlag != "" {
+			return fmt.Errorf("--%s doesn't apply to jobs", providerFlag)
+		}
+		for _, flag := range []string{"env-target", "schedule", "with-tests", "include-tests", "local"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--%s doesn't apply to jobs", flag)
+			}
+		}
+		return runAddJob(cmd, projectRoot, manifest, args[1:])
+	}
+	if cmd.Flags().Changed("schedule") {
+		return fmt.Errorf("--schedule only applies to 'add cron <name>'")
+	}
+	if cmd.Flags().Changed("domain") {
+		return fmt.Errorf("--domain only applies to 'add job <name>'")
+	}
+
+	if addAll {
+		if providerFlag != "" {
+			return fmt.Errorf("--%s takes a single module", providerFlag)
+		}
+		args = unwiredModules(manifest, profile)
+		if len(args) == 0 {
+			ui.StepInfo("Every module is already wired")
+			return nil
+		}
+	} else if len(args) > 1 {
+		for _, arg := range args {
+			if !config.IsWireableModule(arg) {
+				return fmt.Errorf("%s is not a module; scaffold domains one at a time", arg)
+			}
+		}
+		for _, flag := range []string{"ref", "local", "dry-run", "check", "provider", "storage"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--%s takes a single module", flag)
+			}
+		}
+	}
+	arg := args[0]
+
 	// Dispatch: wireable module vs domain path
 	if config.IsWireableModule(arg) {
-		return runWireModule(projectRoot, manifest, arg)
+		if _, err := config.LookupEnvTarget(addEnvTarget); err != nil {
+			return err
+		}
+		// --local moves the module to the checkout as --ref would to a
+		// version.
+		if localCheckout != "" {
+			if addRef, err = remote.LocalRef(localCheckout); err != nil {
+				return err
+			}
+		}
+		requested := args
+		if addRef == "" && !addDryRun && !addCheck {
+			args = withMissingWireables(manifest, args)
+		}
+		if err := profile.ValidateWire(args); err != nil {
+			return err
+		}
+		if err := confirmMissingWireables(requested, args); err != nil {
+			return err
+		}
+		source := addSource
+		if source == "" {
+			source = manifest.Project.Repo
+		}
+		if source != "" {
+			if err := remote.ValidateRepo(source); err != nil {
+				return err
+			}
+		}
+		if addRef != "" {
+			if err := remote.ValidateRef(addRef, remote.RefType(manifest.Project.RefType)); err != nil {
+				return err
+			}
+		}
+		if withTests {
+			manifest.Project.Tests = true
+		}
+		if len(args) > 1 {
+			return runWireModules(projectRoot, manifest, args, source)
+		}
+		return runWireModule(projectRoot, manifest, arg, source)
 	}
 
 	// Domain scaffolding — anything that's not a wireable module
-	return runAddDomain(projectRoot, manifest, arg)
+	if addRef != "" {
+		return fmt.Errorf("--ref only applies to modules; %s is a domain path", arg)
+	}
+	if providerFlag != "" {
+		return fmt.Errorf("--%s only applies to modules; %s is a domain path", providerFlag, arg)
+	}
+	for _, flag := range []string{"env-target", "with-tests", "include-tests", "local"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s only applies to modules; %s is a domain path", flag, arg)
+		}
+	}
+	return runAddDomain(cmd, projectRoot, manifest, arg)
 }
 
-func runWireModule(projectRoot string, manifest *config.Manifest, moduleName string) error {
-	// Check not already wired
-	if manifest.IsWired(moduleName) {
+// runWireModule wires moduleName, downloading any source it needs from the
+// manifesto fork source ("" for upstream).
+func runWireModule(projectRoot string, manifest *config.Manifest, moduleName, source string) error {
+	if addCheck {
+		return checkWireModule(projectRoot, manifest, moduleName)
+	}
+	if addDryRun {
+		return previewWireModule(projectRoot, manifest, moduleName)
+	}
+
+	// Check not already wired; with --ref, only its source moves.
+	wired := manifest.IsWired(moduleName)
+	if wired && addRef == "" {
 		ui.StepInfo(fmt.Sprintf("%s is already wired", moduleName))
 		return nil
 	}
 
 	spec := config.WireableModuleRegistry[moduleName]
+	var provider string
+	if !wired {
+		var err error
+		if provider, err = chooseProvider(spec); err != nil {
+			return err
+		}
+	}
+
+	// Downloading required source, then wiring and tidying unless only
+	// the source moves.
+	total := 1
+	if len(spec.RequiredModules) > 0 && !wired {
+		total = 2
+	}
+	if !wired && !skipTidy {
+		total++
+	}
+	var snapshot *wireSnapshot
+	if !wired && verifies() {
+		total++
+		snapshot = takeWireSnapshot(projectRoot, manifest, []string{moduleName})
+	}
+	steps := ui.NewSteps(total)
 
 	fmt.Println()
 
 	// Download required source modules if not already present.
 	if len(spec.RequiredModules) > 0 {
-		spin := ui.NewSpinner(fmt.Sprintf("Downloading %s...", moduleName))
-		spin.Start()
-
-		client := remote.NewClient("")
-		ref := manifest.Project.Version
+		client := remote.NewClient(source)
+		client.ForceRefType(remote.RefType(manifest.Project.RefType))
+		ref := addRef
 		if ref == "" {
+			ref = scaffold.ChannelRef(manifest, client)
+		}
+
+		spin := steps.Start(fmt.Sprintf("Downloading %s...", moduleName))
+		scaffold.ReportProgress(client, spin)
+
+		var pinned []string
+		if addRef != "" {
 			var err error
-			ref, err = client.GetLatestVersion()
-			if err != nil || ref == "" {
-				ref = remote.DefaultRef
+			pinned, err = scaffold.PinModules(projectRoot, manifest, pinTargets(moduleName, spec), client, ref)
+			if err != nil {
+				spin.Stop(false)
+				return fmt.Errorf("download %s: %w", moduleName, err)
 			}
 		}
-
 		if err := scaffold.EnsureModulesPresent(projectRoot, manifest, spec.RequiredModules, client, ref); err != nil {
 			spin.Stop(false)
 			return fmt.Errorf("download %s: %w", moduleName, err)
 		}
 		spin.Stop(true)
-	}
+		if changed := manifest.ArchiveChanged(ref, client.ArchiveChecksum(ref)); changed != "" {
+			ui.StepWarn(fmt.Sprintf("manifesto@%s has changed upstream since %s was installed from it; the modules may not match", ref, changed))
+		}
+		if mixed := manifest.MixedMajors(); mixed != "" {
+			ui.StepWarn(scaffold.MixedMajorsWarning(mixed))
+		}
 
-	spin := ui.NewSpinner(fmt.Sprintf("Wiring %s...", moduleName))
-	spin.Start()
+		if wired {
+			if err := manifest.Save(projectRoot); err != nil {
+				return fmt.Errorf("save manifesto.yaml: %w", err)
+			}
+			if len(pinned) == 0 {
+				ui.StepInfo(fmt.Sprintf("%s is already on manifesto@%s", moduleName, ref))
+			} else {
+				ui.StepDone(fmt.Sprintf("Moved %s to manifesto@%s", strings.Join(pinned, ", "), ref))
+			}
+			return nil
+		}
+	} else if wired {
+		ui.StepInfo(fmt.Sprintf("%s is already wired and has no source to move", moduleName))
+		return nil
+	}
 
 	result, err := scaffold.WireModule(scaffold.WireOptions{
-		ProjectRoot:  projectRoot,
-		ModuleName:   moduleName,
-		GoModule:     manifest.Project.GoModule,
-		ProjectName:  manifest.Project.Name,
-		WiredModules: manifest.WiredModules,
+		ProjectRoot:    projectRoot,
+		ModuleName:     moduleName,
+		GoModule:       manifest.Project.GoModule,
+		ProjectName:    manifest.Project.Name,
+		WiredModules:   manifest.WiredOrProvided(),
+		WiredProviders: manifest.Providers,
+		NoServer:       !manifest.ServesHTTP(),
+		EnvTarget:      cmp.Or(addEnvTarget, manifest.Project.EnvTarget),
+		Provider:       provider,
+		Steps:          steps,
 	})
 	if err != nil {
-		spin.Stop(false)
 		return err
 	}
-	spin.Stop(true)
 
 	// Update manifest
-	manifest.WiredModules = append(manifest.WiredModules, moduleName)
+	manifest.AddWiredModule(moduleName)
+	manifest.SetGoDeps(moduleName, result.GoDeps)
+	manifest.SetProvider(moduleName, result.Provider)
+	manifest.AddBridges(result.ActivatedBridges...)
 	if err := manifest.Save(projectRoot); err != nil {
 		return fmt.Errorf("save manifesto.yaml: %w", err)
 	}
 
-	ui.PrintWireSuccess(moduleName, result.ModifiedFiles, result.ActivatedBridges)
+	warnSkippedServer(moduleName, result)
+	return finishWire(projectRoot, manifest, steps, snapshot, []string{moduleName}, result.ModifiedFiles, result.ActivatedBridges, result.Deferred, result.ToolchainErr)
+}
+
+// runWireModules wires several modules, downloading the source they need
+// in one go. They are wired in config.WireOrder, so bridges between them
+// fire.
+func runWireModules(projectRoot string, manifest *config.Manifest, names []string, source string) error {
+	var pending, downloads, required []string
+	for _, name := range config.WireOrder(names) {
+		if manifest.IsWired(name) {
+			ui.StepInfo(fmt.Sprintf("%s is already wired", name))
+			continue
+		}
+		pending = append(pending, name)
+		if spec := config.WireableModuleRegistry[name]; len(spec.RequiredModules) > 0 {
+			downloads = append(downloads, name)
+			required = append(required, spec.RequiredModules...)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	providers := make(map[string]string)
+	for _, name := range pending {
+		provider, err := chooseProvider(config.WireableModuleRegistry[name])
+		if err != nil {
+			return err
+		}
+		providers[name] = provider
+	}
+
+	// Downloading required source, wiring each module, then tidying and
+	// building.
+	total := len(pending)
+	if len(required) > 0 {
+		total++
+	}
+	if !skipTidy {
+		total++
+	}
+	var snapshot *wireSnapshot
+	if verifies() {
+		total++
+		snapshot = takeWireSnapshot(projectRoot, manifest, pending)
+	}
+	steps := ui.NewSteps(total)
+
+	fmt.Println()
+
+	if len(required) > 0 {
+		client := remote.NewClient(source)
+		client.ForceRefType(remote.RefType(manifest.Project.RefType))
+		ref := scaffold.ChannelRef(manifest, client)
+
+		spin := steps.Start(fmt.Sprintf("Downloading %s...", strings.Join(downloads, ", ")))
+		scaffold.ReportProgress(client, spin)
+		if err := scaffold.EnsureModulesPresent(projectRoot, manifest, required, client, ref); err != nil {
+			spin.Stop(false)
+			return fmt.Errorf("download %s: %w", strings.Join(downloads, ", "), err)
+		}
+		spin.Stop(true)
+		if changed := manifest.ArchiveChanged(ref, client.ArchiveChecksum(ref)); changed != "" {
+			ui.StepWarn(fmt.Sprintf("manifesto@%s has changed upstream since %s was installed from it; the modules may not match", ref, changed))
+		}
+		if mixed := manifest.MixedMajors(); mixed != "" {
+			ui.StepWarn(scaffold.MixedMajorsWarning(mixed))
+		}
+		if err := manifest.Save(projectRoot); err != nil {
+			return fmt.Errorf("save manifesto.yaml: %w", err)
+		}
+	}
+
+	// The manifest is saved after each module, so a failure leaves it
+	// listing the ones that were wired.
+	var modified, bridges, deferred []string
+	var toolchainErr error
+	for _, name := range pending {
+		result, err := scaffold.WireModule(scaffold.WireOptions{
+			ProjectRoot:    projectRoot,
+			ModuleName:     name,
+			GoModule:       manifest.Project.GoModule,
+			ProjectName:    manifest.Project.Name,
+			WiredModules:   manifest.WiredOrProvided(),
+			WiredProviders: manifest.Providers,
+			NoServer:       !manifest.ServesHTTP(),
+			EnvTarget:      cmp.Or(addEnvTarget, manifest.Project.EnvTarget),
+			Provider:       providers[name],
+			Steps:          steps,
+		})
+		if err != nil {
+			return fmt.Errorf("wire %s: %w", name, err)
+		}
+
+		manifest.AddWiredModule(name)
+		manifest.SetGoDeps(name, result.GoDeps)
+		manifest.SetProvider(name, result.Provider)
+		manifest.AddBridges(result.ActivatedBridges...)
+		if err := manifest.Save(projectRoot); err != nil {
+			return fmt.Errorf("save manifesto.yaml: %w", err)
+		}
+
+		for _, f := range result.ModifiedFiles {
+			if !slices.Contains(modified, f) {
+				modified = append(modified, f)
+			}
+		}
+		warnSkippedServer(name, result)
+		bridges = append(bridges, result.ActivatedBridges...)
+		deferred = append(deferred, result.Deferred...)
+		if result.ToolchainErr != nil {
+			toolchainErr = result.ToolchainErr
+		}
+	}
+
+	return finishWire(projectRoot, manifest, steps, snapshot, pending, modified, bridges, deferred, toolchainErr)
+}
+
+// finishWire tidies the project after wiring modules, checks that it
+// builds when snapshot, taken before the wiring, is set, and prints the
+// summary. toolchainErr and deferred come from the wiring: the Go
+// dependencies that couldn't be installed and why. A build that fails
+// offers to revert to snapshot.
+func finishWire(projectRoot string, manifest *config.Manifest, steps *ui.Steps, snapshot *wireSnapshot, modules, modified, bridges, deferred []string, toolchainErr error) error {
+	depsErr := toolchainErr
+	if !skipTidy {
+		if depsErr == nil {
+			depsErr = tidyProject(projectRoot, steps)
+		}
+		if depsErr != nil {
+			deferred = append(deferred, "go mod tidy")
+		}
+	}
+	if snapshot != nil {
+		// Without the dependencies the build can't pass; it is left to
+		// the user with go mod tidy.
+		if depsErr != nil {
+			steps.Skip()
+		} else if err := verifyBuild(projectRoot, steps); err != nil {
+			return offerRevert(snapshot, manifest, err)
+		}
+	}
+
+	ui.PrintWireSuccess(modules, modified, bridges)
+	if depsErr != nil {
+		ui.PrintDeferred(depsErr.Error(), toolchain.Guidance(depsErr), projectRoot, deferred)
+	}
+	ui.PrintChecklist(followUps(projectRoot, manifest, modules...), scaffold.TodoFile)
+	return nil
+}
+
+// warnSkippedServer notes that a module's routes and middleware were left
+// out because the project has no HTTP server.
+func warnSkippedServer(moduleName string, result *scaffold.WireResult) {
+	if result.SkippedServer {
+		ui.StepWarn(fmt.Sprintf("%s: skipped its routes and middleware; worker projects have no HTTP server", moduleName))
+	}
+}
+
+// withMissingWireables adds to names the wireable modules they require that
+// the project hasn't wired yet.
+func withMissingWireables(manifest *config.Manifest, names []string) []string {
+	return slices.DeleteFunc(config.WithRequiredWireables(names), func(name string) bool {
+		return !slices.Contains(names, name) && manifest.IsWired(name)
+	})
+}
+
+// confirmMissingWireables asks before wiring the modules withMissingWireables
+// added to requested. Without a prompt they are wired; declining fails with
+// the command that wires them all.
+func confirmMissingWireables(requested, names []string) error {
+	var missing, needers []string
+	for _, name := range names {
+		if !slices.Contains(requested, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	for _, name := range requested {
+		required := config.WithRequiredWireables([]string{name})
+		if slices.ContainsFunc(missing, func(m string) bool { return slices.Contains(required, m) }) {
+			needers = append(needers, name)
+		}
+	}
+	if !ui.Interactive() {
+		ui.StepInfo(fmt.Sprintf("Also wiring %s, needed by %s", strings.Join(missing, ", "), strings.Join(needers, ", ")))
+		return nil
+	}
+	pronoun := "it"
+	if len(missing) > 1 {
+		pronoun = "them"
+	}
+	if !ui.Confirm(fmt.Sprintf("%s must be wired for %s. Wire %s too?", strings.Join(missing, ", "), strings.Join(needers, ", "), pronoun), true) {
+		return fmt.Errorf("%s must be wired for %s; run 'manifesto add %s'", strings.Join(missing, ", "), strings.Join(needers, ", "), strings.Join(config.WireOrder(names), " "))
+	}
 	return nil
 }
 
-func runAddDomain(projectRoot string, manifest *config.Manifest, domainPath string) error {
-	data := scaffold.NewDomainData(manifest.Project.GoModule, domainPath)
+// providerFlagName returns the flag that chose a provider, "provider" or
+// "storage", or "" when neither was passed.
+func providerFlagName(cmd *cobra.Command) string {
+	for _, flag := range []string{"provider", "storage"} {
+		if cmd.Flags().Changed(flag) {
+			return flag
+		}
+	}
+	return ""
+}
+
+// chooseProvider returns the provider to wire spec with: --provider or
+// --storage, else the one picked at a prompt, else "" for the module's
+// default. Modules without providers get "".
+func chooseProvider(spec config.WireableModule) (string, error) {
+	if addStorage != "" && spec.Name != "fsx" {
+		return "", fmt.Errorf("--storage only applies to fsx; use --provider for %s", spec.Name)
+	}
+	names := spec.ProviderNames()
+	if len(names) == 0 {
+		if addProvider != "" {
+			return "", fmt.Errorf("%s has no providers; --provider applies to %s", spec.Name, strings.Join(modulesWithProviders(), ", "))
+		}
+		return "", nil
+	}
+	provider := cmp.Or(addProvider, addStorage)
+	if provider == "" {
+		if !ui.Interactive() {
+			return "", nil
+		}
+		for _, name := range names {
+			fmt.Printf("    %-8s  %s\n", name, ui.Dim.Sprint(spec.Providers[name].Description))
+		}
+		provider = ui.Ask(fmt.Sprintf("Which %s provider?", spec.Name), spec.DefaultProvider)
+	}
+	if !slices.Contains(names, provider) {
+		return "", fmt.Errorf("unknown %s provider %q (available: %s)", spec.Name, provider, strings.Join(names, ", "))
+	}
+	return provider, nil
+}
+
+// modulesWithProviders returns the wireable modules --provider applies to.
+func modulesWithProviders() []string {
+	var names []string
+	for _, name := range config.WireableModuleNames() {
+		if len(config.WireableModuleRegistry[name].Providers) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// unwiredModules returns the wireable modules the project's profile allows
+// that aren't wired yet, for --all.
+func unwiredModules(manifest *config.Manifest, profile config.Profile) []string {
+	var names []string
+	for _, name := range config.WireableModuleNames() {
+		if profile.Allows(name) && !manifest.IsWired(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// pinTargets returns the modules --ref moves for moduleName: its own
+// source module, leaving shared dependencies such as fsx where they are.
+func pinTargets(moduleName string, spec config.WireableModule) []string {
+	if slices.Contains(spec.RequiredModules, moduleName) {
+		return []string{moduleName}
+	}
+	return spec.RequiredModules
+}
+
+// previewWireModule performs the wiring in memory and reports the diff.
+func previewWireModule(projectRoot string, manifest *config.Manifest, moduleName string) error {
+	if manifest.IsWired(moduleName) {
+		return fmt.Errorf("%s is already wired", moduleName)
+	}
+
+	actions, err := downloadActions(manifest, moduleName)
+	if err != nil {
+		return err
+	}
+	preview, result, err := scaffold.PreviewWire(scaffold.WireOptions{
+		ProjectRoot:    projectRoot,
+		ModuleName:     moduleName,
+		GoModule:       manifest.Project.GoModule,
+		ProjectName:    manifest.Project.Name,
+		WiredModules:   manifest.WiredOrProvided(),
+		WiredProviders: manifest.Providers,
+		NoServer:       !manifest.ServesHTTP(),
+		EnvTarget:      cmp.Or(addEnvTarget, manifest.Project.EnvTarget),
+		Provider:       cmp.Or(addProvider, addStorage),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range result.GoDeps {
+		actions = append(actions, "go get "+dep)
+	}
+	for _, b := range result.ActivatedBridges {
+		actions = append(actions, "activate bridge "+strings.ReplaceAll(b, "+", " + "))
+	}
+	if !skipTidy {
+		actions = append(actions, "go mod tidy")
+	}
+	if result.Provider != "" {
+		actions = append(actions, fmt.Sprintf("record %s (%s provider) in %s", moduleName, result.Provider, config.ManifestoFile))
+	} else {
+		actions = append(actions, fmt.Sprintf("record %s in %s", moduleName, config.ManifestoFile))
+	}
+
+	return reportPreview(preview, actions)
+}
+
+// checkWireModule reports whether wiring moduleName would change the
+// project. Unlike a dry run it also runs for a wired module, to catch
+// injections that were since removed, but not for one the profile
+// provides.
+func checkWireModule(projectRoot string, manifest *config.Manifest, moduleName string) error {
+	// The profile's templates set the module up; there is nothing to inject.
+	if profile, err := manifest.Profile(); err == nil && profile.Provides(moduleName) {
+		ui.StepInfo(fmt.Sprintf("%s is already wired", moduleName))
+		return nil
+	}
+	actions, err := downloadActions(manifest, moduleName)
+	if err != nil {
+		return err
+	}
+	preview, result, err := scaffold.PreviewWire(scaffold.WireOptions{
+		ProjectRoot:    projectRoot,
+		ModuleName:     moduleName,
+		GoModule:       manifest.Project.GoModule,
+		ProjectName:    manifest.Project.Name,
+		WiredModules:   manifest.WiredOrProvided(),
+		WiredProviders: manifest.Providers,
+		NoServer:       !manifest.ServesHTTP(),
+		EnvTarget:      cmp.Or(addEnvTarget, manifest.Project.EnvTarget),
+		Provider:       cmp.Or(addProvider, addStorage, manifest.Providers[moduleName]),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range missingGoDeps(projectRoot, result.GoDeps) {
+		actions = append(actions, "go get "+dep)
+	}
+	if !manifest.IsWired(moduleName) {
+		actions = append(actions, fmt.Sprintf("record %s in %s", moduleName, config.ManifestoFile))
+	}
+	return reportCheck(preview, actions)
+}
+
+// downloadActions lists the source moduleName needs that the project
+// doesn't have yet.
+func downloadActions(manifest *config.Manifest, moduleName string) ([]string, error) {
+	modules, err := config.ResolveDeps(config.WireableModuleRegistry[moduleName].RequiredModules)
+	if err != nil {
+		return nil, err
+	}
+	var actions []string
+	for _, name := range modules {
+		if _, ok := manifest.Modules[name]; ok {
+			continue
+		}
+		for _, p := range config.ModuleRegistry[name].Dirs() {
+			actions = append(actions, fmt.Sprintf("download %s/", p))
+		}
+	}
+	return actions, nil
+}
+
+func runAddDomain(cmd *cobra.Command, projectRoot string, manifest *config.Manifest, domainPath string) error {
+	data, tracked, err := resolveDomainData(cmd, projectRoot, manifest, domainPath, addDomainF)
+	if err != nil {
+		return err
+	}
+	repo := data.Repo
+
+	if addCheck {
+		preview, err := scaffold.PreviewDomain(projectRoot, data)
+		if err != nil {
+			return err
+		}
+		var actions []string
+		for _, dep := range missingGoDeps(projectRoot, data.GoDeps()) {
+			actions = append(actions, "go get "+dep)
+		}
+		if !tracked {
+			actions = append(actions, fmt.Sprintf("record %s in manifesto.yaml", domainPath))
+		}
+		return reportCheck(preview, actions)
+	}
+
+	if addDryRun {
+		preview, err := scaffold.PreviewDomain(projectRoot, data)
+		if err != nil {
+			return err
+		}
+		var actions []string
+		for _, dep := range data.GoDeps() {
+			actions = append(actions, "go get "+dep)
+		}
+		if !skipTidy {
+			actions = append(actions, "go mod tidy")
+		}
+		actions = append(actions, fmt.Sprintf("record %s in manifesto.yaml", domainPath))
+		return reportPreview(preview, actions)
+	}
 
 	fmt.Println()
 	spin := ui.NewSpinner(fmt.Sprintf("Scaffolding %s...", data.EntityName))
@@ -124,6 +832,106 @@ func runAddDomain(projectRoot string, manifest *config.Manifest, domainPath stri
 	}
 	spin.Stop(true)
 
-	ui.PrintAddSuccess(data.EntityName, domainPath, data.PackageName, data.TableName)
+	// Only an override of the project's convention is recorded, and only
+	// a transport other than the default.
+	naming := data.Naming
+	if project, err := manifest.Naming(); err == nil && project.Name == naming {
+		naming = ""
+	}
+	transport := data.Transport
+	if transport == scaffold.TransportHTTP {
+		transport = ""
+	}
+	manifest.SetDomain(config.DomainConfig{
+		Path:           domainPath,
+		ContainerAlias: data.ContainerAlias,
+		ContainerField: data.ContainerField,
+		Repo:           repo.Name,
+		Fields:         data.FieldsSpec(),
+		WithPolicy:     data.WithPolicy,
+		WithEvents:     data.WithEvents,
+		WithUploads:    data.WithUploads,
+		NoTests:        !data.WithTests,
+		NoMocks:        !data.WithMocks,
+		Public:         data.Public,
+		Group:          data.Group,
+		Layers:         data.Layers,
+		Deps:           data.DepsSpec(),
+		Kind:           data.Kind,
+		Transport:      transport,
+		Naming:         naming,
+		Table:          data.TableName,
+	})
+	if err := manifest.Save(projectRoot); err != nil {
+		return fmt.Errorf("save manifesto.yaml: %w", err)
+	}
+
+	// The domain is in place; a failed go get or tidy only leaves a
+	// manual step.
+	var depsProblem string
+	var depsGuidance, deferred []string
+	if goDeps := data.GoDeps(); len(goDeps) > 0 {
+		if err := toolchain.Check(toolchain.RequiredVersion(projectRoot)); err != nil {
+			depsProblem, depsGuidance = err.Error(), toolchain.Guidance(err)
+		} else if err := scaffold.InstallGoDeps(projectRoot, goDeps); err != nil {
+			depsProblem = err.Error()
+		}
+		if depsProblem != "" {
+			for _, dep := range goDeps {
+				deferred = append(deferred, "go get "+dep)
+			}
+		}
+	}
+	if !skipTidy {
+		if depsProblem == "" && data.HasGRPC() && !data.ProtoGenerated(projectRoot) {
+			// Tidy can't resolve the package make proto generates.
+			depsProblem = fmt.Sprintf("%s has no generated Go code yet, so go mod tidy can't run", data.ProtoFile())
+			deferred = append(deferred, "make proto")
+		}
+		if depsProblem == "" {
+			if err := tidyProject(projectRoot, nil); err != nil {
+				depsProblem, depsGuidance = err.Error(), toolchain.Guidance(err)
+			}
+		}
+		if depsProblem != "" {
+			deferred = append(deferred, "go mod tidy")
+		}
+	}
+
+	injected := data.Generates(scaffold.DomainLayerContainer)
+	if injected && data.ContainerAlias != data.ContainerPkg {
+		ui.StepInfo(fmt.Sprintf("Imported as %s (c.%s) in cmd/container.go to avoid a name collision",
+			data.ContainerAlias, data.ContainerField))
+	}
+	for _, o := range scaffold.DomainTemplateOverrides(projectRoot, data) {
+		ui.StepInfo(fmt.Sprintf("Rendered %s from %s", o.Template, o.Path))
+	}
+
+	var files []ui.FileDisplay
+	for _, f := range scaffold.DomainFiles(data) {
+		files = append(files, ui.FileDisplay{Path: f.Path, Description: f.Description})
+	}
+
+	var uploadsTable string
+	if data.WithUploads {
+		uploadsTable = data.AttachmentsTable()
+	}
+	ui.PrintAddSuccess(ui.AddSummary{
+		EntityName: data.EntityName,
+		TableName:  data.TableName,
+		Files:      files,
+		Migration:  repo.Migration && data.Generates(scaffold.DomainLayerInfra),
+		Columns:    data.MigrationColumns(),
+		Uploads:    uploadsTable,
+		UploadsKey: data.SnakeName + "_id",
+		Injected:   injected,
+		Routes:     injected && data.HasAPI(),
+		RouteGroup: data.RouteGroup(),
+		GRPC:       injected && data.HasGRPC(),
+	})
+	if depsProblem != "" {
+		ui.PrintDeferred(depsProblem, depsGuidance, projectRoot, deferred)
+	}
+	ui.PrintChecklist(followUps(projectRoot, manifest, domainPath), scaffold.TodoFile)
 	return nil
 }
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+)
+
+// initShop inits a quick project named shop from the fake manifesto under
+// a temporary directory and returns its root.
+func initShop(t *testing.T) string {
+	t.Helper()
+	checkout, err := filepath.Abs(filepath.Join("..", "scaffold", "testdata", "manifesto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return initShopFrom(t, checkout)
+}
+
+// initShopFrom is initShop from the manifesto checkout at checkout.
+func initShopFrom(t *testing.T, checkout string, args ...string) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(remote.DefaultRefEnv, "")
+	dir := t.TempDir()
+	args = append([]string{"init", "shop", "--module", "example.com/shop", "--profile", "quick", "--local", checkout, "--skip-tidy"}, args...)
+	if err := runCLI(t, dir, closedStdin(t, false), args...); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "shop")
+}
+
+// copyManifesto returns a copy of the fake manifesto a test can change.
+func copyManifesto(t *testing.T) string {
+	t.Helper()
+	checkout := t.TempDir()
+	if err := os.CopyFS(checkout, os.DirFS(filepath.Join("..", "scaffold", "testdata", "manifesto"))); err != nil {
+		t.Fatal(err)
+	}
+	return checkout
+}
+
+// snapshotFiles returns the content of the files under root, by path.
+func snapshotFiles(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestRewire wires jobx, drops it from wired_modules by hand, as an unwire
+// that leaves the code behind would, and wires it again: the code must not
+// be injected twice, and jobx must be listed once.
+func TestRewire(t *testing.T) {
+	root := initShop(t)
+	if err := runCLI(t, root, closedStdin(t, false), "add", "jobx", "--skip-tidy"); err != nil {
+		t.Fatal(err)
+	}
+	wired := snapshotFiles(t, root)
+	if !strings.Contains(wired["cmd/container.go"], "jobx") {
+		t.Fatal("add jobx didn't wire jobx into cmd/container.go")
+	}
+
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countOf(manifest.WiredModules, "jobx"); n != 1 {
+		t.Fatalf("jobx wired %d times: %v", n, manifest.WiredModules)
+	}
+	manifest.WiredModules = slices.DeleteFunc(manifest.WiredModules, func(m string) bool { return m == "jobx" })
+	if err := manifest.Save(root); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := runCLI(t, root, closedStdin(t, false), "add", "jobx", "--skip-tidy"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rewired := snapshotFiles(t, root)
+	for path, data := range rewired {
+		if path == config.ManifestoFile || path == config.LockFile {
+			continue
+		}
+		if data != wired[path] {
+			t.Errorf("re-wiring jobx changed %s", path)
+		}
+	}
+
+	manifest, err = config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countOf(manifest.WiredModules, "jobx"); n != 1 {
+		t.Errorf("jobx wired %d times after re-wiring: %v", n, manifest.WiredModules)
+	}
+}
+
+// TestLoadDedupesWiredModules checks a manifest listing a module twice,
+// as older versions could write, loads with it once.
+func TestLoadDedupesWiredModules(t *testing.T) {
+	root := initShop(t)
+	path := filepath.Join(root, config.ManifestoFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "wired_modules:\n", "wired_modules:\n    - jobx\n    - jobx\n", 1))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countOf(manifest.WiredModules, "jobx"); n != 1 {
+		t.Errorf("jobx listed %d times: %v", n, manifest.WiredModules)
+	}
+	if !slices.ContainsFunc(manifest.Warnings, func(w string) bool { return strings.Contains(w, `"jobx" is listed twice`) }) {
+		t.Errorf("no warning about jobx listed twice: %v", manifest.Warnings)
+	}
+	manifest.AddWiredModule("jobx")
+	if n := countOf(manifest.WiredModules, "jobx"); n != 1 {
+		t.Errorf("AddWiredModule listed jobx %d times: %v", n, manifest.WiredModules)
+	}
+}
+
+func countOf(list []string, s string) int {
+	n := 0
+	for _, v := range list {
+		if v == s {
+			n++
+		}
+	}
+	return n
+}
+
+// TestCheckProfileProvided runs add --check, --dry-run and a real add for
+// redis in a full project, whose templates set redis up: all three must
+// find it wired and leave the project as it is.
+func TestCheckProfileProvided(t *testing.T) {
+	checkout, err := filepath.Abs(filepath.Join("..", "scaffold", "testdata", "manifesto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := initShopFrom(t, checkout, "--profile", "full")
+	before := snapshotFiles(t, root)
+
+	if err := runCLI(t, root, closedStdin(t, false), "add", "redis", "--check"); err != nil {
+		t.Errorf("add redis --check: %v", err)
+	}
+	if err := runCLI(t, root, closedStdin(t, false), "add", "redis", "--dry-run"); err == nil || !strings.Contains(err.Error(), "redis is already wired") {
+		t.Errorf("add redis --dry-run: got %v, want redis is already wired", err)
+	}
+	if err := runCLI(t, root, closedStdin(t, false), "add", "redis", "--skip-tidy"); err != nil {
+		t.Errorf("add redis: %v", err)
+	}
+	if after := snapshotFiles(t, root); !maps.Equal(before, after) {
+		t.Error("adding redis to a full project changed it")
+	}
+}
+
+// TestInstallSource checks the deprecated install forwards --source to
+// add, which validates it, and refuses it with --local as add does.
+func TestInstallSource(t *testing.T) {
+	root := initShop(t)
+	err := runCLI(t, root, closedStdin(t, false), "install", "asyncx", "--source", "acme/manifesto.git")
+	if err == nil || !strings.Contains(err.Error(), `invalid repo "acme/manifesto.git"`) {
+		t.Errorf("install --source acme/manifesto.git: got %v, want the repo rejected", err)
+	}
+	err = runCLI(t, root, closedStdin(t, false), "install", "asyncx", "--source", "acme/manifesto", "--local", root)
+	if err == nil || !strings.Contains(err.Error(), "[local source]") {
+		t.Errorf("install --source with --local: got %v, want them refused together", err)
+	}
+}
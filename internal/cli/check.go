@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+)
+
+// Exit codes of --check, documented in the add help and the README.
+const (
+	checkDrift  = 1 // The command would change the project
+	checkFailed = 2 // The check itself failed, or an injection can't apply
+)
+
+// reportCheck prints what a --check run found pending and returns an
+// *exitError carrying the exit code; nil means the project is up to date.
+func reportCheck(preview *scaffold.Preview, actions []string) error {
+	var newFiles, modified []string
+	for _, c := range preview.Changes() {
+		if c.Created {
+			newFiles = append(newFiles, c.Path)
+		} else {
+			modified = append(modified, c.Path)
+		}
+	}
+	skipped := preview.Skipped()
+
+	if len(skipped) > 0 {
+		ui.PrintPending(newFiles, modified, actions, skipped)
+		return &exitError{code: checkFailed, err: fmt.Errorf("check: %d change(s) would not apply cleanly; run 'manifesto doctor'", len(skipped))}
+	}
+	if n := len(newFiles) + len(modified) + len(actions); n > 0 {
+		ui.PrintPending(newFiles, modified, actions, nil)
+		return &exitError{code: checkDrift, err: fmt.Errorf("check: %d change(s) pending", n)}
+	}
+	ui.StepDone("Up to date")
+	return nil
+}
+
+// checkExit gives err the --check exit code for a failed check, keeping
+// the code of a drift already reported.
+func checkExit(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*exitError); ok {
+		return err
+	}
+	return &exitError{code: checkFailed, err: err}
+}
+
+// missingGoDeps returns the deps not yet required in the project's go.mod.
+func missingGoDeps(projectRoot string, deps []string) []string {
+	data, _ := os.ReadFile(filepath.Join(projectRoot, "go.mod"))
+	var missing []string
+	for _, dep := range deps {
+		if !strings.Contains(string(data), dep+" ") {
+			missing = append(missing, dep)
+		}
+	}
+	return missing
+}
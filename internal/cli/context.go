@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context <domain-path>",
+	Short: "Print the resolved template data for a domain as JSON",
+	Long: `Print the data manifesto uses to scaffold a domain, for external code
+generators. Tracked domains use the options recorded in manifesto.yaml;
+any other path is resolved as 'manifesto add' would with the same flags.
+
+  manifesto context pkg/billing/invoice
+  manifesto context pkg/billing/invoice --fields "amount:decimal,paid:bool"
+
+Render your own template against the same data instead of printing JSON:
+
+  manifesto context pkg/billing/invoice --template client.ts.tmpl
+
+Templates see the JSON structure by Go field name ({{ .Domain.Entity }},
+{{ range .Domain.Fields }}{{ .GoName }}{{ end }}) and can use the helpers
+pascal, camel, snake, kebab, upperSnake, plural and goName.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runContext,
+}
+
+var (
+	contextDomainF  domainFlags
+	contextTemplate string
+)
+
+func init() {
+	contextDomainF.register(contextCmd)
+	contextCmd.Flags().StringVar(&contextTemplate, "template", "", "Render this template file against the context instead of printing JSON")
+}
+
+func runContext(cmd *cobra.Command, args []string) error {
+	domainPath := args[0]
+
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	data, tracked, err := resolveDomainData(cmd, projectRoot, manifest, domainPath, contextDomainF)
+	if err != nil {
+		return err
+	}
+	ctx := data.Context(manifest.Project.Name, tracked)
+	ctx.FollowUps = scaffold.CheckFollowUps(projectRoot, domainPath, data.FollowUps())
+
+	if contextTemplate != "" {
+		text, err := os.ReadFile(contextTemplate)
+		if err != nil {
+			return fmt.Errorf("read template: %w", err)
+		}
+		if err := scaffold.ExecuteUserTemplate(os.Stdout, filepath.Base(contextTemplate), string(text), ctx); err != nil {
+			return fmt.Errorf("render %s: %w", contextTemplate, err)
+		}
+		return nil
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ctx)
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// runAddCron scaffolds the cron job named in args, the rest of
+// `manifesto add cron <name>`.
+func runAddCron(cmd *cobra.Command, projectRoot string, manifest *config.Manifest, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("add cron takes one job name, e.g. manifesto add cron nightly-report")
+	}
+	for _, flag := range []string{"ref", "check", "domain"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s doesn't apply to cron jobs", flag)
+		}
+	}
+	if !manifest.IsWired("cronx") {
+		return fmt.Errorf("cron jobs run on the cronx scheduler; run 'manifesto add cronx' first")
+	}
+
+	data, err := scaffold.NewCronJobData(manifest.Project.GoModule, args[0], addSchedule)
+	if err != nil {
+		return err
+	}
+
+	if addDryRun {
+		preview, err := scaffold.PreviewCronJob(projectRoot, data)
+		if err != nil {
+			return err
+		}
+		return reportPreview(preview, nil)
+	}
+
+	if err := scaffold.GenerateCronJob(projectRoot, data); err != nil {
+		return err
+	}
+	ui.PrintCronSuccess(data.Name, data.File(), data.Schedule)
+	return nil
+}
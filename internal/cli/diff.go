@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/diff"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <module>",
+	Short: "Show how an installed module differs from manifesto",
+	Long: `Compare an installed module's files with manifesto's, read from the
+manifesto archive (cached like any download) with imports rewritten as
+when it was fetched. Lines starting with - are the project's and + are
+manifesto's.
+
+By default the module is compared with the version it was installed
+from, which shows your local changes; --ref shows what moving to another
+version would change.
+
+  manifesto diff errx                   # local drift
+  manifesto diff errx --ref v1.5.0      # what upgrade --ref v1.5.0 brings
+  manifesto diff errx --stat            # one line per file
+
+diff exits 0 when the module matches, 1 when files differ, and 2 when the
+comparison itself fails, so it can be used as a check.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDiff,
+}
+
+var (
+	diffRef  string
+	diffStat bool
+)
+
+func init() {
+	diffCmd.Flags().StringVar(&diffRef, "ref", "", "Manifesto version to compare with (default: the one the module was installed from)")
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "List the files that differ with their added and removed lines instead of the diff")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	return checkExit(moduleDiff(args[0]))
+}
+
+func moduleDiff(name string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+	if diffRef != "" {
+		if err := remote.ValidateRef(diffRef, remote.RefType(manifest.Project.RefType)); err != nil {
+			return err
+		}
+	}
+
+	result, err := scaffold.DiffModule(scaffold.DiffOptions{
+		ProjectRoot: projectRoot,
+		ModuleName:  name,
+		Ref:         diffRef,
+	})
+	if err != nil {
+		return err
+	}
+	theirs := "manifesto@" + result.Ref
+	if len(result.Files) == 0 {
+		ui.StepDone(fmt.Sprintf("%s matches %s", name, theirs))
+		return nil
+	}
+
+	if diffStat {
+		stats := make([]ui.DiffStat, 0, len(result.Files))
+		for _, f := range result.Files {
+			s := ui.DiffStat{Path: f.Path, Edited: f.Edited}
+			s.Added, s.Removed = diff.Stat(string(f.Ours), string(f.Theirs))
+			switch {
+			case f.Ours == nil:
+				s.Side = "theirs"
+			case f.Theirs == nil:
+				s.Side = "ours"
+			}
+			stats = append(stats, s)
+		}
+		ui.PrintDiffStat(stats, theirs)
+	} else {
+		fmt.Println()
+		for _, f := range result.Files {
+			oldName, newName := "a/"+f.Path, "b/"+f.Path
+			if f.Ours == nil {
+				oldName = "/dev/null"
+			}
+			if f.Theirs == nil {
+				newName = "/dev/null"
+			}
+			ui.PrintDiff(diff.Unified(oldName, newName, string(f.Ours), string(f.Theirs)))
+			fmt.Println()
+		}
+	}
+	return &exitError{code: checkDrift, err: fmt.Errorf("diff: %s differs from %s in %d file(s)", name, theirs, len(result.Files))}
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/fatih/color"
+)
+
+// exitCode returns the status the CLI exits with for err.
+func exitCode(err error) int {
+	var exit *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return exit.code
+	default:
+		return 1
+	}
+}
+
+// captureStdout runs fn and returns what it printed to stdout, colored or
+// not.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, oldColor := os.Stdout, color.Output
+	os.Stdout, color.Output = w, w
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	defer func() { os.Stdout, color.Output = old, oldColor }()
+	fn()
+	w.Close()
+	return <-out
+}
+
+// TestDiffExitCodes runs diff on a module that matches, one that drifted
+// and ones that can't be compared.
+func TestDiffExitCodes(t *testing.T) {
+	root := initShop(t)
+	diff := func(args ...string) (int, string) {
+		var err error
+		out := captureStdout(t, func() {
+			err = runCLI(t, root, closedStdin(t, false), append([]string{"diff"}, args...)...)
+		})
+		return exitCode(err), out
+	}
+
+	if code, out := diff("errx"); code != 0 {
+		t.Fatalf("diff of an unchanged errx exited %d:\n%s", code, out)
+	}
+
+	errxGo := filepath.Join(root, "pkg", "errx", "errx.go")
+	data, err := os.ReadFile(errxGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), "package errx\n", "package errx\n\n// Patched locally.\n", 1)
+	if err := os.WriteFile(errxGo, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "errx", "extra.go"), []byte("package errx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out := diff("errx")
+	if code != checkDrift {
+		t.Fatalf("diff of a patched errx exited %d, want %d:\n%s", code, checkDrift, out)
+	}
+	if !strings.Contains(out, "-// Patched locally.") {
+		t.Errorf("diff doesn't show the patched line:\n%s", out)
+	}
+
+	code, out = diff("errx", "--stat")
+	if code != checkDrift {
+		t.Fatalf("diff --stat of a patched errx exited %d, want %d:\n%s", code, checkDrift, out)
+	}
+	for _, want := range []string{
+		"pkg/errx/errx.go  +0 -2  edited locally",
+		"pkg/errx/extra.go  +0 -1  only in the project",
+		"2 file(s) differ, 0 line(s) added, 3 removed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff --stat lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Patched locally") {
+		t.Errorf("diff --stat prints the diff:\n%s", out)
+	}
+
+	for _, name := range []string{"nosuch", "iam"} {
+		if code, out := diff(name); code != checkFailed {
+			t.Errorf("diff %s exited %d, want %d:\n%s", name, code, checkFailed, out)
+		}
+	}
+}
+
+// TestDiffInstalledMappings compares a module installed from a moved
+// path: both sides must be keyed by where the project keeps it, so an
+// untouched module matches and an upstream change shows at that path.
+func TestDiffInstalledMappings(t *testing.T) {
+	checkout := copyManifesto(t)
+	root := initShopFrom(t, checkout)
+
+	moved := filepath.Join(checkout, "libs", "errx", "pkg", "errx")
+	if err := os.MkdirAll(filepath.Dir(moved), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(checkout, "pkg", "errx"), moved); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errx := manifest.Modules["errx"]
+	errx.Mappings = []config.PathMapping{{SrcPath: "libs/errx/pkg/errx", DestPath: "pkg/errx"}}
+	manifest.Modules["errx"] = errx
+	if err := manifest.Save(root); err != nil {
+		t.Fatal(err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = exitCode(runCLI(t, root, closedStdin(t, false), "diff", "errx", "--stat"))
+	})
+	if code != 0 {
+		t.Fatalf("diff of an untouched errx installed from libs/errx exited %d:\n%s", code, out)
+	}
+
+	upstream := filepath.Join(moved, "errx.go")
+	data, err := os.ReadFile(upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(upstream, append(data, "\n// Changed upstream.\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out = captureStdout(t, func() {
+		code = exitCode(runCLI(t, root, closedStdin(t, false), "diff", "errx", "--stat"))
+	})
+	if code != checkDrift || !strings.Contains(out, "pkg/errx/errx.go  +2 -0") || strings.Contains(out, "libs/") {
+		t.Errorf("diff after an upstream change exited %d, want %d with pkg/errx/errx.go:\n%s", code, checkDrift, out)
+	}
+}
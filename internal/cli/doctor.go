@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the project for missing markers and broken wiring",
+	Long: `Check that manifesto can still inject code into the project:
+
+  • manifesto.yaml parses
+  • the project doesn't track a moving branch (a warning only)
+  • every marker comment is present in cmd/container.go, cmd/server.go,
+    pkg/config/config.go and the Makefile
+  • every wired module's code is still in cmd/container.go
+  • every tracked domain is still imported and its routes registered
+  • every installed module's directories exist
+  • no fetched module file was edited since, according to manifesto.lock
+
+It also lists the follow-ups left by wired modules and tracked domains,
+ticking those it can verify, and refreshes .manifesto/TODO.md.
+
+Markers deleted by hand can be put back with --fix:
+
+  manifesto doctor --fix`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var doctorFix bool
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Re-insert missing marker comments at their usual place")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	if doctorFix {
+		profile, _ := config.LookupProfile("")
+		if manifest, err := config.LoadManifest(projectRoot); err == nil {
+			if p, err := manifest.Profile(); err == nil {
+				profile = p
+			}
+		}
+		fixed, unplaced, err := scaffold.FixMarkers(projectRoot, profile)
+		if err != nil {
+			return fmt.Errorf("fix markers: %w", err)
+		}
+		for _, m := range fixed {
+			ui.StepDone("Restored " + m)
+		}
+		for _, m := range unplaced {
+			ui.StepWarn("Couldn't place " + m + "; add it by hand")
+		}
+	}
+
+	checks := scaffold.Diagnose(projectRoot)
+
+	failed, fixable, warned := 0, 0, 0
+	group := ""
+	for _, c := range checks {
+		if c.Group != group {
+			group = c.Group
+			ui.PrintSection(group)
+		}
+		if !c.OK && c.Warn {
+			ui.PrintCheckWarn(c.Name, c.Detail)
+			warned++
+			continue
+		}
+		ui.PrintCheck(c.OK, c.Name, c.Detail)
+		if !c.OK {
+			failed++
+			if c.Fixable {
+				fixable++
+			}
+		}
+	}
+	ui.PrintSection("")
+
+	if manifest, err := config.LoadManifest(projectRoot); err == nil {
+		ui.PrintChecklist(followUps(projectRoot, manifest), scaffold.TodoFile)
+	}
+
+	if failed == 0 {
+		if warned > 0 {
+			ui.StepWarn(fmt.Sprintf("%d of %d checks passed; %d warning(s)", len(checks)-warned, len(checks), warned))
+			return nil
+		}
+		ui.StepDone(fmt.Sprintf("All %d checks passed", len(checks)))
+		return nil
+	}
+	if fixable > 0 && !doctorFix {
+		ui.StepInfo(fmt.Sprintf("%d of these can be repaired with 'manifesto doctor --fix'", fixable))
+	}
+	return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+}
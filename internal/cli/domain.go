@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// domainFlags are the domain options shared by add and context.
+type domainFlags struct {
+	withPolicy  bool
+	withEvents  bool
+	withUploads bool
+	fields      string
+	repo        string
+	noTests     bool
+	noMocks     bool
+	public      bool
+	group       string
+	layers      string
+	deps        string
+	kind        string
+	transport   string
+	naming      string
+	table       string
+	plural      string
+}
+
+func (f *domainFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.withPolicy, "with-policy", false, "Generate an authorization policy and enforce it in the service layer (domains only)")
+	cmd.Flags().BoolVar(&f.withEvents, "with-events", false, "Generate domain events and publish them from the service layer, on jobx when it is wired (domains only)")
+	cmd.Flags().BoolVar(&f.withUploads, "with-uploads", false, "Generate attachments with upload and download handlers on fsx, which must be wired (domains only)")
+	cmd.Flags().StringVar(&f.fields, "fields", "", "Entity fields as name:type pairs (e.g. amount:decimal,paid:bool)")
+	cmd.Flags().StringVar(&f.repo, "repo", "",
+		fmt.Sprintf("Repository backend for domains (%s; default %s, or memory in a quick project without migrations)", strings.Join(scaffold.RepoBackendNames(), ", "), scaffold.DefaultRepoBackend))
+	cmd.Flags().StringVar(&f.kind, "kind", "",
+		fmt.Sprintf("Domain kind (%s; default from the project profile)", strings.Join(scaffold.DomainKinds, ", ")))
+	cmd.Flags().StringVar(&f.transport, "transport", scaffold.TransportHTTP,
+		fmt.Sprintf("How an http-kind domain is exposed (%s); grpc needs a project created with init --grpc", strings.Join(scaffold.Transports, ", ")))
+	cmd.Flags().BoolVar(&f.noTests, "no-tests", false, "Skip the fake repository and generated service/handler tests (domains only)")
+	cmd.Flags().BoolVar(&f.noMocks, "no-mocks", false, "Skip the configurable repository mock in <pkg>/mocks (domains only)")
+	cmd.Flags().BoolVar(&f.public, "public", false, "Register the domain's routes outside the protected group, without auth (domains only)")
+	cmd.Flags().StringVar(&f.group, "group", "", "Route group variable in cmd/server.go to register the domain on, e.g. admin (default protected)")
+	cmd.Flags().StringVar(&f.layers, "layers", "",
+		fmt.Sprintf("Layers to generate, comma-separated (%s; default all)", strings.Join(scaffold.DomainLayers, ", ")))
+	cmd.Flags().StringVar(&f.deps, "deps", "",
+		fmt.Sprintf("Root container dependencies the module's Deps take, comma-separated (%s); each must be wired", strings.Join(scaffold.DomainDepNames(), ", ")))
+	cmd.Flags().StringVar(&f.naming, "naming", "",
+		fmt.Sprintf("Package naming convention for this domain, overriding the project's (%s)", strings.Join(config.NamingNames(), ", ")))
+	cmd.Flags().StringVar(&f.table, "table", "", "Table name for the domain's repository and routes (default: plural of the package name)")
+	cmd.Flags().StringVar(&f.plural, "plural", "", "Plural of the package name, for when the default is wrong (e.g. people); names the table and routes")
+	cmd.MarkFlagsMutuallyExclusive("table", "plural")
+	cmd.MarkFlagsMutuallyExclusive("public", "group")
+}
+
+// resolveDomainData builds the template data for domainPath. Options recorded
+// in the manifest for a tracked domain apply unless overridden on the command
+// line; its naming convention can't be, since that would leave the packages
+// it has behind. The second result reports whether the domain is tracked.
+func resolveDomainData(cmd *cobra.Command, projectRoot string, manifest *config.Manifest, domainPath string, f domainFlags) (scaffold.DomainData, bool, error) {
+	entry, tracked := manifest.Domain(domainPath)
+	if tracked {
+		if !cmd.Flags().Changed("fields") {
+			f.fields = entry.Fields
+		}
+		if !cmd.Flags().Changed("repo") {
+			// Domains added before the backend was recorded are postgres.
+			f.repo = cmp.Or(entry.Repo, scaffold.DefaultRepoBackend)
+		}
+		if !cmd.Flags().Changed("with-policy") {
+			f.withPolicy = entry.WithPolicy
+		}
+		if !cmd.Flags().Changed("with-events") {
+			f.withEvents = entry.WithEvents
+		}
+		if !cmd.Flags().Changed("with-uploads") {
+			f.withUploads = entry.WithUploads
+		}
+		if !cmd.Flags().Changed("no-tests") {
+			f.noTests = entry.NoTests
+		}
+		if !cmd.Flags().Changed("no-mocks") {
+			f.noMocks = entry.NoMocks
+		}
+		if !cmd.Flags().Changed("public") && !cmd.Flags().Changed("group") {
+			f.public = entry.Public
+			f.group = entry.Group
+		}
+		if !cmd.Flags().Changed("layers") {
+			f.layers = strings.Join(entry.Layers, ",")
+		}
+		if !cmd.Flags().Changed("deps") {
+			f.deps = strings.Join(entry.Deps, ",")
+		}
+		if !cmd.Flags().Changed("kind") {
+			f.kind = entry.Kind
+		}
+		if !cmd.Flags().Changed("transport") {
+			f.transport = cmp.Or(entry.Transport, scaffold.TransportHTTP)
+		}
+		if !cmd.Flags().Changed("table") && !cmd.Flags().Changed("plural") {
+			f.table = scaffold.TrackedTable(entry)
+		}
+	}
+	table := cmp.Or(f.table, f.plural)
+	if table != "" {
+		if err := scaffold.CheckTableName(table); err != nil {
+			return scaffold.DomainData{}, false, err
+		}
+	}
+	if f.kind == "" {
+		profile, err := manifest.Profile()
+		if err != nil {
+			return scaffold.DomainData{}, false, err
+		}
+		f.kind = profile.DomainKind
+	}
+	if !slices.Contains(scaffold.DomainKinds, f.kind) {
+		return scaffold.DomainData{}, false, fmt.Errorf("unknown kind: '%s'. Available: %s", f.kind, strings.Join(scaffold.DomainKinds, ", "))
+	}
+	if f.group == scaffold.ProtectedGroup {
+		f.group = ""
+	}
+	if f.group != "" {
+		if err := scaffold.CheckRouteGroup(f.group); err != nil {
+			return scaffold.DomainData{}, false, err
+		}
+	}
+	if !slices.Contains(scaffold.Transports, f.transport) {
+		return scaffold.DomainData{}, false, fmt.Errorf("unknown transport: '%s'. Available: %s", f.transport, strings.Join(scaffold.Transports, ", "))
+	}
+	if f.transport == scaffold.TransportGRPC {
+		if f.kind == scaffold.DomainKindWorker {
+			return scaffold.DomainData{}, false, fmt.Errorf("--transport grpc needs an http-kind domain; %s domains have no API", f.kind)
+		}
+		if !manifest.Project.GRPC {
+			return scaffold.DomainData{}, false, fmt.Errorf("--transport grpc needs the gRPC server of a project created with init --grpc")
+		}
+	}
+
+	fields, err := scaffold.ParseFields(f.fields)
+	if err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+	layers, err := scaffold.ParseDomainLayers(f.layers)
+	if err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+	deps, err := scaffold.ParseDomainDeps(f.deps)
+	if err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+	for _, dep := range deps {
+		if !manifest.IsWired(dep.Module) {
+			return scaffold.DomainData{}, false, fmt.Errorf("--deps %s needs %s, which the project doesn't have wired; run manifesto add %s first", dep.Name, dep.Module, dep.Module)
+		}
+	}
+	if f.repo == "" {
+		f.repo = scaffold.ProjectRepoBackend(manifest)
+	}
+	repo, err := scaffold.LookupRepoBackend(f.repo)
+	if err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+
+	naming, err := manifest.DomainNaming(entry)
+	if err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+	if cmd.Flags().Changed("naming") {
+		override, err := config.LookupNaming(f.naming)
+		if err != nil {
+			return scaffold.DomainData{}, false, err
+		}
+		if tracked && override.Name != naming.Name {
+			return scaffold.DomainData{}, false, fmt.Errorf("%s was scaffolded with %s naming; --naming %s would leave its packages behind", domainPath, naming.Name, override.Name)
+		}
+		naming = override
+	}
+
+	// A tracked domain passed these checks when it was added.
+	if !tracked {
+		if err := scaffold.ValidateDomainPath(domainPath, naming); err != nil {
+			return scaffold.DomainData{}, false, err
+		}
+	}
+
+	data := scaffold.NewDomainData(manifest.Project.GoModule, domainPath).WithNaming(naming)
+	data.Fields = fields
+	data.Repo = repo
+	data.WithPolicy = f.withPolicy
+	data.WithEvents = f.withEvents
+	data.WithUploads = f.withUploads
+	data.WithTests = !f.noTests
+	data.WithMocks = !f.noMocks
+	data.Public = f.public
+	data.Group = f.group
+	data.Layers = layers
+	data.Deps = deps
+	data.Kind = f.kind
+	data.Transport = f.transport
+	data.HasIAM = manifest.IsWired("iam")
+	data.HasJobx = manifest.IsWired("jobx")
+	data.HasSwagger = manifest.IsWired("swagger")
+	if table != "" {
+		data.TableName = table
+	}
+	if err := data.CheckDeps(); err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+	if data.WithUploads && !manifest.IsWired("fsx") {
+		return scaffold.DomainData{}, false, fmt.Errorf("--with-uploads stores files through fsx, which the project doesn't have wired; run manifesto add fsx first")
+	}
+	if err := data.CheckUploads(); err != nil {
+		return scaffold.DomainData{}, false, err
+	}
+
+	if tracked {
+		data.ContainerAlias = entry.ContainerAlias
+		data.ContainerField = entry.ContainerField
+	} else {
+		data = scaffold.ResolveContainerNames(projectRoot, data)
+	}
+	return data, tracked, nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var domainsCmd = &cobra.Command{
+	Use:   "domains",
+	Short: "List the domains scaffolded in this project",
+	Long: `List the domains recorded in manifesto.yaml with their entity, repository
+backend and scaffold date, and flag any whose generated files were deleted.`,
+	Args: cobra.NoArgs,
+	RunE: runDomains,
+}
+
+func runDomains(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	var domains []ui.DomainDisplay
+	for _, d := range scaffold.TrackedDomains(projectRoot, manifest) {
+		repo := d.Repo
+		if repo == "" {
+			repo = scaffold.DefaultRepoBackend
+		}
+		created := ""
+		if !d.CreatedAt.IsZero() {
+			created = d.CreatedAt.Format("2006-01-02")
+		}
+		domains = append(domains, ui.DomainDisplay{
+			Path:    d.Path,
+			Entity:  d.Entity,
+			Repo:    repo,
+			Kind:    d.Kind,
+			Layers:  strings.Join(d.Layers, ","),
+			Created: created,
+			Missing: d.Missing,
+		})
+	}
+
+	ui.PrintDomains(domains)
+	return nil
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/diff"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+)
+
+// reportPreview prints the outcome of a dry run. It returns an error when
+// any injection would not apply cleanly, so the command exits non-zero.
+func reportPreview(preview *scaffold.Preview, actions []string) error {
+	var newFiles, diffs []string
+	for _, c := range preview.Changes() {
+		if c.Created {
+			newFiles = append(newFiles, c.Path)
+			continue
+		}
+		diffs = append(diffs, diff.Unified("a/"+c.Path, "b/"+c.Path, c.Old, c.New))
+	}
+
+	skipped := preview.Skipped()
+	ui.PrintDryRun(newFiles, diffs, actions, skipped)
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("dry run: %d change(s) would not apply cleanly", len(skipped))
+	}
+	return nil
+}
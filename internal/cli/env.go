@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Show the CLI, project and Go toolchain environment",
+	RunE:  runEnv,
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	ui.PrintSection("Manifesto")
+	ui.PrintField("cli version", Version)
+
+	required := ""
+	projectRoot, _ := findProjectRoot()
+	if manifest, err := config.LoadManifest(projectRoot); err == nil {
+		required = toolchain.RequiredVersion(projectRoot)
+		ui.PrintField("project", manifest.Project.Name)
+		ui.PrintField("root", projectRoot)
+		ui.PrintField("manifesto", manifest.Project.Version)
+		ui.PrintField("ref channel", string(scaffold.ProjectChannel(manifest)))
+		ui.PrintField("go directive", orNone(required))
+	} else {
+		ui.PrintField("project", "(not in a manifesto project)")
+	}
+
+	info := toolchain.Detect()
+	ui.PrintSection("Go toolchain")
+	ui.PrintField("path", orNone(info.Path))
+	ui.PrintField("version", orNone(info.Version))
+	ui.PrintField("GOTOOLCHAIN", orNone(info.GOTOOLCHAIN))
+
+	if err := toolchain.Check(required); err != nil {
+		ui.PrintField("status", ui.Red.Sprint("✗ "+err.Error()))
+		for _, g := range toolchain.Guidance(err) {
+			ui.PrintField("", g)
+		}
+	} else {
+		ui.PrintField("status", ui.Green.Sprint("✓ ok"))
+	}
+	ui.PrintSection("")
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
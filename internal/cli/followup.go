@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+)
+
+// followUps rewrites the project's follow-up checklist and returns its
+// items from sources, or every item when no source is given. A failed
+// write only costs the file, so it is a warning.
+func followUps(projectRoot string, manifest *config.Manifest, sources ...string) []ui.ChecklistItem {
+	items := scaffold.ProjectChecklist(projectRoot, manifest)
+	if err := scaffold.WriteTodo(projectRoot, items); err != nil {
+		ui.StepWarn(fmt.Sprintf("Couldn't write %s: %v", scaffold.TodoFile, err))
+	}
+
+	var shown []ui.ChecklistItem
+	for _, item := range items {
+		if len(sources) > 0 && !slices.Contains(sources, item.Source) {
+			continue
+		}
+		shown = append(shown, ui.ChecklistItem{Source: item.Source, Text: item.Text, Done: item.Done})
+	}
+	return shown
+}
+
+// projectFollowUps is followUps for a project just created in dir.
+func projectFollowUps(dir string) []ui.ChecklistItem {
+	manifest, err := config.LoadManifest(dir)
+	if err != nil {
+		return nil
+	}
+	return followUps(dir, manifest)
+}
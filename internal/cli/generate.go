@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate project files such as migrations",
+}
+
+var generateMigrationCmd = &cobra.Command{
+	Use:   "migration <name>",
+	Short: "Create the .up.sql and .down.sql stubs of a new migration",
+	Long: `Create migrations/<prefix>_<name>.up.sql and .down.sql, numbered after the
+existing migrations. Sequential prefixes (000001_) continue with the next
+number at the same width; timestamp prefixes (20240101120000_) take the
+current time. A directory without migrations starts at 000001.
+
+Needs the migrations module, which quick projects leave out until it is
+added with 'manifesto add migrations'.`,
+	Example: `  manifesto generate migration add_invoice_status`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runGenerateMigration,
+}
+
+func init() {
+	generateCmd.AddCommand(generateMigrationCmd)
+}
+
+func runGenerateMigration(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+	if _, ok := manifest.Modules["migrations"]; !ok {
+		return fmt.Errorf("this project has no %s/ module; run 'manifesto add migrations' first", scaffold.MigrationsDir)
+	}
+
+	files, err := scaffold.NewMigration(projectRoot, args[0], clock.Now())
+	if err != nil {
+		return err
+	}
+	ui.PrintMigrationSuccess(args[0], files)
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// assertGolden compares out with testdata/name.golden. The checkout
+// appears in refs as an absolute path, so it is written as $CHECKOUT.
+func assertGolden(t *testing.T, name, checkout, out string) {
+	t.Helper()
+	out = strings.ReplaceAll(out, checkout, "$CHECKOUT")
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(out), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if out != string(want) {
+		t.Errorf("output differs from %s:\n%s", golden, out)
+	}
+}
+
+// TestGoldenOutput checks what init, modules and a wiring print, which
+// must not change from run to run: the plan init lists, the libraries and
+// modules modules lists, and the files add reports.
+func TestGoldenOutput(t *testing.T) {
+	// The tests run from internal/cli; golden files are read relative to it.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout, err := filepath.Abs(filepath.Join("..", "scaffold", "testdata", "manifesto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root string
+	out := captureStdout(t, func() { root = initShopFrom(t, checkout, "--with", "jobx") })
+	t.Chdir(wd)
+	assertGolden(t, "init", checkout, out)
+
+	out = captureStdout(t, func() {
+		if err := runCLI(t, root, closedStdin(t, false), "modules"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Chdir(wd)
+	assertGolden(t, "modules", checkout, out)
+
+	out = captureStdout(t, func() {
+		if err := runCLI(t, root, closedStdin(t, false), "add", "cronx", "--skip-tidy"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Chdir(wd)
+	assertGolden(t, "wire", checkout, out)
+}
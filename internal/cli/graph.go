@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show what each module pulls in",
+	Long: `Show the dependency graph of the library and wireable modules: the
+libraries each depends on, the source a wireable module downloads, the
+modules it wires first, and its bridges, which only apply when the other
+module is wired too. Inside a project, the modules it has installed or
+wired are marked.
+
+  manifesto graph                              # ASCII tree
+  manifesto graph --format dot | dot -Tsvg > modules.svg
+
+graph fails if modules need each other in a loop, naming the loop.`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+var graphFormat string
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "tree", "Output format: tree or dot (Graphviz)")
+}
+
+// graphEdgeLabels are how the tree and DOT output name each kind of edge.
+var graphEdgeLabels = map[string]string{
+	config.EdgeDep:      "depends on",
+	config.EdgeDownload: "downloads",
+	config.EdgeWire:     "wires first",
+	config.EdgeBridge:   "bridges with",
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	if graphFormat != "tree" && graphFormat != "dot" {
+		return fmt.Errorf("unknown format: '%s'. Available: tree, dot", graphFormat)
+	}
+
+	graph := config.RegistryGraph()
+	projectRoot, _ := findProjectRoot()
+	manifest, _ := config.LoadManifest(projectRoot)
+	present := func(name string) bool {
+		if manifest == nil {
+			return false
+		}
+		_, installed := manifest.Modules[name]
+		return installed || manifest.IsWired(name)
+	}
+
+	if graphFormat == "dot" {
+		fmt.Print(graphDOT(graph, present))
+	} else {
+		printGraphTree(graph, present, manifest != nil)
+	}
+
+	if cycles := graph.Cycles(); len(cycles) > 0 {
+		for _, c := range cycles {
+			ui.StepWarn(c.Error())
+		}
+		return fmt.Errorf("%d dependency cycle(s) in the module registry", len(cycles))
+	}
+	return nil
+}
+
+// printGraphTree prints each module no other needs with what it pulls in
+// beneath it. Bridges are leaves: the module they name has its own tree.
+func printGraphTree(graph *config.ModuleGraph, present func(string) bool, inProject bool) {
+	ui.PrintSection("Modules")
+
+	var walk func(name, indent string, path []string)
+	walk = func(name, indent string, path []string) {
+		edges := groupEdges(graph.Nodes[name].Edges)
+		for i, e := range edges {
+			branch, next := "├── ", "│   "
+			if i == len(edges)-1 {
+				branch, next = "└── ", "    "
+			}
+			line := fmt.Sprintf("    %s%s%s  %s", indent, branch, graphNodeLabel(e.to, present), ui.Dim.Sprint(strings.Join(e.labels, ", ")))
+			switch {
+			case e.bridgeOnly:
+				fmt.Println(line)
+			case config.HasModule(path, e.to):
+				fmt.Println(line + ui.Red.Sprint("  (cycle)"))
+			default:
+				fmt.Println(line)
+				walk(e.to, indent+next, append(path, e.to))
+			}
+		}
+	}
+	for _, root := range graph.Roots() {
+		fmt.Printf("    %s\n", graphNodeLabel(root, present))
+		walk(root, "", []string{root})
+	}
+
+	fmt.Println()
+	if inProject {
+		fmt.Printf("    %s installed or wired   %s not in this project\n", ui.Green.Sprint("●"), ui.Dim.Sprint("○"))
+		fmt.Println()
+	}
+}
+
+// treeEdge is every edge from a module to one other module, such as
+// jobx's library dependency on asyncx and its download of it.
+type treeEdge struct {
+	to         string
+	labels     []string
+	bridgeOnly bool
+}
+
+// groupEdges merges edges, sorted by target, that point at the same module.
+func groupEdges(edges []config.GraphEdge) []treeEdge {
+	var grouped []treeEdge
+	for _, e := range edges {
+		if len(grouped) == 0 || grouped[len(grouped)-1].to != e.To {
+			grouped = append(grouped, treeEdge{to: e.To, bridgeOnly: true})
+		}
+		g := &grouped[len(grouped)-1]
+		g.labels = append(g.labels, graphEdgeLabels[e.Kind])
+		g.bridgeOnly = g.bridgeOnly && e.Kind == config.EdgeBridge
+	}
+	return grouped
+}
+
+func graphNodeLabel(name string, present func(string) bool) string {
+	if present(name) {
+		return ui.Green.Sprint("●") + " " + ui.Cyan.Sprint(name)
+	}
+	return ui.Dim.Sprint("○") + " " + name
+}
+
+// graphDOT renders the graph in Graphviz's DOT language: libraries as
+// boxes, wireable modules as rounded boxes, bridges dashed, and what the
+// project has filled in.
+func graphDOT(graph *config.ModuleGraph, present func(string) bool) string {
+	var b strings.Builder
+	b.WriteString("digraph modules {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	b.WriteString("\tedge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, name := range graph.Names() {
+		n := graph.Nodes[name]
+		var attrs, styles []string
+		switch {
+		case n.Wireable:
+			styles = append(styles, "rounded")
+		case !n.Library:
+			attrs = append(attrs, "shape=ellipse") // Profile infrastructure, e.g. postgres
+		}
+		if present(name) {
+			styles = append(styles, "filled")
+			attrs = append(attrs, `fillcolor="palegreen"`)
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, fmt.Sprintf("style=%q", strings.Join(styles, ",")))
+		}
+		fmt.Fprintf(&b, "\t%q", name)
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	for _, name := range graph.Names() {
+		for _, e := range graph.Nodes[name].Edges {
+			attrs := fmt.Sprintf("label=%q", graphEdgeLabels[e.Kind])
+			if e.Kind == config.EdgeBridge {
+				attrs += ", style=dashed"
+			}
+			fmt.Fprintf(&b, "\t%q -> %q [%s];\n", name, e.To, attrs)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
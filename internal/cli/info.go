@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info <module>",
+	Short: "Show what a module downloads, wires and configures",
+	Long: `Show a library or wireable module in full before adding it: its
+dependencies, the directories it downloads, the project files wiring it
+modifies, the environment variables it introduces, its Go dependencies,
+its providers and its bridges with other modules.
+
+  manifesto info iam
+  manifesto info notifx --json   # for scripts; works outside a project too
+
+Env variables and Go dependencies are those of the default provider; each
+provider's own are listed with it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInfo,
+}
+
+var infoJSON bool
+
+func init() {
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Print the module as JSON")
+}
+
+// infoDoc is the document printed by info --json. Lists are never null,
+// so scripts can iterate them without checks.
+type infoDoc struct {
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	Library           bool           `json:"library"`  // Source downloaded into the project
+	Wireable          bool           `json:"wireable"` // Code injected into the project by add
+	Installed         bool           `json:"installed"`
+	Wired             bool           `json:"wired"`
+	Channel           string         `json:"channel,omitempty"`  // Project's ref channel, which add downloads the module by
+	Deps              []string       `json:"deps"`               // Library modules it depends on
+	RequiredWireables []string       `json:"required_wireables"` // Wired first
+	Profiles          []string       `json:"profiles"`           // Profiles a wireable module can be wired into
+	Downloads         []string       `json:"downloads"`          // Library modules downloaded, dependencies first
+	Paths             []string       `json:"paths"`              // Project directories they are downloaded to
+	Modifies          []string       `json:"modifies"`           // Project files wiring edits or creates
+	Env               []infoEnvVar   `json:"env"`
+	GoDeps            []string       `json:"go_deps"`
+	Providers         []infoProvider `json:"providers"`
+	Bridges           []infoBridge   `json:"bridges"`
+}
+
+type infoEnvVar struct {
+	Name        string `json:"name"`
+	Default     string `json:"default"`
+	Description string `json:"description,omitempty"`
+}
+
+type infoProvider struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Default     bool         `json:"default"`
+	Env         []infoEnvVar `json:"env"`
+	GoDeps      []string     `json:"go_deps"`
+}
+
+type infoBridge struct {
+	Module    string   `json:"module"`
+	Providers []string `json:"providers"` // Of Module; empty means any
+	Summary   string   `json:"summary,omitempty"`
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	doc, err := moduleInfo(args[0])
+	if err != nil {
+		return err
+	}
+
+	projectRoot, _ := findProjectRoot()
+	manifest, _ := config.LoadManifest(projectRoot)
+	if manifest != nil {
+		_, doc.Installed = manifest.Modules[doc.Name]
+		doc.Wired = doc.Wireable && manifest.IsWired(doc.Name)
+		doc.Channel = string(scaffold.ProjectChannel(manifest))
+	}
+
+	if infoJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+	printInfo(doc, manifest != nil)
+	return nil
+}
+
+// moduleInfo describes the named library or wireable module, or both for
+// a name that is both, such as fsx.
+func moduleInfo(name string) (infoDoc, error) {
+	mod, library := config.ModuleRegistry[name]
+	spec, wireable := config.WireableModuleRegistry[name]
+	if !library && !wireable {
+		known := config.WireableModuleNames()
+		for n := range config.ModuleRegistry {
+			if !slices.Contains(known, n) {
+				known = append(known, n)
+			}
+		}
+		slices.Sort(known)
+		return infoDoc{}, fmt.Errorf("unknown module: '%s'. Available: %s", name, strings.Join(known, ", "))
+	}
+
+	doc := infoDoc{
+		Name:              name,
+		Description:       mod.Description,
+		Library:           library,
+		Wireable:          wireable,
+		Deps:              append([]string{}, mod.Deps...),
+		RequiredWireables: []string{},
+		Profiles:          []string{},
+		Modifies:          []string{},
+		Env:               []infoEnvVar{},
+		GoDeps:            []string{},
+		Providers:         []infoProvider{},
+		Bridges:           []infoBridge{},
+	}
+
+	downloads := []string{name}
+	if wireable {
+		merged, err := spec.WithProvider("")
+		if err != nil {
+			return infoDoc{}, err
+		}
+		doc.Description = spec.Description
+		doc.RequiredWireables = append(doc.RequiredWireables, spec.RequiredWireables...)
+		doc.Profiles = append(doc.Profiles, config.ProfilesAllowing(name)...)
+		doc.Modifies = append(doc.Modifies, merged.ProjectFiles()...)
+		doc.Env = infoEnvVars(merged.EnvVars())
+		doc.GoDeps = append(doc.GoDeps, merged.GoDeps...)
+		for _, p := range spec.ProviderNames() {
+			provider := spec.Providers[p]
+			doc.Providers = append(doc.Providers, infoProvider{
+				Name:        p,
+				Description: provider.Description,
+				Default:     p == spec.DefaultProvider,
+				Env:         infoEnvVars(config.WireableModule{MakefileEnv: provider.MakefileEnv}.EnvVars()),
+				GoDeps:      append([]string{}, provider.GoDeps...),
+			})
+		}
+		for _, b := range spec.Bridges {
+			doc.Bridges = append(doc.Bridges, infoBridge{
+				Module:    b.RequiresModule,
+				Providers: append([]string{}, b.RequiresProviders...),
+				Summary:   bridgeSummary(b),
+			})
+		}
+		downloads = spec.RequiredModules
+	}
+
+	resolved, err := config.ResolveDeps(downloads)
+	if err != nil {
+		return infoDoc{}, err
+	}
+	doc.Downloads = append([]string{}, resolved...)
+	doc.Paths = []string{}
+	for _, d := range doc.Downloads {
+		for _, p := range config.ModuleRegistry[d].Dirs() {
+			doc.Paths = append(doc.Paths, p+"/")
+		}
+	}
+	return doc, nil
+}
+
+func infoEnvVars(vars []config.EnvVar) []infoEnvVar {
+	env := make([]infoEnvVar, 0, len(vars))
+	for _, v := range vars {
+		env = append(env, infoEnvVar{Name: v.Name, Default: v.Default, Description: v.Description})
+	}
+	return env
+}
+
+// bridgeSummary returns what the bridge's "// Bridge: a + b — summary"
+// comment says it does, or "" without one.
+func bridgeSummary(b config.Bridge) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(b.ContainerInit), "\n")
+	if _, summary, ok := strings.Cut(first, "— "); ok && strings.HasPrefix(first, "// Bridge:") {
+		return strings.TrimSpace(summary)
+	}
+	return ""
+}
+
+// printInfo prints doc in sections; the project status only inside a
+// project.
+func printInfo(doc infoDoc, inProject bool) {
+	ui.PrintSection(doc.Name)
+	ui.PrintField("description", doc.Description)
+	var kinds []string
+	if doc.Library {
+		kinds = append(kinds, "library")
+	}
+	if doc.Wireable {
+		kinds = append(kinds, "wireable")
+	}
+	ui.PrintField("kind", strings.Join(kinds, ", "))
+	if inProject {
+		status := "not installed"
+		switch {
+		case doc.Wired:
+			status = ui.Green.Sprint("wired")
+		case doc.Installed:
+			status = ui.Green.Sprint("installed")
+		}
+		ui.PrintField("status", status)
+		ui.PrintField("ref channel", doc.Channel)
+	}
+	if len(doc.Deps) > 0 {
+		ui.PrintField("depends on", strings.Join(doc.Deps, ", "))
+	}
+	if len(doc.RequiredWireables) > 0 {
+		ui.PrintField("wires first", strings.Join(doc.RequiredWireables, ", "))
+	}
+	if doc.Wireable && len(doc.Profiles) < len(config.ProfileNames()) {
+		ui.PrintField("profiles", strings.Join(doc.Profiles, ", "))
+	}
+
+	ui.PrintSection("Downloads")
+	if len(doc.Downloads) == 0 {
+		ui.PrintField("(nothing)", "")
+	}
+	var downloads []ui.Field
+	for _, d := range doc.Downloads {
+		downloads = append(downloads, ui.Field{Key: d, Value: strings.Join(withSuffix(config.ModuleRegistry[d].Dirs(), "/"), ", ")})
+	}
+	ui.PrintFields(downloads)
+
+	if doc.Wireable {
+		ui.PrintSection("Modifies")
+		for _, f := range doc.Modifies {
+			ui.PrintField(f, "")
+		}
+	}
+
+	if len(doc.Env) > 0 {
+		ui.PrintSection("Environment")
+		printInfoEnv(doc.Env)
+	}
+
+	if len(doc.GoDeps) > 0 {
+		ui.PrintSection("Go dependencies")
+		for _, d := range doc.GoDeps {
+			ui.PrintField(d, "")
+		}
+	}
+
+	if len(doc.Providers) > 0 {
+		ui.PrintSection("Providers")
+		var providers []ui.Field
+		for _, p := range doc.Providers {
+			name := p.Name
+			if p.Default {
+				name += " (default)"
+			}
+			providers = append(providers, ui.Field{Key: name, Value: p.Description})
+			for _, v := range p.Env {
+				providers = append(providers, ui.Field{Value: ui.Dim.Sprintf("%s = %s", v.Name, v.Default)})
+			}
+		}
+		ui.PrintFields(providers)
+	}
+
+	if len(doc.Bridges) > 0 {
+		ui.PrintSection("Bridges")
+		var bridges []ui.Field
+		for _, b := range doc.Bridges {
+			module := b.Module
+			if len(b.Providers) > 0 {
+				module += " (" + strings.Join(b.Providers, ", ") + ")"
+			}
+			bridges = append(bridges, ui.Field{Key: module, Value: b.Summary})
+		}
+		ui.PrintFields(bridges)
+	}
+	ui.PrintSection("")
+}
+
+func printInfoEnv(env []infoEnvVar) {
+	var fields []ui.Field
+	for _, v := range env {
+		value := orNone(v.Default)
+		if v.Description != "" {
+			value += ui.Dim.Sprint("  " + v.Description)
+		}
+		fields = append(fields, ui.Field{Key: v.Name, Value: value})
+	}
+	ui.PrintFields(fields)
+}
+
+// withSuffix returns each of paths with suffix appended.
+func withSuffix(paths []string, suffix string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = p + suffix
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestInfoChannel checks info shows the project's ref channel, and says
+// nothing of one outside a project.
+func TestInfoChannel(t *testing.T) {
+	root := initShop(t)
+	out := captureStdout(t, func() {
+		if err := runCLI(t, root, closedStdin(t, false), "info", "jobx"); err != nil {
+			t.Error(err)
+		}
+	})
+	if !strings.Contains(out, "ref channel") || !strings.Contains(out, "pinned") {
+		t.Errorf("info in a pinned project lacks its channel:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := runCLI(t, root, closedStdin(t, false), "info", "jobx", "--json"); err != nil {
+			t.Error(err)
+		}
+	})
+	var doc infoDoc
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Channel != "pinned" {
+		t.Errorf("info --json channel = %q, want pinned", doc.Channel)
+	}
+
+	out = captureStdout(t, func() {
+		if err := runCLI(t, t.TempDir(), closedStdin(t, false), "info", "jobx"); err != nil {
+			t.Error(err)
+		}
+	})
+	if strings.Contains(out, "ref channel") {
+		t.Errorf("info outside a project shows a channel:\n%s", out)
+	}
+}
@@ -1,27 +1,45 @@
 package cli
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
 	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
 	"github.com/Abraxas-365/manifesto-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	initGoModule string
-	initModules  []string
-	initRef      string
-	initAll      bool
-	initQuick    bool
+	initGoModule  string
+	initModules   []string
+	initRef       string
+	initAll       bool
+	initQuick     bool
+	initWorker    bool
+	initTemplate  string
+	initProfile   string
+	initResume    bool
+	initRepo      string
+	initRefType   string
+	initChannel   string
+	initNaming    string
+	initEnvTarget string
+	initGit       bool
+	initMerge     bool
+	initGRPC      bool
 )
 
 var initCmd = &cobra.Command{
-	Use:   "init <project-name>",
+	Use:   "init <project-name|module-path>",
 	Short: "Create a new Manifesto app",
 	Long: `Create a new Go project with the Manifesto architecture.
 
@@ -31,58 +49,288 @@ Modules can be added during init or later with 'manifesto add':
   fsx     File system abstraction (local, S3)
   asyncx  Async primitives (futures, fan-out, pools, retry)
   ai      LLM, embeddings, vector store, OCR, speech
+  redis   Redis client (already set up by every profile)
   jobx    Async job processing (Redis-backed dispatcher)
-  notifx  Email notifications (AWS SES)
+  notifx  Email notifications (SES, SMTP, console)
   iam     Identity & Access Management
+  metrics Prometheus metrics (HTTP requests, Go runtime)
+  health  /healthz and /readyz probes for what is wired
+  otel    OpenTelemetry tracing (OTLP exporter, HTTP and SQL spans)
+  cronx   Cron scheduler for periodic jobs
+  swagger Swagger UI for the OpenAPI spec of the handlers
 
-Use --quick for a lightweight project without IAM or migrations:
-  manifesto init myapp --module github.com/me/myapp --quick
+Profiles bundle defaults for a kind of service (--profile):
+  full       HTTP API with postgres and redis; every module available (default)
+  quick      Lightweight HTTP API (no IAM, no migrations); same as --quick
+  api        HTTP API with IAM; no job queue
+  worker     Background job runner with jobx; no HTTP server; same as --worker
+  fullstack  HTTP API with IAM and jobx, serving static files from ./web
+
+Templates start from a preset profile and wire its modules without asking
+(--template); --with adds more:
+  saas       HTTP API with IAM, email notifications and a job queue
+  api        HTTP API with the core libraries; nothing wired, no IAM
+  worker     Background job runner with jobx; no HTTP server
+  minimal    Lightweight HTTP API with nothing wired
 
 Examples:
   manifesto init myapp --module github.com/me/myapp
   manifesto init myapp --module github.com/me/myapp --with fsx,jobx,iam
   manifesto init myapp --module github.com/me/myapp --all
   manifesto init myapp --module github.com/me/myapp --quick
-  manifesto init myapp --module github.com/me/myapp --quick --with fsx,jobx`,
+  manifesto init myapp --module github.com/me/myapp --quick --with fsx,jobx
+  manifesto init myapp --module github.com/me/myapp --worker
+  manifesto init myapp --module github.com/me/myapp --template saas
+  manifesto init myapp --module github.com/me/myapp --template api --with fsx
+  manifesto init myapp --module github.com/me/myapp --repo acme/manifesto
+  manifesto init myapp --module github.com/me/myapp --ref 3f2c1e9
+  manifesto init myapp --module github.com/me/myapp --ref v2 --force-ref-type branch
+  manifesto init myapp --module github.com/me/myapp --ref-channel stable
+  manifesto init myapp --module github.com/me/myapp --naming subdir
+  manifesto init myapp --module github.com/me/myapp --git
+  manifesto init myapp --module github.com/me/myapp --grpc
+  manifesto init myapp --module github.com/me/myapp --env-target dotenv
+  manifesto init github.com/me/myapp
+  mkdir myapp && cd myapp && git init && manifesto init . --module github.com/me/myapp --force
+
+Domain packages follow a naming convention (--naming): suffix lays out
+invoicesrv/, invoiceinfra/, invoiceapi/ and invoicecontainer/ (default),
+subdir service/, repository/, http/ and wire/, and flat keeps a domain in
+one package. 'manifesto add --naming' overrides it for one domain.
+
+With --grpc, cmd/server.go also serves gRPC on GRPC_PORT (default 9090),
+and 'manifesto add --transport grpc' scaffolds domains as gRPC services
+registered there. make proto generates their code with protoc.
+
+Wired modules export their settings from the Makefile by default. With
+--env-target dotenv they go to .env.example and .env instead, which the
+Makefile loads, and with both to all three. The choice is recorded as
+env_target in manifesto.yaml for later 'manifesto add' runs.
+
+With --local ../manifesto, modules are copied from a manifesto checkout
+instead of downloaded, here and in later 'manifesto add' runs.
+
+Modules are downloaded without their _test.go files and testdata. With
+--with-tests they come along, now and in later downloads.
+
+With --git, init runs git init in the new project and commits it. Inside
+an existing git work tree it doesn't create a repository, and commits the
+project there only if you confirm.
+
+Once the project is generated, init runs go mod tidy so it builds right
+away, then go build ./... to check that it does; --no-verify skips the
+build and --skip-tidy skips both. Without a go on PATH, tidy is listed as
+a manual step instead.
+
+Without --with, --template or --all, init asks which modules to wire. With --yes, or
+when stdin isn't a terminal (CI, scripts), it doesn't ask and wires the
+profile's defaults.
+
+Without --module, the module path is inferred and shown for you to
+confirm: from a project argument that names a repository
+(github.com/me/myapp), from default_module_prefix in the user config
+(~/.config/manifesto/config.yaml) plus the project name, or from the
+origin remote of the git repository you're in plus the project's path in
+it. If none applies, init asks for it.
+
+init refuses a directory that already exists. --force inits into one
+that is empty or holds only .git, and --merge into any directory, as long
+as none of the files init generates are there already; it lists the ones
+that are.
+
+If an init is interrupted (e.g. a network error while wiring), re-run the
+same command with --resume to continue from the last completed step.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runInit,
 }
 
 func init() {
-	initCmd.Flags().StringVar(&initGoModule, "module", "", "Go module path (e.g. github.com/user/project)")
+	initCmd.Flags().StringVar(&initGoModule, "module", "", "Go module path (e.g. github.com/user/project; inferred when omitted)")
 	initCmd.Flags().StringSliceVar(&initModules, "with", nil, "Modules to include (comma-separated: fsx,asyncx,ai,jobx,notifx,iam)")
-	initCmd.Flags().StringVar(&initRef, "ref", "", "Manifesto version (tag or branch, default: latest)")
+	initCmd.Flags().StringVar(&initRef, "ref", "", "Manifesto version (tag, branch or commit SHA, default: latest)")
+	initCmd.Flags().StringVar(&initRefType, "force-ref-type", "", "Resolve --ref as a tag, branch or commit instead of guessing")
+	initCmd.Flags().StringVar(&initChannel, "ref-channel", "", "Ref later add/install runs use: stable (latest release), pinned (this ref) or branch (default: pinned, or branch for a branch ref)")
 	initCmd.Flags().BoolVar(&initAll, "all", false, "Wire all available modules")
-	initCmd.Flags().BoolVar(&initQuick, "quick", false, "Create a lightweight project (no IAM, no migrations)")
-	_ = initCmd.MarkFlagRequired("module")
+	initCmd.Flags().BoolVar(&initQuick, "quick", false, "Create a lightweight project (no IAM, no migrations); same as --profile quick")
+	initCmd.Flags().BoolVar(&initWorker, "worker", false, "Create a job runner with no HTTP server; same as --profile worker")
+	initCmd.Flags().StringVar(&initTemplate, "template", "",
+		fmt.Sprintf("Start from a preset profile and module set (%s)", strings.Join(config.PresetNames(), ", ")))
+	initCmd.Flags().StringVar(&initProfile, "profile", "",
+		fmt.Sprintf("Project profile (%s; default: %s)", strings.Join(config.ProfileNames(), ", "), config.DefaultProfile))
+	initCmd.Flags().StringVar(&initNaming, "naming", "",
+		fmt.Sprintf("Domain package naming convention (%s; default: %s)", strings.Join(config.NamingNames(), ", "), config.DefaultNaming))
+	initCmd.Flags().StringVar(&initEnvTarget, "env-target", "", "Where wiring writes module env variables: makefile, dotenv (.env.example and .env) or both (default: makefile)")
+	initCmd.Flags().StringVar(&initRepo, "repo", "", "Fetch modules from this manifesto fork (owner/name) instead of "+remote.DefaultRepo)
+	initCmd.Flags().BoolVar(&initGit, "git", false, "Create a git repository and commit the new project")
+	initCmd.Flags().BoolVar(&initGRPC, "grpc", false, "Serve gRPC next to the HTTP API, for domains added with --transport grpc")
+	initCmd.Flags().BoolVar(&initResume, "resume", false, "Continue an interrupted init in an existing project directory")
+	initCmd.Flags().BoolVar(&initMerge, "merge", false, "Scaffold into an existing directory, refusing to overwrite any of its files")
+	registerModifiedFlags(initCmd)
+	initCmd.Flags().Lookup("force").Usage = "Init into an existing directory that is empty or holds only .git; on --resume, overwrite module files edited since they were fetched"
+	registerTidyFlag(initCmd)
+	registerVerifyFlag(initCmd)
+	registerTestsFlag(initCmd)
+	registerLocalFlag(initCmd)
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
-	projectName := args[0]
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
 
-	// --- CRA-style banner ---
-	ui.PrintBanner()
-	if initQuick {
-		ui.PrintCreateHeaderQuick(projectName, initGoModule)
-	} else {
-		ui.PrintCreateHeader(projectName, initGoModule)
+	// "." inits the current directory, named after it.
+	projectName, argModule := splitProjectArg(args[0])
+	if args[0] == "." {
+		projectName, cwd = filepath.Base(cwd), filepath.Dir(cwd)
+	}
+	if err := checkProjectName(projectName); err != nil {
+		return err
 	}
+	applyModifiedFlags()
 
-	// Build module list (all core modules).
-	selected := config.CoreModules(initQuick)
+	// An interrupted init leaves a state file behind; continue from it
+	// instead of starting over.
+	state, err := scaffold.LoadInitState(filepath.Join(cwd, projectName))
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		return resumeInit(cwd, projectName, state)
+	}
+
+	if initRepo != "" {
+		if err := remote.ValidateRepo(initRepo); err != nil {
+			return err
+		}
+	}
+
+	if localCheckout != "" {
+		for _, flag := range []string{"ref", "force-ref-type", "ref-channel"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--local copies modules from a checkout; drop --%s", flag)
+			}
+		}
+		if initRef, err = remote.LocalRef(localCheckout); err != nil {
+			return err
+		}
+	}
+	refType, err := remote.ParseRefType(initRefType)
+	if err != nil {
+		return err
+	}
+	if refType != remote.RefAuto && initRef == "" {
+		return fmt.Errorf("--force-ref-type needs --ref")
+	}
+	if err := remote.ValidateRef(initRef, refType); err != nil {
+		return err
+	}
+	ref := initRef
+	channel, err := remote.ParseChannel(initChannel)
+	if err != nil {
+		return err
+	}
+	switch channel {
+	case remote.ChannelStable:
+		if ref != "" {
+			return fmt.Errorf("--ref-channel stable follows the latest release; drop --ref")
+		}
+	case remote.ChannelBranch:
+		if refType == remote.RefTag || refType == remote.RefCommit || (refType == remote.RefAuto && remote.IsCommitSHA(ref)) {
+			return fmt.Errorf("--ref-channel branch needs a branch for --ref")
+		}
+		if ref == "" {
+			ref = remote.NewClient(initRepo).DefaultBranch()
+		}
+		if !remote.IsBranch(ref, refType) {
+			refType = remote.RefBranch
+		}
+	case remote.ChannelPinned:
+		if remote.IsBranch(ref, refType) {
+			return fmt.Errorf("%s is a branch, which can't be pinned; pass a tag or commit, or --ref-channel branch to track it", ref)
+		}
+	default:
+		channel = remote.ChannelPinned
+		if remote.IsBranch(ref, refType) {
+			channel = remote.ChannelBranch
+		}
+	}
+
+	profileName := initProfile
+	var preset *config.Preset
+	if initTemplate != "" {
+		p, err := config.LookupPreset(initTemplate)
+		if err != nil {
+			return err
+		}
+		for _, flag := range []string{"profile", "quick", "worker"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--template %s sets the profile; drop --%s", p.Name, flag)
+			}
+		}
+		preset, profileName = &p, p.Profile
+	}
+	if initQuick {
+		if profileName != "" && profileName != "quick" {
+			return fmt.Errorf("--quick conflicts with --profile %s", profileName)
+		}
+		profileName = "quick"
+	}
+	if initWorker {
+		if initQuick {
+			return fmt.Errorf("--worker conflicts with --quick")
+		}
+		if profileName != "" && profileName != "worker" {
+			return fmt.Errorf("--worker conflicts with --profile %s", profileName)
+		}
+		profileName = "worker"
+	}
+	profile, err := config.LookupProfile(profileName)
+	if err != nil {
+		return err
+	}
+	naming, err := config.LookupNaming(initNaming)
+	if err != nil {
+		return err
+	}
+	envTarget, err := config.LookupEnvTarget(initEnvTarget)
+	if err != nil {
+		return err
+	}
+	if initGRPC && !profile.HTTP {
+		return fmt.Errorf("--grpc needs a server profile; %s projects have no cmd/server.go", profile.Name)
+	}
 
-	// Deduplicate.
-	seen := make(map[string]bool)
-	var deduped []string
-	for _, m := range selected {
-		if !seen[m] {
-			seen[m] = true
-			deduped = append(deduped, m)
+	goModule, moduleSource := initGoModule, ""
+	if goModule == "" {
+		if goModule, moduleSource, err = inferModule(cwd, projectName, argModule); err != nil {
+			return err
 		}
 	}
 
-	// Resolve with deps.
-	resolved := config.ResolveDeps(deduped)
+	// --- CRA-style banner ---
+	ui.PrintBanner()
+	moduleDisplay := goModule
+	if moduleSource != "" {
+		moduleDisplay += ui.Dim.Sprintf(" (from %s)", moduleSource)
+	}
+	switch profile.Name {
+	case config.DefaultProfile:
+		ui.PrintCreateHeader(projectName, moduleDisplay)
+	case "quick":
+		ui.PrintCreateHeaderQuick(projectName, moduleDisplay)
+	default:
+		ui.PrintCreateHeaderProfile(projectName, moduleDisplay, profile.Name, profile.Description)
+	}
+	if moduleSource != "" && ui.Interactive() && !ui.Confirm("Use this module path?", true) {
+		return fmt.Errorf("aborted; pass --module to choose the module path")
+	}
+
+	// All core modules plus the profile's extras, with their deps.
+	resolved, err := profile.InitModules()
+	if err != nil {
+		return err
+	}
 
 	// Show what will be installed.
 	fmt.Printf("  Installing %s libraries:\n\n", ui.Bold.Sprintf("%d", len(resolved)))
@@ -97,33 +345,41 @@ func runInit(cmd *cobra.Command, args []string) error {
 	wireableNames := config.WireableModuleNames()
 	sort.Strings(wireableNames)
 
-	// Filter wireable modules based on quick mode (iam not available in quick)
-	availableWireable := wireableNames
-	if initQuick {
-		var filtered []string
-		for _, name := range wireableNames {
-			if name != "iam" {
-				filtered = append(filtered, name)
-			}
+	// Filter wireable modules the profile can't host (e.g. iam in quick).
+	// Builtin modules aren't offered: every project gets them.
+	var availableWireable []string
+	for _, name := range wireableNames {
+		if !profile.Allows(name) || profile.Provides(name) || config.WireableModuleRegistry[name].Builtin {
+			continue
 		}
-		availableWireable = filtered
+		availableWireable = append(availableWireable, name)
 	}
 
 	if initAll {
 		wireModules = availableWireable
-	} else if len(initModules) > 0 {
+	} else if preset != nil || len(initModules) > 0 {
+		// A preset's modules are wired without asking, along with --with.
+		if preset != nil {
+			wireModules = append(wireModules, preset.Wire...)
+		}
 		for _, m := range initModules {
 			m = strings.TrimSpace(m)
 			if !config.IsWireableModule(m) {
 				return fmt.Errorf("unknown wireable module: '%s'. Available: %s", m, strings.Join(wireableNames, ", "))
 			}
-			if initQuick && m == "iam" {
-				return fmt.Errorf("module 'iam' is not available for quick projects")
+			if !config.HasModule(wireModules, m) {
+				wireModules = append(wireModules, m)
 			}
-			wireModules = append(wireModules, m)
 		}
+		if err := profile.ValidateWire(wireModules); err != nil {
+			return err
+		}
+	} else if !ui.Interactive() {
+		// No prompt (--yes, or stdin isn't a terminal): take the
+		// profile's defaults.
+		wireModules = profile.Wire
 	} else {
-		// Interactive selection.
+		// Interactive selection, starting from the profile's defaults.
 		if len(availableWireable) > 0 {
 			items := make([]ui.SelectableItem, len(availableWireable))
 			for i, name := range availableWireable {
@@ -131,6 +387,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 				items[i] = ui.SelectableItem{
 					Name:        name,
 					Description: spec.Description,
+					Selected:    config.HasModule(profile.Wire, name),
 				}
 			}
 
@@ -142,6 +399,10 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Add the builtin modules, and wire what the modules require first,
+	// except what the profile's templates set up already.
+	wireModules = profile.InitWire(wireModules)
+
 	if len(wireModules) > 0 {
 		fmt.Printf("  Wiring %s modules:\n\n", ui.Bold.Sprintf("%d", len(wireModules)))
 		for _, name := range wireModules {
@@ -150,25 +411,151 @@ func runInit(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
-	ref := initRef
-
 	// Run scaffold.
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-
 	if err := scaffold.InitProject(scaffold.InitOptions{
 		ProjectName: projectName,
-		GoModule:    initGoModule,
+		GoModule:    goModule,
 		OutputDir:   cwd,
 		Modules:     resolved,
 		Ref:         ref,
+		RefType:     refType,
+		Channel:     channel,
+		Repo:        initRepo,
+		Profile:     profile.Name,
+		Naming:      naming.Name,
+		GRPC:        initGRPC,
+		Tests:       withTests,
+		EnvTarget:   envTarget,
 		WireModules: wireModules,
+		SkipTidy:    skipTidy,
+		Verify:      verifies(),
+		Force:       forceModified,
+		Merge:       initMerge,
 	}); err != nil {
 		return err
 	}
 
-	ui.PrintSuccess(projectName, wireModules)
+	if initGit {
+		commitNewProject(filepath.Join(cwd, projectName))
+	}
+
+	ui.PrintSuccess(projectName, wireModules, projectFollowUps(filepath.Join(cwd, projectName)), scaffold.TodoFile, skipTidy)
 	return nil
 }
+
+// resumeInit continues an interrupted init with the options it recorded.
+func resumeInit(cwd, projectName string, state *scaffold.InitState) error {
+	if initGoModule != "" && state.GoModule != initGoModule {
+		return fmt.Errorf("%s was started with --module %s; re-run with that module to resume", projectName, state.GoModule)
+	}
+	if initProfile != "" && initProfile != state.Profile {
+		return fmt.Errorf("%s was started with --profile %s; re-run with that profile to resume", projectName, state.Profile)
+	}
+	if started := cmp.Or(state.Naming, config.DefaultNaming); initNaming != "" && initNaming != started {
+		return fmt.Errorf("%s was started with --naming %s; re-run with that convention to resume", projectName, started)
+	}
+	if started := cmp.Or(state.EnvTarget, config.DefaultEnvTarget); initEnvTarget != "" && initEnvTarget != started {
+		return fmt.Errorf("%s was started with --env-target %s; re-run with that target to resume", projectName, started)
+	}
+	if initGRPC && !state.GRPC {
+		return fmt.Errorf("%s was started without --grpc; re-run without it to resume", projectName)
+	}
+	if withTests && !state.Tests {
+		return fmt.Errorf("%s was started without --with-tests; re-run without it to resume", projectName)
+	}
+	if localCheckout != "" {
+		if ref, err := remote.LocalRef(localCheckout); err != nil || ref != state.Ref {
+			return fmt.Errorf("%s was started with manifesto@%s; re-run without --local to resume", projectName, state.Ref)
+		}
+	}
+	if initRepo != "" && initRepo != state.Repo {
+		return fmt.Errorf("%s was started with --repo %s; re-run with that repo to resume", projectName, orNone(state.Repo))
+	}
+
+	progress := "no steps completed"
+	if last := state.LastCompleted(); last != "" {
+		progress = "last completed step: " + last
+	}
+	fmt.Println()
+	ui.StepWarn(fmt.Sprintf("%s holds an unfinished init (%s)", projectName, progress))
+	if !initResume {
+		if !ui.Confirm("Continue where it left off?", true) {
+			return fmt.Errorf("directory %s already exists; re-run with --resume to continue it or remove it to start over",
+				filepath.Join(cwd, projectName))
+		}
+	}
+
+	if err := scaffold.InitProject(scaffold.InitOptions{
+		ProjectName: projectName,
+		OutputDir:   cwd,
+		Resume:      true,
+		SkipTidy:    skipTidy,
+		Verify:      verifies(),
+	}); err != nil {
+		return err
+	}
+
+	if initGit {
+		commitNewProject(filepath.Join(cwd, projectName))
+	}
+
+	ui.PrintSuccess(projectName, state.WireModules, projectFollowUps(filepath.Join(cwd, projectName)), scaffold.TodoFile, skipTidy)
+	return nil
+}
+
+// commitNewProject puts a new project under git for --git: git init, then
+// one commit of everything the generated .gitignore lets through. A
+// repository rooted at the project gets the commit as is. Inside any other
+// work tree it doesn't nest a repository and commits only the project's
+// own files, and only if the user confirms. Failures are warnings: the
+// project itself is complete by then.
+func commitNewProject(projectRoot string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		ui.StepWarn("git not found in PATH; skipped creating a repository")
+		return
+	}
+	ctx := context.Background()
+	message := fmt.Sprintf("Initial commit from manifesto v%s", Version)
+
+	if out, err := execx.Git(ctx, projectRoot, "rev-parse", "--show-toplevel"); err == nil {
+		top := strings.TrimSpace(string(out))
+		if prefix, _ := execx.Git(ctx, projectRoot, "rev-parse", "--show-prefix"); strings.TrimSpace(string(prefix)) == "" {
+			// A repository made for the project, e.g. git init before
+			// init --force: commit without asking.
+			for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", message}} {
+				if _, err := execx.Git(ctx, projectRoot, args...); err != nil {
+					ui.StepWarn(fmt.Sprintf("Couldn't commit the project: %v", err))
+					return
+				}
+			}
+			ui.StepDone(fmt.Sprintf("Committed the project as %q", message))
+			return
+		}
+		if !ui.Confirm(fmt.Sprintf("%s is inside the git repository at %s. Commit the new project there?", filepath.Base(projectRoot), top), false) {
+			ui.StepInfo(fmt.Sprintf("Inside the git repository at %s; left the project uncommitted", top))
+			return
+		}
+		// The pathspec keeps whatever else the user has staged out of
+		// the commit.
+		for _, args := range [][]string{{"add", "-A", "--", "."}, {"commit", "-q", "-m", message, "--", "."}} {
+			if _, err := execx.Git(ctx, projectRoot, args...); err != nil {
+				ui.StepWarn(fmt.Sprintf("Couldn't commit the project: %v", err))
+				return
+			}
+		}
+		ui.StepDone(fmt.Sprintf("Committed the project to %s", top))
+		return
+	}
+
+	if _, err := execx.Git(ctx, projectRoot, "init", "-q"); err != nil {
+		ui.StepWarn(fmt.Sprintf("Couldn't create a git repository: %v", err))
+		return
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", message}} {
+		if _, err := execx.Git(ctx, projectRoot, args...); err != nil {
+			ui.StepWarn(fmt.Sprintf("Created a git repository but couldn't commit the project: %v", err))
+			return
+		}
+	}
+	ui.StepDone(fmt.Sprintf("Created a git repository with %q", message))
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// resetFlags puts every flag of cmd and its subcommands back to its
+// default, as a fresh process would have them.
+func resetFlags(t *testing.T, cmd *cobra.Command) {
+	t.Helper()
+	reset := func(f *pflag.Flag) {
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			s.Replace(nil)
+		} else if err := f.Value.Set(f.DefValue); err != nil {
+			t.Fatalf("reset --%s: %v", f.Name, err)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(t, sub)
+	}
+}
+
+// closedStdin returns a stdin with nothing to read, as CI runners and
+// scripts give: either a pipe whose writer is gone or a closed file.
+func closedStdin(t *testing.T, closed bool) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if closed {
+		r.Close()
+	} else {
+		t.Cleanup(func() { r.Close() })
+	}
+	return r
+}
+
+// runCLI runs the CLI with args in dir and stdin, failing t if it doesn't
+// return within a minute, as a prompt waiting for input wouldn't.
+func runCLI(t *testing.T, dir string, stdin *os.File, args ...string) error {
+	t.Helper()
+	resetFlags(t, rootCmd)
+	t.Chdir(dir)
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	rootCmd.SetArgs(args)
+	done := make(chan error, 1)
+	go func() { done <- rootCmd.Execute() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Minute):
+		t.Fatalf("manifesto %s is still running; is it waiting for input?", strings.Join(args, " "))
+		return nil
+	}
+}
+
+// TestInitClosedStdin runs init without a terminal and without --yes:
+// it must not prompt, and must wire the profile's defaults or --with.
+func TestInitClosedStdin(t *testing.T) {
+	checkout, err := filepath.Abs(filepath.Join("..", "scaffold", "testdata", "manifesto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(remote.DefaultRefEnv, "")
+	quick, err := config.LookupProfile("quick")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		closed bool
+		args   []string
+		wired  []string
+	}{
+		{name: "at EOF", wired: quick.Wire},
+		{name: "closed", closed: true, wired: quick.Wire},
+		{name: "with", args: []string{"--with", "jobx"}, wired: append(slices.Clone(quick.Wire), "jobx")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			args := append([]string{"init", "shop", "--module", "example.com/shop", "--profile", "quick", "--local", checkout, "--skip-tidy"}, tt.args...)
+			if err := runCLI(t, dir, closedStdin(t, tt.closed), args...); err != nil {
+				t.Fatal(err)
+			}
+			if ui.AssumeYes {
+				t.Fatal("--yes was set")
+			}
+
+			manifest, err := config.LoadManifest(filepath.Join(dir, "shop"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.wired {
+				if !manifest.IsWired(name) {
+					t.Errorf("%s isn't wired; wired: %v", name, manifest.WiredModules)
+				}
+			}
+		})
+	}
+}
+
+// TestInitClosedStdinNoModule checks init fails, rather than asking, when
+// it has no terminal to ask for a module path it can't infer.
+func TestInitClosedStdinNoModule(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	err := runCLI(t, dir, closedStdin(t, false), "init", "shop", "--profile", "quick", "--skip-tidy")
+	if err == nil || !strings.Contains(err.Error(), "--module is required") {
+		t.Fatalf("got %v, want --module to be required", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "shop")); !os.IsNotExist(err) {
+		t.Error("init created the project without a module path")
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var installRef string
+var (
+	installRef    string
+	installSource string
+)
 
 var installCmd = &cobra.Command{
 	Use:        "install <module>",
@@ -18,14 +21,22 @@ var installCmd = &cobra.Command{
 }
 
 func init() {
-	installCmd.Flags().StringVar(&installRef, "ref", "", "Manifesto version (default: project version)")
+	installCmd.Flags().StringVar(&installRef, "ref", "", "Manifesto version for this module only (default: project version)")
+	installCmd.Flags().StringVar(&installSource, "source", "", "Fetch modules from this manifesto fork (owner/name); default: the project's repo")
+	registerModifiedFlags(installCmd)
+	registerTidyFlag(installCmd)
+	registerTestsFlag(installCmd)
+	registerLocalFlag(installCmd)
+	installCmd.MarkFlagsMutuallyExclusive("local", "ref")
+	installCmd.MarkFlagsMutuallyExclusive("local", "source")
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
 	ui.StepWarn("'manifesto install' is deprecated. Use 'manifesto add' instead.")
 	fmt.Println()
 
-	// Forward to add command
-	addCmd.SetArgs(args)
-	return addCmd.Execute()
+	// Forward to add. Running addCmd itself would re-run the root command
+	// with os.Args and land back here.
+	addRef, addSource = installRef, installSource
+	return runAdd(addCmd, args)
 }
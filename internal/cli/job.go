@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// runAddJob scaffolds the jobx job named in args, the rest of
+// `manifesto add job <name> --domain <path>`.
+func runAddJob(cmd *cobra.Command, projectRoot string, manifest *config.Manifest, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("add job takes one job name, e.g. manifesto add job send-invoice --domain pkg/billing/invoice")
+	}
+	for _, flag := range []string{"ref", "check"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s doesn't apply to jobs", flag)
+		}
+	}
+	if addJobDomain == "" {
+		return fmt.Errorf("add job needs the domain the job belongs to, e.g. --domain pkg/billing/invoice")
+	}
+
+	data, err := scaffold.NewJobData(manifest.Project.GoModule, addJobDomain, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !manifest.IsWired("jobx") {
+		if addDryRun || !ui.Interactive() || !ui.Confirm("Jobs run on the jobx job queue, which isn't wired. Wire it now?", true) {
+			return fmt.Errorf("jobs run on the jobx job queue; run 'manifesto add jobx' first")
+		}
+		if err := wireForJob(projectRoot, manifest); err != nil {
+			return err
+		}
+	}
+
+	if addDryRun {
+		preview, err := scaffold.PreviewJob(projectRoot, data)
+		if err != nil {
+			return err
+		}
+		return reportPreview(preview, nil)
+	}
+
+	changed, err := scaffold.GenerateJob(projectRoot, data)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		ui.StepInfo(fmt.Sprintf("Job %s is already generated and registered", data.JobName))
+		return nil
+	}
+	ui.PrintJobSuccess(data.JobName, data.File())
+	return nil
+}
+
+// wireForJob wires jobx and whatever it needs before a job is scaffolded.
+func wireForJob(projectRoot string, manifest *config.Manifest) error {
+	profile, err := manifest.Profile()
+	if err != nil {
+		return err
+	}
+	source := cmp.Or(addSource, manifest.Project.Repo)
+	names := withMissingWireables(manifest, []string{"jobx"})
+	if err := profile.ValidateWire(names); err != nil {
+		return err
+	}
+	if len(names) > 1 {
+		return runWireModules(projectRoot, manifest, names, source)
+	}
+	return runWireModule(projectRoot, manifest, "jobx", source)
+}
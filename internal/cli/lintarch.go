@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/archlint"
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var lintArchCmd = &cobra.Command{
+	Use:   "lint-arch",
+	Short: "Check imports against the domain layering",
+	Long: `Check that the project's imports keep the layering the domain scaffold
+sets up, for each domain tracked in manifesto.yaml:
+
+  <pkg>api        may import the domain and <pkg>srv
+  <pkg>srv        may import the domain
+  <pkg>infra      may import the domain
+  domain          imports none of its layers
+  <pkg>container  may import all of them
+
+The directories are those of the domain's naming convention (service/,
+repository/, http/ and wire/ under subdir); a flat domain is one package.
+Another domain may only use a domain's model and service, and code outside
+the domains (cmd/, pkg/server, ...) its model, service and container.
+Exceptions go under arch in manifesto.yaml:
+
+  arch:
+    allow:
+      - from: pkg/billing/invoice/invoiceapi
+        to: pkg/billing/invoice/invoiceinfra
+    deny:
+      - from: pkg/kernel/...
+        to: pkg/...
+    outside: [domain, srv, container]
+
+Exits 1 when an import breaks the rules, for CI. Test files aren't checked.`,
+	Args: cobra.NoArgs,
+	RunE: runLintArch,
+}
+
+func runLintArch(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	domains := make([]archlint.Domain, len(manifest.Domains))
+	for i, d := range manifest.Domains {
+		naming, err := manifest.DomainNaming(d)
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.Path, err)
+		}
+		data := scaffold.NewDomainData(manifest.Project.GoModule, d.Path).WithNaming(naming)
+		domains[i] = archlint.Domain{Path: d.Path, Layout: data.Layout}
+	}
+
+	linter, err := archlint.New(manifest.Project.GoModule, domains, manifest.Arch)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.ManifestoFile, err)
+	}
+	violations, err := linter.Check(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	if len(violations) == 0 {
+		ui.StepDone(fmt.Sprintf("No layering violations in %d domain(s)", len(domains)))
+		return nil
+	}
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	return &exitError{code: 1, err: fmt.Errorf("lint-arch: %d import(s) break the layering", len(violations))}
+}
@@ -0,0 +1,11 @@
+package cli
+
+import "github.com/spf13/cobra"
+
+var localCheckout string
+
+// registerLocalFlag adds --local to a command that downloads library
+// modules.
+func registerLocalFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&localCheckout, "local", "", "Copy modules from this manifesto checkout instead of downloading them; recorded as their version")
+}
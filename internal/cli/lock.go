@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forceModified bool
+	keepModified  bool
+)
+
+// registerModifiedFlags adds --force and --keep-modified to a command that
+// downloads modules, for files edited since they were fetched.
+func registerModifiedFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&forceModified, "force", false, "Overwrite module files edited since they were fetched (see "+config.LockFile+")")
+	cmd.Flags().BoolVar(&keepModified, "keep-modified", false, "Keep module files edited since they were fetched and update the rest")
+	cmd.MarkFlagsMutuallyExclusive("force", "keep-modified")
+}
+
+// applyModifiedFlags sets scaffold.Modified from the flags.
+func applyModifiedFlags() {
+	switch {
+	case forceModified:
+		scaffold.Modified = scaffold.OverwriteModified
+	case keepModified:
+		scaffold.Modified = scaffold.KeepModified
+	}
+}
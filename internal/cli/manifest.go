@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/diff"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Work with manifesto.yaml",
+}
+
+var manifestFmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Repair and normalize a hand-edited manifesto.yaml",
+	Long: `Parse manifesto.yaml leniently and rewrite it in the form manifesto
+writes itself: modules indented under modules, lists as lists, wired modules
+and domains sorted, and every timestamp set. The diff is shown before
+anything is written.
+
+  manifesto manifest fmt
+  manifesto manifest fmt --check   # exit non-zero if the file would change`,
+	Args: cobra.NoArgs,
+	RunE: runManifestFmt,
+}
+
+var manifestFmtCheck bool
+
+func init() {
+	manifestCmd.AddCommand(manifestFmtCmd)
+	manifestFmtCmd.Flags().BoolVar(&manifestFmtCheck, "check", false, "Print the diff and exit non-zero if the file isn't formatted, without writing")
+}
+
+func runManifestFmt(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(projectRoot, config.ManifestoFile)
+	before, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("not a manifesto project (no manifesto.yaml found)")
+	}
+	if err != nil {
+		return err
+	}
+	manifest, err := config.ParseManifest(before)
+	if err != nil {
+		return err
+	}
+	for _, w := range manifest.Warnings {
+		ui.StepWarn(w)
+	}
+
+	manifest.Normalize()
+	after, err := manifest.Marshal()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		ui.StepDone(config.ManifestoFile + " is already formatted")
+		return nil
+	}
+
+	fmt.Println()
+	ui.PrintDiff(diff.Unified("a/"+config.ManifestoFile, "b/"+config.ManifestoFile, string(before), string(after)))
+	fmt.Println()
+
+	if manifestFmtCheck {
+		return fmt.Errorf("%s is not formatted; run 'manifesto manifest fmt'", config.ManifestoFile)
+	}
+	if !ui.Confirm("Write these changes?", true) {
+		ui.StepInfo("Left " + config.ManifestoFile + " unchanged (pass --yes to write without asking)")
+		return nil
+	}
+	if err := os.WriteFile(path, after, 0644); err != nil {
+		return err
+	}
+	ui.StepDone("Rewrote " + config.ManifestoFile)
+	return nil
+}
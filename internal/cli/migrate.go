@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/migrate"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateSteps  int
+	migrateDryRun bool
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply, revert or list the project's SQL migrations",
+	Long: `Apply the SQL files in migrations/ to the project's database, revert them,
+or list which are applied. Applied versions are recorded in the
+manifesto_migrations table.
+
+The connection settings are the DB_* variables the project reads:
+the environment first, then .env, then the defaults the Makefile exports.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending migrations, oldest first",
+	Example: `  manifesto migrate up
+  manifesto migrate up --steps 1
+  manifesto migrate up --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateUp,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert applied migrations, newest first (one by default)",
+	Example: `  manifesto migrate down
+  manifesto migrate down --steps 3 --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateDown,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List applied and pending migrations",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateStatus,
+}
+
+func init() {
+	migrateUpCmd.Flags().IntVar(&migrateSteps, "steps", 0, "Apply at most n migrations (default all)")
+	migrateDownCmd.Flags().IntVar(&migrateSteps, "steps", 1, "Revert n migrations")
+	for _, cmd := range []*cobra.Command{migrateUpCmd, migrateDownCmd} {
+		cmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "List the migrations without running them")
+	}
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+}
+
+// openMigrations loads the project's migrations and connects to its
+// database.
+func openMigrations(ctx context.Context) ([]migrate.Migration, *migrate.Runner, error) {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := loadManifest(projectRoot); err != nil {
+		return nil, nil, err
+	}
+
+	all, err := migrate.Load(filepath.Join(projectRoot, scaffold.MigrationsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("this project has no %s/ directory; run 'manifesto add migrations' first", scaffold.MigrationsDir)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", scaffold.MigrationsDir, err)
+	}
+
+	settings, err := migrate.LoadSettings(projectRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	runner, err := migrate.Open(ctx, settings.DSN())
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", settings, err)
+	}
+	return all, runner, nil
+}
+
+func runMigrateUp(cmd *cobra.Command, args []string) error {
+	if migrateSteps < 0 {
+		return fmt.Errorf("--steps must be positive")
+	}
+	ctx := cmd.Context()
+	all, runner, err := openMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	applied, err := runner.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	pending := migrate.Pending(all, applied)
+	if migrateSteps > 0 && migrateSteps < len(pending) {
+		pending = pending[:migrateSteps]
+	}
+	if len(pending) == 0 {
+		ui.StepDone("No pending migrations")
+		return nil
+	}
+
+	if migrateDryRun {
+		ui.PrintMigrations("Would apply", migrationDisplays(pending, applied))
+		return nil
+	}
+	for _, m := range pending {
+		if err := runner.Up(ctx, m); err != nil {
+			return err
+		}
+		ui.StepDone(fmt.Sprintf("Applied %s_%s", m.Version, m.Name))
+	}
+	return nil
+}
+
+func runMigrateDown(cmd *cobra.Command, args []string) error {
+	if migrateSteps < 1 {
+		return fmt.Errorf("--steps must be at least 1")
+	}
+	ctx := cmd.Context()
+	all, runner, err := openMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	applied, err := runner.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	reverting := migrate.AppliedMigrations(all, applied)
+	if migrateSteps < len(reverting) {
+		reverting = reverting[:migrateSteps]
+	}
+	if len(reverting) == 0 {
+		ui.StepDone("No applied migrations")
+		return nil
+	}
+
+	if migrateDryRun {
+		ui.PrintMigrations("Would revert", migrationDisplays(reverting, applied))
+		return nil
+	}
+	for _, m := range reverting {
+		if err := runner.Down(ctx, m); err != nil {
+			return err
+		}
+		ui.StepDone(fmt.Sprintf("Reverted %s_%s", m.Version, m.Name))
+	}
+	return nil
+}
+
+func runMigrateStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	all, runner, err := openMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	applied, err := runner.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	ui.PrintMigrationStatus(migrationDisplays(all, applied))
+	return nil
+}
+
+func migrationDisplays(migrations []migrate.Migration, applied map[string]bool) []ui.MigrationDisplay {
+	out := make([]ui.MigrationDisplay, len(migrations))
+	for i, m := range migrations {
+		out[i] = ui.MigrationDisplay{Version: m.Version, Name: m.Name, Applied: applied[m.Version]}
+	}
+	return out
+}
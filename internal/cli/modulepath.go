@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"golang.org/x/mod/module"
+)
+
+// splitProjectArg splits init's argument into the project directory name
+// and, for an argument naming a repository such as github.com/acme/billing
+// or https://github.com/acme/billing.git, the module path it implies.
+func splitProjectArg(arg string) (name, module string) {
+	if !strings.Contains(arg, "/") {
+		return arg, ""
+	}
+	module = repoModulePath(arg)
+	return path.Base(module), module
+}
+
+// checkProjectName reports whether name can be the project directory and
+// the last element of its module path, suggesting a name that can.
+func checkProjectName(name string) error {
+	valid := name != "" && !strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "-") && !strings.HasSuffix(name, ".")
+	for _, r := range name {
+		valid = valid && modulePathRune(r)
+	}
+	if valid {
+		return nil
+	}
+
+	// Lowercase, with runs of other characters as one dash.
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if modulePathRune(r) && r != '-' {
+			b.WriteRune(r)
+		} else if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	fixed := strings.Trim(b.String(), "-.")
+	if fixed == "" {
+		return fmt.Errorf("invalid project name %q: use letters, digits and - . _ ~", name)
+	}
+	return fmt.Errorf("invalid project name %q: it names the directory and ends the module path, so use letters, digits and - . _ ~ (e.g. %s)", name, fixed)
+}
+
+// inferModule picks the module path for a project init wasn't given
+// --module for. In order it takes the module the init argument names,
+// default_module_prefix from the user config joined with the project
+// name, or the origin remote of the git repository dir is in joined with
+// the project's path in it, and otherwise asks. source says where an
+// inferred path came from, so the user can confirm it; it is empty for a
+// typed one.
+func inferModule(dir, projectName, argModule string) (module, source string, err error) {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case argModule != "":
+		module, source = argModule, "the project argument"
+	case userConfig.DefaultModulePrefix != "":
+		configPath, _ := config.UserConfigPath()
+		module = path.Join(strings.TrimSuffix(userConfig.DefaultModulePrefix, "/"), projectName)
+		source = "default_module_prefix in " + configPath
+	default:
+		module = gitRemoteModule(dir, projectName)
+		source = "git remote origin"
+	}
+	if module != "" {
+		if err := checkModulePath(module); err != nil {
+			return "", "", fmt.Errorf("module path %s, inferred from %s: %w; pass --module", module, source, err)
+		}
+		return module, source, nil
+	}
+
+	if !ui.Interactive() {
+		return "", "", fmt.Errorf("--module is required: set default_module_prefix in the user config or run init inside a git repository with an origin remote to infer it")
+	}
+	module = ui.Ask("Go module path (e.g. github.com/me/"+projectName+"):", "")
+	if module == "" {
+		return "", "", fmt.Errorf("no module path given; pass --module")
+	}
+	return module, "", nil
+}
+
+// gitRemoteModule returns the module path for projectName created in dir
+// from the origin remote of the git repository dir is in, or "" if there
+// is none.
+func gitRemoteModule(dir, projectName string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		return ""
+	}
+	ctx := context.Background()
+	remote, err := execx.Git(ctx, dir, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	// --show-prefix is dir relative to the repository root, e.g. "services/".
+	prefix, err := execx.Git(ctx, dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return ""
+	}
+	base := repoModulePath(strings.TrimSpace(string(remote)))
+	if base == "" {
+		return ""
+	}
+	return path.Join(base, strings.TrimSpace(string(prefix)), projectName)
+}
+
+// repoModulePath turns a repository URL into the module path it hosts:
+// https://github.com/acme/billing.git, ssh://git@github.com:22/acme/billing
+// and git@github.com:acme/billing.git all become github.com/acme/billing.
+func repoModulePath(url string) string {
+	u := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(url), "/"), ".git")
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		host, p, _ := strings.Cut(rest, "/")
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			host = h
+		}
+		host, _, _ = strings.Cut(host, ":")
+		u = host + "/" + p
+	} else if user, rest, ok := strings.Cut(u, "@"); ok && !strings.Contains(user, "/") {
+		// scp-like syntax: git@github.com:acme/billing
+		u = strings.Replace(rest, ":", "/", 1)
+	}
+	host, p, _ := strings.Cut(strings.TrimSuffix(u, "/"), "/")
+	if p == "" {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(host) + "/" + p
+}
+
+// checkModulePath reports whether p is a valid module path, as
+// module.CheckPath does, with an error that doesn't repeat p.
+func checkModulePath(p string) error {
+	err := module.CheckPath(p)
+	var invalid *module.InvalidPathError
+	if errors.As(err, &invalid) {
+		return invalid.Err
+	}
+	return err
+}
+
+func modulePathRune(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+func TestRepoModulePath(t *testing.T) {
+	tests := []struct{ url, want string }{
+		{"github.com/acme/billing", "github.com/acme/billing"},
+		{"https://github.com/acme/billing.git", "github.com/acme/billing"},
+		{"https://GitHub.com/acme/Billing/", "github.com/acme/Billing"},
+		{"ssh://git@github.com:22/acme/billing", "github.com/acme/billing"},
+		{"git@github.com:acme/billing.git", "github.com/acme/billing"},
+		{"gitlab.example.com/group/sub/billing", "gitlab.example.com/group/sub/billing"},
+	}
+	for _, tt := range tests {
+		if got := repoModulePath(tt.url); got != tt.want {
+			t.Errorf("repoModulePath(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckModulePath(t *testing.T) {
+	tests := []struct {
+		path string
+		err  string // Empty for a valid path
+	}{
+		{"github.com/acme/billing", ""},
+		{"github.com/acme/billing/v2", ""},
+		{"example.com/a_b~c.d", ""},
+		{"acme/billing", "missing dot in first path element"},
+		{"GitHub.com/acme/billing", `invalid char 'G' in first path element`},
+		{"github.com/acme/billing/v1", "invalid version"},
+		{"github.com//billing", "double slash"},
+		{"github.com/acme/.billing", "leading dot in path element"},
+		{"github.com/acme/bill ing", "invalid char ' '"},
+	}
+	for _, tt := range tests {
+		err := checkModulePath(tt.path)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("checkModulePath(%q) = %v", tt.path, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("checkModulePath(%q) = %v, want an error with %q", tt.path, err, tt.err)
+		case err != nil && strings.Contains(err.Error(), tt.path):
+			t.Errorf("checkModulePath(%q) = %v, which repeats the path", tt.path, err)
+		}
+	}
+}
+
+// TestInferModule takes the module path for a project named billing in
+// services/ of a repository with an origin remote, with every source of
+// it set and then with each dropped in turn: the init argument comes
+// first, then default_module_prefix, then the remote, and without any a
+// run without a terminal fails.
+func TestInferModule(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", "git@github.com:acme/workspace.git"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	dir := filepath.Join(repo, "services")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	setPrefix := func(prefix string) {
+		t.Helper()
+		p, err := config.UserConfigPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("default_module_prefix: "+prefix+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setPrefix("example.com/acme/")
+	module, source, err := inferModule(dir, "billing", "github.com/acme/billing")
+	if err != nil || module != "github.com/acme/billing" || source != "the project argument" {
+		t.Errorf("with an argument: %q from %q, %v", module, source, err)
+	}
+	module, source, err = inferModule(dir, "billing", "")
+	if err != nil || module != "example.com/acme/billing" || !strings.HasPrefix(source, "default_module_prefix") {
+		t.Errorf("with default_module_prefix: %q from %q, %v", module, source, err)
+	}
+
+	setPrefix(`""`)
+	module, source, err = inferModule(dir, "billing", "")
+	if err != nil || module != "github.com/acme/workspace/services/billing" || source != "git remote origin" {
+		t.Errorf("with a remote: %q from %q, %v", module, source, err)
+	}
+
+	if _, _, err := inferModule(t.TempDir(), "billing", ""); err == nil || !strings.Contains(err.Error(), "--module is required") {
+		t.Errorf("without a source: %v, want --module is required", err)
+	}
+
+	setPrefix("acme")
+	if _, _, err := inferModule(dir, "billing", ""); err == nil || !strings.Contains(err.Error(), "missing dot in first path element") {
+		t.Errorf("with default_module_prefix acme: %v, want the path rejected", err)
+	}
+}
+
+// TestInitModuleFlag inits a project with --module where every other
+// source of a module path is set: the flag wins.
+func TestInitModuleFlag(t *testing.T) {
+	checkout, err := filepath.Abs(filepath.Join("..", "scaffold", "testdata", "manifesto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	p, err := config.UserConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte("default_module_prefix: example.com/acme\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	err = runCLI(t, dir, closedStdin(t, false), "init", "github.com/acme/shop", "--module", "example.com/shop",
+		"--profile", "quick", "--local", checkout, "--skip-tidy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := config.LoadManifest(filepath.Join(dir, "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Project.GoModule != "example.com/shop" {
+		t.Errorf("module = %q, want the --module example.com/shop", manifest.Project.GoModule)
+	}
+}
@@ -1,10 +1,14 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"sort"
 	"strings"
 
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
 	"github.com/Abraxas-365/manifesto-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -12,7 +16,41 @@ import (
 var modulesCmd = &cobra.Command{
 	Use:   "modules",
 	Short: "List available modules",
-	RunE:  runModules,
+	Long: `List the core libraries and wireable modules, marking those installed
+or wired in the current project.
+
+  manifesto modules
+  manifesto modules --json   # for scripts; works outside a project too`,
+	RunE: runModules,
+}
+
+var modulesJSON bool
+
+func init() {
+	modulesCmd.Flags().BoolVar(&modulesJSON, "json", false, "Print the modules as JSON")
+}
+
+// modulesDoc is the document printed by modules --json. Lists are never
+// null, so scripts can iterate them without checks.
+type modulesDoc struct {
+	Libraries []libraryEntry  `json:"libraries"`
+	Wireable  []wireableEntry `json:"wireable"`
+}
+
+type libraryEntry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Deps        []string `json:"deps"`
+	Core        bool     `json:"core"`
+	Installed   bool     `json:"installed"`
+	Version     string   `json:"version,omitempty"` // Manifesto ref it was installed from
+}
+
+type wireableEntry struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Wired           bool     `json:"wired"`
+	RequiredModules []string `json:"required_modules"`
 }
 
 func runModules(cmd *cobra.Command, args []string) error {
@@ -31,9 +69,11 @@ func runModules(cmd *cobra.Command, args []string) error {
 	var libraries []ui.ModuleDisplay
 	for _, name := range libraryNames {
 		mod := config.ModuleRegistry[name]
-		installed := false
+		installed, version := false, ""
 		if manifest != nil {
-			_, installed = manifest.Modules[name]
+			var mc config.ModuleConfig
+			mc, installed = manifest.Modules[name]
+			version = mc.Version
 		}
 
 		deps := ""
@@ -47,28 +87,74 @@ func runModules(cmd *cobra.Command, args []string) error {
 			Installed:   installed,
 			Core:        mod.Core,
 			Deps:        deps,
+			Version:     version,
 		})
 	}
 
 	// Collect wireable modules
 	wireableNames := config.WireableModuleNames()
-	sort.Strings(wireableNames)
+
+	if modulesJSON {
+		return printModulesJSON(libraryNames, wireableNames, manifest)
+	}
 
 	var wireables []ui.WireableModuleDisplay
 	for _, name := range wireableNames {
 		spec := config.WireableModuleRegistry[name]
-		wired := false
+		wired, version := false, ""
 		if manifest != nil {
 			wired = manifest.IsWired(name)
+			version = manifest.Modules[name].Version
 		}
 
 		wireables = append(wireables, ui.WireableModuleDisplay{
 			Name:        name,
 			Description: spec.Description,
 			Wired:       wired,
+			Version:     version,
 		})
 	}
 
 	ui.PrintModulesWithSections(libraries, wireables)
+	if manifest != nil {
+		if mixed := manifest.MixedMajors(); mixed != "" {
+			ui.StepWarn(scaffold.MixedMajorsWarning(mixed))
+			fmt.Println()
+		}
+	}
 	return nil
 }
+
+// printModulesJSON prints the modules as a modulesDoc. Without a manifest
+// nothing is installed or wired.
+func printModulesJSON(libraryNames, wireableNames []string, manifest *config.Manifest) error {
+	doc := modulesDoc{Libraries: []libraryEntry{}, Wireable: []wireableEntry{}}
+	for _, name := range libraryNames {
+		mod := config.ModuleRegistry[name]
+		entry := libraryEntry{
+			Name:        name,
+			Description: mod.Description,
+			Deps:        append([]string{}, mod.Deps...),
+			Core:        mod.Core,
+		}
+		if manifest != nil {
+			var mc config.ModuleConfig
+			mc, entry.Installed = manifest.Modules[name]
+			entry.Version = mc.Version
+		}
+		doc.Libraries = append(doc.Libraries, entry)
+	}
+	for _, name := range wireableNames {
+		spec := config.WireableModuleRegistry[name]
+		doc.Wireable = append(doc.Wireable, wireableEntry{
+			Name:            name,
+			Description:     spec.Description,
+			Wired:           manifest != nil && manifest.IsWired(name),
+			RequiredModules: append([]string{}, spec.RequiredModules...),
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
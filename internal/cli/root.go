@@ -1,34 +1,106 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var Version = "dev"
 
+// noColor is set by --no-color.
+var noColor bool
+
 var rootCmd = &cobra.Command{
 	Use:   "manifesto",
 	Short: "Create production-grade Go apps with DDD architecture",
+	// Execute prints the error itself; don't dump usage for runtime failures.
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if noColor {
+			ui.DisableColor()
+		}
+		if err := clock.FromEnv(); err != nil {
+			return err
+		}
+		userConfig, err := config.LoadUserConfig()
+		if err != nil {
+			return err
+		}
+		remote.ConfiguredDefaultRef = userConfig.DefaultRef
+		// The project's own wireable modules, so every command knows them.
+		projectRoot, err := findProjectRoot()
+		if err != nil {
+			return err
+		}
+		return config.LoadProjectWireables(projectRoot)
+	},
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitError is a failure that exits with a specific code, for modes like
+// add --check whose exit status is part of their interface.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&remote.Offline, "offline", false, "Use only cached manifesto archives; fail instead of downloading")
+	rootCmd.PersistentFlags().BoolVar(&remote.Refresh, "refresh", false, "Download manifesto archives again even when cached")
+	rootCmd.MarkFlagsMutuallyExclusive("offline", "refresh")
+	rootCmd.PersistentFlags().StringSliceVar(&remote.RewriteExtensions, "rewrite-ext", nil, "Also rewrite the upstream module path in fetched files with these extensions (e.g. yaml,mod); other non-Go files are copied as they are")
+	rootCmd.PersistentFlags().BoolVar(&remote.RewriteStrings, "rewrite-strings", false, "Also rewrite Go string literals that are exactly an import path of the upstream module")
+	rootCmd.PersistentFlags().BoolVar(&execx.Verbose, "verbose", false, "Stream the output of go commands instead of showing it only on failure")
+	rootCmd.PersistentFlags().StringVar(&remote.Token, "token", "", "GitHub token for private manifesto forks and higher rate limits (default $"+remote.TokenEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Print plain text without colors (default when $NO_COLOR is set or stdout isn't a terminal)")
+	rootCmd.PersistentFlags().BoolVarP(&ui.AssumeYes, "yes", "y", false, "Don't prompt; take each prompt's default answer (for CI and scripts)")
+	rootCmd.PersistentFlags().BoolVar(&ui.AssumeYes, "non-interactive", false, "Same as --yes")
+
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(modulesCmd)
+	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(graphCmd)
+	rootCmd.AddCommand(domainsCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(versionsCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(contextCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(lintArchCmd)
+	rootCmd.AddCommand(manifestCmd)
 }
 
 var versionCmd = &cobra.Command{
@@ -60,3 +132,14 @@ func findProjectRoot() (string, error) {
 	// Fallback to cwd.
 	return os.Getwd()
 }
+
+// loadManifest loads the project's manifest. A missing manifest means the
+// command wasn't run in a project; any other error is the manifest's own
+// and is returned as is, with its line and hint.
+func loadManifest(projectRoot string) (*config.Manifest, error) {
+	manifest, err := config.LoadManifest(projectRoot)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("not a manifesto project (no manifesto.yaml found)")
+	}
+	return manifest, err
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show drift between manifesto.yaml and the project",
+	Long: `Compare what manifesto.yaml records with what is in the project:
+
+  • installed modules against their directories, such as pkg/asyncx/
+  • wired modules against their code in cmd/container.go
+  • tracked domains against the files scaffolded for them
+
+Each is listed as installed (recorded and present), missing (recorded
+but gone) or untracked (present but not recorded, e.g. a module directory
+copied in by hand). status exits non-zero when anything is missing or
+untracked, so it can gate CI:
+
+  manifesto status
+
+'manifesto doctor' checks the markers and wiring in more depth.`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	status := scaffold.Status(projectRoot, manifest)
+	ui.PrintStatus(statusDisplays(status.Installed), statusDisplays(status.Missing), statusDisplays(status.Untracked))
+	if status.Drifted() {
+		return fmt.Errorf("manifesto.yaml and the project have drifted: %d missing, %d untracked", len(status.Missing), len(status.Untracked))
+	}
+	return nil
+}
+
+func statusDisplays(entries []scaffold.StatusEntry) []ui.StatusDisplay {
+	displays := make([]ui.StatusDisplay, 0, len(entries))
+	for _, e := range entries {
+		displays = append(displays, ui.StatusDisplay{Kind: e.Kind, Name: e.Name, Detail: e.Detail})
+	}
+	return displays
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var skipTidy bool
+
+// registerTidyFlag adds --skip-tidy to a command that changes the
+// project's dependencies.
+func registerTidyFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&skipTidy, "skip-tidy", false, "Don't run go mod tidy afterwards")
+}
+
+// tidyProject runs go mod tidy after add, as the next of steps or, when
+// steps is nil, on a spinner of its own. The change itself is complete by
+// then, so the error it returns, a missing go or a failed tidy, only
+// leaves go mod tidy as a manual step.
+func tidyProject(projectRoot string, steps *ui.Steps) error {
+	if err := toolchain.Check(toolchain.RequiredVersion(projectRoot)); err != nil {
+		return err
+	}
+	var spin *ui.Spinner
+	if steps != nil {
+		spin = steps.Start("Running go mod tidy...")
+	} else {
+		spin = ui.NewSpinner("Running go mod tidy...")
+		spin.Start()
+	}
+	if err := scaffold.Tidy(projectRoot); err != nil {
+		spin.Stop(false)
+		return err
+	}
+	spin.Stop(true)
+	return nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall <module>",
+	Short: "Remove a library module's files from the project",
+	Long: `Remove a library module, such as ai or asyncx, that the project no
+longer needs: its directories are deleted and it is dropped from
+manifesto.yaml and manifesto.lock.
+
+Core modules, dependencies of other installed modules and modules a wired
+module requires can't be uninstalled. When the project's own code still
+imports the module, the files are listed and you're asked to confirm;
+--force uninstalls without asking.
+
+  manifesto uninstall ai
+  manifesto uninstall asyncx --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUninstall,
+}
+
+var uninstallForce bool
+
+func init() {
+	uninstallCmd.Flags().BoolVar(&uninstallForce, "force", false, "Uninstall even when the project still imports the module")
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if err := scaffold.CheckUninstall(manifest, name); err != nil {
+		return err
+	}
+
+	mod := config.ModuleRegistry[name]
+	importers, err := scaffold.ModuleImporters(projectRoot, manifest.Project.GoModule, mod)
+	if err != nil {
+		return err
+	}
+	if len(importers) > 0 {
+		ui.StepWarn(fmt.Sprintf("%d file(s) still import %s:", len(importers), name))
+		for _, f := range importers {
+			ui.Dim.Printf("    %s\n", f)
+		}
+		fmt.Println()
+		if !uninstallForce && !ui.Confirm(fmt.Sprintf("Uninstall %s anyway? The project won't build until they stop importing it.", name), false) {
+			return fmt.Errorf("%s is still imported; remove those imports first, or pass --force", name)
+		}
+	}
+
+	if err := scaffold.UninstallModule(projectRoot, manifest, name); err != nil {
+		return err
+	}
+	ui.PrintUninstallSuccess(name, mod.Dirs())
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/diff"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade <module>",
+	Short: "Move an installed module to another manifesto version, keeping your changes",
+	Long: `Move an installed module to another manifesto version with a three-way
+merge against the version it was installed from, read again from the
+manifesto archive.
+
+Files you haven't edited since they were fetched (see manifesto.lock) are
+replaced. Files you changed that upstream didn't are kept. Files changed
+on both sides are merged; hunks that conflict are written between
+<<<<<<< and >>>>>>> markers for you to resolve, and the command exits
+non-zero. --theirs takes upstream's side of every conflict and --ours
+keeps yours, for scripts and CI.
+
+  manifesto upgrade fsx --ref v2.1.0
+  manifesto upgrade iam --theirs   # the project's channel, e.g. the latest release`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUpgrade,
+}
+
+var (
+	upgradeRef    string
+	upgradeOurs   bool
+	upgradeTheirs bool
+)
+
+func init() {
+	upgradeCmd.Flags().StringVar(&upgradeRef, "ref", "", "Manifesto version to move the module to (default: as for add)")
+	upgradeCmd.Flags().BoolVar(&upgradeOurs, "ours", false, "Keep your side of conflicting hunks")
+	upgradeCmd.Flags().BoolVar(&upgradeTheirs, "theirs", false, "Take upstream's side of conflicting hunks")
+	upgradeCmd.MarkFlagsMutuallyExclusive("ours", "theirs")
+	registerTidyFlag(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	manifest, err := loadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+	if upgradeRef != "" {
+		if err := remote.ValidateRef(upgradeRef, remote.RefType(manifest.Project.RefType)); err != nil {
+			return err
+		}
+	}
+
+	resolve, resolved := diff.Mark, ""
+	switch {
+	case upgradeOurs:
+		resolve, resolved = diff.Ours, "ours"
+	case upgradeTheirs:
+		resolve, resolved = diff.Theirs, "theirs"
+	}
+
+	fmt.Println()
+	result, err := scaffold.UpgradeModule(scaffold.UpgradeOptions{
+		ProjectRoot: projectRoot,
+		ModuleName:  args[0],
+		Ref:         upgradeRef,
+		Resolve:     resolve,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !skipTidy {
+		if err := tidyProject(projectRoot, nil); err != nil {
+			ui.PrintDeferred(err.Error(), toolchain.Guidance(err), projectRoot, []string{"go mod tidy"})
+		}
+	}
+
+	ui.PrintUpgradeSuccess(ui.UpgradeSummary{
+		Module:    args[0],
+		From:      result.From,
+		To:        result.To,
+		Added:     result.Added,
+		Replaced:  result.Replaced,
+		Removed:   result.Removed,
+		Kept:      result.Kept,
+		Merged:    result.Merged,
+		Conflicts: result.Conflicts,
+		Resolved:  resolved,
+	})
+	if len(result.Conflicts) > 0 && resolved == "" {
+		return fmt.Errorf("%d file(s) have conflicts to resolve", len(result.Conflicts))
+	}
+	return nil
+}
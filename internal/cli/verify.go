@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
+	"github.com/Abraxas-365/manifesto-cli/internal/scaffold"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var noVerify bool
+
+// registerVerifyFlag adds --no-verify to a command that wires code into
+// the project.
+func registerVerifyFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Don't run go build ./... afterwards to check the project compiles")
+}
+
+// verifies reports whether add or init checks that the project builds:
+// unless --no-verify, after go mod tidy, which downloads what the build
+// needs. Offline, --skip-tidy skips both.
+func verifies() bool {
+	return !skipTidy && !noVerify
+}
+
+// verifyBuild runs go build ./... as the next of steps. A failed build
+// prints the compiler's errors and returns an error saying so.
+func verifyBuild(projectRoot string, steps *ui.Steps) error {
+	spin := steps.Start("Running go build ./...")
+	err := scaffold.Build(projectRoot)
+	spin.Stop(err == nil)
+	if err == nil {
+		return nil
+	}
+	var execErr *execx.Error
+	if !errors.As(err, &execErr) {
+		return err
+	}
+	fmt.Println()
+	ui.PrintBuildErrors(execErr.Output)
+	return fmt.Errorf("the project doesn't build after wiring; fix it, or revert the changes and re-run with --no-verify to wire anyway")
+}
+
+// wireFiles are the project files wiring may change besides a module's
+// own files.
+var wireFiles = []string{
+	"pkg/config/config.go",
+	"cmd/container.go",
+	"cmd/server.go",
+	"cmd/main.go",
+	"Makefile",
+	"docker-compose.yml",
+	".env",
+	".env.example",
+	"go.mod",
+	"go.sum",
+	config.ManifestoFile,
+	config.LockFile,
+	scaffold.TodoFile,
+}
+
+// wireSnapshot is the project as it was before modules were wired, so a
+// wiring that doesn't build can be reverted.
+type wireSnapshot struct {
+	root    string
+	files   map[string][]byte // By slash path; nil for a file that didn't exist
+	modules []string          // Library modules installed
+}
+
+func takeWireSnapshot(projectRoot string, manifest *config.Manifest, names []string) *wireSnapshot {
+	s := &wireSnapshot{
+		root:    projectRoot,
+		files:   make(map[string][]byte),
+		modules: slices.Collect(maps.Keys(manifest.Modules)),
+	}
+	paths := slices.Clone(wireFiles)
+	for _, name := range names {
+		paths = append(paths, slices.Collect(maps.Keys(config.WireableModuleRegistry[name].Files))...)
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(p)))
+		if err != nil {
+			data = nil
+		}
+		s.files[p] = data
+	}
+	return s
+}
+
+// restore puts the snapshot's files back and deletes the library modules
+// downloaded since it was taken.
+func (s *wireSnapshot) restore(manifest *config.Manifest) error {
+	for p, data := range s.files {
+		path := filepath.Join(s.root, filepath.FromSlash(p))
+		if data == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	for name := range manifest.Modules {
+		if slices.Contains(s.modules, name) {
+			continue
+		}
+		for _, p := range config.ModuleRegistry[name].Dirs() {
+			if err := os.RemoveAll(filepath.Join(s.root, filepath.FromSlash(p))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// offerRevert asks whether to undo a wiring that doesn't build, and does.
+// buildErr is returned either way, so the command fails.
+func offerRevert(snapshot *wireSnapshot, manifest *config.Manifest, buildErr error) error {
+	if !ui.Confirm("Revert the changes?", true) {
+		return buildErr
+	}
+	if err := snapshot.restore(manifest); err != nil {
+		return fmt.Errorf("revert: %w", err)
+	}
+	ui.StepDone("Reverted the project to how it was before")
+	return errors.New("wiring reverted: the project didn't build with it")
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "List the manifesto refs you can pass to --ref",
+	Long: `List the tags of the manifesto repo, newest first, with their release
+titles, followed by the notable branches (main, quick-project). Inside a
+project the ref it was created with is marked.
+
+The listing comes from the GitHub API and is cached for an hour; --refresh
+asks GitHub again and --offline only uses the cache. Set $GITHUB_TOKEN or
+--token for a private fork or a higher rate limit.
+
+  manifesto versions
+  manifesto versions --json
+  manifesto versions --source acme/manifesto`,
+	Args: cobra.NoArgs,
+	RunE: runVersions,
+}
+
+var (
+	versionsJSON   bool
+	versionsSource string
+)
+
+func init() {
+	versionsCmd.Flags().BoolVar(&versionsJSON, "json", false, "Print the refs as JSON")
+	versionsCmd.Flags().StringVar(&versionsSource, "source", "", "List the refs of this manifesto fork (owner/name); default: the project's repo")
+}
+
+// versionEntry is a ref as printed by versions --json.
+type versionEntry struct {
+	remote.Ref
+	Current bool `json:"current,omitempty"` // The ref the project was created with
+}
+
+func runVersions(cmd *cobra.Command, args []string) error {
+	projectRoot, _ := findProjectRoot()
+	manifest, _ := config.LoadManifest(projectRoot)
+
+	source, current := versionsSource, ""
+	if manifest != nil {
+		if source == "" {
+			source = manifest.Project.Repo
+		}
+		current = manifest.Project.Version
+	}
+	if source != "" {
+		if err := remote.ValidateRepo(source); err != nil {
+			return err
+		}
+	}
+
+	refs, err := remote.NewClient(source).ListRefs()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]versionEntry, 0, len(refs))
+	for _, r := range refs {
+		entries = append(entries, versionEntry{Ref: r, Current: r.Name == current})
+	}
+
+	if versionsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	var tags, branches []ui.RefDisplay
+	for _, e := range entries {
+		d := ui.RefDisplay{Name: e.Name, Release: e.Release, Latest: e.Latest, Current: e.Current}
+		if e.Type == remote.RefBranch {
+			branches = append(branches, d)
+		} else {
+			tags = append(tags, d)
+		}
+	}
+	repo := source
+	if repo == "" {
+		repo = remote.DefaultRepo
+	}
+	ui.PrintVersions(repo, tags, branches, current)
+	return nil
+}
@@ -0,0 +1,13 @@
+package cli
+
+import "github.com/spf13/cobra"
+
+var withTests bool
+
+// registerTestsFlag adds --with-tests to a command that downloads library
+// modules, and --include-tests, the name add and install first gave it.
+func registerTestsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&withTests, "with-tests", false, "Download modules with their _test.go files and testdata; recorded for later downloads")
+	cmd.Flags().BoolVar(&withTests, "include-tests", false, "Same as --with-tests")
+	cmd.Flags().MarkDeprecated("include-tests", "use --with-tests instead")
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// manifestoWithTests returns a copy of the fake manifesto whose modules
+// carry upstream tests and testdata.
+func manifestoWithTests(t *testing.T) string {
+	t.Helper()
+	checkout := copyManifesto(t)
+	for _, name := range []string{"pkg/ptrx/ptrx_test.go", "pkg/cronx/cronx_test.go", "pkg/jobx/jobx_test.go"} {
+		path := filepath.Join(checkout, filepath.FromSlash(name))
+		if err := os.WriteFile(path, []byte("package "+filepath.Base(filepath.Dir(path))+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		testdata := filepath.Join(filepath.Dir(path), "testdata")
+		if err := os.MkdirAll(testdata, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(testdata, "golden.json"), []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return checkout
+}
+
+// TestWithTestsRoundTrip inits a project without tests and adds modules
+// with and then without --with-tests, under both of its names: once a
+// download asks for tests, manifesto.yaml records it and later downloads
+// bring them too.
+func TestWithTestsRoundTrip(t *testing.T) {
+	for _, flag := range []string{"--with-tests", "--include-tests"} {
+		t.Run(flag, func(t *testing.T) {
+			checkout := manifestoWithTests(t)
+			root := initShopFrom(t, checkout)
+			assertTests(t, root, "pkg/ptrx", false)
+
+			if err := runCLI(t, root, closedStdin(t, false), "add", "cronx", flag, "--local", checkout, "--skip-tidy"); err != nil {
+				t.Fatal(err)
+			}
+			assertTests(t, root, "pkg/cronx", true)
+			manifest, err := config.LoadManifest(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !manifest.Project.Tests {
+				t.Fatalf("add %s didn't record tests in manifesto.yaml", flag)
+			}
+
+			if err := runCLI(t, root, closedStdin(t, false), "add", "jobx", "--local", checkout, "--skip-tidy"); err != nil {
+				t.Fatal(err)
+			}
+			assertTests(t, root, "pkg/jobx", true)
+		})
+	}
+}
+
+// TestInitWithTests checks init --with-tests fetches tests and records it.
+func TestInitWithTests(t *testing.T) {
+	checkout := manifestoWithTests(t)
+	root := initShopFrom(t, checkout, "--with-tests")
+	assertTests(t, root, "pkg/ptrx", true)
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !manifest.Project.Tests {
+		t.Error("init --with-tests didn't record tests in manifesto.yaml")
+	}
+}
+
+// assertTests checks whether the module directory dir holds its upstream
+// test file and testdata.
+func assertTests(t *testing.T, root, dir string, want bool) {
+	t.Helper()
+	base := filepath.Base(dir)
+	for _, name := range []string{base + "_test.go", "testdata/golden.json"} {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), filepath.FromSlash(name)))
+		if got := err == nil; got != want {
+			t.Errorf("%s/%s fetched = %v, want %v", dir, name, got, want)
+		}
+	}
+}
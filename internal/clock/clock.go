@@ -0,0 +1,37 @@
+// Package clock is the time source for every timestamp the CLI persists:
+// manifesto.yaml, the init state file and anything else written into a
+// generated project.
+package clock
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// EpochEnv pins the clock for reproducible scaffolds, as defined by
+// https://reproducible-builds.org/specs/source-date-epoch/.
+const EpochEnv = "SOURCE_DATE_EPOCH"
+
+// Now returns the current time in UTC, to the second, which is how
+// timestamps are persisted. Replace it to pin the clock.
+var Now = func() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// FromEnv pins Now to $SOURCE_DATE_EPOCH when it is set, so two runs of
+// the same command write byte-identical files.
+func FromEnv() error {
+	v := os.Getenv(EpochEnv)
+	if v == "" {
+		return nil
+	}
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || sec < 0 {
+		return fmt.Errorf("invalid %s %q: expected seconds since the Unix epoch", EpochEnv, v)
+	}
+	t := time.Unix(sec, 0).UTC()
+	Now = func() time.Time { return t }
+	return nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Where wiring writes a module's environment variables.
+const (
+	EnvTargetMakefile = "makefile" // export lines in the Makefile
+	EnvTargetDotenv   = "dotenv"   // .env.example and .env, which the Makefile loads
+	EnvTargetBoth     = "both"
+)
+
+// DefaultEnvTarget is the env target of projects that don't record one.
+const DefaultEnvTarget = EnvTargetMakefile
+
+var envTargets = []string{EnvTargetMakefile, EnvTargetDotenv, EnvTargetBoth}
+
+// LookupEnvTarget checks that name is an env target. An empty name selects
+// DefaultEnvTarget.
+func LookupEnvTarget(name string) (string, error) {
+	if name == "" {
+		return DefaultEnvTarget, nil
+	}
+	if !HasModule(envTargets, name) {
+		return "", fmt.Errorf("unknown env target: '%s'. Available: %s", name, strings.Join(envTargets, ", "))
+	}
+	return name, nil
+}
+
+// EnvTarget returns where wiring writes the project's environment
+// variables.
+func (m *Manifest) EnvTarget() (string, error) {
+	return LookupEnvTarget(m.Project.EnvTarget)
+}
+
+// EnvVar is one environment variable a wireable module sets.
+type EnvVar struct {
+	Name        string
+	Default     string // As in .env: Make's $(VAR) references become ${VAR}
+	Description string // From the comment above its export, if any
+}
+
+var makeVarRefRe = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// EnvVars returns the variables the module's makefile_env exports, in
+// order. Comment lines directly above an export describe it; the
+// "# ===" banners and their title don't.
+func (m WireableModule) EnvVars() []EnvVar {
+	var vars []EnvVar
+	var desc []string
+	for _, line := range strings.Split(m.MakefileEnv, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", strings.HasPrefix(line, "# ==="), strings.HasPrefix(line, "# Environment Variables"):
+			desc = nil
+		case strings.HasPrefix(line, "#"):
+			desc = append(desc, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+		case strings.HasPrefix(line, "export "):
+			name, value, _ := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			vars = append(vars, EnvVar{
+				Name:        strings.TrimSpace(name),
+				Default:     makeVarRefRe.ReplaceAllString(strings.TrimSpace(value), "$${$1}"),
+				Description: strings.Join(desc, " "),
+			})
+			desc = nil
+		}
+	}
+	return vars
+}
+
+// EnvSection returns the title of the module's makefile_env banner, e.g.
+// "Storage Configuration", or the module's name when it has none.
+func (m WireableModule) EnvSection() string {
+	for _, line := range strings.Split(m.MakefileEnv, "\n") {
+		if _, title, ok := strings.Cut(line, "# Environment Variables - "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return m.Name
+}
@@ -0,0 +1,11 @@
+package config
+
+// FollowUp is a step left to the user after a module is wired or a domain
+// is scaffolded. EnvVar, Table and File let the CLI tick it off by itself;
+// an item with none of them stays open until the user checks it in TODO.md.
+type FollowUp struct {
+	Text   string `yaml:"text"`
+	EnvVar string `yaml:"env_var,omitempty"` // Done once .env sets this variable to a non-empty value
+	Table  string `yaml:"table,omitempty"`   // Done once a migration in migrations/ creates this table
+	File   string `yaml:"file,omitempty"`    // Done once this file, relative to the project root, exists
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of edge in a ModuleGraph.
+const (
+	EdgeDep      = "dep"      // A library module's Deps
+	EdgeDownload = "download" // A wireable module's RequiredModules
+	EdgeWire     = "wire"     // A wireable module's RequiredWireables
+	EdgeBridge   = "bridge"   // A bridge's RequiresModule; optional, so never a cycle
+)
+
+// GraphNode is a module in a ModuleGraph. A name such as fsx can be both a
+// library, downloaded into the project, and a wireable module.
+type GraphNode struct {
+	Name     string
+	Library  bool
+	Wireable bool
+	Edges    []GraphEdge
+}
+
+// GraphEdge points from a module to one it needs.
+type GraphEdge struct {
+	To   string
+	Kind string // EdgeDep, EdgeDownload, EdgeWire or EdgeBridge
+}
+
+// ModuleGraph is what pulls in what among the library and wireable
+// modules.
+type ModuleGraph struct {
+	Nodes map[string]*GraphNode
+}
+
+// NewModuleGraph builds the graph of modules and wireables. Bridges to
+// profile infrastructure such as postgres get a node of their own.
+func NewModuleGraph(modules map[string]Module, wireables map[string]WireableModule) *ModuleGraph {
+	g := &ModuleGraph{Nodes: make(map[string]*GraphNode)}
+	for name, mod := range modules {
+		n := g.node(name)
+		n.Library = true
+		for _, dep := range mod.Deps {
+			n.Edges = append(n.Edges, GraphEdge{To: dep, Kind: EdgeDep})
+		}
+	}
+	for name, spec := range wireables {
+		n := g.node(name)
+		n.Wireable = true
+		for _, m := range spec.RequiredModules {
+			// A module that downloads its own source, like fsx, points
+			// at itself; that edge says nothing.
+			if m != name {
+				n.Edges = append(n.Edges, GraphEdge{To: m, Kind: EdgeDownload})
+			}
+		}
+		for _, w := range spec.RequiredWireables {
+			n.Edges = append(n.Edges, GraphEdge{To: w, Kind: EdgeWire})
+		}
+		for _, b := range spec.Bridges {
+			n.Edges = append(n.Edges, GraphEdge{To: b.RequiresModule, Kind: EdgeBridge})
+		}
+	}
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			g.node(e.To)
+		}
+		slices.SortStableFunc(n.Edges, func(a, b GraphEdge) int { return strings.Compare(a.To, b.To) })
+	}
+	return g
+}
+
+// RegistryGraph returns the graph of ModuleRegistry and
+// WireableModuleRegistry.
+func RegistryGraph() *ModuleGraph {
+	return NewModuleGraph(ModuleRegistry, WireableModuleRegistry)
+}
+
+func (g *ModuleGraph) node(name string) *GraphNode {
+	n, ok := g.Nodes[name]
+	if !ok {
+		n = &GraphNode{Name: name}
+		g.Nodes[name] = n
+	}
+	return n
+}
+
+// Names returns the names of the graph's nodes, sorted.
+func (g *ModuleGraph) Names() []string {
+	names := make([]string, 0, len(g.Nodes))
+	for name := range g.Nodes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Roots returns the modules no other module needs, sorted. Bridges don't
+// count: they are optional.
+func (g *ModuleGraph) Roots() []string {
+	needed := make(map[string]bool)
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			if e.Kind != EdgeBridge {
+				needed[e.To] = true
+			}
+		}
+	}
+	var roots []string
+	for _, name := range g.Names() {
+		if !needed[name] {
+			roots = append(roots, name)
+		}
+	}
+	return roots
+}
+
+// CycleError reports modules that need each other in a loop.
+type CycleError struct {
+	Path []string // The modules in the cycle, the first repeated at the end
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Path, " → ")
+}
+
+// Resolve returns names and every module they need through edges of the
+// given kinds, each after the ones it needs. A cycle is reported as a
+// *CycleError along with the order, which skips the edge closing it.
+func (g *ModuleGraph) Resolve(names []string, kinds ...string) ([]string, error) {
+	var order, path []string
+	var cycle error
+	done := make(map[string]bool)
+	onPath := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		if done[name] {
+			return
+		}
+		if onPath[name] {
+			if cycle == nil {
+				start := slices.Index(path, name)
+				cycle = &CycleError{Path: append(slices.Clone(path[start:]), name)}
+			}
+			return
+		}
+		onPath[name] = true
+		path = append(path, name)
+		if n, ok := g.Nodes[name]; ok {
+			for _, e := range n.Edges {
+				if slices.Contains(kinds, e.Kind) {
+					visit(e.To)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		onPath[name] = false
+		done[name] = true
+		order = append(order, name)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return order, cycle
+}
+
+// Cycles returns the cycles among the modules through every edge but
+// bridges, each once.
+func (g *ModuleGraph) Cycles() []*CycleError {
+	var cycles []*CycleError
+	seen := make(map[string]bool)
+	for _, name := range g.Names() {
+		_, err := g.Resolve([]string{name}, EdgeDep, EdgeDownload, EdgeWire)
+		if c, ok := err.(*CycleError); ok {
+			key := fmt.Sprint(canonicalCycle(c.Path))
+			if !seen[key] {
+				seen[key] = true
+				cycles = append(cycles, c)
+			}
+		}
+	}
+	return cycles
+}
+
+// canonicalCycle rotates a cycle path to start at its smallest name, so
+// the same cycle found from different modules compares equal.
+func canonicalCycle(path []string) []string {
+	loop := path[:len(path)-1]
+	start := slices.Index(loop, slices.Min(loop))
+	return append(slices.Clone(loop[start:]), loop[:start]...)
+}
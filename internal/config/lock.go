@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LockFile sits next to ManifestoFile and records the SHA-256 of every file
+// fetched from manifesto, so a later download can tell a hand-patched
+// module file from a pristine one.
+const LockFile = "manifesto.lock"
+
+// Lock is the content of LockFile.
+type Lock struct {
+	Files map[string]string // SHA-256 by slash path relative to the project root
+}
+
+// LoadLock reads the project's lock. A project without one gets an empty
+// lock, as does one created before the lock existed.
+func LoadLock(projectRoot string) (*Lock, error) {
+	lock := &Lock{Files: make(map[string]string)}
+	data, err := os.ReadFile(filepath.Join(projectRoot, LockFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		sum, path, ok := strings.Cut(text, "  ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<sha256>  <path>\"", LockFile, line)
+		}
+		lock.Files[strings.TrimSpace(path)] = sum
+	}
+	return lock, sc.Err()
+}
+
+// Save writes the lock, one "<sha256>  <path>" line per file, sorted by
+// path like the output of sha256sum.
+func (l *Lock) Save(projectRoot string) error {
+	paths := make([]string, 0, len(l.Files))
+	for p := range l.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	b.WriteString("# Generated by manifesto: the SHA-256 of each file fetched from manifesto.\n")
+	b.WriteString("# A file whose hash no longer matches was edited after it was fetched.\n")
+	for _, p := range paths {
+		fmt.Fprintf(&b, "%s  %s\n", l.Files[p], p)
+	}
+	return os.WriteFile(filepath.Join(projectRoot, LockFile), []byte(b.String()), 0644)
+}
+
+// Record sets the hash of the file at path to that of content.
+func (l *Lock) Record(path string, content []byte) {
+	l.Files[path] = fileHash(content)
+}
+
+// Modified returns the locked files under dirs (all of them when dirs is
+// empty) whose content on disk no longer matches the lock, sorted. A
+// deleted file isn't counted: downloading it again loses nothing.
+func (l *Lock) Modified(projectRoot string, dirs []string) []string {
+	var modified []string
+	for path, sum := range l.Files {
+		if len(dirs) > 0 && !underAny(path, dirs) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(path)))
+		if err != nil {
+			continue
+		}
+		if fileHash(data) != sum {
+			modified = append(modified, path)
+		}
+	}
+	sort.Strings(modified)
+	return modified
+}
+
+// Refresh re-hashes the locked files among paths from disk, for fetched
+// files the CLI edits itself, such as pkg/config/config.go when wiring.
+// It reports whether any hash changed.
+func (l *Lock) Refresh(projectRoot string, paths []string) bool {
+	changed := false
+	for _, path := range paths {
+		sum, ok := l.Files[path]
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(path)))
+		if err != nil {
+			continue
+		}
+		if h := fileHash(data); h != sum {
+			l.Files[path] = h
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Forget drops the locked files under dirs, for a module removed from
+// the project. It reports whether there were any.
+func (l *Lock) Forget(dirs []string) bool {
+	forgot := false
+	for path := range l.Files {
+		if underAny(path, dirs) {
+			delete(l.Files, path)
+			forgot = true
+		}
+	}
+	return forgot
+}
+
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// underAny reports whether path is one of dirs or inside one of them.
+func underAny(path string, dirs []string) bool {
+	for _, d := range dirs {
+		if path == d || strings.HasPrefix(path, d+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,29 +20,176 @@ type Manifest struct {
 	Project      ProjectConfig           `yaml:"project"`
 	Modules      map[string]ModuleConfig `yaml:"modules"`
 	WiredModules []string                `yaml:"wired_modules,omitempty"`
+	GoDeps       map[string][]string     `yaml:"go_deps,omitempty"`   // Go modules each wired module added to go.mod
+	Providers    map[string]string       `yaml:"providers,omitempty"` // Provider each wired module was wired with, if it has several
+	Bridges      []string                `yaml:"bridges,omitempty"`   // Bridges injected, by config.BridgeName
+	Domains      []DomainConfig          `yaml:"domains,omitempty"`
+	Arch         ArchConfig              `yaml:"arch,omitempty"` // Exceptions to the layering rules of lint-arch
 	CreatedAt    time.Time               `yaml:"created_at"`
 	UpdatedAt    time.Time               `yaml:"updated_at"`
+
+	// Warnings lists the mistakes ParseManifest worked around.
+	Warnings []string `yaml:"-"`
 }
 
 type ProjectConfig struct {
-	Name     string `yaml:"name"`
-	GoModule string `yaml:"go_module"`
-	Version  string `yaml:"manifesto_version"`
+	Name      string `yaml:"name"`
+	GoModule  string `yaml:"go_module"`
+	Version   string `yaml:"manifesto_version"`
+	RefType   string `yaml:"ref_type,omitempty"`   // How Version resolves (tag, branch, commit); empty means guessed
+	Channel   string `yaml:"channel,omitempty"`    // Ref later downloads use (stable, pinned, branch); empty means pinned
+	Repo      string `yaml:"repo,omitempty"`       // Manifesto fork modules are fetched from; empty means upstream
+	Profile   string `yaml:"profile,omitempty"`    // Init profile; empty means DefaultProfile
+	Naming    string `yaml:"naming,omitempty"`     // Domain package convention; empty means DefaultNaming
+	GRPC      bool   `yaml:"grpc,omitempty"`       // cmd/server.go also serves gRPC (init --grpc)
+	EnvTarget string `yaml:"env_target,omitempty"` // Where wiring writes env variables; empty means DefaultEnvTarget
+	Tests     bool   `yaml:"tests,omitempty"`      // Modules are fetched with their tests and testdata (--with-tests)
 }
 
 type ModuleConfig struct {
-	Version     string    `yaml:"version"`
-	InstalledAt time.Time `yaml:"installed_at"`
+	Version     string        `yaml:"version"`
+	InstalledAt time.Time     `yaml:"installed_at"`
+	SHA256      string        `yaml:"sha256,omitempty"`   // Of the archive the module was fetched from
+	Mappings    []PathMapping `yaml:"mappings,omitempty"` // Paths fetched from elsewhere in the archive
+}
+
+// DomainConfig records a scaffolded domain and the names it was given in
+// cmd/container.go, so later runs refer to it the same way.
+type DomainConfig struct {
+	Path           string    `yaml:"path"`
+	ContainerAlias string    `yaml:"container_alias"`
+	ContainerField string    `yaml:"container_field"`
+	Repo           string    `yaml:"repo,omitempty"`
+	Kind           string    `yaml:"kind,omitempty"`
+	Transport      string    `yaml:"transport,omitempty"`
+	Naming         string    `yaml:"naming,omitempty"` // Set when it overrides the project's convention
+	Table          string    `yaml:"table,omitempty"`  // Table and route name; empty for domains added before it was recorded
+	Fields         string    `yaml:"fields,omitempty"` // --fields spec, e.g. "amount:decimal,paid:bool"
+	WithPolicy     bool      `yaml:"with_policy,omitempty"`
+	WithEvents     bool      `yaml:"with_events,omitempty"`
+	WithUploads    bool      `yaml:"with_uploads,omitempty"`
+	NoTests        bool      `yaml:"no_tests,omitempty"`
+	NoMocks        bool      `yaml:"no_mocks,omitempty"`
+	Public         bool      `yaml:"public,omitempty"`     // Routes registered outside the protected group
+	Group          string    `yaml:"group,omitempty"`      // Route group other than protected, from --group
+	Layers         []string  `yaml:"layers,omitempty"`     // --layers selection; empty means all
+	Deps           []string  `yaml:"deps,omitempty"`       // --deps selection, e.g. [db, redis]
+	CreatedAt      time.Time `yaml:"created_at,omitempty"` // First scaffolded; regenerating keeps it
+}
+
+// ArchConfig tunes the layering rules manifesto lint-arch enforces.
+// Package patterns are relative to the module root, with "..." matching
+// any string as in go list, e.g. "pkg/billing/...".
+type ArchConfig struct {
+	Allow   []ImportRule `yaml:"allow,omitempty"`   // Imports exempt from the rules
+	Deny    []ImportRule `yaml:"deny,omitempty"`    // Imports rejected on top of them
+	Outside []string     `yaml:"outside,omitempty"` // Domain layers code outside domains may import; empty means the default
+}
+
+// ImportRule matches imports of a package matching To by one matching From.
+type ImportRule struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
 }
 
+// Entries in a Module's Paths are evaluated in order, and the last one to
+// match a project path decides whether it is fetched. An entry matches a
+// path and everything under it. Its segments match as in path.Match, with
+// ** matching any number of segments, and one starting with ! leaves out
+// what it matches: "pkg/ai" then "!pkg/ai/testdata" fetches pkg/ai without
+// its fixtures, and "pkg/iam" then "!pkg/iam/examples" all of iam but its
+// examples.
 type Module struct {
 	Name        string
 	Description string
-	Paths       []string // Remote paths fetched from GitHub
+	Paths       []string      // Project paths fetched from the same path in the archive, or patterns of them
+	Mappings    []PathMapping // Paths that live elsewhere in the archive, e.g. in a monorepo
+	Tests       bool          // Always fetched with its tests, which serve as examples
 	Deps        []string
 	Core        bool
 }
 
+// Dirs returns the directories the module's Paths fetch into: each entry
+// not starting with ! up to its first segment with a wildcard, less those
+// inside another.
+func (m Module) Dirs() []string {
+	var entries []string
+	for _, p := range m.Paths {
+		if strings.HasPrefix(p, "!") {
+			continue
+		}
+		var literal []string
+		for _, seg := range strings.Split(p, "/") {
+			if strings.ContainsAny(seg, `*?[\`) {
+				break
+			}
+			literal = append(literal, seg)
+		}
+		if dir := strings.Join(literal, "/"); dir != "" {
+			entries = append(entries, dir)
+		}
+	}
+
+	var dirs []string
+	for _, dir := range entries {
+		inside := slices.ContainsFunc(entries, func(other string) bool {
+			return strings.HasPrefix(dir, other+"/")
+		})
+		if !inside && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// TestPatterns leave out the tests and fixtures under a module's Paths,
+// which are only fetched into projects that opt in with --with-tests, or
+// for a module whose Tests are kept.
+var TestPatterns = []string{"!**/*_test.go", "!**/testdata"}
+
+// FetchPaths returns the module's Paths followed by the TestPatterns,
+// unless tests or m.Tests.
+func (m Module) FetchPaths(tests bool) []string {
+	if tests || m.Tests {
+		return m.Paths
+	}
+	return append(slices.Clone(m.Paths), TestPatterns...)
+}
+
+// PathMapping places an archive directory at another path in the project.
+type PathMapping struct {
+	SrcPath  string `yaml:"src"`  // In the archive, e.g. libs/fsx/pkg/fsx
+	DestPath string `yaml:"dest"` // In the project, one of the module's Dirs
+}
+
+// Sources maps each of the module's Dirs to where it is fetched from.
+func (m Module) Sources() []PathMapping {
+	dirs := m.Dirs()
+	sources := make([]PathMapping, 0, len(dirs))
+	for _, p := range dirs {
+		src := p
+		for _, mp := range m.Mappings {
+			if mp.DestPath == p {
+				src = mp.SrcPath
+			}
+		}
+		sources = append(sources, PathMapping{SrcPath: src, DestPath: p})
+	}
+	return sources
+}
+
+// Remapped returns the Sources that don't come from the same path, as
+// recorded in the manifest.
+func (m Module) Remapped() []PathMapping {
+	var moved []PathMapping
+	for _, s := range m.Sources() {
+		if s.SrcPath != s.DestPath {
+			moved = append(moved, s)
+		}
+	}
+	return moved
+}
+
 var ModuleRegistry = map[string]Module{
 	"kernel": {
 		Name: "kernel", Description: "Domain primitives, value objects, pagination, UoW",
@@ -91,13 +243,17 @@ var ModuleRegistry = map[string]Module{
 		Name: "notifx", Description: "Email notifications (AWS SES)",
 		Paths: []string{"pkg/notifx"}, Core: false,
 	},
+	"cronx": {
+		Name: "cronx", Description: "Cron scheduler for periodic jobs",
+		Paths: []string{"pkg/cronx"}, Core: false,
+	},
 }
 
 // QuickProjectRef is kept for backwards compatibility but no longer needed.
 // Quick projects now use the same source as full projects (latest tag/main).
 const QuickProjectRef = ""
 
-// CoreModules returns all Core modules to download during init.
+// CoreModules returns all Core modules to download during init, sorted.
 func CoreModules(quick bool) []string {
 	var core []string
 	for name, mod := range ModuleRegistry {
@@ -105,31 +261,26 @@ func CoreModules(quick bool) []string {
 			core = append(core, name)
 		}
 	}
+	sort.Strings(core)
 	return core
 }
 
-func ResolveDeps(names []string) []string {
-	seen := make(map[string]bool)
-	var result []string
-
-	var resolve func(string)
-	resolve = func(name string) {
-		if seen[name] {
-			return
-		}
-		seen[name] = true
-		if mod, ok := ModuleRegistry[name]; ok {
-			for _, dep := range mod.Deps {
-				resolve(dep)
-			}
-		}
-		result = append(result, name)
+// ResolveDeps returns names and the library modules they depend on, each
+// after its dependencies. The order doesn't depend on the order of names.
+// A module missing from ModuleRegistry is an error, and so are
+// dependencies in a loop, which the error traces.
+func ResolveDeps(names []string) ([]string, error) {
+	sorted := slices.Compact(slices.Sorted(slices.Values(names)))
+	order, err := RegistryGraph().Resolve(sorted, EdgeDep)
+	if err != nil {
+		return nil, err
 	}
-
-	for _, n := range names {
-		resolve(n)
+	for _, name := range order {
+		if _, ok := ModuleRegistry[name]; !ok {
+			return nil, fmt.Errorf("unknown module: '%s'. Available: %s", name, strings.Join(moduleNames(), ", "))
+		}
 	}
-	return result
+	return order, nil
 }
 
 func HasModule(modules []string, name string) bool {
@@ -141,14 +292,180 @@ func HasModule(modules []string, name string) bool {
 	return false
 }
 
-// IsWired returns true if the given module name is in the manifest's WiredModules list.
+// ArchiveChanged returns a module installed from ref whose recorded
+// archive checksum isn't sum, meaning ref's content changed upstream since.
+// It returns "" when there is none.
+func (m *Manifest) ArchiveChanged(ref, sum string) string {
+	if sum == "" {
+		return ""
+	}
+	names := make([]string, 0, len(m.Modules))
+	for name := range m.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		mod := m.Modules[name]
+		if mod.Version == ref && mod.SHA256 != "" && mod.SHA256 != sum {
+			return name
+		}
+	}
+	return ""
+}
+
+var majorRe = regexp.MustCompile(`^v(\d+)(\.|$)`)
+
+// MixedMajors describes the modules installed from different major
+// versions of manifesto, which aren't expected to work together, e.g.
+// "v2 (ai), v1 (errx, kernel)". Branches and commits aren't compared. It
+// returns "" when every version tag shares one major version.
+func (m *Manifest) MixedMajors() string {
+	names := make([]string, 0, len(m.Modules))
+	for name := range m.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byMajor := make(map[string][]string)
+	var majors []string
+	for _, name := range names {
+		match := majorRe.FindStringSubmatch(m.Modules[name].Version)
+		if match == nil {
+			continue
+		}
+		if _, ok := byMajor[match[1]]; !ok {
+			majors = append(majors, match[1])
+		}
+		byMajor[match[1]] = append(byMajor[match[1]], name)
+	}
+	if len(majors) < 2 {
+		return ""
+	}
+
+	sort.Slice(majors, func(i, j int) bool {
+		if len(majors[i]) != len(majors[j]) {
+			return len(majors[i]) > len(majors[j])
+		}
+		return majors[i] > majors[j]
+	})
+	groups := make([]string, len(majors))
+	for i, major := range majors {
+		groups[i] = fmt.Sprintf("v%s (%s)", major, strings.Join(byMajor[major], ", "))
+	}
+	return strings.Join(groups, ", ")
+}
+
+// IsWired reports whether the named module is wired: listed in WiredModules,
+// or set up by the templates of the project's profile.
 func (m *Manifest) IsWired(name string) bool {
 	for _, wm := range m.WiredModules {
 		if wm == name {
 			return true
 		}
 	}
-	return false
+	profile, err := m.Profile()
+	return err == nil && profile.Provides(name)
+}
+
+// ServesHTTP reports whether the project's profile has an HTTP server in
+// cmd/server.go; worker projects don't.
+func (m *Manifest) ServesHTTP() bool {
+	profile, err := m.Profile()
+	return err != nil || profile.HTTP
+}
+
+// WiredOrProvided returns WiredModules followed by what the project's profile
+// provides that isn't among them, for bridges to check against.
+func (m *Manifest) WiredOrProvided() []string {
+	wired := append([]string(nil), m.WiredModules...)
+	profile, err := m.Profile()
+	if err != nil {
+		return wired
+	}
+	for _, name := range append(WireableModuleNames(), profileInfrastructure...) {
+		if profile.Provides(name) && !HasModule(wired, name) {
+			wired = append(wired, name)
+		}
+	}
+	return wired
+}
+
+// AddWiredModule records name as wired, once however often it is wired.
+func (m *Manifest) AddWiredModule(name string) {
+	if !HasModule(m.WiredModules, name) {
+		m.WiredModules = append(m.WiredModules, name)
+	}
+}
+
+// AddBridges records bridges, named by BridgeName, as injected.
+func (m *Manifest) AddBridges(bridges ...string) {
+	for _, b := range bridges {
+		if !HasModule(m.Bridges, b) {
+			m.Bridges = append(m.Bridges, b)
+		}
+	}
+}
+
+// SetGoDeps records the Go modules wiring name added to go.mod, so they can
+// be traced back to it once it is unwired.
+func (m *Manifest) SetGoDeps(name string, deps []string) {
+	if len(deps) == 0 {
+		return
+	}
+	if m.GoDeps == nil {
+		m.GoDeps = make(map[string][]string)
+	}
+	m.GoDeps[name] = slices.Sorted(slices.Values(deps))
+}
+
+// SetProvider records the provider name was wired with. Modules without
+// providers record nothing.
+func (m *Manifest) SetProvider(name, provider string) {
+	if provider == "" {
+		return
+	}
+	if m.Providers == nil {
+		m.Providers = make(map[string]string)
+	}
+	m.Providers[name] = provider
+}
+
+// WiredSpec returns the spec of the wired module name with the provider it
+// was wired with; a module recorded without one gets its default.
+func (m *Manifest) WiredSpec(name string) (WireableModule, error) {
+	spec, ok := WireableModuleRegistry[name]
+	if !ok {
+		return spec, fmt.Errorf("unknown wireable module: %s", name)
+	}
+	return spec.WithProvider(m.Providers[name])
+}
+
+// Domain returns the recorded entry for the domain at path.
+func (m *Manifest) Domain(path string) (DomainConfig, bool) {
+	for _, d := range m.Domains {
+		if d.Path == path {
+			return d, true
+		}
+	}
+	return DomainConfig{}, false
+}
+
+// SetDomain records d, replacing any entry with the same path but keeping
+// its CreatedAt.
+func (m *Manifest) SetDomain(d DomainConfig) {
+	for i := range m.Domains {
+		if m.Domains[i].Path == d.Path {
+			if d.CreatedAt.IsZero() {
+				d.CreatedAt = m.Domains[i].CreatedAt
+			}
+			m.Domains[i] = d
+			return
+		}
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = clock.Now()
+	}
+	m.Domains = append(m.Domains, d)
 }
 
 func LoadManifest(projectRoot string) (*Manifest, error) {
@@ -157,31 +474,40 @@ func LoadManifest(projectRoot string) (*Manifest, error) {
 	if err != nil {
 		return nil, fmt.Errorf("no manifesto.yaml at %s: %w", projectRoot, err)
 	}
-	var m Manifest
-	if err := yaml.Unmarshal(data, &m); err != nil {
-		return nil, fmt.Errorf("invalid manifesto.yaml: %w", err)
-	}
-	return &m, nil
+	return ParseManifest(data)
 }
 
 func (m *Manifest) Save(projectRoot string) error {
-	m.UpdatedAt = time.Now()
-	data, err := yaml.Marshal(m)
+	m.UpdatedAt = clock.Now()
+	m.Normalize()
+	data, err := m.Marshal()
 	if err != nil {
-		return fmt.Errorf("marshal manifesto.yaml: %w", err)
+		return err
 	}
 	return os.WriteFile(filepath.Join(projectRoot, ManifestoFile), data, 0644)
 }
 
-func NewManifest(name, goModule, version string) *Manifest {
+// Marshal returns the manifest as Save writes it. Map keys come out sorted,
+// so a normalized manifest always marshals to the same bytes.
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifesto.yaml: %w", err)
+	}
+	return data, nil
+}
+
+func NewManifest(name, goModule, version, profile string) *Manifest {
+	now := clock.Now()
 	return &Manifest{
 		Project: ProjectConfig{
 			Name:     name,
 			GoModule: goModule,
 			Version:  version,
+			Profile:  profile,
 		},
 		Modules:   make(map[string]ModuleConfig),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 }
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestModuleDirs(t *testing.T) {
+	tests := []struct {
+		paths, want []string
+	}{
+		{[]string{"pkg/ai"}, []string{"pkg/ai"}},
+		{[]string{"pkg/ai", "!pkg/ai/testdata"}, []string{"pkg/ai"}},
+		{[]string{"pkg/iam", "!pkg/iam/examples", "pkg/iam/examples/README.md"}, []string{"pkg/iam"}},
+		{[]string{"pkg/fsx/**/*.go", "pkg/fsx/*.md"}, []string{"pkg/fsx"}},
+		{[]string{"pkg/kernel", "pkg/ptrx", "pkg/kernel"}, []string{"pkg/kernel", "pkg/ptrx"}},
+		{[]string{"!**/testdata"}, nil},
+	}
+	for _, tt := range tests {
+		if got := (Module{Paths: tt.paths}).Dirs(); !slices.Equal(got, tt.want) {
+			t.Errorf("Dirs of %q = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestModuleFetchPaths(t *testing.T) {
+	mod := Module{Paths: []string{"pkg/ai", "!pkg/ai/examples"}}
+	if got, want := mod.FetchPaths(false), append(slices.Clone(mod.Paths), TestPatterns...); !slices.Equal(got, want) {
+		t.Errorf("FetchPaths(false) = %q, want %q", got, want)
+	}
+	for _, m := range []Module{mod, {Paths: mod.Paths, Tests: true}} {
+		if got := m.FetchPaths(!m.Tests); !slices.Equal(got, mod.Paths) {
+			t.Errorf("FetchPaths with tests = %q, want %q", got, mod.Paths)
+		}
+	}
+}
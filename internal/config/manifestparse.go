@@ -0,0 +1,467 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestError is a manifesto.yaml problem the parser can't work around.
+// Line and Column are 1-based; zero when unknown.
+type ManifestError struct {
+	Line    int
+	Column  int
+	Msg     string
+	Snippet string // The offending lines, with a caret under Column
+	Hint    string
+}
+
+func (e *ManifestError) Error() string {
+	var b strings.Builder
+	b.WriteString(ManifestoFile)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ":%d", e.Column)
+		}
+	}
+	b.WriteString(": " + e.Msg)
+	if e.Snippet != "" {
+		b.WriteString("\n\n" + e.Snippet)
+	}
+	if e.Hint != "" {
+		b.WriteString("\n  hint: " + e.Hint)
+	}
+	return b.String()
+}
+
+// manifestTimeLayouts are the timestamp forms accepted in hand-edited
+// manifests, after RFC 3339.
+var manifestTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// yamlSyntaxHints maps fragments of yaml.v3 syntax errors to advice.
+var yamlSyntaxHints = []struct{ fragment, hint string }{
+	{"found character that cannot start any token", "YAML doesn't allow tabs for indentation; indent with spaces"},
+	{"found a tab character", "YAML doesn't allow tabs for indentation; indent with spaces"},
+	{"mapping values are not allowed", "check this line's indentation, or quote a value that contains \": \""},
+	{"did not find expected key", "a line near here is indented differently from the keys around it"},
+	{"did not find expected '-' indicator", "items of the same list need the same indentation"},
+	{"could not find expected ':'", "each entry needs the form \"key: value\""},
+}
+
+var (
+	yamlLineRe      = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	yamlTypeErrorRe = regexp.MustCompile(`^line (\d+): (.*)$`)
+)
+
+// ParseManifest reads a manifest, working around the mistakes hand edits
+// tend to introduce: modules indented at the wrong level, a string or list
+// where the other is expected, and timestamps that are missing or not
+// timestamps. Each workaround is recorded in Manifest.Warnings. Problems it
+// can't resolve, such as YAML syntax errors and duplicate keys, are returned
+// as a *ManifestError.
+func ParseManifest(data []byte) (*Manifest, error) {
+	p := &manifestParser{lines: strings.Split(string(data), "\n")}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, p.syntaxError(err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, &ManifestError{Msg: "file is empty", Hint: "restore it from version control"}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, p.errorAt(root, "expected project, modules and other keys at the top level", "")
+	}
+	if err := p.checkDuplicates(root, ""); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Modules: make(map[string]ModuleConfig)}
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		var err error
+		switch key.Value {
+		case "project":
+			err = p.decode(value, &m.Project, "project")
+		case "modules":
+			err = p.modules(value, m)
+		case "wired_modules":
+			m.WiredModules = p.stringList(value, "wired_modules")
+		case "go_deps":
+			err = p.decode(value, &m.GoDeps, "go_deps")
+		case "providers":
+			err = p.decode(value, &m.Providers, "providers")
+		case "bridges":
+			m.Bridges = p.stringList(value, "bridges")
+		case "domains":
+			err = p.domains(value, m)
+		case "arch":
+			err = p.decode(value, &m.Arch, "arch")
+		case "created_at":
+			m.CreatedAt = p.timestamp(value, "created_at")
+		case "updated_at":
+			m.UpdatedAt = p.timestamp(value, "updated_at")
+		default:
+			if _, ok := ModuleRegistry[key.Value]; ok && value.Kind != yaml.SequenceNode {
+				p.warnf(key, "module %q is outside modules; indent it under modules", key.Value)
+				m.Modules[key.Value], err = p.module(key.Value, value)
+				break
+			}
+			p.warnf(key, "unknown key %q ignored", key.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.Warnings = p.warnings
+	return m, nil
+}
+
+type manifestParser struct {
+	lines    []string
+	warnings []string
+}
+
+func (p *manifestParser) warnf(n *yaml.Node, format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf("line %d: ", n.Line)+fmt.Sprintf(format, args...))
+}
+
+// errorAt returns a *ManifestError pointing at n.
+func (p *manifestParser) errorAt(n *yaml.Node, msg, hint string) *ManifestError {
+	return &ManifestError{
+		Line:    n.Line,
+		Column:  n.Column,
+		Msg:     msg,
+		Snippet: p.snippet(n.Line, n.Column),
+		Hint:    hint,
+	}
+}
+
+func (p *manifestParser) syntaxError(err error) error {
+	match := yamlLineRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return &ManifestError{Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	line, _ := strconv.Atoi(match[1])
+	// yaml.v3 reports a tab at the start of a line against the line
+	// before it.
+	if strings.Contains(match[2], "tab character") && line < len(p.lines) &&
+		!strings.HasPrefix(p.lines[line-1], "\t") && strings.HasPrefix(strings.TrimLeft(p.lines[line], " "), "\t") {
+		line++
+	}
+	e := &ManifestError{Line: line, Msg: match[2], Snippet: p.snippet(line, 0)}
+	for _, h := range yamlSyntaxHints {
+		if strings.Contains(e.Msg, h.fragment) {
+			e.Hint = h.hint
+			break
+		}
+	}
+	return e
+}
+
+// snippet renders the line before line and line itself with line numbers,
+// and a caret under column when it is known.
+func (p *manifestParser) snippet(line, column int) string {
+	if line < 1 || line > len(p.lines) {
+		return ""
+	}
+	var b strings.Builder
+	for n := max(line-1, 1); n <= line; n++ {
+		fmt.Fprintf(&b, "  %4d | %s\n", n, strings.ReplaceAll(p.lines[n-1], "\t", "→"))
+	}
+	if column > 0 {
+		fmt.Fprintf(&b, "       | %s^\n", strings.Repeat(" ", column-1))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// checkDuplicates rejects mappings that define a key twice. yaml.v3 only
+// does so when decoding into Go values, and picking either entry would
+// silently drop the other.
+func (p *manifestParser) checkDuplicates(n *yaml.Node, path string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		seen := make(map[string]*yaml.Node)
+		for i := 0; i < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if first, ok := seen[key.Value]; ok {
+				what := fmt.Sprintf("key %q", key.Value)
+				hint := "remove one of them"
+				if path == "modules" {
+					what = fmt.Sprintf("module %q", key.Value)
+					hint = "keep the entry with the version you have installed"
+				}
+				return p.errorAt(key, fmt.Sprintf("%s is defined twice (lines %d and %d)", what, first.Line, key.Line), hint)
+			}
+			seen[key.Value] = key
+			if err := p.checkDuplicates(value, key.Value); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if err := p.checkDuplicates(item, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decode decodes n into out, reporting a type mismatch at n.
+func (p *manifestParser) decode(n *yaml.Node, out any, what string) error {
+	if err := n.Decode(out); err != nil {
+		e := p.errorAt(n, what+": "+err.Error(), "")
+		if te, ok := err.(*yaml.TypeError); ok && len(te.Errors) > 0 {
+			e.Msg = what + ": " + te.Errors[0]
+			if match := yamlTypeErrorRe.FindStringSubmatch(te.Errors[0]); match != nil {
+				e.Line, _ = strconv.Atoi(match[1])
+				e.Column = 0
+				e.Msg = what + ": " + match[2]
+				e.Snippet = p.snippet(e.Line, 0)
+			}
+			if strings.Contains(e.Msg, "into bool") {
+				e.Hint = "use true or false"
+			}
+		}
+		return e
+	}
+	return nil
+}
+
+// modules accepts the usual name → {version, installed_at} mapping, a bare
+// version instead of the mapping, or a list of module names. Within the
+// mapping, a version or installed_at that lost its indentation is moved
+// back under the module above it.
+func (p *manifestParser) modules(n *yaml.Node, m *Manifest) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return nil
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return p.errorAt(item, "modules: expected a module name", "list modules as \"name: {version: ...}\" entries")
+			}
+			m.Modules[item.Value] = ModuleConfig{}
+		}
+		return nil
+	case yaml.MappingNode:
+		prev := ""
+		for i := 0; i < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if _, known := ModuleRegistry[key.Value]; !known && prev != "" && (key.Value == "version" || key.Value == "installed_at") {
+				mod := m.Modules[prev]
+				if key.Value == "version" && mod.Version == "" {
+					mod.Version = value.Value
+				} else if key.Value == "installed_at" && mod.InstalledAt.IsZero() {
+					mod.InstalledAt = p.timestamp(value, prev+".installed_at")
+				}
+				m.Modules[prev] = mod
+				p.warnf(key, "%s belongs to module %q; indent it under %s", key.Value, prev, prev)
+				continue
+			}
+			mod, err := p.module(key.Value, value)
+			if err != nil {
+				return err
+			}
+			m.Modules[key.Value] = mod
+			prev = key.Value
+		}
+		return nil
+	}
+	return p.errorAt(n, "modules: expected a mapping of module names", "")
+}
+
+// module decodes one modules entry: a mapping, a bare version, or nothing.
+func (p *manifestParser) module(name string, n *yaml.Node) (ModuleConfig, error) {
+	var mod ModuleConfig
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag != "!!null" {
+			mod.Version = n.Value
+		}
+		return mod, nil
+	case yaml.MappingNode:
+		for i := 0; i < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			switch key.Value {
+			case "version":
+				if value.Kind != yaml.ScalarNode {
+					return mod, p.errorAt(value, fmt.Sprintf("modules.%s.version: expected a version such as v1.2.0", name), "")
+				}
+				mod.Version = value.Value
+			case "installed_at":
+				mod.InstalledAt = p.timestamp(value, "modules."+name+".installed_at")
+			case "sha256":
+				mod.SHA256 = value.Value
+			case "mappings":
+				if err := p.decode(value, &mod.Mappings, "modules."+name+".mappings"); err != nil {
+					return mod, err
+				}
+			default:
+				p.warnf(key, "unknown key %q in module %q ignored", key.Value, name)
+			}
+		}
+		return mod, nil
+	}
+	return mod, p.errorAt(n, fmt.Sprintf("modules.%s: expected version and installed_at", name), "")
+}
+
+// domains decodes the domains list, accepting fields as a list of
+// name:type pairs as well as the comma-separated form.
+func (p *manifestParser) domains(n *yaml.Node, m *Manifest) error {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return nil
+	}
+	if n.Kind != yaml.SequenceNode {
+		return p.errorAt(n, "domains: expected a list", "start each domain with \"- path: ...\"")
+	}
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode {
+			return p.errorAt(item, "domains: expected path, container_alias and container_field", "")
+		}
+		var createdAt time.Time
+		for i := 0; i < len(item.Content); i += 2 {
+			switch key, value := item.Content[i].Value, item.Content[i+1]; {
+			case key == "fields" && value.Kind == yaml.SequenceNode:
+				fields := p.stringList(value, "fields")
+				item.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: strings.Join(fields, ",")}
+			case key == "created_at":
+				createdAt = p.timestamp(value, "domains: created_at")
+				item.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			}
+		}
+		var d DomainConfig
+		if err := p.decode(item, &d, "domains"); err != nil {
+			return err
+		}
+		d.CreatedAt = createdAt
+		if d.Path == "" {
+			return p.errorAt(item, "domains: entry has no path", "")
+		}
+		m.Domains = append(m.Domains, d)
+	}
+	return nil
+}
+
+// stringList accepts a list of strings or one comma- or space-separated
+// string, dropping duplicates.
+func (p *manifestParser) stringList(n *yaml.Node, what string) []string {
+	var items []string
+	switch n.Kind {
+	case yaml.ScalarNode:
+		items = strings.FieldsFunc(n.Value, func(r rune) bool { return r == ',' || r == ' ' })
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				p.warnf(item, "%s: ignoring an entry that isn't a string", what)
+				continue
+			}
+			items = append(items, item.Value)
+		}
+	default:
+		p.warnf(n, "%s: expected a list; ignored", what)
+	}
+
+	var out []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if slices.Contains(out, item) {
+			p.warnf(n, "%s: %q is listed twice", what, item)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// timestamp parses a timestamp, treating an empty or unparseable one as
+// unset.
+func (p *manifestParser) timestamp(n *yaml.Node, what string) time.Time {
+	if n.Kind != yaml.ScalarNode || n.Tag == "!!null" || n.Value == "" {
+		return time.Time{}
+	}
+	for _, layout := range manifestTimeLayouts {
+		if t, err := time.Parse(layout, n.Value); err == nil {
+			return t
+		}
+	}
+	p.warnf(n, "%s: %q isn't a timestamp; treated as unset", what, n.Value)
+	return time.Time{}
+}
+
+// Normalize puts the manifest in the form Save writes: wired modules,
+// bridges, domains and each module's Go dependencies sorted, and every
+// timestamp set, in UTC, to the second. Missing timestamps fall back to
+// the project's creation time and missing module versions to the
+// project's manifesto version.
+func (m *Manifest) Normalize() {
+	if m.Modules == nil {
+		m.Modules = make(map[string]ModuleConfig)
+	}
+	slices.Sort(m.WiredModules)
+	m.WiredModules = slices.Compact(m.WiredModules)
+	slices.Sort(m.Bridges)
+	m.Bridges = slices.Compact(m.Bridges)
+	slices.SortStableFunc(m.Domains, func(a, b DomainConfig) int { return strings.Compare(a.Path, b.Path) })
+	for name, deps := range m.GoDeps {
+		slices.Sort(deps)
+		m.GoDeps[name] = slices.Compact(deps)
+	}
+
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = m.UpdatedAt
+		for _, mod := range m.Modules {
+			if !mod.InstalledAt.IsZero() && (m.CreatedAt.IsZero() || mod.InstalledAt.Before(m.CreatedAt)) {
+				m.CreatedAt = mod.InstalledAt
+			}
+		}
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = clock.Now()
+		}
+	}
+	if m.UpdatedAt.IsZero() {
+		m.UpdatedAt = m.CreatedAt
+	}
+	m.CreatedAt = normalizeTime(m.CreatedAt)
+	m.UpdatedAt = normalizeTime(m.UpdatedAt)
+	for name, mod := range m.Modules {
+		if mod.Version == "" {
+			mod.Version = m.Project.Version
+		}
+		if mod.InstalledAt.IsZero() {
+			mod.InstalledAt = m.CreatedAt
+		}
+		mod.InstalledAt = normalizeTime(mod.InstalledAt)
+		m.Modules[name] = mod
+	}
+	for i := range m.Domains {
+		if !m.Domains[i].CreatedAt.IsZero() {
+			m.Domains[i].CreatedAt = normalizeTime(m.Domains[i].CreatedAt)
+		}
+	}
+}
+
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata/manifests")
+
+// formatManifest does what manifest fmt does to data.
+func formatManifest(t *testing.T, data []byte) ([]byte, []string) {
+	t.Helper()
+	m, err := ParseManifest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Normalize()
+	out, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out, m.Warnings
+}
+
+// pinClock fixes clock.Now for the rest of t.
+func pinClock(t *testing.T) {
+	t.Helper()
+	now := clock.Now
+	clock.Now = func() time.Time { return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { clock.Now = now })
+}
+
+// TestManifestCorpus runs every manifest in testdata/manifests through
+// parse, normalize and marshal, and compares the result with the file's
+// .golden. Formatting the result again must change nothing and warn about
+// nothing; formatted.yaml is already formatted, so it must come out as is.
+func TestManifestCorpus(t *testing.T) {
+	pinClock(t)
+	inputs, err := filepath.Glob(filepath.Join("testdata", "manifests", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no manifests in testdata/manifests")
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, warnings := formatManifest(t, data)
+			if name == "formatted" {
+				if len(warnings) > 0 {
+					t.Errorf("formatted manifest has warnings: %q", warnings)
+				}
+				if !bytes.Equal(got, data) {
+					t.Errorf("formatting a formatted manifest changed it:\n%s", got)
+				}
+			} else if len(warnings) == 0 {
+				t.Error("a broken manifest parsed without warnings")
+			}
+
+			golden := strings.TrimSuffix(input, ".yaml") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("formatted manifest differs from %s:\n%s", golden, got)
+			}
+
+			again, warnings := formatManifest(t, got)
+			if len(warnings) > 0 {
+				t.Errorf("formatted manifest has warnings: %q", warnings)
+			}
+			if !bytes.Equal(again, got) {
+				t.Errorf("formatting twice isn't stable:\n%s", again)
+			}
+		})
+	}
+}
+
+// TestManifestCorpusInvalid checks each manifest in
+// testdata/manifests/invalid is rejected with a *ManifestError pointing at
+// the offending line.
+func TestManifestCorpusInvalid(t *testing.T) {
+	lines := map[string]int{
+		"bool-type":        3,
+		"duplicate-module": 6,
+		"empty":            0,
+		"tab-indent":       3,
+	}
+	inputs, err := filepath.Glob(filepath.Join("testdata", "manifests", "invalid", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			line, ok := lines[name]
+			if !ok {
+				t.Fatalf("no expected line for %s", input)
+			}
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ParseManifest(data)
+			var me *ManifestError
+			if !errors.As(err, &me) {
+				t.Fatalf("got %v, want a *ManifestError", err)
+			}
+			if me.Line != line {
+				t.Errorf("error at line %d, want %d:\n%v", me.Line, line, err)
+			}
+		})
+	}
+	if len(inputs) != len(lines) {
+		t.Errorf("%d invalid manifests, want %d", len(inputs), len(lines))
+	}
+}
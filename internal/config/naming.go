@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Naming is a convention for the packages a domain's layers live in.
+// Each layer is a directory relative to the domain directory, in which
+// %s stands for the domain's package name; an empty directory puts the
+// layer in the domain package itself.
+type Naming struct {
+	Name        string
+	Description string
+
+	Service   string
+	Infra     string
+	API       string
+	GRPC      string
+	Container string
+}
+
+const DefaultNaming = "suffix"
+
+// NamingRegistry defines the conventions selectable with `init --naming`.
+var NamingRegistry = map[string]Naming{
+	"suffix": {
+		Name: "suffix", Description: "invoicesrv/, invoiceinfra/, invoiceapi/, invoicecontainer/",
+		Service: "%ssrv", Infra: "%sinfra", API: "%sapi", GRPC: "%sgrpc", Container: "%scontainer",
+	},
+	"subdir": {
+		Name: "subdir", Description: "service/, repository/, http/, wire/",
+		Service: "service", Infra: "repository", API: "http", GRPC: "rpc", Container: "wire",
+	},
+	"flat": {
+		Name: "flat", Description: "one package per domain, for small domains",
+	},
+}
+
+// NamingNames returns the registered naming conventions, sorted.
+func NamingNames() []string {
+	names := make([]string, 0, len(NamingRegistry))
+	for name := range NamingRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupNaming returns the named convention. An empty name selects
+// DefaultNaming.
+func LookupNaming(name string) (Naming, error) {
+	if name == "" {
+		name = DefaultNaming
+	}
+	n, ok := NamingRegistry[name]
+	if !ok {
+		return Naming{}, fmt.Errorf("unknown naming convention: '%s'. Available: %s", name, strings.Join(NamingNames(), ", "))
+	}
+	return n, nil
+}
+
+// DomainLayout is where the layers of one domain live.
+type DomainLayout struct {
+	Domain    string // Package name of the domain directory
+	Service   string // Directories relative to the domain directory;
+	Infra     string // "" is the domain directory itself
+	API       string
+	GRPC      string // gRPC server, for domains with --transport grpc
+	Container string
+}
+
+// Layout applies the convention to the domain package pkg.
+func (n Naming) Layout(pkg string) DomainLayout {
+	dir := func(pattern string) string {
+		if !strings.Contains(pattern, "%s") {
+			return pattern
+		}
+		return fmt.Sprintf(pattern, pkg)
+	}
+	return DomainLayout{
+		Domain:    pkg,
+		Service:   dir(n.Service),
+		Infra:     dir(n.Infra),
+		API:       dir(n.API),
+		GRPC:      dir(n.GRPC),
+		Container: dir(n.Container),
+	}
+}
+
+// PackageName returns the name of the package in the layer directory dir.
+func (l DomainLayout) PackageName(dir string) string {
+	if dir == "" {
+		return l.Domain
+	}
+	return path.Base(dir)
+}
+
+// Naming returns the convention the project's domains use by default.
+func (m *Manifest) Naming() (Naming, error) {
+	return LookupNaming(m.Project.Naming)
+}
+
+// DomainNaming returns the convention d was scaffolded with: its own
+// override, or the project's.
+func (m *Manifest) DomainNaming(d DomainConfig) (Naming, error) {
+	if d.Naming != "" {
+		return LookupNaming(d.Naming)
+	}
+	return m.Naming()
+}
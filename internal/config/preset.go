@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Preset is a named starting point for init, selected with --template: the
+// profile to create the project with and the modules to wire without
+// asking. Modules passed with --with are wired as well.
+type Preset struct {
+	Name        string
+	Description string
+
+	Profile string   // Profile the project is created with
+	Wire    []string // Wireable modules wired at init
+}
+
+// PresetRegistry defines the presets selectable with `init --template`.
+var PresetRegistry = map[string]Preset{
+	"saas": {
+		Name: "saas", Description: "HTTP API with IAM, email notifications and a job queue",
+		Profile: "full",
+		Wire:    []string{"iam", "notifx", "jobx"},
+	},
+	"api": {
+		Name: "api", Description: "HTTP API with the core libraries; nothing wired, no IAM",
+		Profile: "full",
+	},
+	"worker": {
+		Name: "worker", Description: "Background job runner with jobx; no HTTP server",
+		Profile: "worker",
+		Wire:    []string{"jobx"},
+	},
+	"minimal": {
+		Name: "minimal", Description: "Lightweight HTTP API with nothing wired",
+		Profile: "quick",
+	},
+}
+
+// PresetNames returns the registered preset names, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(PresetRegistry))
+	for name := range PresetRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupPreset returns the named preset.
+func LookupPreset(name string) (Preset, error) {
+	p, ok := PresetRegistry[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown template: '%s'. Available: %s", name, strings.Join(PresetNames(), ", "))
+	}
+	return p, nil
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Profile bundles the init defaults for a kind of service.
+type Profile struct {
+	Name        string
+	Description string
+
+	Modules []string // Source modules added to the core set
+	Wire    []string // Wireable modules pre-selected at init
+	Exclude []string // Wireable modules the profile can't host
+
+	HTTP     bool // cmd/server.go serves HTTP; otherwise cmd/main.go runs background services only
+	Static   bool // Serve ./web as static files next to the API
+	Postgres bool // docker-compose runs postgres and the container connects to it
+	Redis    bool // docker-compose runs redis and the container connects to it
+
+	DomainKind string // Default --kind for `manifesto add <domain>`
+}
+
+const DefaultProfile = "full"
+
+// ProfileRegistry defines the profiles selectable with `init --profile`.
+var ProfileRegistry = map[string]Profile{
+	"full": {
+		Name: "full", Description: "HTTP API with postgres and redis; every module available",
+		HTTP: true, Postgres: true, Redis: true,
+		DomainKind: "http",
+	},
+	"quick": {
+		Name: "quick", Description: "Lightweight HTTP API (no IAM, no migrations)",
+		HTTP: true, Postgres: true, Redis: true,
+		DomainKind: "http",
+	},
+	"api": {
+		Name: "api", Description: "HTTP API with IAM; no job queue",
+		Wire:    []string{"iam"},
+		Exclude: []string{"jobx"},
+		HTTP:    true, Postgres: true, Redis: true, // iam keeps sessions in redis
+		DomainKind: "http",
+	},
+	"worker": {
+		Name: "worker", Description: "Background job runner with jobx; no HTTP server",
+		Modules:  []string{"asyncx"},
+		Wire:     []string{"jobx"},
+		Postgres: true, Redis: true,
+		DomainKind: "worker",
+	},
+	"fullstack": {
+		Name: "fullstack", Description: "HTTP API with IAM and jobx, serving static files from ./web",
+		Wire: []string{"iam", "jobx"},
+		HTTP: true, Static: true, Postgres: true, Redis: true,
+		DomainKind: "http",
+	},
+}
+
+// ProfileNames returns the registered profile names, sorted.
+func ProfileNames() []string {
+	names := make([]string, 0, len(ProfileRegistry))
+	for name := range ProfileRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile returns the named profile. An empty name selects DefaultProfile.
+func LookupProfile(name string) (Profile, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	p, ok := ProfileRegistry[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile: '%s'. Available: %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	return p, nil
+}
+
+// Allows reports whether the wireable module can be wired into a project
+// with this profile: the profile doesn't exclude it and the module doesn't
+// limit itself to other profiles.
+func (p Profile) Allows(module string) bool {
+	if HasModule(p.Exclude, module) {
+		return false
+	}
+	spec, ok := WireableModuleRegistry[module]
+	return !ok || len(spec.Profiles) == 0 || HasModule(spec.Profiles, p.Name)
+}
+
+// ProfilesAllowing returns the names of the profiles that allow the
+// wireable module, sorted.
+func ProfilesAllowing(module string) []string {
+	var names []string
+	for _, name := range ProfileNames() {
+		if ProfileRegistry[name].Allows(module) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// profileInfrastructure is what profiles set up that has no wireable module
+// of its own. Bridges may require it like a wired module.
+var profileInfrastructure = []string{"postgres"}
+
+// Provides reports whether the project templates set up the wireable module,
+// or the profileInfrastructure, themselves, so it counts as wired from the
+// start.
+func (p Profile) Provides(module string) bool {
+	switch module {
+	case "postgres":
+		return p.Postgres
+	case "redis":
+		return p.Redis
+	}
+	return false
+}
+
+// InitModules returns the library modules init downloads for the profile:
+// the core ones and the profile's, after their dependencies.
+func (p Profile) InitModules() ([]string, error) {
+	return ResolveDeps(append(CoreModules(p.Name == "quick"), p.Modules...))
+}
+
+// InitWire returns the wireable modules init wires for the profile when
+// chosen are picked: those, the builtin modules the profile allows and
+// the modules they require, in WireOrder and without the ones the project
+// templates provide.
+func (p Profile) InitWire(chosen []string) []string {
+	wire := slices.Clone(chosen)
+	for _, name := range WireableModuleNames() {
+		if WireableModuleRegistry[name].Builtin && p.Allows(name) && !HasModule(wire, name) {
+			wire = append(wire, name)
+		}
+	}
+	wire = WireOrder(WithRequiredWireables(wire))
+	return slices.DeleteFunc(wire, p.Provides)
+}
+
+// ValidateWire returns an error for the first module the profile can't host.
+func (p Profile) ValidateWire(modules []string) error {
+	for _, m := range modules {
+		if !p.Allows(m) {
+			return fmt.Errorf("module '%s' is not available for %s projects; it supports %s", m, p.Name, strings.Join(ProfilesAllowing(m), ", "))
+		}
+	}
+	return nil
+}
+
+// Profile returns the profile the project was created with.
+func (m *Manifest) Profile() (Profile, error) {
+	return LookupProfile(m.Project.Profile)
+}
+
+// HasDatabase reports whether the project keeps its data in a database.
+// Quick projects don't until the migrations module is added.
+func (m *Manifest) HasDatabase() bool {
+	if m.Project.Profile != "quick" {
+		return true
+	}
+	_, ok := m.Modules["migrations"]
+	return ok
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigFile holds settings that apply to every project a user
+// creates. It lives in a manifesto directory under os.UserConfigDir, e.g.
+// ~/.config/manifesto/config.yaml.
+const UserConfigFile = "config.yaml"
+
+// UserConfig is the content of UserConfigFile.
+type UserConfig struct {
+	// DefaultModulePrefix is joined with the project name to form the
+	// module path when init isn't given --module, e.g. github.com/acme.
+	DefaultModulePrefix string `yaml:"default_module_prefix,omitempty"`
+	// DefaultRef is the branch downloaded when the manifesto repo has no
+	// release or version tag, instead of the repo's default branch.
+	DefaultRef string `yaml:"default_ref,omitempty"`
+}
+
+// UserConfigPath returns where UserConfigFile is read from.
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "manifesto", UserConfigFile), nil
+}
+
+// LoadUserConfig reads UserConfigFile. A missing file, or a system without
+// a user config directory, yields an empty config.
+func LoadUserConfig() (*UserConfig, error) {
+	p, err := UserConfigPath()
+	if err != nil {
+		return &UserConfig{}, nil
+	}
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return &UserConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c UserConfig
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", p, err)
+	}
+	return &c, nil
+}
+
+// UserTemplatesDir returns the directory whose templates override the
+// embedded ones in every project, e.g. ~/.config/manifesto/templates.
+func UserTemplatesDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "manifesto", "templates"), nil
+}
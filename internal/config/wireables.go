@@ -0,0 +1,370 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectWireablesDir holds a project's own wireable modules, one YAML file
+// per module, relative to the project root.
+const ProjectWireablesDir = ".manifesto/wireables"
+
+//go:embed wireables/*.yaml
+var builtinWireables embed.FS
+
+// WireableModuleRegistry holds every module that can be wired: the ones
+// embedded in the CLI and, after LoadProjectWireables, the project's own.
+var WireableModuleRegistry = mustLoadBuiltinWireables()
+
+// WireableError is a problem in a wireable module's YAML file. Field is the
+// offending field, e.g. "bridges[0].requires_module"; Line is 1-based and
+// zero when unknown.
+type WireableError struct {
+	File  string
+	Line  int
+	Field string
+	Msg   string
+	Hint  string
+}
+
+func (e *WireableError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, ": %s", e.Field)
+	}
+	b.WriteString(": " + e.Msg)
+	if e.Hint != "" {
+		b.WriteString("\n  hint: " + e.Hint)
+	}
+	return b.String()
+}
+
+// yaml.v3 can't tell a block scalar's indentation when its first line starts
+// with a tab, as the container and config snippets usually do.
+const (
+	wireableTabMessage = "found a tab character where an indentation space is expected"
+	wireableTabHint    = "a block whose first line starts with a tab needs an indentation indicator: write |2- instead of |-"
+)
+
+var (
+	wireableNameRe    = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	wireableUnknownRe = regexp.MustCompile(`^field (\S+) not found in type`)
+)
+
+func mustLoadBuiltinWireables() map[string]WireableModule {
+	registry := make(map[string]WireableModule)
+	files, err := loadWireables(builtinWireables, "wireables", "wireables")
+	if err == nil {
+		err = mergeWireables(registry, files)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// LoadProjectWireables adds the modules defined in the project's
+// ProjectWireablesDir to WireableModuleRegistry. A module named like a
+// built-in one replaces it. Files are checked like the built-in ones, and
+// bridges may only require modules that exist.
+func LoadProjectWireables(projectRoot string) error {
+	dir := filepath.Join(projectRoot, ProjectWireablesDir)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	files, err := loadWireables(os.DirFS(dir), ".", ProjectWireablesDir)
+	if err != nil {
+		return err
+	}
+	return mergeWireables(WireableModuleRegistry, files)
+}
+
+// wireableFile is a module spec and the file it was read from.
+type wireableFile struct {
+	name string // As shown in errors
+	doc  *yaml.Node
+	spec WireableModule
+}
+
+// loadWireables reads and checks every *.yaml file in dir of fsys, naming
+// them under display in errors.
+func loadWireables(fsys fs.FS, dir, display string) ([]wireableFile, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var files []wireableFile
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parseWireable(path.Join(display, path.Base(p)), data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// parseWireable decodes one module spec, rejecting unknown fields, and
+// checks the fields that don't depend on other modules.
+func parseWireable(name string, data []byte) (wireableFile, error) {
+	f := wireableFile{name: name, doc: &yaml.Node{}}
+	if err := yaml.Unmarshal(data, f.doc); err != nil {
+		return f, wireableSyntaxError(name, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f.spec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return f, &WireableError{File: name, Msg: "the file is empty"}
+		}
+		return f, f.typeError(err)
+	}
+
+	spec := f.spec
+	switch {
+	case spec.Name == "":
+		return f, f.errorAt("name", "is required")
+	case !wireableNameRe.MatchString(spec.Name):
+		return f, f.errorAt("name", fmt.Sprintf("%q must be lowercase letters, digits, - and _, starting with a letter", spec.Name))
+	case spec.Description == "":
+		return f, f.errorAt("description", "is required")
+	}
+	for i, m := range spec.RequiredModules {
+		if _, ok := ModuleRegistry[m]; !ok {
+			return f, f.errorAt(fmt.Sprintf("required_modules[%d]", i),
+				fmt.Sprintf("unknown module %q; required_modules lists manifesto modules to download (%s)", m, strings.Join(moduleNames(), ", ")))
+		}
+	}
+	for i, b := range spec.Bridges {
+		if b.RequiresModule == "" {
+			return f, f.errorAt(fmt.Sprintf("bridges[%d].requires_module", i), "is required")
+		}
+	}
+	for _, p := range slices.Sorted(maps.Keys(spec.Files)) {
+		if p == "" || path.IsAbs(p) || path.Clean(p) != p || strings.HasPrefix(p, "../") {
+			return f, f.errorAt("files", fmt.Sprintf("%q must be a clean slash-separated path inside the project, like docs/openapi.go", p))
+		}
+	}
+	for i, fu := range spec.FollowUps {
+		if fu.Text == "" {
+			return f, f.errorAt(fmt.Sprintf("follow_ups[%d].text", i), "is required")
+		}
+	}
+	if len(spec.Providers) > 0 {
+		if _, ok := spec.Providers[spec.DefaultProvider]; !ok {
+			return f, f.errorAt("default_provider", fmt.Sprintf("must name one of the providers (%s)", strings.Join(spec.ProviderNames(), ", ")))
+		}
+	} else if spec.DefaultProvider != "" {
+		return f, f.errorAt("default_provider", "is set but the module has no providers")
+	}
+	for _, name := range spec.ProviderNames() {
+		p := spec.Providers[name]
+		if !wireableNameRe.MatchString(name) {
+			return f, f.errorAt("providers", fmt.Sprintf("%q must be lowercase letters, digits, - and _, starting with a letter", name))
+		}
+		if p.Description == "" {
+			return f, f.errorAt(fmt.Sprintf("providers.%s.description", name), "is required")
+		}
+		for i, fu := range p.FollowUps {
+			if fu.Text == "" {
+				return f, f.errorAt(fmt.Sprintf("providers.%s.follow_ups[%d].text", name, i), "is required")
+			}
+		}
+	}
+	return f, nil
+}
+
+// mergeWireables adds files to registry and checks that the wireable modules
+// they require, and those their bridges require, are in the registry. Bridges
+// may also require profileInfrastructure.
+func mergeWireables(registry map[string]WireableModule, files []wireableFile) error {
+	seen := make(map[string]string)
+	for _, f := range files {
+		if other, ok := seen[f.spec.Name]; ok {
+			return f.errorAt("name", fmt.Sprintf("%q is also defined in %s", f.spec.Name, other))
+		}
+		seen[f.spec.Name] = f.name
+		registry[f.spec.Name] = f.spec
+	}
+	for _, f := range files {
+		for i, name := range f.spec.Profiles {
+			if _, ok := ProfileRegistry[name]; !ok {
+				return f.errorAt(fmt.Sprintf("profiles[%d]", i), fmt.Sprintf("unknown profile %q", name))
+			}
+		}
+		for i, req := range f.spec.RequiredWireables {
+			if _, ok := registry[req]; !ok {
+				return f.errorAt(fmt.Sprintf("required_wireables[%d]", i), fmt.Sprintf("unknown wireable module %q", req))
+			}
+		}
+		for i, b := range f.spec.Bridges {
+			other, ok := registry[b.RequiresModule]
+			if !ok && !HasModule(profileInfrastructure, b.RequiresModule) {
+				return f.errorAt(fmt.Sprintf("bridges[%d].requires_module", i), fmt.Sprintf("unknown wireable module %q", b.RequiresModule))
+			}
+			for j, p := range b.RequiresProviders {
+				if _, ok := other.Providers[p]; !ok {
+					return f.errorAt(fmt.Sprintf("bridges[%d].requires_providers[%d]", i, j), fmt.Sprintf("%s has no provider %q", b.RequiresModule, p))
+				}
+			}
+		}
+	}
+	graph := NewModuleGraph(ModuleRegistry, registry)
+	for _, f := range files {
+		if _, err := graph.Resolve([]string{f.spec.Name}, EdgeWire); err != nil {
+			return f.errorAt("required_wireables", err.Error())
+		}
+	}
+	return nil
+}
+
+// errorAt returns a *WireableError for field, a path such as
+// "bridges[0].requires_module", at the line it is on.
+func (f wireableFile) errorAt(field, msg string) *WireableError {
+	e := &WireableError{File: f.name, Field: field, Msg: msg}
+	if n := fieldNode(f.doc, field); n != nil {
+		e.Line = n.Line
+	}
+	return e
+}
+
+// typeError turns a yaml.v3 decoding error into a *WireableError for its
+// first problem, naming the field on that line.
+func (f wireableFile) typeError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) || len(typeErr.Errors) == 0 {
+		return wireableSyntaxError(f.name, err)
+	}
+	e := &WireableError{File: f.name, Msg: typeErr.Errors[0]}
+	if m := yamlTypeErrorRe.FindStringSubmatch(e.Msg); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Msg = m[2]
+	}
+	e.Field = fieldOnLine(f.doc, e.Line, "")
+	if m := wireableUnknownRe.FindStringSubmatch(e.Msg); m != nil {
+		e.Field, e.Msg = cmp.Or(e.Field, m[1]), "unknown field"
+		return e
+	}
+	e.Msg = strings.Replace(e.Msg, "config.", "", 1)
+	return e
+}
+
+func wireableSyntaxError(name string, err error) error {
+	e := &WireableError{File: name, Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Msg = m[2]
+	}
+	if strings.Contains(e.Msg, wireableTabMessage) {
+		e.Hint = wireableTabHint
+	}
+	return e
+}
+
+// fieldNode returns the value node of field, a path such as
+// "bridges[0].requires_module", in doc; nil if it isn't there.
+func fieldNode(doc *yaml.Node, field string) *yaml.Node {
+	n := doc
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, part := range strings.Split(field, ".") {
+		key, index := part, -1
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+			index, _ = strconv.Atoi(strings.TrimSuffix(part[i+1:], "]"))
+		}
+		n = mappingValue(n, key)
+		if n == nil {
+			return nil
+		}
+		if index >= 0 {
+			if n.Kind != yaml.SequenceNode || index >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[index]
+		}
+	}
+	return n
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// fieldOnLine returns the path of the deepest field whose value starts on
+// line, with prefix being the path of n.
+func fieldOnLine(n *yaml.Node, line int, prefix string) string {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return fieldOnLine(n.Content[0], line, prefix)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			field := key.Value
+			if prefix != "" {
+				field = prefix + "." + field
+			}
+			if found := fieldOnLine(value, line, field); found != "" {
+				return found
+			}
+			if key.Line == line || value.Line == line {
+				return field
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			field := fmt.Sprintf("%s[%d]", prefix, i)
+			if found := fieldOnLine(item, line, field); found != "" {
+				return found
+			}
+			if item.Kind == yaml.ScalarNode && item.Line == line {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// moduleNames returns the ModuleRegistry names, sorted.
+func moduleNames() []string {
+	names := make([]string, 0, len(ModuleRegistry))
+	for name := range ModuleRegistry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -1,490 +1,174 @@
 package config
 
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
 // WireableModule defines a module that can be wired into a project's
 // container, config, server, and Makefile via code injection at marker points.
+// Specs are read from YAML files, one per module, with the fields' yaml
+// names; see WireableModuleRegistry.
 type WireableModule struct {
-	Name        string
-	Description string
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+
+	// Builtin modules are part of every new project's templates; wiring
+	// adds them to projects created before they were.
+	Builtin bool `yaml:"builtin,omitempty"`
+
+	// Profiles the module can be wired into, e.g. iam needs the migrations
+	// quick projects leave out. Empty means every profile.
+	Profiles []string `yaml:"profiles,omitempty"`
 
 	// Config injection (pkg/config/config.go)
-	ConfigFields string // Struct fields to add
-	ConfigLoads  string // Load() assignments to add
+	ConfigFields string `yaml:"config_fields,omitempty"` // Struct fields to add
+	ConfigLoads  string `yaml:"config_loads,omitempty"`  // Load() assignments to add
 
 	// Container injection (cmd/container.go)
-	ContainerImports string // Import lines
-	ContainerFields  string // Struct fields
-	ModuleInit       string // initModules() code
-	BackgroundStart  string // StartBackgroundServices() code
-	ContainerHelpers string // Top-level functions/types
+	ContainerImports string `yaml:"container_imports,omitempty"` // Import lines
+	ContainerFields  string `yaml:"container_fields,omitempty"`  // Struct fields
+	ModuleInit       string `yaml:"module_init,omitempty"`       // initModules() code
+	BackgroundStart  string `yaml:"background_start,omitempty"`  // StartBackgroundServices() code
+	ContainerHelpers string `yaml:"container_helpers,omitempty"` // Top-level functions/types
 
 	// Server injection (cmd/server.go)
-	ServerImports     string // Import lines
-	PublicRoutes      string // Public (unauthenticated) routes
-	RouteRegistration string // Protected routes
-	AuthMiddleware    string // Middleware for protected group
+	ServerImports     string `yaml:"server_imports,omitempty"`     // Import lines
+	ServerMiddleware  string `yaml:"server_middleware,omitempty"`  // setupMiddleware() code, ahead of every route
+	PublicRoutes      string `yaml:"public_routes,omitempty"`      // Public (unauthenticated) routes
+	RouteRegistration string `yaml:"route_registration,omitempty"` // Protected routes
+	AuthMiddleware    string `yaml:"auth_middleware,omitempty"`    // Middleware for protected group
 
 	// Makefile injection (Makefile)
-	MakefileEnv        string // Environment variable blocks (top-level exports)
-	MakefileEnvDisplay string // @echo lines for `make env` target (NO leading tab — added by injector)
-
-	// External Go dependencies to install
-	GoDeps []string
-
-	// Required source modules (from ModuleRegistry) that must be downloaded
-	RequiredModules []string
-
-	// Cross-module bridges
-	Bridges []Bridge
-}
-
-// Bridge defines code to inject when two modules are both wired.
-type Bridge struct {
-	RequiresModule   string // Other module that must also be wired
-	ContainerImports string // Additional imports for bridge
-	ContainerInit    string // Code to inject into initModules()
-	ContainerHelpers string // Top-level helper functions for bridge
-}
-
-// WireableModuleRegistry defines all modules that can be wired into a project.
-var WireableModuleRegistry = map[string]WireableModule{
-	"fsx": {
-		Name:        "fsx",
-		Description: "File system abstraction (local, S3)",
-
-		RequiredModules: []string{"fsx"},
-
-		ContainerImports: `	"{{GOMODULE}}/pkg/fsx"
-	"{{GOMODULE}}/pkg/fsx/fsxlocal"
-	"{{GOMODULE}}/pkg/fsx/fsxs3"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"`,
-
-		ContainerFields: `	FileSystem fsx.FileSystem
-	S3Client   *s3.Client`,
-
-		ModuleInit: `	c.initFileStorage()`,
-
-		ContainerHelpers: `func (c *Container) initFileStorage() {
-	storageMode := getEnv("STORAGE_MODE", "local")
-
-	switch storageMode {
-	case "s3":
-		awsRegion := getEnv("AWS_REGION", "us-east-1")
-		awsBucket := getEnv("AWS_BUCKET", "{{PROJECTNAME}}-uploads")
-
-		cfg, err := awsConfig.LoadDefaultConfig(context.TODO(), awsConfig.WithRegion(awsRegion))
-		if err != nil {
-			logx.Fatalf("Unable to load AWS SDK config: %v", err)
-		}
-		c.S3Client = s3.NewFromConfig(cfg)
-		c.FileSystem = fsxs3.NewS3FileSystem(c.S3Client, awsBucket, "")
-		logx.Infof("  S3 file system configured (bucket: %s, region: %s)", awsBucket, awsRegion)
-
-	case "local":
-		uploadDir := getEnv("UPLOAD_DIR", "./uploads")
-		localFS, err := fsxlocal.NewLocalFileSystem(uploadDir)
-		if err != nil {
-			logx.Fatalf("Failed to initialize local file system: %v", err)
-		}
-		c.FileSystem = localFS
-		logx.Infof("  Local file system configured (path: %s)", localFS.GetBasePath())
-
-	default:
-		logx.Fatalf("Unknown STORAGE_MODE: %s (use 'local' or 's3')", storageMode)
-	}
-}`,
-
-		MakefileEnv: `# ============================================================================
-# Environment Variables - Storage Configuration
-# ============================================================================
-
-export STORAGE_MODE = local
-export UPLOAD_DIR = ./uploads
-export AWS_REGION = us-east-1
-export AWS_BUCKET = {{PROJECTNAME}}-uploads`,
-
-		MakefileEnvDisplay: `@echo "Storage:"
-@echo "  MODE:              $(STORAGE_MODE)"
-@echo "  UPLOAD_DIR:        $(UPLOAD_DIR)"
-@echo ""`,
+	MakefileEnv        string `yaml:"makefile_env,omitempty"`         // Environment variable blocks (top-level exports)
+	MakefileEnvDisplay string `yaml:"makefile_env_display,omitempty"` // @echo lines for `make env` target (NO leading tab — added by injector)
+	MakefileTargets    string `yaml:"makefile_targets,omitempty"`     // Targets, recipes tab-indented as in the Makefile
 
-		GoDeps: []string{
-			"github.com/aws/aws-sdk-go-v2/config",
-			"github.com/aws/aws-sdk-go-v2/service/s3",
-		},
-	},
+	// docker-compose.yml injection, indented as they appear in the file
+	ComposeServices string `yaml:"compose_services,omitempty"` // Entries under services:
+	ComposeVolumes  string `yaml:"compose_volumes,omitempty"`  // Entries under volumes:
 
-	"asyncx": {
-		Name:        "asyncx",
-		Description: "Async primitives: futures, fan-out, pools, retry, timeout",
+	// Files to create, by slash-separated path relative to the project
+	// root. A file that already exists is left alone.
+	Files map[string]string `yaml:"files,omitempty"`
 
-		RequiredModules: []string{"asyncx"},
-	},
-
-	"ai": {
-		Name:        "ai",
-		Description: "LLM, embeddings, vector store, OCR, speech",
-
-		RequiredModules: []string{"ai", "fsx"},
-	},
-
-	"jobx": {
-		Name:        "jobx",
-		Description: "Redis-backed job queue with worker pools",
-
-		RequiredModules: []string{"jobx", "asyncx"},
-
-		ConfigFields: `	Jobx JobxConfig`,
-		ConfigLoads:  `	cfg.Jobx = loadJobxConfig()`,
-
-		ContainerImports: `	"{{GOMODULE}}/pkg/jobx"
-	"{{GOMODULE}}/pkg/jobx/jobxredis"`,
-		ContainerFields: `	JobClient *jobx.Client`,
-		ModuleInit:      `	c.initJobx()`,
-		BackgroundStart: `	go c.JobClient.Start(ctx)`,
-
-		ContainerHelpers: `func (c *Container) initJobx() {
-	queue := jobxredis.NewRedisQueue(c.Redis)
-	c.JobClient = jobx.NewClient(queue,
-		jobx.WithConcurrency(c.Config.Jobx.Concurrency),
-		jobx.WithQueues(c.Config.Jobx.Queues...),
-		jobx.WithPollInterval(c.Config.Jobx.PollInterval),
-		jobx.WithShutdownTimeout(c.Config.Jobx.ShutdownTimeout),
-		jobx.WithDequeueTimeout(c.Config.Jobx.DequeueTimeout),
-		jobx.WithDefaultRetryDelay(c.Config.Jobx.DefaultRetryDelay),
-	)
-	logx.Info("  Job queue configured")
-}`,
-
-		MakefileEnv: `# ============================================================================
-# Environment Variables - Job Queue Configuration
-# ============================================================================
-
-export JOBX_CONCURRENCY = 4
-export JOBX_QUEUES = default
-export JOBX_POLL_INTERVAL = 1s
-export JOBX_SHUTDOWN_TIMEOUT = 30s
-export JOBX_DEQUEUE_TIMEOUT = 5s
-export JOBX_DEFAULT_RETRY_DELAY = 30s`,
-
-		MakefileEnvDisplay: `@echo "Jobx:"
-@echo "  CONCURRENCY:       $(JOBX_CONCURRENCY)"
-@echo "  QUEUES:            $(JOBX_QUEUES)"
-@echo ""`,
-	},
-
-	"notifx": {
-		Name:        "notifx",
-		Description: "Email notifications (SES, console)",
-
-		RequiredModules: []string{"notifx"},
-
-		ConfigFields: `	Notifx NotifxConfig`,
-		ConfigLoads:  `	cfg.Notifx = loadNotifxConfig()`,
-
-		ContainerImports: `	"{{GOMODULE}}/pkg/notifx"
-	"{{GOMODULE}}/pkg/notifx/notifxses"
-	"{{GOMODULE}}/pkg/notifx/notifxconsole"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ses"`,
-		ContainerFields: `	NotifxClient *notifx.Client`,
-		ModuleInit:      `	c.initNotifx()`,
-
-		ContainerHelpers: `func (c *Container) initNotifx() {
-	var provider notifx.EmailSender
-
-	switch c.Config.Notifx.Provider {
-	case "ses":
-		awsCfg, err := awsConfig.LoadDefaultConfig(context.TODO(),
-			awsConfig.WithRegion(c.Config.Notifx.AWSRegion))
-		if err != nil {
-			logx.Fatalf("Unable to load AWS config for notifx: %v", err)
-		}
-		sesClient := ses.NewFromConfig(awsCfg)
-		provider = notifxses.NewSESProvider(sesClient, c.Config.Notifx.FromAddress)
-		logx.Infof("  Notifx: SES provider (region: %s)", c.Config.Notifx.AWSRegion)
-
-	default:
-		provider = notifxconsole.NewConsoleProvider()
-		logx.Info("  Notifx: console provider (dev mode)")
-	}
-
-	c.NotifxClient = notifx.NewClient(provider)
-}`,
-
-		MakefileEnv: `# ============================================================================
-# Environment Variables - Notification Configuration
-# ============================================================================
-
-export NOTIFX_PROVIDER = console
-export NOTIFX_FROM_ADDRESS = noreply@{{PROJECTNAME}}.com
-export NOTIFX_FROM_NAME = {{PROJECTNAME}}
-export NOTIFX_AWS_REGION = us-east-1`,
-
-		MakefileEnvDisplay: `@echo "Notifx:"
-@echo "  PROVIDER:          $(NOTIFX_PROVIDER)"
-@echo "  FROM:              $(NOTIFX_FROM_ADDRESS)"
-@echo ""`,
-
-		GoDeps: []string{
-			"github.com/aws/aws-sdk-go-v2/config",
-			"github.com/aws/aws-sdk-go-v2/service/ses",
-		},
-	},
-
-	"iam": {
-		Name:        "iam",
-		Description: "Auth, users, tenants, scopes, API keys",
-
-		RequiredModules: []string{"iam", "migrations"},
-
-		ConfigFields: ``,
-		ConfigLoads:  ``,
+	// External Go dependencies to install
+	GoDeps []string `yaml:"go_deps,omitempty"`
 
-		ContainerImports: `	"{{GOMODULE}}/pkg/iam/iamcontainer"
-	"{{GOMODULE}}/pkg/kernel"`,
-		ContainerFields:  `	IAM *iamcontainer.Container`,
-		ModuleInit: `	c.IAM = iamcontainer.New(iamcontainer.Deps{
-		DB:                 c.DB,
-		Redis:              c.Redis,
-		Cfg:                c.Config,
-		OTPNotifier:        NewConsoleNotifier(),
-		InvitationNotifier: NewConsoleInvitationNotifier(),
-	})`,
-		BackgroundStart: `	c.IAM.StartBackgroundServices(ctx)`,
+	// Required source modules (from ModuleRegistry) that must be downloaded
+	RequiredModules []string `yaml:"required_modules,omitempty"`
 
-		ContainerHelpers: `// ConsoleNotifier implements the NotificationService interface
-// by printing OTP codes to the terminal/console
-type ConsoleNotifier struct{}
+	// Wireable modules that must be wired first
+	RequiredWireables []string `yaml:"required_wireables,omitempty"`
 
-// NewConsoleNotifier creates a new console-based OTP notifier
-func NewConsoleNotifier() *ConsoleNotifier {
-	return &ConsoleNotifier{}
-}
+	// Cross-module bridges
+	Bridges []Bridge `yaml:"bridges,omitempty"`
 
-// SendOTP prints the OTP code to the terminal
-func (n *ConsoleNotifier) SendOTP(ctx context.Context, contact string, code string) error {
-	fmt.Println("\n" + repeatString("=", 60))
-	fmt.Println("📧 OTP NOTIFICATION (Console Output)")
-	fmt.Println(repeatString("=", 60))
-	fmt.Printf("📨 To: %s\n", contact)
-	fmt.Printf("🔐 Code: %s\n", code)
-	fmt.Println(repeatString("=", 60))
-	fmt.Println("⚠️  This is console output for development only")
-	fmt.Println("⚠️  In production, configure email service in config")
-	fmt.Println(repeatString("=", 60) + "\n")
+	// Steps the user still has to take after wiring
+	FollowUps []FollowUp `yaml:"follow_ups,omitempty"`
 
-	logx.Infof("📧 OTP sent to %s: %s", contact, code)
-	return nil
+	// Alternative implementations, chosen with add --provider. The chosen
+	// one's fields are added to the module's; DefaultProvider is used when
+	// none is chosen.
+	Providers       map[string]Provider `yaml:"providers,omitempty"`
+	DefaultProvider string              `yaml:"default_provider,omitempty"`
 }
 
-// ConsoleInvitationNotifier implements invitation.NotificationService
-// by printing invitation details to the terminal/console
-type ConsoleInvitationNotifier struct{}
-
-func NewConsoleInvitationNotifier() *ConsoleInvitationNotifier {
-	return &ConsoleInvitationNotifier{}
+// Provider is one implementation of a wireable module, e.g. the SES or
+// SMTP sender behind notifx. Its code is appended to the module's.
+type Provider struct {
+	Description        string     `yaml:"description"`
+	ContainerImports   string     `yaml:"container_imports,omitempty"`    // Additional imports
+	ContainerFields    string     `yaml:"container_fields,omitempty"`     // Struct fields after the module's
+	ModuleInit         string     `yaml:"module_init,omitempty"`          // initModules() code after the module's
+	ContainerHelpers   string     `yaml:"container_helpers,omitempty"`    // Top-level functions/types
+	MakefileEnv        string     `yaml:"makefile_env,omitempty"`         // Exports after the module's
+	MakefileEnvDisplay string     `yaml:"makefile_env_display,omitempty"` // @echo lines after the module's
+	GoDeps             []string   `yaml:"go_deps,omitempty"`
+	FollowUps          []FollowUp `yaml:"follow_ups,omitempty"`
 }
 
-func (n *ConsoleInvitationNotifier) SendInvitation(ctx context.Context, email string, token string, tenantID kernel.TenantID, invitedBy kernel.UserID) error {
-	fmt.Println("\n" + repeatString("=", 60))
-	fmt.Println("📧 INVITATION NOTIFICATION (Console Output)")
-	fmt.Println(repeatString("=", 60))
-	fmt.Printf("📨 To: %s\n", email)
-	fmt.Printf("🔗 Token: %s\n", token)
-	fmt.Printf("🏢 Tenant: %s\n", tenantID)
-	fmt.Printf("👤 Invited by: %s\n", invitedBy)
-	fmt.Println(repeatString("=", 60))
-	fmt.Println("⚠️  This is console output for development only")
-	fmt.Println("⚠️  In production, configure notifx for email delivery")
-	fmt.Println(repeatString("=", 60) + "\n")
-
-	logx.Infof("📧 Invitation sent to %s (token: %s...)", email, token[:8])
-	return nil
-}`,
-
-		MakefileEnv: `# ============================================================================
-# Environment Variables - JWT Configuration
-# ============================================================================
-
-export JWT_SECRET_KEY = development-supersecret-key-must-be-at-least-32-characters-long-change-in-prod
-export JWT_ACCESS_TOKEN_TTL = 15m
-export JWT_REFRESH_TOKEN_TTL = 168h
-export JWT_ISSUER = {{PROJECTNAME}}
-export JWT_AUDIENCE = {{PROJECTNAME}}-api,{{PROJECTNAME}}-web
-
-# ============================================================================
-# Environment Variables - API Key Configuration
-# ============================================================================
-
-export API_KEY_LIVE_PREFIX = {{PROJECTNAME}}_live
-export API_KEY_TEST_PREFIX = {{PROJECTNAME}}_test
-export API_KEY_TOKEN_LENGTH = 32
-
-# ============================================================================
-# Environment Variables - Session Configuration
-# ============================================================================
-
-export SESSION_EXPIRATION_TIME = 24h
-export SESSION_CLEANUP_INTERVAL = 1h
-export SESSION_MAX_PER_USER = 10
-
-# ============================================================================
-# Environment Variables - OTP Configuration
-# ============================================================================
-
-export OTP_CODE_LENGTH = 6
-export OTP_EXPIRATION_TIME = 10m
-export OTP_MAX_ATTEMPTS = 5
-export OTP_RATE_LIMIT_WINDOW = 1m
-export OTP_TOKEN_BYTE_LENGTH = 3
-
-# ============================================================================
-# Environment Variables - Invitation Configuration
-# ============================================================================
-
-export INVITATION_DEFAULT_EXPIRATION_DAYS = 7
-export INVITATION_TOKEN_BYTE_LENGTH = 32
-export INVITATION_MAX_PENDING_PER_TENANT = 100
-
-# ============================================================================
-# Environment Variables - Password Configuration
-# ============================================================================
-
-export PASSWORD_RESET_TOKEN_BYTE_LENGTH = 32
-export PASSWORD_RESET_EXPIRATION_TIME = 1h
-export PASSWORD_RESET_RATE_LIMIT_WINDOW = 15m
-export PASSWORD_RESET_MAX_ATTEMPTS = 3
-export BCRYPT_COST = 10
-
-# ============================================================================
-# Environment Variables - Cookie Configuration
-# ============================================================================
-
-export COOKIE_ACCESS_TOKEN_NAME = access_token
-export COOKIE_REFRESH_TOKEN_NAME = refresh_token
-export COOKIE_DOMAIN =
-export COOKIE_PATH = /
-export COOKIE_SECURE = false
-export COOKIE_HTTP_ONLY = true
-export COOKIE_SAME_SITE = Lax
-
-# ============================================================================
-# Environment Variables - OAuth Configuration
-# ============================================================================
-
-# Google OAuth
-export OAUTH_GOOGLE_ENABLED = false
-export OAUTH_GOOGLE_CLIENT_ID =
-export OAUTH_GOOGLE_CLIENT_SECRET =
-export OAUTH_GOOGLE_REDIRECT_URL = http://localhost:5173/auth/callback/?provider=google
-export OAUTH_GOOGLE_SCOPES = openid,email,profile
-export OAUTH_GOOGLE_AUTH_URL = https://accounts.google.com/o/oauth2/auth
-export OAUTH_GOOGLE_TOKEN_URL = https://oauth2.googleapis.com/token
-export OAUTH_GOOGLE_USER_INFO_URL = https://www.googleapis.com/oauth2/v2/userinfo
-export OAUTH_GOOGLE_TIMEOUT = 30s
-
-# Microsoft OAuth
-export OAUTH_MICROSOFT_ENABLED = false
-export OAUTH_MICROSOFT_CLIENT_ID =
-export OAUTH_MICROSOFT_CLIENT_SECRET =
-export OAUTH_MICROSOFT_REDIRECT_URL = http://localhost:$(SERVER_PORT)/auth/callback/microsoft
-export OAUTH_MICROSOFT_SCOPES = openid,email,profile,User.Read
-export OAUTH_MICROSOFT_AUTH_URL = https://login.microsoftonline.com/common/oauth2/v2.0/authorize
-export OAUTH_MICROSOFT_TOKEN_URL = https://login.microsoftonline.com/common/oauth2/v2.0/token
-export OAUTH_MICROSOFT_USER_INFO_URL = https://graph.microsoft.com/v1.0/me
-export OAUTH_MICROSOFT_TIMEOUT = 30s
-
-# OAuth State Manager
-export OAUTH_STATE_MANAGER_TYPE = redis
-export OAUTH_STATE_TTL = 10m
-
-# ============================================================================
-# Environment Variables - Tenant Configuration
-# ============================================================================
-
-export TENANT_TRIAL_DAYS = 30
-export TENANT_SUBSCRIPTION_YEARS = 1
-export TENANT_MAX_USERS_BASIC = 5
-export TENANT_MAX_USERS_PROFESSIONAL = 50
-export TENANT_MAX_USERS_ENTERPRISE = 500`,
-
-		MakefileEnvDisplay: `@echo "JWT:"
-@echo "  ISSUER:            $(JWT_ISSUER)"
-@echo "  ACCESS_TTL:        $(JWT_ACCESS_TOKEN_TTL)"
-@echo "  REFRESH_TTL:       $(JWT_REFRESH_TOKEN_TTL)"
-@echo ""
-@echo "OAuth:"
-@echo "  GOOGLE:            $(OAUTH_GOOGLE_ENABLED)"
-@echo "  MICROSOFT:         $(OAUTH_MICROSOFT_ENABLED)"
-@echo "  STATE_MANAGER:     $(OAUTH_STATE_MANAGER_TYPE)"
-@echo ""`,
-
-		PublicRoutes: `	// IAM Routes
-	container.IAM.OAuthHandlers.RegisterRoutes(app)
-	logx.Info("  > OAuth routes registered")
-
-	container.IAM.PasswordlessHandlers.RegisterRoutes(app)
-	logx.Info("  > Passwordless auth routes registered")`,
-
-		AuthMiddleware: `container.IAM.UnifiedAuthMiddleware.Authenticate()`,
-
-		RouteRegistration: `	container.IAM.APIKeyHandlers.RegisterRoutes(protected, container.IAM.UnifiedAuthMiddleware)
-	logx.Info("  > API key routes registered")
-
-	container.IAM.InvitationHandlers.RegisterRoutes(protected, container.IAM.UnifiedAuthMiddleware)
-	logx.Info("  > Invitation routes registered")`,
-
-		Bridges: []Bridge{
-			{
-				RequiresModule:   "notifx",
-				ContainerImports: `	"{{GOMODULE}}/pkg/notifx"`,
-				ContainerInit: `	// Bridge: iam + notifx — use notifx for OTP and invitation emails
-	c.IAM = iamcontainer.New(iamcontainer.Deps{
-		DB:                 c.DB,
-		Redis:              c.Redis,
-		Cfg:                c.Config,
-		OTPNotifier:        NewNotifxOTPNotifier(c.NotifxClient),
-		InvitationNotifier: NewNotifxInvitationNotifier(c.NotifxClient),
-	})`,
-				ContainerHelpers: `// NotifxOTPNotifier implements otp.NotificationService using notifx
-type NotifxOTPNotifier struct {
-	client *notifx.Client
+// Bridge defines code to inject when two modules are both wired. The other
+// module may also be infrastructure a profile provides, such as postgres.
+type Bridge struct {
+	RequiresModule    string   `yaml:"requires_module,omitempty"`    // Other module that must also be wired
+	RequiresProviders []string `yaml:"requires_providers,omitempty"` // Providers of it the bridge applies to; empty means any
+	ContainerImports  string   `yaml:"container_imports,omitempty"`  // Additional imports for bridge
+	ContainerInit     string   `yaml:"container_init,omitempty"`     // Code to inject into initModules()
+	ContainerHelpers  string   `yaml:"container_helpers,omitempty"`  // Top-level helper functions for bridge
+	GoDeps            []string `yaml:"go_deps,omitempty"`            // External Go dependencies of the bridge code
 }
 
-func NewNotifxOTPNotifier(client *notifx.Client) *NotifxOTPNotifier {
-	return &NotifxOTPNotifier{client: client}
+// BridgeName names the bridge module declares with other, as WireResult
+// and the manifest record it: "iam+notifx".
+func BridgeName(module, other string) string {
+	return module + "+" + other
 }
 
-func (n *NotifxOTPNotifier) SendOTP(ctx context.Context, contact string, code string) error {
-	return n.client.SendEmail(ctx, notifx.EmailMessage{
-		To:      []string{contact},
-		Subject: "Your verification code",
-		HTMLBody: fmt.Sprintf("<h2>Your verification code is: <strong>%s</strong></h2><p>This code will expire shortly.</p>", code),
-		TextBody: fmt.Sprintf("Your verification code is: %s", code),
-	})
+// ProjectFiles returns the project files wiring the module edits or
+// creates, derived from which of its fields are set. Env variables are
+// counted in the Makefile, where the default env target puts them.
+func (m WireableModule) ProjectFiles() []string {
+	var files []string
+	if m.ConfigFields != "" || m.ConfigLoads != "" {
+		files = append(files, "pkg/config/config.go")
+	}
+	if m.ContainerImports != "" || m.ContainerFields != "" || m.ModuleInit != "" || m.BackgroundStart != "" || m.ContainerHelpers != "" || len(m.Bridges) > 0 {
+		files = append(files, "cmd/container.go")
+	}
+	if m.ServerImports != "" || m.ServerMiddleware != "" || m.PublicRoutes != "" || m.RouteRegistration != "" || m.AuthMiddleware != "" {
+		files = append(files, "cmd/server.go")
+	}
+	if m.MakefileEnv != "" || m.MakefileEnvDisplay != "" || m.MakefileTargets != "" {
+		files = append(files, "Makefile")
+	}
+	if m.ComposeServices != "" || m.ComposeVolumes != "" {
+		files = append(files, "docker-compose.yml")
+	}
+	return append(files, slices.Sorted(maps.Keys(m.Files))...)
 }
 
-// NotifxInvitationNotifier implements invitation.NotificationService using notifx
-type NotifxInvitationNotifier struct {
-	client *notifx.Client
+// ProviderNames returns the names of the module's providers, sorted.
+func (m WireableModule) ProviderNames() []string {
+	return slices.Sorted(maps.Keys(m.Providers))
 }
 
-func NewNotifxInvitationNotifier(client *notifx.Client) *NotifxInvitationNotifier {
-	return &NotifxInvitationNotifier{client: client}
-}
+// WithProvider returns the module with the named provider's code added, or
+// DefaultProvider's when name is empty. A module without providers is
+// returned as is for an empty name.
+func (m WireableModule) WithProvider(name string) (WireableModule, error) {
+	if len(m.Providers) == 0 {
+		if name != "" {
+			return m, fmt.Errorf("%s has no providers to choose from", m.Name)
+		}
+		return m, nil
+	}
+	if name == "" {
+		name = m.DefaultProvider
+	}
+	p, ok := m.Providers[name]
+	if !ok {
+		return m, fmt.Errorf("unknown %s provider %q (available: %s)", m.Name, name, strings.Join(m.ProviderNames(), ", "))
+	}
 
-func (n *NotifxInvitationNotifier) SendInvitation(ctx context.Context, email string, token string, tenantID kernel.TenantID, invitedBy kernel.UserID) error {
-	return n.client.SendEmail(ctx, notifx.EmailMessage{
-		To:      []string{email},
-		Subject: "You've been invited",
-		HTMLBody: fmt.Sprintf("<h2>You've been invited!</h2><p>Use the following token to accept your invitation: <strong>%s</strong></p>", token),
-		TextBody: fmt.Sprintf("You've been invited! Use the following token to accept your invitation: %s", token),
-	})
-}`,
-			},
-		},
-	},
+	join := func(a, sep, b string) string {
+		if a == "" || b == "" {
+			return a + b
+		}
+		return a + sep + b
+	}
+	m.ContainerImports = join(m.ContainerImports, "\n", p.ContainerImports)
+	m.ContainerFields = join(m.ContainerFields, "\n", p.ContainerFields)
+	m.ModuleInit = join(m.ModuleInit, "\n", p.ModuleInit)
+	m.ContainerHelpers = join(m.ContainerHelpers, "\n\n", p.ContainerHelpers)
+	m.MakefileEnv = join(m.MakefileEnv, "\n", p.MakefileEnv)
+	m.MakefileEnvDisplay = join(m.MakefileEnvDisplay, "\n", p.MakefileEnvDisplay)
+	m.GoDeps = append(slices.Clip(m.GoDeps), p.GoDeps...)
+	m.FollowUps = append(slices.Clip(m.FollowUps), p.FollowUps...)
+	return m, nil
 }
 
 // IsWireableModule returns true if the given name is a wireable module.
@@ -493,11 +177,61 @@ func IsWireableModule(name string) bool {
 	return ok
 }
 
-// WireableModuleNames returns the names of all wireable modules.
+// WireableModuleNames returns the names of all wireable modules, sorted.
 func WireableModuleNames() []string {
-	var names []string
+	names := make([]string, 0, len(WireableModuleRegistry))
 	for name := range WireableModuleRegistry {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
+
+// WireOrder returns names sorted so that every module comes after the
+// wireable modules it requires and the modules its bridges require, letting
+// each bridge fire as its module is wired. Modules are otherwise in
+// alphabetical order.
+func WireOrder(names []string) []string {
+	pending := append([]string(nil), names...)
+	sort.Strings(pending)
+
+	ordered := make([]string, 0, len(pending))
+	placed := make(map[string]bool)
+	var place func(name string)
+	place = func(name string) {
+		if placed[name] {
+			return
+		}
+		placed[name] = true
+		spec := WireableModuleRegistry[name]
+		for _, req := range spec.RequiredWireables {
+			if HasModule(pending, req) {
+				place(req)
+			}
+		}
+		for _, b := range spec.Bridges {
+			if HasModule(pending, b.RequiresModule) {
+				place(b.RequiresModule)
+			}
+		}
+		ordered = append(ordered, name)
+	}
+	for _, name := range pending {
+		place(name)
+	}
+	return ordered
+}
+
+// WithRequiredWireables returns names followed by the wireable modules they
+// require, directly or through each other, that aren't among them.
+func WithRequiredWireables(names []string) []string {
+	all := append([]string(nil), names...)
+	for i := 0; i < len(all); i++ {
+		for _, req := range WireableModuleRegistry[all[i]].RequiredWireables {
+			if !HasModule(all, req) {
+				all = append(all, req)
+			}
+		}
+	}
+	return all
+}
@@ -0,0 +1,177 @@
+// Package diff renders line-based unified diffs and merges changes to a
+// common base.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff between a and b, labelled with the given
+// file names. It returns "" when the inputs are identical.
+func Unified(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := lineOps(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, h := range hunks(ops) {
+		out.WriteString(h)
+	}
+	return out.String()
+}
+
+// Stat returns the number of lines added and removed from a to b.
+func Stat(a, b string) (added, removed int) {
+	for _, o := range lineOps(splitLines(a), splitLines(b)) {
+		switch o.kind {
+		case opInsert:
+			added++
+		case opDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.TrimSuffix(s, "\n")
+	return strings.Split(s, "\n")
+}
+
+// lineOps computes an edit script from a to b using a longest common
+// subsequence table. Inputs are source files, so O(n*m) is fine.
+func lineOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
+
+// hunks groups ops into unified-diff hunks with surrounding context.
+func hunks(ops []op) []string {
+	var result []string
+
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend the hunk until a run of unchanged lines is long enough to split.
+		last := first
+		for k := first; k < len(ops); k++ {
+			if ops[k].kind != opEqual {
+				last = k
+				continue
+			}
+			if k-last > 2*contextLines {
+				break
+			}
+		}
+
+		from := max(first-contextLines, 0)
+		to := min(last+contextLines+1, len(ops))
+
+		// Line numbers (1-based) of the hunk in each file.
+		oldLine, newLine := 1, 1
+		for _, o := range ops[:from] {
+			if o.kind != opInsert {
+				oldLine++
+			}
+			if o.kind != opDelete {
+				newLine++
+			}
+		}
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, o := range ops[from:to] {
+			switch o.kind {
+			case opEqual:
+				body.WriteString(" " + o.line + "\n")
+				oldCount++
+				newCount++
+			case opDelete:
+				body.WriteString("-" + o.line + "\n")
+				oldCount++
+			case opInsert:
+				body.WriteString("+" + o.line + "\n")
+				newCount++
+			}
+		}
+
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		result = append(result, fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", oldLine, oldCount, newLine, newCount, body.String()))
+		start = to
+	}
+
+	return result
+}
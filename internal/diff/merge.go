@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+// Resolution says how Merge3 settles a hunk both sides changed
+// differently.
+type Resolution int
+
+const (
+	Mark   Resolution = iota // Keep both sides between conflict markers
+	Ours                     // Keep our side
+	Theirs                   // Take their side
+)
+
+// Merge3 merges the changes ours and theirs each made to base, line by
+// line as diff3 does. Hunks only one side changed take that side's lines;
+// hunks both changed the same way are taken once. Other hunks conflict and
+// are settled by res; with Mark they are written between git-style
+// markers labelled oursLabel and theirsLabel. It returns the merged text
+// and the number of conflicting hunks, whichever way they were settled.
+func Merge3(base, ours, theirs string, res Resolution, oursLabel, theirsLabel string) (string, int) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	mo, mt := matches(b, o), matches(b, t)
+
+	var out []string
+	conflicts := 0
+	chunk := func(bc, oc, tc []string) {
+		switch {
+		case slices.Equal(oc, bc):
+			out = append(out, tc...)
+		case slices.Equal(tc, bc), slices.Equal(oc, tc):
+			out = append(out, oc...)
+		default:
+			conflicts++
+			switch res {
+			case Ours:
+				out = append(out, oc...)
+			case Theirs:
+				out = append(out, tc...)
+			default:
+				out = append(out, "<<<<<<< "+oursLabel)
+				out = append(out, oc...)
+				out = append(out, "=======")
+				out = append(out, tc...)
+				out = append(out, ">>>>>>> "+theirsLabel)
+			}
+		}
+	}
+
+	// Lines of base both sides kept split the files into hunks.
+	i, oi, ti := 0, 0, 0
+	for k := range b {
+		if mo[k] < 0 || mt[k] < 0 {
+			continue
+		}
+		chunk(b[i:k], o[oi:mo[k]], t[ti:mt[k]])
+		out = append(out, b[k])
+		i, oi, ti = k+1, mo[k]+1, mt[k]+1
+	}
+	chunk(b[i:], o[oi:], t[ti:])
+
+	if len(out) == 0 {
+		return "", conflicts
+	}
+	merged := strings.Join(out, "\n")
+	// The final newline merges like a line: the side that changed it wins.
+	newline := strings.HasSuffix(ours, "\n")
+	if newline == strings.HasSuffix(base, "\n") {
+		newline = strings.HasSuffix(theirs, "\n")
+	}
+	if newline {
+		merged += "\n"
+	}
+	return merged, conflicts
+}
+
+// matches returns, for each line of a, the index of the line of b it is
+// kept as in the edit script from a to b, or -1 when it is deleted.
+func matches(a, b []string) []int {
+	m := make([]int, len(a))
+	i, j := 0, 0
+	for _, o := range lineOps(a, b) {
+		switch o.kind {
+		case opEqual:
+			m[i] = j
+			i++
+			j++
+		case opDelete:
+			m[i] = -1
+			i++
+		case opInsert:
+			j++
+		}
+	}
+	return m
+}
@@ -0,0 +1,47 @@
+package diff
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		res                Resolution
+		want               string
+		conflicts          int
+	}{
+		{name: "unchanged", base: base, ours: base, theirs: base, want: base},
+		{name: "ours only", base: base, ours: "a\nB\nc\nd\ne\n", theirs: base, want: "a\nB\nc\nd\ne\n"},
+		{name: "theirs only", base: base, ours: base, theirs: "a\nb\nc\nD\ne\n", want: "a\nb\nc\nD\ne\n"},
+		{name: "both, apart", base: base, ours: "a\nB\nc\nd\ne\n", theirs: "a\nb\nc\nD\ne\n", want: "a\nB\nc\nD\ne\n"},
+		{name: "both, identical", base: base, ours: "a\nB\nc\nd\ne\nf\n", theirs: "a\nB\nc\nd\ne\nf\n", want: "a\nB\nc\nd\ne\nf\n"},
+		{name: "deleted by ours", base: base, ours: "a\nc\nd\ne\n", theirs: "a\nb\nc\nD\ne\n", want: "a\nc\nD\ne\n"},
+		{name: "added by theirs", base: base, ours: "a\nB\nc\nd\ne\n", theirs: base + "f\n", want: "a\nB\nc\nd\ne\nf\n"},
+		{
+			name: "conflict marked", base: base, ours: "a\nX\nc\nd\ne\n", theirs: "a\nY\nc\nd\ne\n", res: Mark,
+			want:      "a\n<<<<<<< local\nX\n=======\nY\n>>>>>>> manifesto@v2\nc\nd\ne\n",
+			conflicts: 1,
+		},
+		{name: "conflict ours", base: base, ours: "a\nX\nc\nd\ne\n", theirs: "a\nY\nc\nd\ne\n", res: Ours, want: "a\nX\nc\nd\ne\n", conflicts: 1},
+		{name: "conflict theirs", base: base, ours: "a\nX\nc\nd\ne\n", theirs: "a\nY\nc\nd\ne\n", res: Theirs, want: "a\nY\nc\nd\ne\n", conflicts: 1},
+		{
+			name: "two conflicts, one clean hunk", base: base, ours: "X\nb\nc\nD\nX\n", theirs: "Y\nb\nc\nD\nY\n", res: Theirs,
+			want:      "Y\nb\nc\nD\nY\n",
+			conflicts: 2,
+		},
+		{name: "no final newline anywhere", base: "a\nb\nc", ours: "A\nb\nc", theirs: "a\nb\nC", want: "A\nb\nC"},
+		{name: "final newline added by theirs", base: "a\nb", ours: "A\nb", theirs: "a\nb\n", want: "A\nb\n"},
+		{name: "final newline removed by ours", base: "a\nb\n", ours: "a\nb", theirs: "a\nB\n", want: "a\nB"},
+		{name: "final newline removed by theirs", base: "a\nb\n", ours: "A\nb\n", theirs: "a\nb", want: "A\nb"},
+		{name: "emptied by both", base: base, ours: "", theirs: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(tt.base, tt.ours, tt.theirs, tt.res, "local", "manifesto@v2")
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("Merge3 = %q with %d conflict(s), want %q with %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package execx runs the external commands the CLI shells out to, such as
+// go get, with captured output, a timeout and Ctrl-C handling.
+package execx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a command that sets no Timeout of its own.
+const DefaultTimeout = 5 * time.Minute
+
+// Verbose streams command output to stderr as it runs. Otherwise output
+// is captured and only shown, as part of the error, when a command fails.
+var Verbose bool
+
+// Command is an external command to run.
+type Command struct {
+	Name    string
+	Args    []string
+	Dir     string
+	Env     []string      // KEY=value entries set over the CLI's environment
+	Timeout time.Duration // DefaultTimeout when zero
+}
+
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Error is a failed command, with its output and hints on likely causes.
+type Error struct {
+	Command string // e.g. "go get github.com/jmoiron/sqlx"
+	Err     error
+	Output  string   // Combined stdout and stderr
+	Hints   []string // Set by the helper that ran the command, e.g. Go
+	shown   bool     // Output was already streamed in verbose mode
+}
+
+// outputLines caps how much of a failed command's output Error includes.
+const outputLines = 20
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %v", e.Command, e.Err)
+	if !e.shown {
+		lines := strings.Split(strings.TrimSpace(e.Output), "\n")
+		if len(lines) > outputLines {
+			lines = append([]string{"..."}, lines[len(lines)-outputLines:]...)
+		}
+		for _, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				b.WriteString("\n    " + line)
+			}
+		}
+	}
+	for _, h := range e.Hints {
+		b.WriteString("\n    hint: " + h)
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Run runs c and returns its stdout. The command is killed when ctx is
+// done, its timeout passes, or the user presses Ctrl-C; a failure is
+// returned as an *Error.
+func Run(ctx context.Context, c Command) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Ctrl-C stops the command rather than the CLI, so the caller can
+	// report what was left undone.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+
+	var stdout, combined bytes.Buffer
+	if Verbose {
+		cmd.Stdout = io.MultiWriter(&stdout, &combined, os.Stderr)
+		cmd.Stderr = io.MultiWriter(&combined, os.Stderr)
+	} else {
+		cmd.Stdout = io.MultiWriter(&stdout, &combined)
+		cmd.Stderr = &combined
+	}
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("timed out after %s", timeout)
+	case ctx.Err() != nil:
+		err = fmt.Errorf("interrupted: %w", ctx.Err())
+	}
+	return stdout.Bytes(), &Error{Command: c.String(), Err: err, Output: combined.String(), shown: Verbose}
+}
@@ -0,0 +1,25 @@
+package execx
+
+import (
+	"context"
+	"strings"
+)
+
+// Git runs git in dir, adding hints for failures git doesn't explain
+// itself.
+func Git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	out, err := Run(ctx, Command{Name: "git", Args: args, Dir: dir})
+	if e, ok := err.(*Error); ok {
+		e.Hints = gitHints(e.Output)
+	}
+	return out, err
+}
+
+// gitHints matches well-known failure signatures in git's output.
+func gitHints(output string) []string {
+	var hints []string
+	if strings.Contains(output, "gpg failed to sign") {
+		hints = append(hints, "commit signing failed; check your gpg agent or commit with git -c commit.gpgsign=false")
+	}
+	return hints
+}
@@ -0,0 +1,69 @@
+package execx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Go runs the go command in dir with GoEnv, adding hints for the usual
+// causes of a failed module download.
+func Go(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	out, err := Run(ctx, Command{Name: "go", Args: args, Dir: dir, Env: GoEnv()})
+	if e, ok := err.(*Error); ok {
+		e.Hints = goHints(e.Output)
+	}
+	return out, err
+}
+
+// GoEnv returns the environment go commands run with: the CLI's own, which
+// carries GOPROXY, GOPRIVATE, GONOSUMDB and friends through unchanged,
+// with -mod=mod added to GOFLAGS so go get and go mod tidy also work in
+// vendored projects. A -mod the user set in GOFLAGS is kept.
+func GoEnv() []string {
+	flags := os.Getenv("GOFLAGS")
+	if !strings.Contains(flags, "-mod=") {
+		flags = strings.TrimSpace(flags + " -mod=mod")
+	}
+	return []string{"GOFLAGS=" + flags}
+}
+
+// goHints matches well-known failure signatures in go's output.
+func goHints(output string) []string {
+	var hints []string
+	has := func(subs ...string) bool {
+		for _, s := range subs {
+			if strings.Contains(output, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	private := "if the module is private, set GOPRIVATE (e.g. GOPRIVATE=github.com/your-org/*) so go fetches it directly"
+	switch {
+	case has("unknown revision", "invalid version: unknown"):
+		hints = append(hints, "the version doesn't exist upstream; "+private)
+	case has("410 Gone", "403 Forbidden", "404 Not Found") && has("proxy"):
+		hints = append(hints, fmt.Sprintf("the module proxy (%s) refused the module; %s", goProxy(), private))
+	case has("disabled by GOPROXY=off"):
+		hints = append(hints, "GOPROXY=off blocks module downloads; unset it or point it at a proxy, e.g. GOPROXY=https://proxy.golang.org,direct")
+	case has("verifying module", "SECURITY ERROR"):
+		hints = append(hints, "the checksum database couldn't verify the module; for private modules set GOPRIVATE or GONOSUMDB")
+	}
+	if has("terminal prompts disabled", "could not read Username", "Permission denied (publickey)", "Authentication failed") {
+		hints = append(hints, "git has no credentials for the host; configure a credential helper, a ~/.netrc entry, or an SSH key with git config url.\"git@github.com:\".insteadOf \"https://github.com/\"")
+	}
+	if has("dial tcp", "i/o timeout", "no such host", "connection refused") {
+		hints = append(hints, fmt.Sprintf("the network or module proxy (%s) is unreachable; check your connection, proxy settings or GOPROXY", goProxy()))
+	}
+	return hints
+}
+
+func goProxy() string {
+	if p := os.Getenv("GOPROXY"); p != "" {
+		return "GOPROXY=" + p
+	}
+	return "proxy.golang.org"
+}
@@ -0,0 +1,211 @@
+// Package migrate applies and reverts a project's SQL migrations and
+// records which are applied in the database.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// Table records the applied migrations, one row per version.
+const Table = "manifesto_migrations"
+
+// fileRe matches a migration file: a numeric version, a name and either
+// .up.sql/.down.sql or a plain .sql applied like an up migration.
+var fileRe = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+// Migration is one version in the migrations directory.
+type Migration struct {
+	Version string
+	Name    string
+	Up      string // Path of the script applying it
+	Down    string // Path of the script reverting it; empty when it can't be
+}
+
+// Load returns the migrations in dir, oldest first. Sequence numbers and
+// timestamps order numerically, so 000010 follows 000009.
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[string]*Migration{}
+	for _, e := range entries {
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		version, name, direction := m[1], m[2], m[3]
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("version %s is used by both %s and %s", version, mig.Name, name)
+		}
+		p := filepath.Join(dir, e.Name())
+		switch direction {
+		case ".down":
+			mig.Down = p
+		default:
+			if mig.Up != "" {
+				return nil, fmt.Errorf("version %s has two up scripts: %s and %s", version, filepath.Base(mig.Up), e.Name())
+			}
+			mig.Up = p
+		}
+	}
+
+	var out []Migration
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("version %s (%s) has a down script but no up script", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return versionLess(out[i].Version, out[j].Version) })
+	return out, nil
+}
+
+// versionLess orders versions numerically.
+func versionLess(a, b string) bool {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// Runner applies migrations to a database.
+type Runner struct {
+	DB *sql.DB
+}
+
+// Open connects to the postgres database at dsn.
+func Open(ctx context.Context, dsn string) (*Runner, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to the database: %w", err)
+	}
+	return &Runner{DB: db}, nil
+}
+
+// Close closes the database connection.
+func (r *Runner) Close() error {
+	return r.DB.Close()
+}
+
+// Applied returns the applied versions. A database without Table has
+// none, and is left as it is.
+func (r *Runner) Applied(ctx context.Context) (map[string]bool, error) {
+	var table sql.NullString
+	if err := r.DB.QueryRowContext(ctx, `SELECT to_regclass($1)::text`, Table).Scan(&table); err != nil {
+		return nil, fmt.Errorf("look up %s: %w", Table, err)
+	}
+	if !table.Valid {
+		return map[string]bool{}, nil
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT version FROM `+Table)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", Table, err)
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+// Up applies m and records it, in one transaction, creating Table when
+// it doesn't exist yet.
+func (r *Runner) Up(ctx context.Context, m Migration) error {
+	_, err := r.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+Table+` (
+	version    TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", Table, err)
+	}
+	return r.run(ctx, m.Up, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO `+Table+` (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+		return err
+	})
+}
+
+// Down reverts m and forgets it, in one transaction.
+func (r *Runner) Down(ctx context.Context, m Migration) error {
+	if m.Down == "" {
+		return fmt.Errorf("%s_%s has no down script, so it can't be reverted", m.Version, m.Name)
+	}
+	return r.run(ctx, m.Down, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM `+Table+` WHERE version = $1`, m.Version)
+		return err
+	})
+}
+
+// run executes the script at path and then record in a transaction.
+func (r *Runner) run(ctx context.Context, path string, record func(*sql.Tx) error) error {
+	script, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(string(script)) != "" {
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("record %s: %w", filepath.Base(path), err)
+	}
+	return tx.Commit()
+}
+
+// Pending returns the migrations in all that aren't applied, oldest first.
+func Pending(all []Migration, applied map[string]bool) []Migration {
+	var out []Migration
+	for _, m := range all {
+		if !applied[m.Version] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// AppliedMigrations returns the migrations in all that are applied,
+// newest first, the order they are reverted in.
+func AppliedMigrations(all []Migration, applied map[string]bool) []Migration {
+	var out []Migration
+	for i := len(all) - 1; i >= 0; i-- {
+		if applied[all[i].Version] {
+			out = append(out, all[i])
+		}
+	}
+	return out
+}
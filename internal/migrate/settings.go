@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// exportRe matches a Makefile export, e.g. "export DB_PORT = 5432".
+var exportRe = regexp.MustCompile(`^export\s+([A-Za-z_][A-Za-z0-9_]*)\s*[:?]?=\s*(.*)$`)
+
+// makeRefRe matches a $(NAME) or ${NAME} reference in a Makefile value.
+var makeRefRe = regexp.MustCompile(`\$[({]([A-Za-z_][A-Za-z0-9_]*)[)}]`)
+
+// Settings are the database connection settings of a project, read as
+// the generated config.Load reads them.
+type Settings struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+}
+
+// LoadSettings reads the DB_* variables of the project at projectRoot.
+// The environment takes precedence over .env, and .env over the defaults
+// the Makefile exports.
+func LoadSettings(projectRoot string) (Settings, error) {
+	vars := makefileExports(filepath.Join(projectRoot, "Makefile"))
+	for k, v := range dotenv(filepath.Join(projectRoot, ".env")) {
+		vars[k] = v
+	}
+	get := func(name string) string {
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		return vars[name]
+	}
+
+	s := Settings{
+		Host:     cmp.Or(get("DB_HOST"), "localhost"),
+		Port:     cmp.Or(get("DB_PORT"), "5432"),
+		User:     get("DB_USER"),
+		Password: get("DB_PASSWORD"),
+		Name:     get("DB_NAME"),
+		SSLMode:  cmp.Or(get("DB_SSL_MODE"), "disable"),
+	}
+	if s.User == "" || s.Name == "" {
+		return s, fmt.Errorf("DB_USER and DB_NAME are not set in the environment, .env or the Makefile")
+	}
+	return s, nil
+}
+
+// DSN returns the postgres connection URL for s.
+func (s Settings) DSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(s.User, s.Password),
+		Host:     s.Host + ":" + s.Port,
+		Path:     "/" + s.Name,
+		RawQuery: url.Values{"sslmode": {s.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// String describes s without the password.
+func (s Settings) String() string {
+	return fmt.Sprintf("%s@%s:%s/%s", s.User, s.Host, s.Port, s.Name)
+}
+
+// makefileExports returns the variables the Makefile at path exports,
+// with references to other exports expanded.
+func makefileExports(path string) map[string]string {
+	vars := map[string]string{}
+	f, err := os.Open(path)
+	if err != nil {
+		return vars
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		m := exportRe.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if m == nil {
+			continue
+		}
+		vars[m[1]] = makeRefRe.ReplaceAllStringFunc(strings.TrimSpace(m[2]), func(ref string) string {
+			return vars[makeRefRe.FindStringSubmatch(ref)[1]]
+		})
+	}
+	return vars
+}
+
+// dotenv returns the variables set in the .env file at path.
+func dotenv(path string) map[string]string {
+	vars := map[string]string{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return vars
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		vars[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return vars
+}
@@ -0,0 +1,54 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Token authenticates requests to GitHub, for private manifesto forks and
+// the higher API rate limit. The CLI sets it from --token; when empty,
+// NewClient falls back to $GITHUB_TOKEN.
+var Token string
+
+// TokenEnv is the environment variable the token is read from.
+const TokenEnv = "GITHUB_TOKEN"
+
+// Errors wrapped by the HTTP failures the client reports, so callers can
+// tell auth problems from a bad ref.
+var (
+	ErrUnauthorized = errors.New("GitHub rejected the token")
+	ErrForbidden    = errors.New("GitHub denied access")
+	ErrRateLimited  = errors.New("GitHub API rate limit exceeded")
+	ErrNotFound     = errors.New("not found on GitHub")
+)
+
+func resolveToken() string {
+	if Token != "" {
+		return Token
+	}
+	return os.Getenv(TokenEnv)
+}
+
+// statusError describes a failed response for what, e.g. "manifesto@v1.2.0".
+func (c *Client) statusError(resp *http.Response, what string) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%s: %w (HTTP 401); check %s or --token", what, ErrUnauthorized, TokenEnv)
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if c.token == "" {
+				return fmt.Errorf("%s: %w; set %s or pass --token to raise it", what, ErrRateLimited, TokenEnv)
+			}
+			return fmt.Errorf("%s: %w for this token; try again later", what, ErrRateLimited)
+		}
+		return fmt.Errorf("%s: %w (HTTP %d); the token may lack read access to %s", what, ErrForbidden, resp.StatusCode, c.repo)
+	case http.StatusNotFound:
+		if c.token == "" {
+			return fmt.Errorf("%s: %w (HTTP 404); check the version, or set %s if %s is private", what, ErrNotFound, TokenEnv, c.repo)
+		}
+		return fmt.Errorf("%s: %w (HTTP 404); check the version and that the token can read %s", what, ErrNotFound, c.repo)
+	}
+	return fmt.Errorf("%s: HTTP %d", what, resp.StatusCode)
+}
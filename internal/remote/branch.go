@@ -0,0 +1,48 @@
+package remote
+
+import "os"
+
+// ConfiguredDefaultRef replaces the default branch GitHub reports, for a
+// fork developed on another branch. The CLI sets it from default_ref in
+// the user config; $MANIFESTO_DEFAULT_REF takes precedence.
+var ConfiguredDefaultRef string
+
+// DefaultRefEnv is the environment variable the default ref is read from.
+const DefaultRefEnv = "MANIFESTO_DEFAULT_REF"
+
+func resolveDefaultRef() string {
+	if ref := os.Getenv(DefaultRefEnv); ref != "" {
+		return ref
+	}
+	return ConfiguredDefaultRef
+}
+
+// DefaultBranch returns the branch used when the repo has no release to
+// download: $MANIFESTO_DEFAULT_REF or default_ref in the user config when
+// set, else the default branch GitHub reports for the repo, and DefaultRef
+// if it can't be asked. The answer is kept for the client's lifetime.
+func (c *Client) DefaultBranch() string {
+	if c.defaultBranch != "" {
+		return c.defaultBranch
+	}
+	c.defaultBranch = DefaultRef
+	if c.offline {
+		return c.defaultBranch
+	}
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := c.getJSON("", "metadata of "+c.repo, &repo); err == nil && repo.DefaultBranch != "" {
+		c.defaultBranch = repo.DefaultBranch
+	}
+	return c.defaultBranch
+}
+
+// fallbackBranch returns the default branch without asking GitHub, for
+// when it just failed to answer: the configured one, or DefaultRef.
+func (c *Client) fallbackBranch() string {
+	if c.defaultBranch == "" {
+		c.defaultBranch = DefaultRef
+	}
+	return c.defaultBranch
+}
@@ -0,0 +1,131 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
+)
+
+// Offline and Refresh apply to every Client created afterwards. The CLI
+// sets them from its global --offline and --refresh flags.
+var (
+	// Offline serves everything from the archive cache and fails instead
+	// of touching the network.
+	Offline bool
+
+	// Refresh downloads archives even when they are cached.
+	Refresh bool
+)
+
+// ErrNotCached is returned in offline mode for a ref with no cached archive.
+var ErrNotCached = errors.New("not in the local cache")
+
+// archiveCacheDir returns ~/.cache/manifesto/<repo> (or the platform's
+// equivalent), or "" when there's no cache directory to use.
+func archiveCacheDir(repo string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "manifesto", filepath.FromSlash(repo))
+}
+
+// archivePath returns where the archive for ref is cached.
+func (c *Client) archivePath(ref string) string {
+	if c.cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.cacheDir, url.PathEscape(c.cacheKey(ref))+".tar.gz")
+}
+
+// cachedArchive returns the cached archive for ref, or nil.
+func (c *Client) cachedArchive(ref string) []byte {
+	path := c.archivePath(ref)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// storeArchive caches data as the archive for ref. A failure only costs a
+// download next time, so it isn't reported.
+func (c *Client) storeArchive(ref string, data []byte) {
+	path := c.archivePath(ref)
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	// Write to a temp file first so an interrupted write never leaves a
+	// truncated archive behind to be reused.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// latestCachedRef returns the highest version tag with a cached archive,
+// falling back to the default branch if that is cached. It returns ""
+// when nothing is cached.
+func (c *Client) latestCachedRef() string {
+	if c.cacheDir == "" {
+		return ""
+	}
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return ""
+	}
+
+	branch := c.fallbackBranch()
+	latest, hasDefault := "", false
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".tar.gz")
+		if !ok || e.IsDir() {
+			continue
+		}
+		ref, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		if ref == branch {
+			hasDefault = true
+			continue
+		}
+		if !strings.HasPrefix(ref, "v") {
+			continue
+		}
+		if latest == "" || toolchain.Compare(strings.TrimPrefix(ref, "v"), strings.TrimPrefix(latest, "v")) > 0 {
+			latest = ref
+		}
+	}
+	if latest == "" && hasDefault {
+		return branch
+	}
+	return latest
+}
+
+// offlineError explains that ref can't be used without the network.
+func offlineError(ref string) error {
+	return fmt.Errorf("manifesto@%s is %w; run once without --offline to download it", ref, ErrNotCached)
+}
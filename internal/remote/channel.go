@@ -0,0 +1,53 @@
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Channel says which ref a project's later downloads use when no --ref is
+// given.
+type Channel string
+
+const (
+	ChannelPinned Channel = "pinned" // The exact tag or commit the project was created with
+	ChannelStable Channel = "stable" // The latest release at each download, recorded as its tag
+	ChannelBranch Channel = "branch" // A moving branch: each download may get different code
+)
+
+// Channels lists the values accepted by ParseChannel.
+var Channels = []Channel{ChannelStable, ChannelPinned, ChannelBranch}
+
+// ParseChannel parses a --ref-channel value. "" is returned as is, for the
+// caller to pick a channel from the ref.
+func ParseChannel(s string) (Channel, error) {
+	if s == "" || slices.Contains(Channels, Channel(s)) {
+		return Channel(s), nil
+	}
+	return "", fmt.Errorf("unknown ref channel %q (available: stable, pinned, branch)", s)
+}
+
+// IsBranch reports whether ref resolved as t names a branch: a forced
+// branch, or one of the NotableBranches when the type is guessed.
+func IsBranch(ref string, t RefType) bool {
+	return t == RefBranch || (t == RefAuto && slices.Contains(NotableBranches, ref))
+}
+
+// BranchCommit returns the SHA of the commit branch points at now.
+func (c *Client) BranchCommit(branch string) (string, error) {
+	what := "commit of " + branch
+	if c.offline {
+		return "", fmt.Errorf("%s: offline", what)
+	}
+	url := fmt.Sprintf("%s/repos/%s/commits/%s", GitHubAPI, c.repo, branch)
+	resp, body, err := c.fetch(url, "application/vnd.github.sha", what, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", what, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", c.statusError(resp, what)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,131 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves the github.com, codeload, raw and API endpoints the
+// client uses from memory, keyed by host and request URI, e.g.
+// "github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz". Anything
+// else is a 404.
+type fakeGitHub struct {
+	mu        sync.Mutex
+	responses map[string][]byte
+	requests  []string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Host + r.URL.RequestURI()
+	f.mu.Lock()
+	f.requests = append(f.requests, key)
+	body, ok := f.responses[key]
+	f.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(body)
+}
+
+// serve makes key answer with body.
+func (f *fakeGitHub) serve(key string, body []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[key] = body
+}
+
+// requested returns the keys requested so far, in order.
+func (f *fakeGitHub) requested() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.requests)
+}
+
+// rewriteTransport sends every request to one server, keeping the host it
+// was meant for in the Host header.
+type rewriteTransport struct{ server *url.URL }
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = req.URL.Host
+	req.URL.Scheme = rt.server.Scheme
+	req.URL.Host = rt.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newFakeGitHub starts a fakeGitHub and returns it with a client of
+// acme/manifesto that talks to it, without a token and with an empty
+// archive cache.
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *Client) {
+	t.Helper()
+	t.Setenv(TokenEnv, "")
+	t.Setenv(DefaultRefEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	fake := &fakeGitHub{responses: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient("acme/manifesto")
+	c.httpClient = &http.Client{Transport: rewriteTransport{serverURL}}
+	c.cacheDir = t.TempDir()
+	c.offline, c.refresh = false, false
+	return fake, c
+}
+
+// tarEntry is one entry of a tarball built by tarball.
+type tarEntry struct {
+	header tar.Header
+	body   string
+}
+
+// file is a regular file entry named name.
+func file(name, body string) tarEntry {
+	return tarEntry{header: tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(body))}, body: body}
+}
+
+// tarball returns a gzipped tar of entries, laid out like GitHub's under
+// one top-level directory, which entry names must include.
+func tarball(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		if err := tw.WriteHeader(&e.header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// moduleArchive returns a tarball of a manifesto checkout with a kernel
+// module whose only file says which ref it came from.
+func moduleArchive(t *testing.T, ref string) []byte {
+	t.Helper()
+	return tarball(t,
+		file("manifesto-"+ref+"/go.mod", "module github.com/Abraxas-365/manifesto\n"),
+		file("manifesto-"+ref+"/pkg/kernel/ref.go", "package kernel\n\nconst Ref = \""+ref+"\"\n"),
+	)
+}
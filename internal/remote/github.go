@@ -9,8 +9,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,13 +24,42 @@ const (
 	DefaultRef  = "main"
 )
 
+var repoRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._-]+$`)
+
+// ValidateRepo checks that repo has the GitHub "owner/name" form.
+func ValidateRepo(repo string) error {
+	if !repoRe.MatchString(repo) || strings.HasSuffix(repo, ".git") {
+		return fmt.Errorf("invalid repo %q: expected owner/name, e.g. %s", repo, DefaultRepo)
+	}
+	return nil
+}
+
 type Release struct {
-	TagName string `json:"tag_name"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
 }
 
 type Client struct {
 	repo       string
 	httpClient *http.Client
+	token      string // Sent as a Bearer token when set
+
+	// Archives are cached in cacheDir by ref; "" disables the cache.
+	cacheDir string
+	offline  bool
+	refresh  bool
+
+	refType  RefType                                // Set by ForceRefType; RefAuto guesses per ref
+	progress func(Progress)                         // Set by OnProgress
+	extract  func(path string, content []byte) bool // Set by OnExtract
+
+	mu        sync.Mutex        // Guards checksums and archives, for Prefetch
+	checksums map[string]string // SHA-256 of each archive used, by ref
+	archives  map[string][]byte // Archives this client has used, by ref
+
+	defaultBranch string // Set by DefaultBranch, or configured; "" until known
 }
 
 func NewClient(repo string) *Client {
@@ -37,66 +69,134 @@ func NewClient(repo string) *Client {
 	return &Client{
 		repo:       repo,
 		httpClient: &http.Client{Timeout: 60 * time.Second},
+		token:      resolveToken(),
+		cacheDir:   archiveCacheDir(repo),
+		offline:    Offline,
+		refresh:    Refresh,
+		checksums:  make(map[string]string),
+		archives:   make(map[string][]byte),
+
+		defaultBranch: resolveDefaultRef(),
 	}
 }
 
+// GetLatestVersion returns the latest release tag or, for a repo with
+// tags but no releases, its highest stable semver tag. Without either it
+// returns DefaultBranch. When GitHub can't be reached, or in offline mode,
+// it returns the latest cached version instead, and the default branch if
+// nothing is cached. A rejected token or an exhausted rate limit is
+// returned alongside the default branch.
 func (c *Client) GetLatestVersion() (string, error) {
+	if c.offline {
+		return c.fallbackRef(), nil
+	}
+
 	url := fmt.Sprintf("%s/repos/%s/releases/latest", GitHubAPI, c.repo)
-	resp, err := c.httpClient.Get(url)
+	resp, body, err := c.fetch(url, "application/vnd.github+json", "latest release of "+c.repo, nil)
 	if err != nil {
-		return DefaultRef, nil
+		return c.fallbackRef(), nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return DefaultRef, nil
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return c.fallbackBranch(), c.statusError(resp, "latest release of "+c.repo)
+	default:
+		// No releases yet.
+		if tag := c.latestTag(); tag != "" {
+			return tag, nil
+		}
+		return c.DefaultBranch(), nil
 	}
 
 	var release Release
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil || release.TagName == "" {
-		return DefaultRef, nil
+	if err := json.Unmarshal(body, &release); err != nil || release.TagName == "" {
+		return c.DefaultBranch(), nil
 	}
 	return release.TagName, nil
 }
 
-// FetchModulePaths downloads the repo at ref and extracts only the given paths.
-// It rewrites Go imports from goModuleOld to goModuleNew.
-func (c *Client) FetchModulePaths(ref string, paths []string, destRoot, goModuleOld, goModuleNew string) error {
+// latestTag returns the repo's highest semver tag that isn't a
+// pre-release, or "" without one.
+func (c *Client) latestTag() string {
+	var tags []namedRef
+	if err := c.getJSON("/tags?per_page=100", "tags of "+c.repo, &tags); err != nil {
+		return ""
+	}
+	latest := ""
+	for _, t := range tags {
+		m := semverRe.FindStringSubmatch(t.Name)
+		if m == nil || m[4] != "" {
+			continue
+		}
+		if latest == "" || compareTags(t.Name, latest) > 0 {
+			latest = t.Name
+		}
+	}
+	return latest
+}
+
+func (c *Client) fallbackRef() string {
+	if ref := c.latestCachedRef(); ref != "" {
+		return ref
+	}
+	return c.fallbackBranch()
+}
+
+// PathMapping extracts the archive directory Src to Dest in the project.
+// Most modules sit at the same path in both.
+type PathMapping struct {
+	Src   string
+	Dest  string
+	Paths []string // Patterns of the project paths under Dest extracted; see Match
+}
+
+// OnExtract sets a function FetchModulePaths calls before writing each
+// file, with its slash path relative to destRoot and its content after
+// import rewriting. Returning false leaves the file on disk as it is.
+func (c *Client) OnExtract(fn func(path string, content []byte) bool) {
+	c.extract = fn
+}
+
+// FetchModulePaths downloads the repo at ref and extracts only the given
+// paths, and under each only what its Paths select. It rewrites Go
+// imports from goModuleOld to goModuleNew, pointing imports of a moved
+// path at its Dest; see RewriteExtensions and RewriteStrings for rewriting
+// more. It fails if the repo has nothing under one of the paths, as a fork
+// without a module would.
+func (c *Client) FetchModulePaths(ref string, paths []PathMapping, destRoot, goModuleOld, goModuleNew string) error {
 	archiveData, err := c.downloadArchive(ref)
 	if err != nil {
 		return err
 	}
 
-	gz, err := gzip.NewReader(bytes.NewReader(archiveData))
-	if err != nil {
-		return fmt.Errorf("decompress: %w", err)
+	srcs := make([]string, len(paths))
+	bySrc := make(map[string]PathMapping, len(paths))
+	for i, p := range paths {
+		srcs[i] = p.Src
+		bySrc[p.Src] = p
 	}
-	defer gz.Close()
-
-	tr := tar.NewReader(gz)
+	rewrite := newImportRewriter(paths, goModuleOld, goModuleNew)
 
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("tar read: %w", err)
+	found := make(map[string]bool)
+	files := 0
+	err = walkArchive(archiveData, func(relPath string, header *tar.Header, tr io.Reader) error {
+		prefix, ok := matchingPrefix(relPath, srcs)
+		if !ok {
+			return nil
 		}
+		found[prefix] = true
 
-		// Strip top-level GitHub dir (e.g. "manifesto-main/").
-		parts := strings.SplitN(header.Name, "/", 2)
-		if len(parts) < 2 || parts[1] == "" {
-			continue
+		mapping := bySrc[prefix]
+		destRel := mapping.Dest + strings.TrimPrefix(relPath, prefix)
+		if !Match(destRel, mapping.Paths) {
+			return nil
 		}
-		relPath := parts[1]
-
-		if !matchesAnyPrefix(relPath, paths) {
-			continue
+		destPath, err := extractPath(destRoot, destRel)
+		if err != nil {
+			return err
 		}
 
-		destPath := filepath.Join(destRoot, relPath)
-
 		switch header.Typeflag {
 		case tar.TypeDir:
 			if err := os.MkdirAll(destPath, 0755); err != nil {
@@ -112,65 +212,295 @@ func (c *Client) FetchModulePaths(ref string, paths []string, destRoot, goModule
 				return fmt.Errorf("read %s: %w", relPath, err)
 			}
 
-			// Rewrite Go imports.
-			if strings.HasSuffix(relPath, ".go") && goModuleOld != "" && goModuleNew != "" {
-				content = []byte(strings.ReplaceAll(string(content), goModuleOld, goModuleNew))
+			// Rewrite Go imports, and the files opted in.
+			if rewrite != nil {
+				content = rewrite.RewriteFile(relPath, content)
+			}
+			if c.extract != nil && !c.extract(destRel, content) {
+				return nil
 			}
 
-			if err := os.WriteFile(destPath, content, os.FileMode(header.Mode)); err != nil {
+			if err := os.WriteFile(destPath, content, os.FileMode(header.Mode).Perm()); err != nil {
 				return err
 			}
+			files++
+			if c.progress != nil {
+				c.progress(Progress{Stage: StageExtract, Files: files})
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("archive entry %s is a link; refusing to extract it", relPath)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	var missing []string
+	for _, src := range srcs {
+		if !found[src] {
+			missing = append(missing, src)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s@%s has no %s; check that it is a manifesto fork with these modules", c.repo, ref, strings.Join(missing, ", "))
+	}
 	return nil
 }
 
+// ReadModulePaths returns the files FetchModulePaths would write for
+// paths, keyed by their slash path relative to the project root, without
+// writing anything. Imports are rewritten the same way.
+func (c *Client) ReadModulePaths(ref string, paths []PathMapping, goModuleOld, goModuleNew string) (map[string][]byte, error) {
+	archiveData, err := c.downloadArchive(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	srcs := make([]string, len(paths))
+	bySrc := make(map[string]PathMapping, len(paths))
+	for i, p := range paths {
+		srcs[i] = p.Src
+		bySrc[p.Src] = p
+	}
+	rewrite := newImportRewriter(paths, goModuleOld, goModuleNew)
+
+	files := make(map[string][]byte)
+	found := make(map[string]bool)
+	err = walkArchive(archiveData, func(relPath string, header *tar.Header, tr io.Reader) error {
+		prefix, ok := matchingPrefix(relPath, srcs)
+		if !ok {
+			return nil
+		}
+		found[prefix] = true
+		if header.Typeflag != tar.TypeReg {
+			return nil
+		}
+
+		mapping := bySrc[prefix]
+		destRel := mapping.Dest + strings.TrimPrefix(relPath, prefix)
+		if !Match(destRel, mapping.Paths) {
+			return nil
+		}
+		if _, err := extractPath(".", destRel); err != nil {
+			return err
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", relPath, err)
+		}
+		if rewrite != nil {
+			content = rewrite.RewriteFile(relPath, content)
+		}
+		files[destRel] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, src := range srcs {
+		if !found[src] {
+			return nil, fmt.Errorf("%s@%s has no %s; check that it is a manifesto fork with these modules", c.repo, ref, src)
+		}
+	}
+	return files, nil
+}
+
+// walkArchive calls fn for each entry of a GitHub tarball, with its path
+// relative to the repo root.
+func walkArchive(archiveData []byte, fn func(relPath string, header *tar.Header, r io.Reader) error) error {
+	gz, err := gzip.NewReader(bytes.NewReader(archiveData))
+	if err != nil {
+		return fmt.Errorf("decompress: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar read: %w", err)
+		}
+
+		if path.IsAbs(header.Name) {
+			return fmt.Errorf("archive entry %s has an absolute path", header.Name)
+		}
+
+		// Strip top-level GitHub dir (e.g. "manifesto-main/").
+		parts := strings.SplitN(header.Name, "/", 2)
+		if len(parts) < 2 || parts[1] == "" {
+			continue
+		}
+		if err := fn(parts[1], header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// FetchGoMod returns the upstream go.mod at ref, read from the cached
+// archive when there is one, or from the checkout a local ref names.
 func (c *Client) FetchGoMod(ref string) (string, error) {
-	url := fmt.Sprintf("%s/%s/%s/go.mod", RawGitHub, c.repo, ref)
-	resp, err := c.httpClient.Get(url)
+	if dir, ok := LocalPath(ref); ok {
+		goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		return string(goMod), err
+	}
+	if archiveData := c.cachedArchive(ref); archiveData != nil {
+		var goMod []byte
+		err := walkArchive(archiveData, func(relPath string, header *tar.Header, r io.Reader) error {
+			if relPath != "go.mod" {
+				return nil
+			}
+			var err error
+			goMod, err = io.ReadAll(r)
+			return err
+		})
+		if err == nil && goMod != nil {
+			return string(goMod), nil
+		}
+	}
+	if c.offline {
+		return "", offlineError(ref)
+	}
+
+	// raw.githubusercontent.com ignores tokens, so authenticated clients
+	// read the file through the API instead.
+	url, accept := fmt.Sprintf("%s/%s/%s/go.mod", RawGitHub, c.repo, c.qualifiedRef(ref)), ""
+	if c.token != "" {
+		url = fmt.Sprintf("%s/repos/%s/contents/go.mod?ref=%s", GitHubAPI, c.repo, c.qualifiedRef(ref))
+		accept = "application/vnd.github.raw"
+	}
+	resp, data, err := c.fetch(url, accept, "go.mod at "+ref, nil)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("go.mod not found: HTTP %d", resp.StatusCode)
+		return "", c.statusError(resp, "go.mod at "+ref)
 	}
-
-	data, err := io.ReadAll(resp.Body)
-	return string(data), err
+	return string(data), nil
 }
 
+// downloadArchive returns the repo tarball at ref, from the cache unless
+// refresh is set. The archive is checked to decode in full first, and a
+// corrupt cached copy is downloaded again. A local ref is packed from its
+// checkout instead.
 func (c *Client) downloadArchive(ref string) ([]byte, error) {
-	urls := []string{
-		fmt.Sprintf("https://github.com/%s/archive/refs/tags/%s.tar.gz", c.repo, ref),
-		fmt.Sprintf("https://github.com/%s/archive/refs/heads/%s.tar.gz", c.repo, ref),
+	return c.downloadArchiveReporting(ref, c.progress)
+}
+
+// Prefetch downloads the archive for ref, reporting to progress if not
+// nil, so later fetches from ref read it from memory. It is safe to call
+// for several refs at once.
+func (c *Client) Prefetch(ref string, progress func(Progress)) error {
+	_, err := c.downloadArchiveReporting(ref, progress)
+	return err
+}
+
+// recordArchive keeps data as the archive used for ref.
+func (c *Client) recordArchive(ref string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checksums[ref] = checksum(data)
+	c.archives[ref] = data
+}
+
+// downloadArchiveReporting is downloadArchive reporting to progress. An
+// archive this client has already used is returned again without reading
+// the cache or the network.
+func (c *Client) downloadArchiveReporting(ref string, progress func(Progress)) ([]byte, error) {
+	if _, ok := LocalPath(ref); ok {
+		return localArchive(ref)
+	}
+	c.mu.Lock()
+	data, ok := c.archives[ref]
+	c.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+	if !c.refresh {
+		if data := c.cachedArchive(ref); data != nil {
+			err := verifyArchive(data)
+			if err == nil {
+				c.recordArchive(ref, data)
+				return data, nil
+			}
+			if c.offline {
+				return nil, fmt.Errorf("cached archive for manifesto@%s is corrupt (%v); run again without --offline to download it", ref, err)
+			}
+		}
+	}
+	if c.offline {
+		return nil, offlineError(ref)
 	}
-	if ref == DefaultRef || ref == "" {
-		urls = []string{urls[1]}
+
+	urls := c.archiveURLs(ref)
+	// github.com archive links don't accept tokens; the API's tarball
+	// endpoint does, and resolves tags, branches and commits alike.
+	if c.token != "" {
+		urls = []string{fmt.Sprintf("%s/repos/%s/tarball/%s", GitHubAPI, c.repo, c.qualifiedRef(ref))}
 	}
 
+	var onRead func(n, total int64)
+	if progress != nil {
+		onRead = func(n, total int64) {
+			progress(Progress{Stage: StageDownload, Bytes: n, Total: total})
+		}
+	}
+
+	var lastErr error
 	for _, u := range urls {
-		resp, err := c.httpClient.Get(u)
+		resp, data, err := c.fetch(u, "", c.repo+"@"+ref, onRead)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to download archive for ref '%s': %w", ref, err)
 		}
-		defer resp.Body.Close()
-
 		if resp.StatusCode == http.StatusOK {
-			return io.ReadAll(resp.Body)
+			if err := verifyArchive(data); err != nil {
+				return nil, fmt.Errorf("archive for ref '%s' is incomplete or corrupt: %w", ref, err)
+			}
+			c.storeArchive(ref, data)
+			c.recordArchive(ref, data)
+			return data, nil
+		}
+
+		lastErr = c.statusError(resp, c.repo+"@"+ref)
+		// Only a 404 is worth retrying as a branch.
+		if resp.StatusCode != http.StatusNotFound {
+			return nil, lastErr
+		}
+	}
+	return nil, fmt.Errorf("failed to download archive for ref '%s': %w", ref, lastErr)
+}
+
+// extractPath returns where the archive entry relPath is written under
+// destRoot. Absolute names and ".." components are rejected so a crafted
+// archive can't write outside destRoot.
+func extractPath(destRoot, relPath string) (string, error) {
+	if path.IsAbs(relPath) || filepath.IsAbs(relPath) || filepath.VolumeName(relPath) != "" {
+		return "", fmt.Errorf("archive entry %s has an absolute path; refusing to extract it", relPath)
+	}
+	for _, part := range strings.FieldsFunc(relPath, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return "", fmt.Errorf("archive entry %s leaves the project directory; refusing to extract it", relPath)
 		}
 	}
 
-	return nil, fmt.Errorf("failed to download archive for ref '%s'", ref)
+	dest := filepath.Join(destRoot, filepath.FromSlash(relPath))
+	if rel, err := filepath.Rel(destRoot, dest); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s leaves the project directory; refusing to extract it", relPath)
+	}
+	return dest, nil
 }
 
-func matchesAnyPrefix(path string, prefixes []string) bool {
+// matchingPrefix returns the first of prefixes that path is, or is under.
+func matchingPrefix(path string, prefixes []string) (string, bool) {
 	for _, prefix := range prefixes {
 		if path == prefix || strings.HasPrefix(path, prefix+"/") {
-			return true
+			return prefix, true
 		}
 	}
-	return false
+	return "", false
 }
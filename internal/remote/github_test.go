@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExtractRejectsUnsafeEntries serves archives with one crafted entry
+// each under pkg/kernel, and checks extracting the module fails without
+// writing anything outside the project.
+func TestExtractRejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry tarEntry
+		want  string
+	}{
+		{
+			name:  "absolute path",
+			entry: file("/manifesto-v1.0.0/pkg/kernel/evil.go", "package evil\n"),
+			want:  "absolute path",
+		},
+		{
+			name:  "parent directory",
+			entry: file("manifesto-v1.0.0/pkg/kernel/../../../evil.go", "package evil\n"),
+			want:  "leaves the project directory",
+		},
+		{
+			name: "symlink",
+			entry: tarEntry{header: tar.Header{
+				Name:     "manifesto-v1.0.0/pkg/kernel/evil",
+				Typeflag: tar.TypeSymlink,
+				Linkname: "../../../../evil",
+			}},
+			want: "is a link",
+		},
+		{
+			name: "hard link",
+			entry: tarEntry{header: tar.Header{
+				Name:     "manifesto-v1.0.0/pkg/kernel/evil",
+				Typeflag: tar.TypeLink,
+				Linkname: "/etc/passwd",
+			}},
+			want: "is a link",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, c := newFakeGitHub(t)
+			fake.serve("github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz", tarball(t,
+				file("manifesto-v1.0.0/pkg/kernel/kernel.go", "package kernel\n"),
+				tt.entry,
+			))
+
+			// The project is two levels down, so every escape stays inside
+			// the test's directory.
+			outside := t.TempDir()
+			dest := filepath.Join(outside, "a", "shop")
+			err := c.FetchModulePaths("v1.0.0", []PathMapping{{Src: "pkg/kernel", Dest: "pkg/kernel"}}, dest, "", "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want an error about a %s", err, tt.want)
+			}
+			filepath.WalkDir(outside, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() && filepath.Base(path) != "kernel.go" {
+					t.Errorf("extraction wrote %s", path)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+// TestReadModulePathsRejectsUnsafePaths checks the dry-run reader refuses
+// the same paths as extraction.
+func TestReadModulePathsRejectsUnsafePaths(t *testing.T) {
+	for name, entry := range map[string]tarEntry{
+		"absolute path":    file("/manifesto-v1.0.0/pkg/kernel/evil.go", "package evil\n"),
+		"parent directory": file("manifesto-v1.0.0/pkg/kernel/../../evil.go", "package evil\n"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			fake, c := newFakeGitHub(t)
+			fake.serve("github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz", tarball(t,
+				file("manifesto-v1.0.0/pkg/kernel/kernel.go", "package kernel\n"),
+				entry,
+			))
+			if _, err := c.ReadModulePaths("v1.0.0", []PathMapping{{Src: "pkg/kernel", Dest: "pkg/kernel"}}, "", ""); err == nil {
+				t.Error("reading an unsafe archive succeeded")
+			}
+		})
+	}
+}
+
+func TestExtractPath(t *testing.T) {
+	root := filepath.Join("project", "shop")
+	for _, rel := range []string{"/etc/passwd", "../evil.go", "pkg/../../evil.go", `pkg\..\..\evil.go`, "pkg/kernel/.."} {
+		if _, err := extractPath(root, rel); err == nil {
+			t.Errorf("extractPath(%q) accepted", rel)
+		}
+	}
+	got, err := extractPath(root, "pkg/kernel/kernel.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "pkg", "kernel", "kernel.go"); got != want {
+		t.Errorf("extractPath = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+package remote
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RewriteExtensions and RewriteStrings widen what fetching rewrites beyond
+// Go imports, for every Client. The CLI sets them from its global
+// --rewrite-ext and --rewrite-strings flags.
+var (
+	// RewriteExtensions lists the extensions of other files, like "yaml"
+	// or ".mod", in which mentions of the upstream module are rewritten
+	// too. Files of any other extension are copied as they are.
+	RewriteExtensions []string
+
+	// RewriteStrings rewrites Go string literals that are one of the
+	// module's import paths, for code that names a package in a string.
+	RewriteStrings bool
+)
+
+// importRewriter points the imports of fetched Go files at the project's
+// module: goModuleOld becomes goModuleNew, and imports of a moved path its
+// new location.
+type importRewriter struct {
+	old, new string
+	moved    []PathMapping // Longest Src first, so a nested path wins over its parent
+	exts     []string      // RewriteExtensions, each with its leading dot
+	strings  bool          // RewriteStrings
+}
+
+// newImportRewriter returns nil when there's nothing to rewrite.
+func newImportRewriter(paths []PathMapping, goModuleOld, goModuleNew string) *importRewriter {
+	if goModuleOld == "" || goModuleNew == "" {
+		return nil
+	}
+	moved := slices.DeleteFunc(slices.Clone(paths), func(p PathMapping) bool { return p.Src == p.Dest })
+	slices.SortFunc(moved, func(a, b PathMapping) int { return len(b.Src) - len(a.Src) })
+	exts := make([]string, 0, len(RewriteExtensions))
+	for _, ext := range RewriteExtensions {
+		if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+			exts = append(exts, "."+strings.TrimPrefix(ext, "."))
+		}
+	}
+	return &importRewriter{old: goModuleOld, new: goModuleNew, moved: moved, exts: exts, strings: RewriteStrings}
+}
+
+// RewriteFile returns the content of the fetched file name rewritten: the
+// imports of a Go file, mentions of the module in a file of one of
+// RewriteExtensions, and nothing in any other.
+func (r *importRewriter) RewriteFile(name string, src []byte) []byte {
+	ext := strings.ToLower(path.Ext(name))
+	switch {
+	case ext == ".go":
+		return r.Rewrite(src)
+	case slices.Contains(r.exts, ext):
+		return r.RewriteText(src)
+	}
+	return src
+}
+
+// Rewrite returns src with its import paths rewritten. Only the import
+// declarations change, and with RewriteStrings the string literals that
+// are exactly an import path of the module: a comment linking to the
+// upstream module or a literal merely mentioning it is left as it is. A
+// file that doesn't parse is returned unchanged.
+func (r *importRewriter) Rewrite(src []byte) []byte {
+	fset := token.NewFileSet()
+	mode := parser.ImportsOnly
+	if r.strings {
+		mode = parser.SkipObjectResolution
+	}
+	f, err := parser.ParseFile(fset, "", src, mode)
+	if err != nil {
+		return src
+	}
+
+	lits := make([]*ast.BasicLit, 0, len(f.Imports))
+	for _, spec := range f.Imports {
+		lits = append(lits, spec.Path)
+	}
+	if r.strings {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.ImportSpec); ok && spec.Path != nil {
+				return false // Already listed
+			}
+			// Only literals without escapes, which stay valid as they are.
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && !strings.Contains(lit.Value, `\`) {
+				lits = append(lits, lit)
+			}
+			return true
+		})
+		slices.SortFunc(lits, func(a, b *ast.BasicLit) int { return int(a.Pos() - b.Pos()) })
+	}
+
+	var out []byte
+	last := 0
+	for _, lit := range lits {
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			continue
+		}
+		rewritten, ok := r.path(value)
+		if !ok {
+			continue
+		}
+		start := fset.Position(lit.Pos()).Offset
+		out = append(out, src[last:start]...)
+		out = append(out, lit.Value[0])
+		out = append(out, rewritten...)
+		out = append(out, lit.Value[len(lit.Value)-1])
+		last = start + len(lit.Value)
+	}
+	if out == nil {
+		return src
+	}
+	return append(out, src[last:]...)
+}
+
+// RewriteText returns src, a file other than Go source, with every
+// mention of one of the module's paths rewritten. A mention is the module
+// path followed by path segments, up to the first character that can't be
+// in one; a longer module path that starts with it isn't one.
+func (r *importRewriter) RewriteText(src []byte) []byte {
+	var out []byte
+	text := string(src)
+	last := 0
+	for i := strings.Index(text, r.old); i != -1; i = strings.Index(text[last:], r.old) {
+		i += last
+		end := i + len(r.old)
+		if i > 0 && isPathChar(text[i-1]) || end < len(text) && isPathChar(text[end]) {
+			// Part of a longer name, like the module path plus -cli.
+			out = append(out, text[last:end]...)
+			last = end
+			continue
+		}
+		for end < len(text) && text[end] == '/' && end+1 < len(text) && isPathChar(text[end+1]) {
+			end++
+			for end < len(text) && (isPathChar(text[end]) || text[end] == '.' && end+1 < len(text) && isPathChar(text[end+1])) {
+				end++
+			}
+		}
+		rewritten, _ := r.path(text[i:end])
+		out = append(out, text[last:i]...)
+		out = append(out, rewritten...)
+		last = end
+	}
+	if out == nil {
+		return src
+	}
+	return append(out, text[last:]...)
+}
+
+// isPathChar reports whether c can be in a path segment, other than a dot.
+func isPathChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-' || c == '~'
+}
+
+// path returns the import path p points at in the project, and whether
+// it is one of the module's.
+func (r *importRewriter) path(p string) (string, bool) {
+	rest, ok := strings.CutPrefix(p, r.old)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return p, false
+	}
+	// Matched whole segments, so libs/fsx/pkg/fsx doesn't also catch
+	// libs/fsx/pkg/fsxs3.
+	for _, m := range r.moved {
+		if sub, ok := strings.CutPrefix(rest, "/"+m.Src); ok && (sub == "" || strings.HasPrefix(sub, "/")) {
+			return r.new + "/" + m.Dest + sub, true
+		}
+	}
+	return r.new + rest, true
+}
@@ -0,0 +1,176 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const upstream = "github.com/Abraxas-365/manifesto"
+
+// fetchedGo is a Go file of the errx module that mentions the upstream
+// module everywhere but in code: doc and line comments, a URL, literals
+// naming its packages, and a module with a longer path.
+const fetchedGo = `// Package errx wraps errors. See
+// https://github.com/Abraxas-365/manifesto/pkg/errx and
+// github.com/Abraxas-365/manifesto/pkg/kernel for the kernel.
+package errx
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto/libs/fsx/pkg/fsx"
+	kernel "github.com/Abraxas-365/manifesto/pkg/kernel"
+)
+
+const (
+	Upstream = "github.com/Abraxas-365/manifesto"
+	Kernel   = "github.com/Abraxas-365/manifesto/pkg/kernel" // Named in a string
+	Raw      = ` + "`github.com/Abraxas-365/manifesto/pkg/errx`" + `
+	Escaped  = "github.com/Abraxas-365/manifesto/pkg/errx\t"
+	Mention  = "see github.com/Abraxas-365/manifesto/pkg/errx"
+	CLI      = "github.com/Abraxas-365/manifesto-cli/internal"
+	Docs     = "https://github.com/Abraxas-365/manifesto/pkg/errx"
+)
+
+/* github.com/Abraxas-365/manifesto/pkg/errx in a block comment */
+var _ = fmt.Sprint(kernel.UserID(""), fsx.FS(nil))
+`
+
+func TestRewriteGoImportsOnly(t *testing.T) {
+	r := testRewriter(t, nil, false)
+	got := string(r.RewriteFile("pkg/errx/errx.go", []byte(fetchedGo)))
+
+	want := strings.NewReplacer(
+		`"github.com/Abraxas-365/manifesto/libs/fsx/pkg/fsx"`, `"example.com/shop/pkg/fsx"`,
+		`kernel "github.com/Abraxas-365/manifesto/pkg/kernel"`, `kernel "example.com/shop/pkg/kernel"`,
+	).Replace(fetchedGo)
+	if got != want {
+		t.Errorf("rewritten:\n%s\nwant only the imports changed:\n%s", got, want)
+	}
+}
+
+func TestRewriteGoStrings(t *testing.T) {
+	r := testRewriter(t, nil, true)
+	got := string(r.RewriteFile("pkg/errx/errx.go", []byte(fetchedGo)))
+
+	for _, want := range []string{
+		`"example.com/shop/pkg/fsx"`,
+		`kernel "example.com/shop/pkg/kernel"`,
+		`Upstream = "example.com/shop"`,
+		`Kernel   = "example.com/shop/pkg/kernel" // Named in a string`,
+		"Raw      = `example.com/shop/pkg/errx`",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rewritten file lacks %s", want)
+		}
+	}
+	// Comments, literals that aren't exactly a path, and ones with escapes
+	// keep the upstream path.
+	for _, line := range []string{
+		"// https://github.com/Abraxas-365/manifesto/pkg/errx and",
+		"// github.com/Abraxas-365/manifesto/pkg/kernel for the kernel.",
+		`Escaped  = "github.com/Abraxas-365/manifesto/pkg/errx\t"`,
+		`Mention  = "see github.com/Abraxas-365/manifesto/pkg/errx"`,
+		`CLI      = "github.com/Abraxas-365/manifesto-cli/internal"`,
+		`Docs     = "https://github.com/Abraxas-365/manifesto/pkg/errx"`,
+		"/* github.com/Abraxas-365/manifesto/pkg/errx in a block comment */",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("rewritten file changed %s", line)
+		}
+	}
+}
+
+// fetchedYAML mentions the module as a path, a moved path, in a URL, and
+// as the prefix of a longer module path.
+const fetchedYAML = `# Config for github.com/Abraxas-365/manifesto/pkg/config.
+package: github.com/Abraxas-365/manifesto/pkg/config
+storage: "github.com/Abraxas-365/manifesto/libs/fsx/pkg/fsx"
+docs: https://github.com/Abraxas-365/manifesto.
+cli: github.com/Abraxas-365/manifesto-cli
+`
+
+func TestRewriteOtherFiles(t *testing.T) {
+	for _, ext := range []string{"yaml", ".yaml", "YAML"} {
+		r := testRewriter(t, []string{"mod", ext}, false)
+		if got := string(r.RewriteFile("pkg/config/config.yml", []byte(fetchedYAML))); got != fetchedYAML {
+			t.Errorf("%s: rewrote a .yml file:\n%s", ext, got)
+		}
+
+		got := string(r.RewriteFile("pkg/config/config.yaml", []byte(fetchedYAML)))
+		want := `# Config for example.com/shop/pkg/config.
+package: example.com/shop/pkg/config
+storage: "example.com/shop/pkg/fsx"
+docs: https://example.com/shop.
+cli: github.com/Abraxas-365/manifesto-cli
+`
+		if got != want {
+			t.Errorf("%s: rewritten:\n%s\nwant:\n%s", ext, got, want)
+		}
+	}
+
+	// Without opting in, nothing but Go files is touched.
+	r := testRewriter(t, nil, true)
+	for _, name := range []string{"pkg/config/config.yaml", "pkg/config/testdata/go.mod", "README.md"} {
+		if got := string(r.RewriteFile(name, []byte(fetchedYAML))); got != fetchedYAML {
+			t.Errorf("rewrote %s by default:\n%s", name, got)
+		}
+	}
+}
+
+// TestFetchRewritesOnlyImports fetches a module with Go and other files
+// that mention the upstream module with the default settings.
+func TestFetchRewritesOnlyImports(t *testing.T) {
+	fake, c := newFakeGitHub(t)
+	files := map[string]string{
+		"pkg/errx/errx.go":             fetchedGo,
+		"pkg/errx/testdata/go.mod":     "module github.com/Abraxas-365/manifesto/pkg/errx/testdata\n",
+		"pkg/errx/testdata/paths.yaml": fetchedYAML,
+	}
+	var entries []tarEntry
+	for name, body := range files {
+		entries = append(entries, file("manifesto-v1.0.0/"+name, body))
+	}
+	fake.serve("github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz", tarball(t, entries...))
+
+	dest := t.TempDir()
+	if err := c.FetchModulePaths("v1.0.0", []PathMapping{{Src: "pkg/errx", Dest: "pkg/errx"}}, dest, upstream, "example.com/shop"); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range files {
+		data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(data)
+		if !strings.HasSuffix(name, ".go") {
+			if got != body {
+				t.Errorf("%s changed:\n%s", name, got)
+			}
+			continue
+		}
+		if !strings.Contains(got, `kernel "example.com/shop/pkg/kernel"`) {
+			t.Errorf("%s: import not rewritten", name)
+		}
+		for _, line := range []string{
+			"// https://github.com/Abraxas-365/manifesto/pkg/errx and",
+			`Kernel   = "github.com/Abraxas-365/manifesto/pkg/kernel" // Named in a string`,
+		} {
+			if !strings.Contains(got, line) {
+				t.Errorf("%s: changed %s", name, line)
+			}
+		}
+	}
+}
+
+// testRewriter returns the rewriter FetchModulePaths uses for upstream's
+// fsx module, moved to pkg/fsx, in the project example.com/shop.
+func testRewriter(t *testing.T, exts []string, rewriteStrings bool) *importRewriter {
+	t.Helper()
+	oldExts, oldStrings := RewriteExtensions, RewriteStrings
+	t.Cleanup(func() { RewriteExtensions, RewriteStrings = oldExts, oldStrings })
+	RewriteExtensions, RewriteStrings = exts, rewriteStrings
+	return newImportRewriter([]PathMapping{{Src: "libs/fsx/pkg/fsx", Dest: "pkg/fsx"}, {Src: "pkg/kernel", Dest: "pkg/kernel"}}, upstream, "example.com/shop")
+}
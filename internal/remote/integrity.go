@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// verifyArchive checks that data is a complete gzipped tarball, so a
+// truncated or corrupt download fails before anything is extracted.
+func verifyArchive(data []byte) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		_, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// The tar end marker can come before the gzip trailer; read on so the
+	// trailer's checksum is verified too.
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return err
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ArchiveChecksum returns the SHA-256 of the archive for ref, as last
+// downloaded or read from the cache by this client, or "" if the client
+// has neither.
+func (c *Client) ArchiveChecksum(ref string) string {
+	c.mu.Lock()
+	sum, ok := c.checksums[ref]
+	c.mu.Unlock()
+	if ok {
+		return sum
+	}
+	if data := c.cachedArchive(ref); data != nil {
+		return checksum(data)
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalPrefix starts a ref naming a manifesto checkout on this machine
+// rather than a version on GitHub, e.g. "local:/home/me/manifesto". It is
+// recorded as the version of modules copied with --local.
+const LocalPrefix = "local:"
+
+// LocalRef returns the ref for the manifesto checkout at dir, made
+// absolute. It fails unless dir holds a go.mod.
+func LocalRef(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(abs, "go.mod")); err != nil {
+		return "", fmt.Errorf("--local %s is not a manifesto checkout: it has no go.mod", dir)
+	}
+	return LocalPrefix + abs, nil
+}
+
+// LocalPath returns the checkout directory a LocalRef names, and false
+// for any other ref.
+func LocalPath(ref string) (string, bool) {
+	return strings.CutPrefix(ref, LocalPrefix)
+}
+
+// localArchive packs the checkout a local ref names into an archive laid
+// out like GitHub's, so it is filtered and rewritten as a download is.
+// The checkout changes as you work on it, so the archive is neither
+// cached nor checksummed.
+func localArchive(ref string) ([]byte, error) {
+	dir, _ := LocalPath(ref)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("local manifesto checkout %s no longer exists", dir)
+	}
+
+	var buf bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		// Links are left out; an archive from GitHub with one is refused.
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = "local/" + filepath.ToSlash(rel)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read local manifesto checkout %s: %w", dir, err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
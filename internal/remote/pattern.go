@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether patterns select the slash path p, as in
+// config.Module.Paths. They are evaluated in order and the last one to
+// match decides: one starting with ! leaves p out. A pattern matches a
+// path and everything under it; its segments match as in path.Match, and
+// a ** segment matches any number of them, including none. No patterns
+// select every path.
+func Match(p string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	selected := false
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		if matchPattern(strings.TrimPrefix(pattern, "!"), p) {
+			selected = !exclude
+		}
+	}
+	return selected
+}
+
+// matchPattern reports whether pattern matches p or one of the
+// directories it is in.
+func matchPattern(pattern, p string) bool {
+	segments := strings.Split(p, "/")
+	for n := len(segments); n > 0; n-- {
+		if matchSegments(strings.Split(pattern, "/"), segments[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,122 @@
+package remote
+
+import (
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{nil, "pkg/ai/ai.go", true},
+		{[]string{"pkg/ai"}, "pkg/ai", true},
+		{[]string{"pkg/ai"}, "pkg/ai/ai.go", true},
+		{[]string{"pkg/ai"}, "pkg/aix/aix.go", false},
+		{[]string{"pkg/ai", "!pkg/ai/testdata"}, "pkg/ai/testdata/golden.json", false},
+		{[]string{"pkg/ai", "!pkg/ai/testdata"}, "pkg/ai/testdata.go", true},
+		{[]string{"pkg/ai", "!**/*_test.go"}, "pkg/ai/llm/client_test.go", false},
+		{[]string{"pkg/ai", "!**/*_test.go"}, "pkg/ai/llm/client.go", true},
+		{[]string{"pkg/ai", "!**/testdata"}, "pkg/ai/llm/testdata/a/b.json", false},
+		{[]string{"pkg/iam", "!pkg/iam/examples", "pkg/iam/examples/README.md"}, "pkg/iam/examples/README.md", true},
+		{[]string{"pkg/iam", "!pkg/iam/examples", "pkg/iam/examples/README.md"}, "pkg/iam/examples/main.go", false},
+		{[]string{"pkg/iam/examples/README.md", "pkg/iam", "!pkg/iam/examples"}, "pkg/iam/examples/README.md", false},
+		{[]string{"pkg/iam/*/*.go"}, "pkg/iam/user/user.go", true},
+		{[]string{"pkg/iam/*/*.go"}, "pkg/iam/iam.go", false},
+		{[]string{"pkg/**/*.sql"}, "pkg/migrations/001.sql", true},
+		{[]string{"pkg/**/*.sql"}, "pkg/a/b/c/001.sql", true},
+		{[]string{"pkg/**/*.sql"}, "pkg/a/b/c/001.go", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.path, tt.patterns); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.path, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+// TestFetchPatterns fetches module layouts through Paths patterns, and
+// checks FetchModulePaths and ReadModulePaths take the same files.
+func TestFetchPatterns(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string // Under manifesto-v1.0.0/ in the archive
+		paths []PathMapping
+		want  []string
+	}{
+		{
+			name: "testdata left out",
+			files: []string{
+				"pkg/ai/ai.go",
+				"pkg/ai/ai_test.go",
+				"pkg/ai/testdata/prompt.txt",
+				"pkg/ai/llm/client.go",
+				"pkg/ai/llm/testdata/response.json",
+				"pkg/fsx/fsx.go",
+			},
+			paths: []PathMapping{{Src: "pkg/ai", Dest: "pkg/ai", Paths: []string{"pkg/ai", "!**/testdata"}}},
+			want:  []string{"pkg/ai/ai.go", "pkg/ai/ai_test.go", "pkg/ai/llm/client.go"},
+		},
+		{
+			name: "examples left out but one",
+			files: []string{
+				"pkg/iam/iam.go",
+				"pkg/iam/examples/README.md",
+				"pkg/iam/examples/basic/main.go",
+				"pkg/iam/user/user.go",
+			},
+			paths: []PathMapping{{Src: "pkg/iam", Dest: "pkg/iam", Paths: []string{"pkg/iam", "!pkg/iam/examples", "pkg/iam/examples/README.md"}}},
+			want:  []string{"pkg/iam/examples/README.md", "pkg/iam/iam.go", "pkg/iam/user/user.go"},
+		},
+		{
+			name: "glob in a moved path",
+			files: []string{
+				"libs/fsx/pkg/fsx/fsx.go",
+				"libs/fsx/pkg/fsx/fsx_test.go",
+				"libs/fsx/pkg/fsx/s3/s3.go",
+				"libs/fsx/pkg/fsx/s3/doc.md",
+			},
+			paths: []PathMapping{{Src: "libs/fsx/pkg/fsx", Dest: "pkg/fsx", Paths: []string{"pkg/fsx/**/*.go", "!**/*_test.go"}}},
+			want:  []string{"pkg/fsx/fsx.go", "pkg/fsx/s3/s3.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, c := newFakeGitHub(t)
+			var entries []tarEntry
+			for _, name := range tt.files {
+				entries = append(entries, file("manifesto-v1.0.0/"+name, "// "+name+"\n"))
+			}
+			fake.serve("github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz", tarball(t, entries...))
+
+			dest := t.TempDir()
+			if err := c.FetchModulePaths("v1.0.0", tt.paths, dest, "", ""); err != nil {
+				t.Fatal(err)
+			}
+			var fetched []string
+			filepath.WalkDir(dest, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(dest, path)
+					fetched = append(fetched, filepath.ToSlash(rel))
+				}
+				return err
+			})
+			slices.Sort(fetched)
+			if !slices.Equal(fetched, tt.want) {
+				t.Errorf("fetched %q, want %q", fetched, tt.want)
+			}
+
+			read, err := c.ReadModulePaths("v1.0.0", tt.paths, "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys := slices.Sorted(maps.Keys(read)); !slices.Equal(keys, tt.want) {
+				t.Errorf("read %q, want %q", keys, tt.want)
+			}
+		})
+	}
+}
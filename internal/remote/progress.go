@@ -0,0 +1,42 @@
+package remote
+
+import "io"
+
+// Progress is reported while an archive downloads and is extracted.
+type Progress struct {
+	Stage Stage
+	Bytes int64 // Downloaded so far
+	Total int64 // Archive size from Content-Length, or -1 when unknown
+	Files int   // Files written so far, during StageExtract
+}
+
+// Stage is the part of FetchModulePaths a Progress belongs to.
+type Stage int
+
+const (
+	StageDownload Stage = iota
+	StageExtract
+)
+
+// OnProgress makes the client report download and extraction progress to
+// fn. Cached archives skip straight to StageExtract.
+func (c *Client) OnProgress(fn func(Progress)) {
+	c.progress = fn
+}
+
+// countingReader reports the bytes read through it.
+type countingReader struct {
+	r     io.Reader
+	n     int64
+	total int64
+	fn    func(n, total int64)
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if n > 0 {
+		cr.n += int64(n)
+		cr.fn(cr.n, cr.total)
+	}
+	return n, err
+}
@@ -0,0 +1,110 @@
+package remote
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RefType says how a ref is resolved. The zero value guesses: a 7 to 40
+// character hex string is a commit, anything else a tag, then a branch.
+type RefType string
+
+const (
+	RefAuto   RefType = ""
+	RefTag    RefType = "tag"
+	RefBranch RefType = "branch"
+	RefCommit RefType = "commit"
+)
+
+// RefTypes lists the values accepted by ParseRefType.
+var RefTypes = []RefType{RefTag, RefBranch, RefCommit}
+
+var shaRe = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// ParseRefType parses a --force-ref-type value; "" means RefAuto.
+func ParseRefType(s string) (RefType, error) {
+	if s == "" {
+		return RefAuto, nil
+	}
+	for _, t := range RefTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return RefAuto, fmt.Errorf("unknown ref type %q (available: tag, branch, commit)", s)
+}
+
+// IsCommitSHA reports whether ref looks like an abbreviated or full commit SHA.
+func IsCommitSHA(ref string) bool {
+	return shaRe.MatchString(ref)
+}
+
+// ValidateRef checks that ref can be resolved as t.
+func ValidateRef(ref string, t RefType) error {
+	if _, ok := LocalPath(ref); ok {
+		return nil
+	}
+	if t == RefCommit && !IsCommitSHA(ref) {
+		return fmt.Errorf("ref %q is not a commit SHA (7 to 40 hex characters)", ref)
+	}
+	return nil
+}
+
+// ForceRefType makes the client resolve refs as t instead of guessing.
+func (c *Client) ForceRefType(t RefType) {
+	c.refType = t
+}
+
+// resolvedRefType returns how ref is resolved: the forced type, or commit
+// for a SHA-like ref and RefAuto otherwise.
+func (c *Client) resolvedRefType(ref string) RefType {
+	if c.refType != RefAuto {
+		return c.refType
+	}
+	if IsCommitSHA(ref) {
+		return RefCommit
+	}
+	return RefAuto
+}
+
+// qualifiedRef returns ref in the refs/tags/ or refs/heads/ form when its
+// type is forced, so a branch named like a tag resolves as a branch.
+func (c *Client) qualifiedRef(ref string) string {
+	switch c.resolvedRefType(ref) {
+	case RefTag:
+		return "refs/tags/" + ref
+	case RefBranch:
+		return "refs/heads/" + ref
+	}
+	return ref
+}
+
+// archiveURLs returns the unauthenticated archive URLs to try for ref, in
+// order.
+func (c *Client) archiveURLs(ref string) []string {
+	tag := fmt.Sprintf("https://github.com/%s/archive/refs/tags/%s.tar.gz", c.repo, ref)
+	branch := fmt.Sprintf("https://github.com/%s/archive/refs/heads/%s.tar.gz", c.repo, ref)
+
+	switch c.resolvedRefType(ref) {
+	case RefCommit:
+		return []string{fmt.Sprintf("https://codeload.github.com/%s/tar.gz/%s", c.repo, strings.ToLower(ref))}
+	case RefTag:
+		return []string{tag}
+	case RefBranch:
+		return []string{branch}
+	}
+	if ref == DefaultRef || ref == c.defaultBranch || ref == "" {
+		return []string{branch}
+	}
+	return []string{tag, branch}
+}
+
+// cacheKey names ref's archive in the cache. A forced branch is kept apart
+// from a tag of the same name.
+func (c *Client) cacheKey(ref string) string {
+	if c.refType == RefBranch {
+		return "heads/" + ref
+	}
+	return ref
+}
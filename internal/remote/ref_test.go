@@ -0,0 +1,166 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testSHA = "0123456789abcdef0123456789abcdef01234567"
+
+// fetchKernel fetches pkg/kernel at ref into a temporary project and
+// returns the ref its file says it came from.
+func fetchKernel(t *testing.T, c *Client, ref string) string {
+	t.Helper()
+	dest := t.TempDir()
+	paths := []PathMapping{{Src: "pkg/kernel", Dest: "pkg/kernel"}}
+	if err := c.FetchModulePaths(ref, paths, dest, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "pkg", "kernel", "ref.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, got, _ := strings.Cut(string(data), `const Ref = "`)
+	return strings.TrimSuffix(got, "\"\n")
+}
+
+func TestFetchRefTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      string
+		force    RefType
+		token    string
+		serve    map[string]string // Request key → ref of the archive served
+		requests []string
+	}{
+		{
+			name:     "tag",
+			ref:      "v1.0.0",
+			serve:    map[string]string{"github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz": "v1.0.0"},
+			requests: []string{"github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz"},
+		},
+		{
+			name:  "branch",
+			ref:   "dev",
+			serve: map[string]string{"github.com/acme/manifesto/archive/refs/heads/dev.tar.gz": "dev"},
+			requests: []string{
+				"github.com/acme/manifesto/archive/refs/tags/dev.tar.gz",
+				"github.com/acme/manifesto/archive/refs/heads/dev.tar.gz",
+			},
+		},
+		{
+			name:     "commit",
+			ref:      strings.ToUpper(testSHA),
+			serve:    map[string]string{"codeload.github.com/acme/manifesto/tar.gz/" + testSHA: testSHA},
+			requests: []string{"codeload.github.com/acme/manifesto/tar.gz/" + testSHA},
+		},
+		{
+			name:     "abbreviated commit",
+			ref:      testSHA[:7],
+			serve:    map[string]string{"codeload.github.com/acme/manifesto/tar.gz/" + testSHA[:7]: testSHA},
+			requests: []string{"codeload.github.com/acme/manifesto/tar.gz/" + testSHA[:7]},
+		},
+		{
+			name:  "branch named like a tag",
+			ref:   "v1.0.0",
+			force: RefBranch,
+			serve: map[string]string{
+				"github.com/acme/manifesto/archive/refs/tags/v1.0.0.tar.gz":  "v1.0.0",
+				"github.com/acme/manifesto/archive/refs/heads/v1.0.0.tar.gz": "branch v1.0.0",
+			},
+			requests: []string{"github.com/acme/manifesto/archive/refs/heads/v1.0.0.tar.gz"},
+		},
+		{
+			name:     "branch named like a commit",
+			ref:      "cafe123",
+			force:    RefBranch,
+			serve:    map[string]string{"github.com/acme/manifesto/archive/refs/heads/cafe123.tar.gz": "cafe123"},
+			requests: []string{"github.com/acme/manifesto/archive/refs/heads/cafe123.tar.gz"},
+		},
+		{
+			name:     "commit with a token",
+			ref:      testSHA,
+			token:    "secret",
+			serve:    map[string]string{"api.github.com/repos/acme/manifesto/tarball/" + testSHA: testSHA},
+			requests: []string{"api.github.com/repos/acme/manifesto/tarball/" + testSHA},
+		},
+		{
+			name:     "forced tag with a token",
+			ref:      "v1.0.0",
+			force:    RefTag,
+			token:    "secret",
+			serve:    map[string]string{"api.github.com/repos/acme/manifesto/tarball/refs/tags/v1.0.0": "v1.0.0"},
+			requests: []string{"api.github.com/repos/acme/manifesto/tarball/refs/tags/v1.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, c := newFakeGitHub(t)
+			c.token = tt.token
+			c.ForceRefType(tt.force)
+			for key, ref := range tt.serve {
+				fake.serve(key, moduleArchive(t, ref))
+			}
+
+			want := tt.serve[tt.requests[len(tt.requests)-1]]
+			if got := fetchKernel(t, c, tt.ref); got != want {
+				t.Errorf("fetched %s's archive, want %s's", got, want)
+			}
+			if got := fake.requested(); !slices.Equal(got, tt.requests) {
+				t.Errorf("requested %q, want %q", got, tt.requests)
+			}
+			if c.ArchiveChecksum(tt.ref) == "" {
+				t.Error("no checksum recorded for the archive")
+			}
+		})
+	}
+}
+
+// TestFetchGoModRefTypes checks go.mod is read from the raw URL of the
+// ref as given, qualified when its type is forced.
+func TestFetchGoModRefTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		ref   string
+		force RefType
+		token string
+		key   string
+	}{
+		{"tag", "v1.0.0", RefAuto, "", "raw.githubusercontent.com/acme/manifesto/v1.0.0/go.mod"},
+		{"commit", testSHA, RefAuto, "", "raw.githubusercontent.com/acme/manifesto/" + testSHA + "/go.mod"},
+		{"forced branch", "v1.0.0", RefBranch, "", "raw.githubusercontent.com/acme/manifesto/refs/heads/v1.0.0/go.mod"},
+		{"commit with a token", testSHA, RefAuto, "secret", "api.github.com/repos/acme/manifesto/contents/go.mod?ref=" + testSHA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, c := newFakeGitHub(t)
+			c.token = tt.token
+			c.ForceRefType(tt.force)
+			fake.serve(tt.key, []byte("module github.com/Abraxas-365/manifesto\n"))
+
+			goMod, err := c.FetchGoMod(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(goMod, "module ") {
+				t.Errorf("got go.mod %q", goMod)
+			}
+		})
+	}
+}
+
+// TestFetchMissingRef checks a ref that is neither a tag nor a branch
+// fails once both have been tried.
+func TestFetchMissingRef(t *testing.T) {
+	fake, c := newFakeGitHub(t)
+	err := c.FetchModulePaths("nope", []PathMapping{{Src: "pkg/kernel", Dest: "pkg/kernel"}}, t.TempDir(), "", "")
+	if err == nil {
+		t.Fatal("fetching a missing ref succeeded")
+	}
+	if got := fake.requested(); len(got) != 2 {
+		t.Errorf("requested %q, want the tag and branch archives", got)
+	}
+}
@@ -0,0 +1,77 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Requests are retried on transport errors, 5xx and 429, waiting
+// retryBackoff, then twice that, between attempts. The client's timeout
+// applies to each attempt.
+const (
+	maxAttempts  = 3
+	retryBackoff = 500 * time.Millisecond
+)
+
+// fetch GETs url and reads the whole body, retrying transient failures
+// (including a body cut off mid-download). Other statuses are returned
+// with their body for the caller to handle. what names the resource in
+// errors, e.g. "go.mod at v1.2.0". onRead, if set, is called as a 200
+// response's body is read, with the total from Content-Length or -1.
+func (c *Client) fetch(url, accept, what string, onRead func(n, total int64)) (*http.Response, []byte, error) {
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(retryBackoff << (attempt - 2))
+		}
+
+		resp, body, err := c.fetchOnce(url, accept, onRead)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			lastErr = c.statusError(resp, what)
+			continue
+		}
+		return resp, body, nil
+	}
+	return nil, nil, fmt.Errorf("%w (gave up after %d attempts)", lastErr, maxAttempts)
+}
+
+// fetchOnce makes a single attempt, with the client's token if any. Go
+// drops the token when GitHub redirects to another host, such as
+// codeload.github.com.
+func (c *Client) fetchOnce(url, accept string, onRead func(n, total int64)) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	var r io.Reader = resp.Body
+	if onRead != nil && resp.StatusCode == http.StatusOK {
+		r = &countingReader{r: resp.Body, total: resp.ContentLength, fn: onRead}
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", url, err)
+	}
+	if resp.ContentLength >= 0 && int64(len(body)) != resp.ContentLength {
+		return nil, nil, fmt.Errorf("read %s: got %d of %d bytes", url, len(body), resp.ContentLength)
+	}
+	return resp, body, nil
+}
@@ -0,0 +1,197 @@
+package remote
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Ref is a ref of the manifesto repo that can be passed to --ref.
+type Ref struct {
+	Name    string  `json:"name"`
+	Type    RefType `json:"type"`              // RefTag or RefBranch
+	Release string  `json:"release,omitempty"` // Title of the tag's release
+	Latest  bool    `json:"latest,omitempty"`  // The latest stable release
+}
+
+// NotableBranches are the branches ListRefs includes when the repo has them.
+var NotableBranches = []string{DefaultRef, "quick-project"}
+
+// refsTTL is how long ListRefs reuses a cached listing before asking GitHub
+// again. Unauthenticated clients get 60 API requests an hour.
+const refsTTL = time.Hour
+
+// refsCache is the listing ListRefs caches next to the archives.
+type refsCache struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Refs      []Ref     `json:"refs"`
+}
+
+// ListRefs returns the repo's tags, semver first and newest first, followed
+// by its default branch and NotableBranches. The listing is cached for
+// refsTTL; --refresh skips the cache and --offline only uses it. When
+// GitHub can't be reached, a stale cached listing is returned instead.
+func (c *Client) ListRefs() ([]Ref, error) {
+	cached, ok := c.cachedRefs()
+	if c.offline {
+		if !ok {
+			return nil, fmt.Errorf("the refs of %s are %w; run once without --offline to list them", c.repo, ErrNotCached)
+		}
+		return cached.Refs, nil
+	}
+	if ok && !c.refresh && time.Since(cached.FetchedAt) < refsTTL {
+		return cached.Refs, nil
+	}
+
+	refs, err := c.fetchRefs()
+	if err != nil {
+		if ok {
+			return cached.Refs, nil
+		}
+		return nil, err
+	}
+	c.storeRefs(refs)
+	return refs, nil
+}
+
+// namedRef is a tag or branch in a GitHub API listing.
+type namedRef struct {
+	Name string `json:"name"`
+}
+
+func (c *Client) fetchRefs() ([]Ref, error) {
+	var tags []namedRef
+	if err := c.getJSON("/tags?per_page=100", "tags of "+c.repo, &tags); err != nil {
+		return nil, err
+	}
+	var releases []Release
+	if err := c.getJSON("/releases?per_page=100", "releases of "+c.repo, &releases); err != nil {
+		return nil, err
+	}
+	var branches []namedRef
+	if err := c.getJSON("/branches?per_page=100", "branches of "+c.repo, &branches); err != nil {
+		return nil, err
+	}
+
+	// Releases are listed newest first; the first stable one is the latest.
+	titles := make(map[string]string)
+	latest := ""
+	for _, r := range releases {
+		if r.Draft {
+			continue
+		}
+		titles[r.TagName] = r.Name
+		if latest == "" && !r.Prerelease {
+			latest = r.TagName
+		}
+	}
+
+	var refs []Ref
+	for _, t := range tags {
+		refs = append(refs, Ref{Name: t.Name, Type: RefTag, Release: titles[t.Name], Latest: t.Name == latest})
+	}
+	slices.SortFunc(refs, func(a, b Ref) int { return compareTags(b.Name, a.Name) })
+
+	notable := NotableBranches
+	if b := c.DefaultBranch(); !slices.Contains(notable, b) {
+		notable = append([]string{b}, notable...)
+	}
+	for _, name := range notable {
+		if slices.Contains(branches, namedRef{Name: name}) {
+			refs = append(refs, Ref{Name: name, Type: RefBranch})
+		}
+	}
+	return refs, nil
+}
+
+// getJSON decodes the GitHub API response for the repo path into out.
+func (c *Client) getJSON(path, what string, out any) error {
+	url := fmt.Sprintf("%s/repos/%s%s", GitHubAPI, c.repo, path)
+	resp, body, err := c.fetch(url, "application/vnd.github+json", what, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return c.statusError(resp, what)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s: decode response: %w", what, err)
+	}
+	return nil
+}
+
+// refsPath returns where the ref listing is cached, or "" without a cache.
+func (c *Client) refsPath() string {
+	if c.cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.cacheDir, "refs.json")
+}
+
+func (c *Client) cachedRefs() (refsCache, bool) {
+	var cached refsCache
+	path := c.refsPath()
+	if path == "" {
+		return cached, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &cached) != nil {
+		return cached, false
+	}
+	return cached, true
+}
+
+// storeRefs caches refs. Like storeArchive, a failure isn't reported.
+func (c *Client) storeRefs(refs []Ref) {
+	path := c.refsPath()
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(refsCache{FetchedAt: time.Now(), Refs: refs})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
+
+var semverRe = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?$`)
+
+// compareTags orders semver tags by version, a pre-release before its
+// release, and after every tag that isn't semver; those sort by name.
+func compareTags(a, b string) int {
+	ma, mb := semverRe.FindStringSubmatch(a), semverRe.FindStringSubmatch(b)
+	switch {
+	case ma == nil && mb == nil:
+		return cmp.Compare(a, b)
+	case ma == nil:
+		return -1
+	case mb == nil:
+		return 1
+	}
+	for i := 1; i <= 3; i++ {
+		na, _ := strconv.Atoi(ma[i])
+		nb, _ := strconv.Atoi(mb[i])
+		if n := cmp.Compare(na, nb); n != 0 {
+			return n
+		}
+	}
+	switch {
+	case ma[4] == mb[4]:
+		return 0
+	case ma[4] == "":
+		return 1
+	case mb[4] == "":
+		return -1
+	}
+	return cmp.Compare(ma[4], mb[4])
+}
@@ -0,0 +1,82 @@
+package remote
+
+import (
+	"slices"
+	"testing"
+)
+
+const apiRepo = "api.github.com/repos/acme/manifesto"
+
+// TestTagsWithoutReleases lists and picks the latest version of a repo with
+// tags but no GitHub releases, as many forks are.
+func TestTagsWithoutReleases(t *testing.T) {
+	fake, c := newFakeGitHub(t)
+	fake.serve(apiRepo+"/tags?per_page=100", []byte(`[{"name":"v1.2.0"},{"name":"nightly"},{"name":"v1.10.0"},{"name":"v2.0.0-rc.1"},{"name":"v1.9.3"}]`))
+	fake.serve(apiRepo+"/releases?per_page=100", []byte(`[]`))
+	fake.serve(apiRepo+"/branches?per_page=100", []byte(`[{"name":"develop"},{"name":"quick-project"},{"name":"feature/x"}]`))
+	fake.serve(apiRepo, []byte(`{"default_branch":"develop"}`))
+
+	latest, err := c.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "v1.10.0" {
+		t.Errorf("GetLatestVersion = %q, want the highest stable tag v1.10.0", latest)
+	}
+
+	refs, err := c.ListRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Ref{
+		{Name: "v2.0.0-rc.1", Type: RefTag},
+		{Name: "v1.10.0", Type: RefTag},
+		{Name: "v1.9.3", Type: RefTag},
+		{Name: "v1.2.0", Type: RefTag},
+		{Name: "nightly", Type: RefTag},
+		{Name: "develop", Type: RefBranch},
+		{Name: "quick-project", Type: RefBranch},
+	}
+	if !slices.Equal(refs, want) {
+		t.Errorf("ListRefs = %+v, want %+v", refs, want)
+	}
+}
+
+// TestDefaultRefPrecedence picks the version of a repo with neither
+// releases nor tags, where the default branch decides: MANIFESTO_DEFAULT_REF
+// over default_ref in the user config, over the branch GitHub reports.
+func TestDefaultRefPrecedence(t *testing.T) {
+	tests := []struct {
+		name, env, configured, want string
+	}{
+		{name: "env over user config", env: "next", configured: "develop", want: "next"},
+		{name: "user config", configured: "develop", want: "develop"},
+		{name: "GitHub", want: "trunk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, c := newFakeGitHub(t)
+			fake.serve(apiRepo+"/tags?per_page=100", []byte(`[]`))
+			fake.serve(apiRepo, []byte(`{"default_branch":"trunk"}`))
+
+			t.Setenv(DefaultRefEnv, tt.env)
+			old := ConfiguredDefaultRef
+			t.Cleanup(func() { ConfiguredDefaultRef = old })
+			ConfiguredDefaultRef = tt.configured
+			// The default ref is resolved when the client is made.
+			fresh := NewClient("acme/manifesto")
+			fresh.httpClient, fresh.cacheDir = c.httpClient, c.cacheDir
+
+			got, err := fresh.GetLatestVersion()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GetLatestVersion = %q, want %q", got, tt.want)
+			}
+			if asked := slices.Contains(fake.requested(), apiRepo); asked != (tt.want == "trunk") {
+				t.Errorf("asked GitHub for the default branch = %v with %s", asked, tt.name)
+			}
+		})
+	}
+}
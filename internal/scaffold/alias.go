@@ -0,0 +1,132 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ResolveContainerNames picks the import alias and field name the domain
+// gets in cmd/container.go. See resolveContainerNames.
+func ResolveContainerNames(projectRoot string, data DomainData) DomainData {
+	return resolveContainerNames(diskStore{}, projectRoot, data)
+}
+
+// resolveContainerNames keeps the default names (invoicecontainer, Invoice)
+// unless cmd/container.go already uses them for something else. On a
+// collision both are qualified with parent directories, nearest first, so
+// pkg/billing/invoice becomes billinginvoicecontainer / BillingInvoice.
+//
+// Names that differ from the defaults were chosen earlier (and recorded in
+// the manifest) and are returned untouched, as is data when the domain is
+// already injected or the file can't be parsed.
+func resolveContainerNames(fs FileStore, projectRoot string, data DomainData) DomainData {
+	if data.ContainerAlias != data.defaultContainerAlias() || data.ContainerField != data.EntityName {
+		return data
+	}
+
+	content, err := fs.ReadFile(filepath.Join(projectRoot, "cmd", "container.go"))
+	if err != nil {
+		return data
+	}
+
+	containerImport := strconv.Quote(data.GoModule + "/" + data.ContainerPath)
+	if strings.Contains(string(content), containerImport) {
+		return data
+	}
+
+	imports, fields, err := containerNames(content)
+	if err != nil {
+		return data
+	}
+
+	alias, field := data.ContainerAlias, data.EntityName
+	parents := strings.Split(data.DomainPath, "/")
+	parents = parents[:len(parents)-1]
+	for i := len(parents) - 1; imports[alias] || fields[field]; i-- {
+		if i < 0 {
+			// Every parent is exhausted; fall back to a numeric suffix.
+			for n := 2; ; n++ {
+				a, f := fmt.Sprintf("%s%d", alias, n), fmt.Sprintf("%s%d", field, n)
+				if !imports[a] && !fields[f] {
+					alias, field = a, f
+					break
+				}
+			}
+			break
+		}
+		qualifier := identWord(parents[i])
+		alias = strings.ToLower(qualifier) + alias
+		field = toGoName(parents[i]) + field
+	}
+
+	data.ContainerAlias = alias
+	data.ContainerField = field
+	return data
+}
+
+// containerNames returns the package names imported by a root container
+// file and the field names of its Container struct.
+func containerNames(src []byte) (imports, fields map[string]bool, err error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "container.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	imports = make(map[string]bool)
+	for _, spec := range f.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			imports[spec.Name.Name] = true
+		} else {
+			imports[importName(p)] = true
+		}
+	}
+
+	fields = make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != "Container" {
+			return true
+		}
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			for _, fld := range st.Fields.List {
+				for _, name := range fld.Names {
+					fields[name.Name] = true
+				}
+			}
+		}
+		return false
+	})
+	return imports, fields, nil
+}
+
+// importName guesses the package name of an unaliased import path:
+// github.com/gofiber/fiber/v2 → fiber, gopkg.in/yaml.v3 → yaml,
+// github.com/redis/go-redis/v9 → redis.
+func importName(p string) string {
+	base := path.Base(p)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(p))
+	}
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		base = base[:i]
+	}
+	return strings.TrimPrefix(base, "go-")
+}
+
+// identWord strips characters that can't appear in a Go identifier.
+func identWord(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,116 @@
+package scaffold
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// RepoBackend describes a repository implementation a domain can be
+// generated with. Adding a backend means adding a template and an entry
+// here; GenerateDomain picks it up through domainFiles.
+type RepoBackend struct {
+	Name        string
+	Description string
+	Template    string // Template under domain/, e.g. "domain/postgres.go.tmpl"
+	File        string // File name inside <pkg>infra/
+	Constructor string // Infra constructor prefix: New<Constructor><Entity>Repository
+
+	// Dependency the domain container needs. Empty DepsField means the
+	// repository is self-contained and Deps only carries optional fields.
+	DepsImport string // e.g. "github.com/jmoiron/sqlx"
+	DepsField  string // e.g. "DB *sqlx.DB"
+
+	// RootDeps is the Deps literal injected into cmd/container.go. It is
+	// commented out with a TODO when the root Container lacks RootField.
+	RootDeps  string // e.g. "DB: c.DB,"
+	RootField string // e.g. "DB"
+
+	TagKey    string   // Struct tag key for storage column names ("db", "bson")
+	Migration bool     // Suggest a SQL migration after scaffolding
+	GoDeps    []string // Modules to go get after scaffolding
+	NextStep  string   // Extra setup hint shown after scaffolding
+}
+
+// RepoBackendRegistry holds every backend accepted by --repo.
+var RepoBackendRegistry = map[string]RepoBackend{
+	"postgres": {
+		Name:        "postgres",
+		Description: "Postgres repository",
+		Template:    "domain/postgres.go.tmpl",
+		File:        "postgres.go",
+		Constructor: "Postgres",
+		DepsImport:  "github.com/jmoiron/sqlx",
+		DepsField:   "DB *sqlx.DB",
+		RootDeps:    "DB: c.DB,",
+		RootField:   "DB",
+		TagKey:      "db",
+		Migration:   true,
+	},
+	"memory": {
+		Name:        "memory",
+		Description: "In-memory repository (prototyping and tests)",
+		Template:    "domain/memory.go.tmpl",
+		File:        "memory.go",
+		Constructor: "InMemory",
+		TagKey:      "db",
+	},
+	"mongo": {
+		Name:        "mongo",
+		Description: "MongoDB repository",
+		Template:    "domain/mongo.go.tmpl",
+		File:        "mongo.go",
+		Constructor: "Mongo",
+		DepsImport:  "go.mongodb.org/mongo-driver/v2/mongo",
+		DepsField:   "DB *mongo.Database",
+		RootDeps:    "DB: c.Mongo,",
+		RootField:   "Mongo",
+		TagKey:      "bson",
+		GoDeps:      []string{"go.mongodb.org/mongo-driver/v2"},
+		NextStep:    "Connect to MongoDB and pass the *mongo.Database as Deps.DB in cmd/container.go",
+	},
+}
+
+// DefaultRepoBackend is used when --repo is not given.
+const DefaultRepoBackend = "postgres"
+
+// ProjectRepoBackend returns the backend --repo defaults to in the project:
+// memory when it has no database to store domains in, DefaultRepoBackend
+// otherwise.
+func ProjectRepoBackend(m *config.Manifest) string {
+	if !m.HasDatabase() {
+		return "memory"
+	}
+	return DefaultRepoBackend
+}
+
+// RepoBackendNames returns the registered backend names, sorted.
+func RepoBackendNames() []string {
+	names := make([]string, 0, len(RepoBackendRegistry))
+	for name := range RepoBackendRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupRepoBackend returns the backend registered under name.
+func LookupRepoBackend(name string) (RepoBackend, error) {
+	b, ok := RepoBackendRegistry[name]
+	if !ok {
+		return RepoBackend{}, fmt.Errorf("unknown repository backend %q (supported: %s)",
+			name, strings.Join(RepoBackendNames(), ", "))
+	}
+	return b, nil
+}
+
+// StorageTag returns the struct tag that maps a field to column in the
+// selected backend, e.g. `db:"tenant_id"` or `bson:"_id"`.
+func (d DomainData) StorageTag(column string) string {
+	if d.Repo.TagKey == "bson" && column == "id" {
+		column = "_id"
+	}
+	return fmt.Sprintf("%s:%q", d.Repo.TagKey, column)
+}
@@ -0,0 +1,66 @@
+package scaffold
+
+import (
+	"fmt"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+)
+
+// ProjectChannel returns the project's ref channel. Projects that predate
+// channels are pinned, unless their ref is a branch.
+func ProjectChannel(manifest *config.Manifest) remote.Channel {
+	if manifest.Project.Channel != "" {
+		return remote.Channel(manifest.Project.Channel)
+	}
+	if remote.IsBranch(manifest.Project.Version, remote.RefType(manifest.Project.RefType)) {
+		return remote.ChannelBranch
+	}
+	return remote.ChannelPinned
+}
+
+// ChannelRef returns the ref a download uses when no --ref is given: the
+// latest release for a stable project, the recorded ref otherwise. Falling
+// back from the latest release, or fetching a branch, is warned about.
+func ChannelRef(manifest *config.Manifest, client *remote.Client) string {
+	ref := manifest.Project.Version
+	switch ProjectChannel(manifest) {
+	case remote.ChannelStable:
+		latest, err := client.GetLatestVersion()
+		if err == nil && latest != "" && latest != client.DefaultBranch() {
+			return latest
+		}
+		if ref == "" {
+			ref = client.DefaultBranch()
+		}
+		ui.StepWarn(fmt.Sprintf("Couldn't find the latest release; using manifesto@%s", ref))
+		return ref
+	case remote.ChannelBranch:
+		if ref == "" {
+			ref = client.DefaultBranch()
+		}
+		warnBranch(client, ref)
+		return ref
+	}
+
+	if ref == "" {
+		latest, err := client.GetLatestVersion()
+		if err != nil || latest == "" || latest == client.DefaultBranch() {
+			latest = client.DefaultBranch()
+			ui.StepWarn(fmt.Sprintf("Couldn't find the latest release; using the %s branch", latest))
+		}
+		ref = latest
+	}
+	return ref
+}
+
+// warnBranch warns that downloading branch gets whatever it points at now,
+// naming the commit when GitHub can tell.
+func warnBranch(client *remote.Client, branch string) {
+	at := ""
+	if sha, err := client.BranchCommit(branch); err == nil && len(sha) >= 12 {
+		at = fmt.Sprintf(" (now at %s)", sha[:12])
+	}
+	ui.StepWarn(fmt.Sprintf("This project tracks the %s branch%s; downloads may differ from run to run. Pass --ref with a tag or commit to pin one", branch, at))
+}
@@ -0,0 +1,220 @@
+package scaffold
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// ContextSchemaVersion is bumped whenever a DomainContext field is renamed
+// or removed. Adding fields doesn't change it.
+const ContextSchemaVersion = 1
+
+// DomainContext is the resolved template data for a domain in a stable,
+// JSON-friendly shape for external generators.
+type DomainContext struct {
+	SchemaVersion int            `json:"schema_version"`
+	Tracked       bool           `json:"tracked"` // Domain is recorded in manifesto.yaml
+	Project       ProjectContext `json:"project"`
+	Domain        DomainInfo     `json:"domain"`
+
+	// FollowUps are the steps left after scaffolding the domain. Context
+	// leaves it empty; CheckFollowUps fills it in against a project.
+	FollowUps []ChecklistItem `json:"follow_ups"`
+}
+
+type ProjectContext struct {
+	GoModule    string `json:"go_module"`
+	ProjectName string `json:"project_name"`
+}
+
+type DomainInfo struct {
+	Path         string           `json:"path"`
+	Package      string           `json:"package"`
+	Entity       string           `json:"entity"`
+	Names        Casings          `json:"names"`
+	Plural       Casings          `json:"plural"`
+	Table        string           `json:"table"`
+	RegistryCode string           `json:"registry_code"`
+	KernelID     string           `json:"kernel_id"`
+	RoutePrefix  string           `json:"route_prefix"`
+	Naming       string           `json:"naming"`
+	Packages     DomainPackages   `json:"packages"`
+	Dirs         DomainPackages   `json:"dirs"` // Relative to Path; "" is Path itself
+	Container    ContainerContext `json:"container"`
+	Repo         string           `json:"repo"`
+	Kind         string           `json:"kind"`
+	Transport    string           `json:"transport"`
+	Options      DomainOptions    `json:"options"`
+	Fields       []FieldContext   `json:"fields"`
+}
+
+// Casings holds one name in every casing the templates use.
+type Casings struct {
+	Pascal     string `json:"pascal"`
+	Camel      string `json:"camel"`
+	Snake      string `json:"snake"`
+	Kebab      string `json:"kebab"`
+	UpperSnake string `json:"upper_snake"`
+}
+
+type DomainPackages struct {
+	Domain    string `json:"domain"`
+	Service   string `json:"service"`
+	Infra     string `json:"infra"`
+	API       string `json:"api"`
+	GRPC      string `json:"grpc"`
+	Container string `json:"container"`
+}
+
+type ContainerContext struct {
+	ImportPath string `json:"import_path"`
+	Alias      string `json:"alias"`
+	Field      string `json:"field"`
+}
+
+type DomainOptions struct {
+	TenantScoped bool     `json:"tenant_scoped"`
+	WithPolicy   bool     `json:"with_policy"`
+	HasIAM       bool     `json:"has_iam"`
+	WithEvents   bool     `json:"with_events"`
+	HasJobx      bool     `json:"has_jobx"`
+	WithUploads  bool     `json:"with_uploads"`
+	HasSwagger   bool     `json:"has_swagger"`
+	WithTests    bool     `json:"with_tests"`
+	WithMocks    bool     `json:"with_mocks"`
+	Public       bool     `json:"public"`
+	RouteGroup   string   `json:"route_group"`
+	Layers       []string `json:"layers"`
+	Deps         []string `json:"deps"`
+}
+
+type FieldContext struct {
+	Name    string  `json:"name"`
+	Type    string  `json:"type"`
+	GoName  string  `json:"go_name"`
+	GoType  string  `json:"go_type"`
+	SQLType string  `json:"sql_type"`
+	JSONTag string  `json:"json_tag"`
+	Names   Casings `json:"names"`
+}
+
+// Context resolves data into a DomainContext.
+func (d DomainData) Context(projectName string, tracked bool) DomainContext {
+	fields := make([]FieldContext, 0, len(d.Fields))
+	for _, f := range d.Fields {
+		fields = append(fields, FieldContext{
+			Name:    f.Name,
+			Type:    f.Type,
+			GoName:  f.GoName,
+			GoType:  f.GoType,
+			SQLType: f.SQLType,
+			JSONTag: f.JSONTag,
+			Names:   casings(f.Name),
+		})
+	}
+
+	return DomainContext{
+		SchemaVersion: ContextSchemaVersion,
+		Tracked:       tracked,
+		Project: ProjectContext{
+			GoModule:    d.GoModule,
+			ProjectName: projectName,
+		},
+		Domain: DomainInfo{
+			Path:         d.DomainPath,
+			Package:      d.PackageName,
+			Entity:       d.EntityName,
+			Names:        casings(d.PackageName),
+			Plural:       casings(d.TableName),
+			Table:        d.TableName,
+			RegistryCode: d.RegistryCode,
+			KernelID:     d.EntityName + "ID",
+			RoutePrefix:  "/api/v1/" + d.TableName,
+			Naming:       d.Naming,
+			Packages: DomainPackages{
+				Domain:    d.PackageName,
+				Service:   d.Pkg(layerService),
+				Infra:     d.Pkg(layerInfra),
+				API:       d.Pkg(layerAPI),
+				GRPC:      d.Pkg(layerGRPC),
+				Container: d.ContainerPkg,
+			},
+			Dirs: DomainPackages{
+				Service:   d.Layout.Service,
+				Infra:     d.Layout.Infra,
+				API:       d.Layout.API,
+				GRPC:      d.Layout.GRPC,
+				Container: d.Layout.Container,
+			},
+			Container: ContainerContext{
+				ImportPath: d.GoModule + "/" + d.ContainerPath,
+				Alias:      d.ContainerAlias,
+				Field:      d.ContainerField,
+			},
+			Repo:      d.Repo.Name,
+			Kind:      d.Kind,
+			Transport: d.Transport,
+			Options: DomainOptions{
+				TenantScoped: d.TenantScoped,
+				WithPolicy:   d.WithPolicy,
+				HasIAM:       d.HasIAM,
+				WithEvents:   d.WithEvents,
+				HasJobx:      d.HasJobx,
+				WithUploads:  d.WithUploads,
+				HasSwagger:   d.HasSwagger,
+				WithTests:    d.WithTests,
+				WithMocks:    d.WithMocks,
+				Public:       d.Public,
+				RouteGroup:   d.RouteGroup(),
+				Layers:       d.SelectedLayers(),
+				Deps:         d.DepsSpec(),
+			},
+			Fields: fields,
+		},
+		FollowUps: []ChecklistItem{},
+	}
+}
+
+// casings returns s in every casing. Pascal and Camel are Go identifiers,
+// so they upper-case initialisms past the first word: user_id is UserID
+// and userID.
+func casings(s string) Casings {
+	words := splitWords(s)
+	pascal := toGoName(s)
+	camel := ""
+	if len(words) > 0 {
+		camel = words[0] + strings.TrimPrefix(pascal, toGoName(words[0]))
+	}
+	return Casings{
+		Pascal:     pascal,
+		Camel:      camel,
+		Snake:      strings.Join(words, "_"),
+		Kebab:      strings.Join(words, "-"),
+		UpperSnake: toUpperSnake(s),
+	}
+}
+
+// TemplateFuncs are the helpers available to user templates rendered with
+// ExecuteUserTemplate, and to the scaffolding templates and their
+// overrides.
+var TemplateFuncs = template.FuncMap{
+	"pascal":     toPascalCase,
+	"camel":      func(s string) string { return casings(s).Camel },
+	"snake":      func(s string) string { return casings(s).Snake },
+	"kebab":      func(s string) string { return casings(s).Kebab },
+	"upperSnake": toUpperSnake,
+	"plural":     toPlural,
+	"goName":     toGoName,
+	"title":      toTitle,
+	"lower":      strings.ToLower,
+}
+
+// ExecuteUserTemplate renders a user-supplied template against ctx.
+func ExecuteUserTemplate(w io.Writer, name, text string, ctx DomainContext) error {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, ctx)
+}
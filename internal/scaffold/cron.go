@@ -0,0 +1,98 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CronJobsDir holds the jobs `manifesto add cron` generates, one file per
+// job, relative to the project root.
+const CronJobsDir = "pkg/cronjobs"
+
+// DefaultCronSchedule runs a generated job every day at midnight.
+const DefaultCronSchedule = "0 0 * * *"
+
+var cronJobNameRe = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// CronJobData is the template context for a cron job.
+type CronJobData struct {
+	GoModule string
+	Name     string // As given, e.g. nightly-report
+	TypeName string // e.g. NightlyReport
+	Schedule string // Cron expression
+}
+
+// NewCronJobData returns the data for the job name, or an error when name
+// isn't lowercase words joined by dashes.
+func NewCronJobData(goModule, name, schedule string) (CronJobData, error) {
+	if !cronJobNameRe.MatchString(name) {
+		return CronJobData{}, fmt.Errorf("invalid cron job name %q: use lowercase words joined by -, like nightly-report", name)
+	}
+	if strings.TrimSpace(schedule) == "" {
+		return CronJobData{}, fmt.Errorf("cron job %s needs a schedule", name)
+	}
+	return CronJobData{
+		GoModule: goModule,
+		Name:     name,
+		TypeName: toGoName(name),
+		Schedule: schedule,
+	}, nil
+}
+
+// File is the job's file, relative to the project root.
+func (d CronJobData) File() string {
+	return path.Join(CronJobsDir, strings.ReplaceAll(d.Name, "-", "_")+".go")
+}
+
+// GenerateCronJob writes the job's file and registers it at the
+// "// manifesto:cron-jobs" marker the cronx module adds to cmd/container.go.
+func GenerateCronJob(projectRoot string, data CronJobData) error {
+	return generateCronJob(diskStore{}, projectRoot, data)
+}
+
+// PreviewCronJob runs GenerateCronJob against an in-memory Preview.
+func PreviewCronJob(projectRoot string, data CronJobData) (*Preview, error) {
+	preview := NewPreview(projectRoot)
+	err := generateCronJob(preview, projectRoot, data)
+	return preview, err
+}
+
+func generateCronJob(fs FileStore, projectRoot string, data CronJobData) error {
+	dest := filepath.Join(projectRoot, filepath.FromSlash(data.File()))
+	if _, err := fs.ReadFile(dest); err == nil {
+		return fmt.Errorf("%s already exists", data.File())
+	}
+	if err := renderTemplate(fs, projectRoot, "project/cron_job.go.tmpl", dest, data); err != nil {
+		return fmt.Errorf("render %s: %w", data.File(), err)
+	}
+	return injectCronJob(fs, projectRoot, data)
+}
+
+// injectCronJob imports the jobs package into cmd/container.go, once, and
+// registers the job with its schedule.
+func injectCronJob(fs FileStore, projectRoot string, data CronJobData) error {
+	containerFile := filepath.Join(projectRoot, "cmd", "container.go")
+
+	content, err := fs.ReadFile(containerFile)
+	if err != nil {
+		return fmt.Errorf("read cmd/container.go: %w", err)
+	}
+
+	text := string(content)
+
+	importSpec := strconv.Quote(data.GoModule + "/" + CronJobsDir)
+	if !strings.Contains(text, importSpec) {
+		importLine := fmt.Sprintf("\t%s\n\t// manifesto:container-imports", importSpec)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:container-imports", importLine)
+	}
+
+	registerLine := fmt.Sprintf("\tc.mustRegisterCron(cronjobs.%sSchedule, cronjobs.New%s())\n\t// manifesto:cron-jobs",
+		data.TypeName, data.TypeName)
+	text = replaceMarker(fs, containerFile, text, "// manifesto:cron-jobs", registerLine)
+
+	return fs.WriteFile(containerFile, []byte(text), 0644)
+}
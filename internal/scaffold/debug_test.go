@@ -0,0 +1,104 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// debugServerTest is cmd/debug_test.go for a generated project: it starts
+// the debug server with DEBUG_ENDPOINTS_ENABLED off and on, and looks for
+// its endpoints on DEBUG_PORT.
+const debugServerTest = `package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// freePort returns a localhost port nothing listens on.
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func TestDebugEndpointsOff(t *testing.T) {
+	for _, enabled := range []string{"", "false"} {
+		port := freePort(t)
+		t.Setenv("DEBUG_ENDPOINTS_ENABLED", enabled)
+		t.Setenv("DEBUG_PORT", port)
+		ctx, cancel := context.WithCancel(context.Background())
+		startDebugServer(ctx)
+		time.Sleep(200 * time.Millisecond)
+		if conn, err := net.Dial("tcp", "localhost:"+port); err == nil {
+			conn.Close()
+			t.Errorf("DEBUG_ENDPOINTS_ENABLED=%q: something listens on DEBUG_PORT", enabled)
+		}
+		cancel()
+	}
+}
+
+func TestDebugEndpointsOn(t *testing.T) {
+	port := freePort(t)
+	t.Setenv("DEBUG_ENDPOINTS_ENABLED", "true")
+	t.Setenv("DEBUG_PORT", port)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	startDebugServer(ctx)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/buildinfo"} {
+		url := "http://localhost:" + port + path
+		var resp *http.Response
+		var err error
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if resp, err = http.Get(url); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			t.Fatalf("GET %s: %v", url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", url, resp.StatusCode)
+		}
+	}
+}
+`
+
+// TestDebugEndpoints runs debugServerTest in a generated project, which the
+// quick profile wires debug into.
+func TestDebugEndpoints(t *testing.T) {
+	requireGo(t)
+	root := initTestProject(t, InitOptions{})
+	if err := os.WriteFile(filepath.Join(root, "cmd", "debug_test.go"), []byte(debugServerTest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, root, "test", "-run", "TestDebugEndpoints", "./cmd/")
+}
+
+// TestWireIndentsAtMarker checks injected lines take the indentation of the
+// marker they replace rather than adding their own to it.
+func TestWireIndentsAtMarker(t *testing.T) {
+	root := initTestProject(t, InitOptions{})
+	container := readFile(t, root, "cmd/container.go")
+	if !strings.Contains(container, "\n\t\"net/http/pprof\"\n") {
+		t.Error("container.go doesn't import net/http/pprof at one tab")
+	}
+	imports, _, _ := strings.Cut(container[strings.Index(container, "import ("):], ")")
+	for _, line := range strings.Split(imports, "\n")[1:] {
+		if strings.HasPrefix(line, "\t\t") {
+			t.Errorf("container.go imports %q indented twice", strings.TrimSpace(line))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DomainDep is a dependency of the root Container a domain container can
+// take through its Deps, selected with --deps. The field is declared in
+// the module's Deps and filled from the root Container in cmd/container.go.
+type DomainDep struct {
+	Name      string // As given to --deps
+	Module    string // Wireable module, or profile infrastructure, that provides it
+	Import    string // Import path of FieldType; relative to the project module when Local
+	Local     bool
+	FieldName string // Deps field, e.g. "Redis"
+	FieldType string // e.g. "*redis.Client"
+	Root      string // Root Container field passed to it, e.g. "Redis"
+}
+
+// DomainDepRegistry holds every dependency accepted by --deps.
+var DomainDepRegistry = map[string]DomainDep{
+	"db": {
+		Name: "db", Module: "postgres",
+		Import:    "github.com/jmoiron/sqlx",
+		FieldName: "DB", FieldType: "*sqlx.DB", Root: "DB",
+	},
+	"redis": {
+		Name: "redis", Module: "redis",
+		Import:    "github.com/redis/go-redis/v9",
+		FieldName: "Redis", FieldType: "*redis.Client", Root: "Redis",
+	},
+	"fs": {
+		Name: "fs", Module: "fsx",
+		Import: "pkg/fsx", Local: true,
+		FieldName: "FileSystem", FieldType: "fsx.FileSystem", Root: "FileSystem",
+	},
+	"dispatcher": {
+		Name: "dispatcher", Module: "jobx",
+		Import: "pkg/jobx", Local: true,
+		FieldName: "Jobs", FieldType: "*jobx.Client", Root: "JobClient",
+	},
+	"notifier": {
+		Name: "notifier", Module: "notifx",
+		Import: "pkg/notifx", Local: true,
+		FieldName: "Notifier", FieldType: "*notifx.Client", Root: "NotifxClient",
+	},
+}
+
+// DomainDepNames returns the registered dependency names, sorted.
+func DomainDepNames() []string {
+	names := make([]string, 0, len(DomainDepRegistry))
+	for name := range DomainDepRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseDomainDeps parses a --deps spec such as "db,redis" into the
+// dependencies it selects, in the order given.
+func ParseDomainDeps(spec string) ([]DomainDep, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	var deps []DomainDep
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		dep, ok := DomainDepRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown dependency %q in --deps. Available: %s", name, strings.Join(DomainDepNames(), ", "))
+		}
+		if !slices.ContainsFunc(deps, func(d DomainDep) bool { return d.Name == name }) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// ImportPath returns the import path of the dependency's type in a
+// project of goModule.
+func (dep DomainDep) ImportPath(goModule string) string {
+	if dep.Local {
+		return path.Join(goModule, dep.Import)
+	}
+	return dep.Import
+}
+
+// rootDeps is the Deps literal line passing the dependency in
+// cmd/container.go, e.g. "Redis: c.Redis,".
+func (dep DomainDep) rootDeps() string {
+	return fmt.Sprintf("%s: c.%s,", dep.FieldName, dep.Root)
+}
+
+// DepsSpec returns the --deps spec the domain was generated with.
+func (d DomainData) DepsSpec() []string {
+	names := make([]string, 0, len(d.Deps))
+	for _, dep := range d.Deps {
+		names = append(names, dep.Name)
+	}
+	return names
+}
+
+// ExtraDeps returns the --deps the container declares on top of what the
+// repository backend and jobx events already take, and the file system
+// uploads need.
+func (d DomainData) ExtraDeps() []DomainDep {
+	var out []DomainDep
+	for _, dep := range d.Deps {
+		switch {
+		case d.Generates(DomainLayerInfra) && d.Repo.RootDeps == dep.rootDeps():
+		case d.PublishesOnJobx() && dep.Name == "dispatcher":
+		default:
+			out = append(out, dep)
+		}
+	}
+	if d.WithUploads && !slices.ContainsFunc(out, func(dep DomainDep) bool { return dep.Name == "fs" }) {
+		out = append(out, DomainDepRegistry["fs"])
+	}
+	return out
+}
+
+// CheckDeps returns an error for a dependency whose Deps field would clash
+// with the repository backend's, such as db next to mongo's DB.
+func (d DomainData) CheckDeps() error {
+	if !d.Generates(DomainLayerInfra) || d.Repo.DepsField == "" {
+		return nil
+	}
+	repoField, _, _ := strings.Cut(d.Repo.DepsField, " ")
+	for _, dep := range d.ExtraDeps() {
+		if dep.FieldName == repoField {
+			return fmt.Errorf("--deps %s clashes with the %s repository's %s field", dep.Name, d.Repo.Name, repoField)
+		}
+	}
+	return nil
+}
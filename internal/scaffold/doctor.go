@@ -0,0 +1,317 @@
+package scaffold
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+)
+
+// DoctorCheck is the outcome of one doctor check.
+type DoctorCheck struct {
+	Group   string // "manifest", "markers", "wiring", "domains", "modules" or "templates"
+	Name    string
+	OK      bool
+	Detail  string // Why the check failed
+	Fixable bool   // Diagnose --fix can repair it
+	Warn    bool   // A failure worth knowing about that doesn't fail doctor
+}
+
+// Diagnose checks that projectRoot is still in a state the CLI can inject
+// into: manifesto.yaml parses, every marker comment is present, wired
+// modules and tracked domains are still in the generated code, installed
+// modules are on disk and unedited since they were fetched, and template
+// overrides still render.
+func Diagnose(projectRoot string) []DoctorCheck {
+	var checks []DoctorCheck
+
+	manifest, err := config.LoadManifest(projectRoot)
+	checks = append(checks, DoctorCheck{
+		Group:  "manifest",
+		Name:   config.ManifestoFile + " parses",
+		OK:     err == nil,
+		Detail: errDetail(err),
+	})
+
+	// Without a manifest, fall back to the default profile for markers.
+	profile, _ := config.LookupProfile("")
+	if manifest != nil {
+		check := DoctorCheck{Group: "manifest", Name: config.ManifestoFile + " is well-formed", OK: len(manifest.Warnings) == 0}
+		if !check.OK {
+			check.Detail = fmt.Sprintf("%s (%d issue(s); run 'manifesto manifest fmt')", manifest.Warnings[0], len(manifest.Warnings))
+		}
+		checks = append(checks, check)
+
+		p, err := manifest.Profile()
+		checks = append(checks, DoctorCheck{
+			Group:  "manifest",
+			Name:   "profile is known",
+			OK:     err == nil,
+			Detail: errDetail(err),
+		})
+		if err == nil {
+			profile = p
+		}
+
+		check = DoctorCheck{Group: "manifest", Name: "manifesto ref is pinned", OK: ProjectChannel(manifest) != remote.ChannelBranch, Warn: true}
+		if !check.OK {
+			check.Detail = fmt.Sprintf("tracks the %s branch, so downloads may differ from run to run; pass --ref with a tag or commit", manifest.Project.Version)
+		}
+		checks = append(checks, check)
+	}
+
+	checks = append(checks, checkMarkers(projectRoot, profile)...)
+	if manifest == nil {
+		return checks
+	}
+	checks = append(checks, checkWiring(projectRoot, manifest)...)
+	checks = append(checks, checkDomains(projectRoot, manifest)...)
+	checks = append(checks, checkModules(projectRoot, manifest)...)
+	checks = append(checks, checkTemplates(projectRoot, manifest)...)
+	return checks
+}
+
+// FixMarkers re-inserts missing marker comments at their usual anchors.
+// It returns the markers it added and the ones it couldn't place, as
+// "file: marker".
+func FixMarkers(projectRoot string, profile config.Profile) (fixed, unplaced []string, err error) {
+	for _, file := range markerFiles(profile) {
+		path := filepath.Join(projectRoot, filepath.FromSlash(file))
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		text := string(content)
+		markers := markersIn(file)
+		updated, missed := insertMissingMarkers(text, markers)
+		for _, m := range markers {
+			if !hasMarker(text, m.Marker) && hasMarker(updated, m.Marker) {
+				fixed = append(fixed, file+": "+strings.TrimSpace(m.Marker))
+			}
+		}
+		for _, m := range missed {
+			unplaced = append(unplaced, file+": "+m)
+		}
+		if updated == text {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+			return nil, nil, err
+		}
+		if err := Relock(projectRoot, file); err != nil {
+			return nil, nil, err
+		}
+	}
+	return fixed, unplaced, nil
+}
+
+// markerFiles returns the files that carry markers for the profile.
+func markerFiles(profile config.Profile) []string {
+	var files []string
+	for _, m := range projectMarkers {
+		if m.Server && !profile.HTTP {
+			continue
+		}
+		if len(files) == 0 || files[len(files)-1] != m.File {
+			files = append(files, m.File)
+		}
+	}
+	return files
+}
+
+func checkMarkers(projectRoot string, profile config.Profile) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, file := range markerFiles(profile) {
+		content, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(file)))
+		if err != nil {
+			checks = append(checks, DoctorCheck{
+				Group:  "markers",
+				Name:   file + " exists",
+				Detail: errDetail(err),
+			})
+			continue
+		}
+		text := string(content)
+		for _, m := range markersIn(file) {
+			ok := hasMarker(text, m.Marker)
+			check := DoctorCheck{
+				Group:   "markers",
+				Name:    fmt.Sprintf("%s: %s", file, strings.TrimSpace(m.Marker)),
+				OK:      ok,
+				Fixable: !ok,
+			}
+			if !ok {
+				check.Detail = "marker missing; later injections into this file are skipped"
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// checkWiring looks for each wired module's guard string, the same one
+// WireModule uses to detect that it already ran.
+func checkWiring(projectRoot string, manifest *config.Manifest) []DoctorCheck {
+	container, _ := os.ReadFile(filepath.Join(projectRoot, "cmd", "container.go"))
+
+	var checks []DoctorCheck
+	for _, name := range manifest.WiredModules {
+		check := DoctorCheck{Group: "wiring", Name: name + " is wired", OK: true}
+		if provider := manifest.Providers[name]; provider != "" {
+			check.Name = fmt.Sprintf("%s is wired (%s)", name, provider)
+		}
+		guards, err := wiringGuards(manifest, name)
+		if !config.IsWireableModule(name) {
+			check.OK = false
+			check.Detail = "not a wireable module"
+		} else if err != nil {
+			check.OK = false
+			check.Detail = err.Error()
+		} else if guard := missingGuard(string(container), guards); guard != "" {
+			check.OK = false
+			check.Detail = fmt.Sprintf("%q not found in cmd/container.go", guard)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// wiringGuards returns the strings cmd/container.go holds once the named
+// module is wired: its guard string and, for a module with providers, the
+// one of the provider the manifest records.
+func wiringGuards(manifest *config.Manifest, name string) ([]string, error) {
+	spec, err := manifest.WiredSpec(name)
+	if err != nil {
+		return nil, err
+	}
+	spec = replacePlaceholders(spec, manifest.Project.GoModule, manifest.Project.Name)
+	guards := []string{wireGuardString(spec)}
+	if p, ok := spec.Providers[cmp.Or(manifest.Providers[name], spec.DefaultProvider)]; ok {
+		// The provider's own import tells which one was injected.
+		provider := config.WireableModule{ContainerImports: p.ContainerImports}
+		provider = replacePlaceholders(provider, manifest.Project.GoModule, manifest.Project.Name)
+		guards = append(guards, wireGuardString(provider))
+	}
+	return guards, nil
+}
+
+// missingGuard returns the first of guards not in text, or "" if all are.
+func missingGuard(text string, guards []string) string {
+	for _, guard := range guards {
+		if guard != "" && !strings.Contains(text, guard) {
+			return guard
+		}
+	}
+	return ""
+}
+
+// checkDomains looks for each tracked domain's container import and its
+// route or gRPC service registration, if it has one.
+func checkDomains(projectRoot string, manifest *config.Manifest) []DoctorCheck {
+	container, _ := os.ReadFile(filepath.Join(projectRoot, "cmd", "container.go"))
+	server, _ := os.ReadFile(filepath.Join(projectRoot, "cmd", "server.go"))
+
+	var checks []DoctorCheck
+	for _, d := range manifest.Domains {
+		data := trackedDomainData(manifest, d)
+		if !data.Generates(DomainLayerContainer) {
+			continue
+		}
+		importPath := strconv.Quote(manifest.Project.GoModule + "/" + data.ContainerPath)
+
+		check := DoctorCheck{Group: "domains", Name: d.Path + " is injected", OK: true}
+		switch {
+		case !strings.Contains(string(container), importPath):
+			check.OK = false
+			check.Detail = fmt.Sprintf("%s not imported in cmd/container.go", importPath)
+		case data.HasAPI() && !strings.Contains(string(server), fmt.Sprintf("container.%s.RegisterRoutes(", d.ContainerField)):
+			check.OK = false
+			check.Detail = fmt.Sprintf("container.%s.RegisterRoutes not called in cmd/server.go", d.ContainerField)
+		case data.HasGRPC() && !strings.Contains(string(server), fmt.Sprintf("container.%s.RegisterGRPC(", d.ContainerField)):
+			check.OK = false
+			check.Detail = fmt.Sprintf("container.%s.RegisterGRPC not called in cmd/server.go", d.ContainerField)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func checkModules(projectRoot string, manifest *config.Manifest) []DoctorCheck {
+	names := make([]string, 0, len(manifest.Modules))
+	for name := range manifest.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []DoctorCheck
+	for _, name := range names {
+		check := DoctorCheck{Group: "modules", Name: name + " is installed", OK: true}
+		mod, ok := config.ModuleRegistry[name]
+		if !ok {
+			check.OK = false
+			check.Detail = "not a known module"
+		}
+		for _, p := range mod.Dirs() {
+			if info, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(p))); err != nil || !info.IsDir() {
+				check.OK = false
+				check.Detail = p + "/ is missing"
+				break
+			}
+		}
+		checks = append(checks, check)
+
+		if dir, ok := remote.LocalPath(manifest.Modules[name].Version); ok {
+			check := DoctorCheck{Group: "modules", Name: name + "'s local checkout exists", OK: true, Warn: true}
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				check.OK = false
+				check.Detail = fmt.Sprintf("%s, which %s was copied from with --local, is gone; it can't be copied again from there", dir, name)
+			}
+			checks = append(checks, check)
+		}
+	}
+	return append(checks, checkLock(projectRoot, names)...)
+}
+
+// checkLock reports the installed modules with files edited since they
+// were fetched, which a later download would stop on.
+func checkLock(projectRoot string, names []string) []DoctorCheck {
+	lock, err := config.LoadLock(projectRoot)
+	if err != nil {
+		return []DoctorCheck{{Group: "modules", Name: config.LockFile + " parses", Detail: err.Error()}}
+	}
+	if len(lock.Files) == 0 {
+		return nil
+	}
+
+	var checks []DoctorCheck
+	for _, name := range names {
+		paths := config.ModuleRegistry[name].Dirs()
+		if len(paths) == 0 {
+			continue
+		}
+		modified := lock.Modified(projectRoot, paths)
+		check := DoctorCheck{Group: "modules", Name: name + " matches " + config.LockFile, OK: len(modified) == 0}
+		if !check.OK {
+			check.Detail = fmt.Sprintf("edited since fetched: %s; downloads need --force or --keep-modified", strings.Join(modified, ", "))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func errDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -3,82 +3,455 @@ package scaffold
 import (
 	"bytes"
 	"fmt"
+	"go/format"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 	"unicode"
 
-	"github.com/Abraxas-365/manifesto-cli/internal/templates"
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
 )
 
 // DomainData is the template context for domain scaffolding.
 type DomainData struct {
-	GoModule      string
-	PackageName   string
-	EntityName    string
-	RegistryCode  string
-	TableName     string
-	DomainPath    string
+	GoModule     string
+	PackageName  string
+	EntityName   string
+	RegistryCode string
+	TableName    string
+	DomainPath   string
+
+	// Other forms of the package name, for variables, route paths, JSON
+	// tags and messages: lineItem, line-item, line_item and "line item".
+	CamelName string
+	KebabName string
+	SnakeName string
+	Label     string
+
 	ContainerPkg  string // e.g. "candidatecontainer"
 	ContainerPath string // e.g. "pkg/recruitment/candidate/candidatecontainer"
+
+	// Naming is the convention the layers are laid out by; Layout applies
+	// it to this domain. Set both with WithNaming.
+	Naming string
+	Layout config.DomainLayout
+
+	// Names used for the domain in cmd/container.go. They default to
+	// ContainerPkg and EntityName and are qualified on collision.
+	ContainerAlias string // e.g. "billinginvoicecontainer"
+	ContainerField string // e.g. "BillingInvoice"
+
+	Fields []FieldSpec // Custom entity fields from --fields
+	Repo   RepoBackend // Repository implementation from --repo
+	Deps   []DomainDep // Root Container dependencies from --deps
+	Kind   string      // DomainKindHTTP or DomainKindWorker, from --kind
+
+	// Transport is how an HTTP-kind domain is exposed: TransportHTTP or
+	// TransportGRPC, from --transport.
+	Transport string
+
+	// Public registers the domain's routes on app, outside the protected
+	// group and its auth middleware, from --public. Otherwise Group names
+	// the group they go on, from --group; empty means protected. See
+	// RouteGroup.
+	Public bool
+	Group  string
+
+	TenantScoped bool // Entity carries a TenantID and is owned by a tenant
+	WithPolicy   bool // Generate policy.go and enforce it in the service layer
+	HasIAM       bool // Project has iam wired (handlers pass the caller to the policy)
+	WithEvents   bool // Generate events.go and publish them from the service layer
+	HasJobx      bool // Project has jobx wired (events are enqueued as jobs)
+	WithUploads  bool // Generate attachments.go and upload/download handlers on fsx
+	HasSwagger   bool // Project has swagger wired (handlers carry swag annotations)
+	WithTests    bool // Generate a fake repository and service/handler tests
+	WithMocks    bool // Generate a configurable repository mock in <pkg>/mocks
+
+	// Layers are the DomainLayers to generate, from --layers; nil
+	// generates them all.
+	Layers []string
+
+	in string // Layer directory of the file being rendered
+}
+
+// Domain kinds. Worker domains have no HTTP layer: no <pkg>api package and
+// no route registration in cmd/server.go.
+const (
+	DomainKindHTTP   = "http"
+	DomainKindWorker = "worker"
+)
+
+// DomainKinds lists the values accepted by --kind.
+var DomainKinds = []string{DomainKindHTTP, DomainKindWorker}
+
+// Domain transports. gRPC domains get a .proto file and a gRPC server in
+// place of the HTTP handlers, registered in cmd/server.go's registerGRPC.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Transports lists the values accepted by --transport.
+var Transports = []string{TransportHTTP, TransportGRPC}
+
+// HasAPI reports whether the domain gets HTTP handlers and routes.
+func (d DomainData) HasAPI() bool {
+	return d.Kind != DomainKindWorker && d.Transport != TransportGRPC && d.Generates(DomainLayerAPI)
+}
+
+// HasGRPC reports whether the domain gets a gRPC service.
+func (d DomainData) HasGRPC() bool {
+	return d.Kind != DomainKindWorker && d.Transport == TransportGRPC && d.Generates(DomainLayerAPI)
+}
+
+// PublishesOnJobx reports whether the domain's events are enqueued on jobx
+// through the generated infra publisher.
+func (d DomainData) PublishesOnJobx() bool {
+	return d.WithEvents && d.HasJobx && d.Generates(DomainLayerInfra)
+}
+
+// AuthenticatesActor reports whether the domain's handlers build the Actor
+// its policy checks from iam's auth context. Public routes skip iam's
+// middleware, so there is no caller to build one from.
+func (d DomainData) AuthenticatesActor() bool {
+	return d.WithPolicy && d.HasIAM && d.HasAPI() && d.RouteGroup() != PublicGroup
+}
+
+// AttachmentsTable is the table holding the records of the domain's
+// uploads, e.g. invoice_attachments.
+func (d DomainData) AttachmentsTable() string {
+	return d.SnakeName + "_attachments"
+}
+
+// CheckUploads returns an error when the domain can't take --with-uploads:
+// the handlers need HTTP routes, and the attachment records a postgres
+// repository.
+func (d DomainData) CheckUploads() error {
+	if !d.WithUploads {
+		return nil
+	}
+	if !d.HasAPI() {
+		return fmt.Errorf("--with-uploads adds HTTP upload and download routes; %s has none", d.DomainPath)
+	}
+	if !d.Generates(DomainLayerInfra) || d.Repo.Name != DefaultRepoBackend {
+		return fmt.Errorf("--with-uploads stores attachment records with the %s repository; use --repo %s", DefaultRepoBackend, DefaultRepoBackend)
+	}
+	return nil
+}
+
+// GoDeps returns the modules the generated code needs added to go.mod:
+// the repository backend's, when the infra layer is generated.
+func (d DomainData) GoDeps() []string {
+	if !d.Generates(DomainLayerInfra) {
+		return nil
+	}
+	return d.Repo.GoDeps
+}
+
+// TestsUseMocks reports whether the service tests use the repository mock.
+// A flat domain's can't: they are in the domain package the mock imports.
+func (d DomainData) TestsUseMocks() bool {
+	return d.WithTests && d.WithMocks && d.Layout.Service != ""
+}
+
+// ProtoPackage is the protobuf package of a gRPC domain: its path with
+// dots, e.g. pkg.billing.invoice, so domains sharing a package name don't
+// clash in the protobuf registry.
+func (d DomainData) ProtoPackage() string {
+	return strings.ReplaceAll(d.DomainPath, "/", ".")
+}
+
+// ProtoFile is the .proto file of a gRPC domain, relative to the project
+// root.
+func (d DomainData) ProtoFile() string {
+	return path.Join(d.DomainPath, protoDir, d.PackageName+".proto")
+}
+
+// ProtoGoFile is the Go file make proto generates from ProtoFile.
+func (d DomainData) ProtoGoFile() string {
+	return strings.TrimSuffix(d.ProtoFile(), ".proto") + ".pb.go"
+}
+
+// ProtoGenerated reports whether make proto has generated ProtoGoFile in
+// projectRoot.
+func (d DomainData) ProtoGenerated(projectRoot string) bool {
+	_, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(d.ProtoGoFile())))
+	return err == nil
+}
+
+// PluralEntityName names the collection RPC and its messages, e.g.
+// ListInvoices.
+func (d DomainData) PluralEntityName() string {
+	return toGoName(d.TableName)
+}
+
+// Layers as named in templates: {{.Pkg "srv"}}, {{.Ref "domain"}},
+// {{.Import "api"}}.
+const (
+	layerDomain    = "domain"
+	layerService   = "srv"
+	layerInfra     = "infra"
+	layerAPI       = "api"
+	layerGRPC      = "grpc"
+	layerProto     = "proto"
+	layerMocks     = "mocks"
+	layerContainer = "container"
+)
+
+// protoDir holds a gRPC domain's .proto file and the code protoc generates
+// from it, relative to the domain directory.
+const protoDir = "proto"
+
+// mocksDir holds the domain's test doubles, relative to the domain
+// directory, whatever its naming convention.
+const mocksDir = "mocks"
+
+// layerDir returns the directory of layer relative to the domain directory.
+func (d DomainData) layerDir(layer string) string {
+	switch layer {
+	case layerService:
+		return d.Layout.Service
+	case layerInfra:
+		return d.Layout.Infra
+	case layerAPI:
+		return d.Layout.API
+	case layerGRPC:
+		return d.Layout.GRPC
+	case layerProto:
+		return protoDir
+	case layerMocks:
+		return mocksDir
+	case layerContainer:
+		return d.Layout.Container
+	}
+	return ""
+}
+
+// Pkg returns the package name of layer. The generated protobuf package
+// is named after the domain, e.g. invoicepb, rather than its directory.
+func (d DomainData) Pkg(layer string) string {
+	if layer == layerProto {
+		return d.PackageName + "pb"
+	}
+	return d.Layout.PackageName(d.layerDir(layer))
+}
+
+// Ref returns the qualifier for identifiers of layer in the file being
+// rendered, e.g. "invoicesrv.", or "" when the file is in that package.
+func (d DomainData) Ref(layer string) string {
+	if d.layerDir(layer) == d.in {
+		return ""
+	}
+	return d.Pkg(layer) + "."
+}
+
+// Import returns the quoted import path of layer, or "" when the file
+// being rendered is in that package.
+func (d DomainData) Import(layer string) string {
+	if d.layerDir(layer) == d.in {
+		return ""
+	}
+	return strconv.Quote(path.Join(d.GoModule, d.DomainPath, d.layerDir(layer)))
+}
+
+// GeneratedFile describes a file produced by domain scaffolding.
+type GeneratedFile struct {
+	Path        string // Relative to the project root
+	Description string
 }
 
 func NewDomainData(goModule, domainPath string) DomainData {
 	parts := strings.Split(domainPath, "/")
 	pkgName := parts[len(parts)-1]
+	names := casings(pkgName)
+
+	data := DomainData{
+		GoModule:       goModule,
+		PackageName:    pkgName,
+		EntityName:     toGoName(pkgName),
+		RegistryCode:   toUpperSnake(pkgName),
+		TableName:      toPlural(pkgName),
+		DomainPath:     domainPath,
+		CamelName:      names.Camel,
+		KebabName:      names.Kebab,
+		SnakeName:      names.Snake,
+		Label:          toLabel(pkgName),
+		ContainerField: toGoName(pkgName),
+		Repo:           RepoBackendRegistry[DefaultRepoBackend],
+		Kind:           DomainKindHTTP,
+		Transport:      TransportHTTP,
+		TenantScoped:   true,
+		WithTests:      true,
+		WithMocks:      true,
+	}
+	return data.WithNaming(config.NamingRegistry[config.DefaultNaming])
+}
+
+// WithNaming lays the domain out by the convention n. A container alias
+// still at its default follows the new container package.
+func (d DomainData) WithNaming(n config.Naming) DomainData {
+	defaultAlias := d.ContainerAlias == "" || d.ContainerAlias == d.defaultContainerAlias()
+	d.Naming = n.Name
+	d.Layout = n.Layout(d.PackageName)
+	d.ContainerPkg = d.Pkg(layerContainer)
+	d.ContainerPath = path.Join(d.DomainPath, d.Layout.Container)
+	if defaultAlias {
+		d.ContainerAlias = d.defaultContainerAlias()
+	}
+	return d
+}
+
+// defaultContainerAlias is the name cmd/container.go imports the domain's
+// container under unless it collides: the package name, prefixed with the
+// domain's when every domain shares it (wire under subdir).
+func (d DomainData) defaultContainerAlias() string {
+	if d.ContainerPkg == "" || strings.Contains(d.ContainerPkg, d.PackageName) {
+		return d.ContainerPkg
+	}
+	return d.PackageName + d.ContainerPkg
+}
+
+type domainFile struct {
+	tmpl  string
+	layer string
+	name  string // File name within the layer's directory
+	desc  string
+}
+
+// path returns the file's path relative to the domain directory.
+func (f domainFile) path(data DomainData) string {
+	return path.Join(data.layerDir(f.layer), f.name)
+}
+
+// domainFiles returns the files to render for a domain, in display order.
+func domainFiles(data DomainData) []domainFile {
+	files := []domainFile{
+		{"domain/entity.go.tmpl", layerDomain, data.PackageName + ".go", "Entity + DTOs"},
+		{"domain/port.go.tmpl", layerDomain, "port.go", "Repository interface"},
+	}
+	if data.WithTests {
+		files = append(files, domainFile{"domain/port_fake.go.tmpl", layerDomain, "port_fake.go", "Fake repository for tests"})
+	}
+	files = append(files, domainFile{"domain/errors.go.tmpl", layerDomain, "errors.go", "Error registry"})
+	if data.WithEvents {
+		files = append(files, domainFile{"domain/events.go.tmpl", layerDomain, "events.go", "Domain events"})
+	}
+	if data.WithUploads {
+		files = append(files, domainFile{"domain/attachments.go.tmpl", layerDomain, "attachments.go", "Attachment entity"})
+	}
+	if data.WithPolicy {
+		files = append(files,
+			domainFile{"domain/policy.go.tmpl", layerDomain, "policy.go", "Authorization policy"},
+			domainFile{"domain/policy_test.go.tmpl", layerService, "policy_test.go", "Policy enforcement tests"},
+		)
+	}
+	files = append(files,
+		domainFile{"domain/service.go.tmpl", layerService, "service.go", "Service layer"},
+	)
+	if data.WithTests {
+		files = append(files, domainFile{"domain/service_test.go.tmpl", layerService, "service_test.go", "Service tests"})
+	}
+	if data.WithMocks {
+		files = append(files, domainFile{"domain/repository_mock.go.tmpl", layerMocks, "repository_mock.go", "Configurable repository mock"})
+	}
+	// A tracked domain with an unknown backend has no repository to list.
+	if data.Repo.File != "" {
+		files = append(files, domainFile{data.Repo.Template, layerInfra, data.Repo.File, data.Repo.Description})
+	}
+	if data.PublishesOnJobx() {
+		files = append(files, domainFile{"domain/event_publisher.go.tmpl", layerInfra, "event_publisher.go", "Event publisher (jobx)"})
+	}
+	if data.HasAPI() {
+		files = append(files, domainFile{"domain/handler.go.tmpl", layerAPI, "handler.go", "HTTP handlers (CRUD ready)"})
+		if data.WithTests {
+			files = append(files, domainFile{"domain/handler_test.go.tmpl", layerAPI, "handler_test.go", "Handler tests"})
+		}
+		if data.WithUploads {
+			files = append(files, domainFile{"domain/attachment_handler.go.tmpl", layerAPI, "attachment_handler.go", "Upload/download handlers (fsx)"})
+		}
+	}
+	if data.HasGRPC() {
+		files = append(files,
+			domainFile{"domain/service.proto.tmpl", layerProto, data.PackageName + ".proto", "gRPC service definition"},
+			domainFile{"domain/grpc_server.go.tmpl", layerGRPC, "server.go", "gRPC server (CRUD ready)"},
+		)
+	}
+	files = append(files,
+		domainFile{"domain/container.go.tmpl", layerContainer, "container.go", "Module container (DI wiring)"},
+	)
+	return slices.DeleteFunc(files, func(f domainFile) bool { return !data.Generates(f.selectedBy()) })
+}
 
-	return DomainData{
-		GoModule:      goModule,
-		PackageName:   pkgName,
-		EntityName:    toPascalCase(pkgName),
-		RegistryCode:  toUpperSnake(pkgName),
-		TableName:     toPlural(pkgName),
-		DomainPath:    domainPath,
-		ContainerPkg:  pkgName + "container",
-		ContainerPath: domainPath + "/" + pkgName + "container",
+// DomainFiles lists the files GenerateDomain creates for data.
+func DomainFiles(data DomainData) []GeneratedFile {
+	var out []GeneratedFile
+	for _, f := range domainFiles(data) {
+		out = append(out, GeneratedFile{
+			Path:        data.DomainPath + "/" + f.path(data),
+			Description: f.desc,
+		})
 	}
+	return out
 }
 
 func GenerateDomain(projectRoot string, data DomainData) error {
+	if err := generateDomain(diskStore{}, projectRoot, data); err != nil {
+		return err
+	}
+	return Relock(projectRoot, kernelIDsFile)
+}
+
+// PreviewDomain runs GenerateDomain against an in-memory Preview.
+func PreviewDomain(projectRoot string, data DomainData) (*Preview, error) {
+	preview := NewPreview(projectRoot)
+	err := generateDomain(preview, projectRoot, data)
+	return preview, err
+}
+
+func generateDomain(fs FileStore, projectRoot string, data DomainData) error {
+	data = resolveContainerNames(fs, projectRoot, data)
 	baseDir := filepath.Join(projectRoot, data.DomainPath)
 
-	files := []struct {
-		tmpl string
-		dest string
-	}{
-		{"domain/entity.go.tmpl", filepath.Join(baseDir, data.PackageName+".go")},
-		{"domain/port.go.tmpl", filepath.Join(baseDir, "port.go")},
-		{"domain/errors.go.tmpl", filepath.Join(baseDir, "errors.go")},
-		{"domain/service.go.tmpl", filepath.Join(baseDir, data.PackageName+"srv", "service.go")},
-		{"domain/postgres.go.tmpl", filepath.Join(baseDir, data.PackageName+"infra", "postgres.go")},
-		{"domain/handler.go.tmpl", filepath.Join(baseDir, data.PackageName+"api", "handler.go")},
-		{"domain/container.go.tmpl", filepath.Join(baseDir, data.ContainerPkg, "container.go")},
-	}
-
-	for _, f := range files {
-		if err := renderTemplate(f.tmpl, f.dest, data); err != nil {
-			return fmt.Errorf("generate %s: %w", filepath.Base(f.dest), err)
+	for _, f := range domainFiles(data) {
+		dest := filepath.Join(baseDir, filepath.FromSlash(f.path(data)))
+		fileData := data
+		fileData.in = data.layerDir(f.layer)
+		if err := renderTemplate(fs, projectRoot, f.tmpl, dest, fileData); err != nil {
+			return fmt.Errorf("generate %s: %w", filepath.Base(dest), err)
 		}
 	}
 
 	// Append kernel IDs
-	kernelSnippet, err := renderToString("domain/kernel_ids.go.tmpl", data)
+	kernelSnippet, err := renderToString(projectRoot, "domain/kernel_ids.go.tmpl", data)
 	if err != nil {
 		return fmt.Errorf("render kernel IDs: %w", err)
 	}
 
-	if err := appendKernelIDs(projectRoot, kernelSnippet); err != nil {
+	if err := appendKernelIDs(fs, projectRoot, kernelSnippet); err != nil {
 		return fmt.Errorf("append kernel IDs: %w", err)
 	}
 
 	// NEW: inject module into cmd/container.go and cmd/server.go
-	if err := injectIntoRootContainer(projectRoot, data); err != nil {
+	if !data.Generates(DomainLayerContainer) {
+		return nil
+	}
+	if err := injectIntoRootContainer(fs, projectRoot, data); err != nil {
 		return fmt.Errorf("inject into container: %w", err)
 	}
 
-	if err := injectIntoServerRoutes(projectRoot, data); err != nil {
-		return fmt.Errorf("inject into server routes: %w", err)
+	if data.HasAPI() {
+		if err := injectIntoServerRoutes(fs, projectRoot, data); err != nil {
+			return fmt.Errorf("inject into server routes: %w", err)
+		}
+	}
+	if data.HasGRPC() {
+		if err := injectIntoServerGRPC(fs, projectRoot, data); err != nil {
+			return fmt.Errorf("inject into gRPC registration: %w", err)
+		}
 	}
 
 	return nil
@@ -90,10 +463,10 @@ func GenerateDomain(projectRoot string, data DomainData) error {
 
 // injectIntoRootContainer adds the new module's import, field, and init call
 // into cmd/container.go using marker comments.
-func injectIntoRootContainer(projectRoot string, data DomainData) error {
+func injectIntoRootContainer(fs FileStore, projectRoot string, data DomainData) error {
 	containerFile := filepath.Join(projectRoot, "cmd", "container.go")
 
-	content, err := os.ReadFile(containerFile)
+	content, err := fs.ReadFile(containerFile)
 	if err != nil {
 		return fmt.Errorf("read cmd/container.go: %w (skip injection)", err)
 	}
@@ -102,32 +475,71 @@ func injectIntoRootContainer(projectRoot string, data DomainData) error {
 	containerImport := fmt.Sprintf("%s/%s", data.GoModule, data.ContainerPath)
 
 	// Guard: don't inject if already present
-	if strings.Contains(text, containerImport) {
+	if strings.Contains(text, strconv.Quote(containerImport)) {
+		fs.Present(containerFile, fmt.Sprintf("%s already injected", data.ContainerField))
 		return nil
 	}
 
-	// 1. Inject import
-	importLine := fmt.Sprintf("\t\"%s\"\n\t// manifesto:container-imports", containerImport)
-	text = strings.Replace(text, "// manifesto:container-imports", importLine, 1)
+	// 1. Inject import (aliased when the package name is taken)
+	importSpec := strconv.Quote(containerImport)
+	if data.ContainerAlias != data.ContainerPkg {
+		importSpec = data.ContainerAlias + " " + importSpec
+	}
+	importLine := fmt.Sprintf("\t%s\n\t// manifesto:container-imports", importSpec)
+	text = replaceMarker(fs, containerFile, text, "// manifesto:container-imports", importLine)
 
 	// 2. Inject struct field
 	fieldLine := fmt.Sprintf("\t%s *%s.Container\n\t// manifesto:container-fields",
-		data.EntityName, data.ContainerPkg)
-	text = strings.Replace(text, "// manifesto:container-fields", fieldLine, 1)
+		data.ContainerField, data.ContainerAlias)
+	text = replaceMarker(fs, containerFile, text, "// manifesto:container-fields", fieldLine)
 
 	// 3. Inject init call in initModules()
-	initBlock := fmt.Sprintf(`	c.%s = %s.New(%s.Deps{
-		DB: c.DB,
-	})
+	initBlock := fmt.Sprintf(`	c.%s = %s.New(%s.Deps{%s})
 
-	// manifesto:module-init`, data.EntityName, data.ContainerPkg, data.ContainerPkg)
-	text = strings.Replace(text, "// manifesto:module-init", initBlock, 1)
+	// manifesto:module-init`, data.ContainerField, data.ContainerAlias, data.ContainerAlias,
+		rootDeps(text, data))
+	text = replaceMarker(fs, containerFile, text, "// manifesto:module-init", initBlock)
 
 	// 4. Inject background service start (optional — modules can add if needed)
 	// We don't auto-inject background services since most domains don't need them.
 	// The marker stays for manual use.
 
-	return os.WriteFile(containerFile, []byte(text), 0644)
+	return fs.WriteFile(containerFile, []byte(text), 0644)
+}
+
+// rootDeps renders the Deps literal body: the backend's dependency, for a
+// domain publishing events on jobx the job client, and the --deps. A dependency the
+// root Container doesn't provide is left as a TODO rather than breaking the
+// build, as is the repository of a domain generated without infra.
+func rootDeps(containerSrc string, data DomainData) string {
+	type dep struct{ line, field string }
+	var deps []dep
+	if data.Generates(DomainLayerInfra) && data.Repo.RootDeps != "" {
+		deps = append(deps, dep{data.Repo.RootDeps, data.Repo.RootField})
+	}
+	if data.PublishesOnJobx() {
+		deps = append(deps, dep{"Jobs: c.JobClient,", "JobClient"})
+	}
+	for _, d := range data.ExtraDeps() {
+		deps = append(deps, dep{d.rootDeps(), d.Root})
+	}
+
+	_, fields, err := containerNames([]byte(containerSrc))
+	var lines []string
+	if !data.Generates(DomainLayerInfra) {
+		lines = append(lines, fmt.Sprintf("// TODO: pass Repository: %s has no infra layer to build one.", data.DomainPath))
+	}
+	for _, d := range deps {
+		line := d.line
+		if err == nil && !fields[d.field] {
+			line = fmt.Sprintf("// TODO: add %s to Container and pass it here.\n\t\t// %s", d.field, d.line)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\t\t" + strings.Join(lines, "\n\t\t") + "\n\t"
 }
 
 // ---------------------------------------------------------------------------
@@ -136,10 +548,10 @@ func injectIntoRootContainer(projectRoot string, data DomainData) error {
 
 // injectIntoServerRoutes adds the new module's route registration
 // into cmd/server.go using a marker comment.
-func injectIntoServerRoutes(projectRoot string, data DomainData) error {
+func injectIntoServerRoutes(fs FileStore, projectRoot string, data DomainData) error {
 	serverFile := filepath.Join(projectRoot, "cmd", "server.go")
 
-	content, err := os.ReadFile(serverFile)
+	content, err := fs.ReadFile(serverFile)
 	if err != nil {
 		return fmt.Errorf("read cmd/server.go: %w (skip injection)", err)
 	}
@@ -147,36 +559,76 @@ func injectIntoServerRoutes(projectRoot string, data DomainData) error {
 	text := string(content)
 
 	// Guard: don't inject if already present
-	routeCall := fmt.Sprintf("container.%s.RegisterRoutes", data.EntityName)
+	routeCall := fmt.Sprintf("container.%s.RegisterRoutes(", data.ContainerField)
 	if strings.Contains(text, routeCall) {
+		fs.Present(serverFile, fmt.Sprintf("%s routes already registered", data.ContainerField))
 		return nil
 	}
 
-	// Ensure protected group exists
-	if !strings.Contains(text, "protected :=") {
-		groupLine := "\tprotected := app.Group(\"/api/v1\")\n\n\t// manifesto:route-registration"
-		text = strings.Replace(text, "// manifesto:route-registration", groupLine, 1)
+	// Public domains register on app ahead of the protected group. The
+	// protected group is declared here the way wiring does when the
+	// project has none yet; any other group must already be declared.
+	marker, router := "// manifesto:route-registration", data.RouteGroup()
+	switch router {
+	case PublicGroup:
+		marker, router = "// manifesto:public-routes", `app.Group("/api/v1")`
+	case ProtectedGroup:
+		if !hasProtectedGroup(text) {
+			text = addProtectedGroup(fs, serverFile, text, "")
+		}
+	default:
+		if !declaresRouteGroup(text, router) {
+			return fmt.Errorf("cmd/server.go declares no %s route group; declare it in registerRoutes or drop --group", router)
+		}
 	}
 
 	// Inject route registration
-	routeLine := fmt.Sprintf("\tcontainer.%s.RegisterRoutes(protected)\n\t// manifesto:route-registration",
-		data.EntityName)
-	text = strings.Replace(text, "// manifesto:route-registration", routeLine, 1)
+	routeLine := fmt.Sprintf("\tcontainer.%s.RegisterRoutes(%s)\n\t%s",
+		data.ContainerField, router, marker)
+	text = replaceMarker(fs, serverFile, text, marker, routeLine)
+
+	return fs.WriteFile(serverFile, []byte(text), 0644)
+}
+
+// injectIntoServerGRPC adds the new module's gRPC service registration
+// into registerGRPC in cmd/server.go, which init --grpc generates.
+func injectIntoServerGRPC(fs FileStore, projectRoot string, data DomainData) error {
+	serverFile := filepath.Join(projectRoot, "cmd", "server.go")
+
+	content, err := fs.ReadFile(serverFile)
+	if err != nil {
+		return fmt.Errorf("read cmd/server.go: %w (skip injection)", err)
+	}
+
+	text := string(content)
+
+	// Guard: don't inject if already present
+	registerCall := fmt.Sprintf("container.%s.RegisterGRPC(", data.ContainerField)
+	if strings.Contains(text, registerCall) {
+		fs.Present(serverFile, fmt.Sprintf("%s gRPC service already registered", data.ContainerField))
+		return nil
+	}
 
-	return os.WriteFile(serverFile, []byte(text), 0644)
+	registerLine := fmt.Sprintf("\tcontainer.%s.RegisterGRPC(grpcServer)\n\t// manifesto:grpc-registration",
+		data.ContainerField)
+	text = replaceMarker(fs, serverFile, text, "// manifesto:grpc-registration", registerLine)
+
+	return fs.WriteFile(serverFile, []byte(text), 0644)
 }
 
 // ---------------------------------------------------------------------------
-// Template rendering (unchanged)
+// Template rendering
 // ---------------------------------------------------------------------------
 
-func renderTemplate(tmplPath, destPath string, data any) error {
-	content, err := templates.FS.ReadFile(tmplPath)
+// renderTemplate renders tmplPath, or its override in projectRoot, to
+// destPath.
+func renderTemplate(fs FileStore, projectRoot, tmplPath, destPath string, data any) error {
+	content, err := readTemplate(projectRoot, tmplPath)
 	if err != nil {
 		return fmt.Errorf("read template %s: %w", tmplPath, err)
 	}
 
-	tmpl, err := template.New(filepath.Base(tmplPath)).Parse(string(content))
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(TemplateFuncs).Parse(string(content))
 	if err != nil {
 		return fmt.Errorf("parse template: %w", err)
 	}
@@ -186,20 +638,25 @@ func renderTemplate(tmplPath, destPath string, data any) error {
 		return fmt.Errorf("execute template: %w", err)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-		return err
+	out := buf.Bytes()
+	if strings.HasSuffix(destPath, ".go") {
+		// Field lists vary in width; let gofmt align them. Leave the raw
+		// output in place if it doesn't parse so the problem is visible.
+		if formatted, err := format.Source(out); err == nil {
+			out = formatted
+		}
 	}
 
-	return os.WriteFile(destPath, buf.Bytes(), 0644)
+	return fs.WriteFile(destPath, out, 0644)
 }
 
-func renderToString(tmplPath string, data any) (string, error) {
-	content, err := templates.FS.ReadFile(tmplPath)
+func renderToString(projectRoot, tmplPath string, data any) (string, error) {
+	content, err := readTemplate(projectRoot, tmplPath)
 	if err != nil {
 		return "", err
 	}
 
-	tmpl, err := template.New(filepath.Base(tmplPath)).Parse(string(content))
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(TemplateFuncs).Parse(string(content))
 	if err != nil {
 		return "", err
 	}
@@ -212,35 +669,6 @@ func renderToString(tmplPath string, data any) (string, error) {
 	return buf.String(), nil
 }
 
-func appendKernelIDs(projectRoot, snippet string) error {
-	idFile := filepath.Join(projectRoot, "pkg", "kernel", "proj_ids.go")
-
-	if _, err := os.Stat(idFile); os.IsNotExist(err) {
-		if err := os.MkdirAll(filepath.Dir(idFile), 0755); err != nil {
-			return err
-		}
-		return os.WriteFile(idFile, []byte("package kernel\n"+snippet), 0644)
-	}
-
-	existing, err := os.ReadFile(idFile)
-	if err != nil {
-		return err
-	}
-
-	if strings.Contains(string(existing), strings.TrimSpace(snippet)) {
-		return nil
-	}
-
-	f, err := os.OpenFile(idFile, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	_, err = f.WriteString("\n" + snippet)
-	return err
-}
-
 // ---------------------------------------------------------------------------
 // String helpers (unchanged)
 // ---------------------------------------------------------------------------
@@ -257,32 +685,60 @@ func toPascalCase(s string) string {
 	return b.String()
 }
 
-func toUpperSnake(s string) string {
+// toLabel returns s as lowercase words for messages, e.g. "line item".
+func toLabel(s string) string {
+	return strings.Join(splitWords(s), " ")
+}
+
+// toTitle returns s as capitalized words, initialisms in upper case, e.g.
+// "API Key".
+func toTitle(s string) string {
 	words := splitWords(s)
 	for i, w := range words {
-		words[i] = strings.ToUpper(w)
+		if commonInitialisms[w] {
+			words[i] = strings.ToUpper(w)
+			continue
+		}
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
 	}
-	return strings.Join(words, "_")
+	return strings.Join(words, " ")
 }
 
-func toPlural(s string) string {
-	if strings.HasSuffix(s, "s") {
-		return s + "es"
-	}
-	if strings.HasSuffix(s, "y") && len(s) > 1 {
-		return s[:len(s)-1] + "ies"
+func toUpperSnake(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w)
 	}
-	return s + "s"
+	return strings.Join(words, "_")
 }
 
+// splitWords splits s into lowercase words at underscores, dashes,
+// spaces and case changes: apiKey, APIKey, api_key and api-key are all
+// api, key.
 func splitWords(s string) []string {
-	s = strings.ReplaceAll(s, "-", "_")
-	parts := strings.Split(s, "_")
 	var words []string
-	for _, p := range parts {
-		if p != "" {
-			words = append(words, strings.ToLower(p))
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prev := runes[i-1]
+			// A hump (apiKey) or the end of an initialism (APIKey).
+			if !unicode.IsUpper(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
 		}
+		word = append(word, r)
 	}
+	flush()
 	return words
 }
@@ -0,0 +1,177 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// TestPolicyDomainWithIAM generates a tenant-scoped domain with a policy in
+// a project with iam wired, and runs its generated tests: the handlers
+// must hand the authenticated caller to the policy for CRUD to pass it.
+func TestPolicyDomainWithIAM(t *testing.T) {
+	requireGo(t)
+	root := initTestProject(t, InitOptions{Profile: "api"})
+
+	data := NewDomainData("example.com/shop", "pkg/invoice")
+	data.WithPolicy = true
+	data.WithTests = true
+	data.HasIAM = true
+	if err := GenerateDomain(root, data); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := readFile(t, root, "pkg/invoice/invoiceapi/handler.go")
+	if !strings.Contains(handler, `router.Group("/invoices", actorFromAuth)`) {
+		t.Errorf("routes don't run actorFromAuth:\n%s", handler)
+	}
+	runGo(t, root, "test", "./pkg/invoice/...")
+	runGo(t, root, "build", "./...")
+}
+
+// TestPolicyDomainPublic checks a public domain keeps the allow-all policy:
+// its routes skip iam's middleware, so there is no caller to check.
+func TestPolicyDomainPublic(t *testing.T) {
+	data := NewDomainData("example.com/shop", "pkg/invoice")
+	data.WithPolicy = true
+	data.HasIAM = true
+	data.Public = true
+	if data.AuthenticatesActor() {
+		t.Error("public domain authenticates an actor")
+	}
+	data.Public = false
+	if !data.AuthenticatesActor() {
+		t.Error("protected domain with iam doesn't authenticate an actor")
+	}
+}
+
+// TestSameNamedDomains scaffolds two invoice domains and a domain named
+// like the config library into one project: cmd/container.go must import
+// each container under a name of its own for the project to build.
+func TestSameNamedDomains(t *testing.T) {
+	requireGo(t)
+	root := initTestProject(t, InitOptions{})
+
+	for _, path := range []string{"pkg/billing/invoice", "pkg/sales/invoice", "pkg/settings/config"} {
+		data := ResolveContainerNames(root, NewDomainData("example.com/shop", path))
+		if err := GenerateDomain(root, data); err != nil {
+			t.Fatalf("generate %s: %v", path, err)
+		}
+	}
+
+	container := readFile(t, root, "cmd/container.go")
+	for _, want := range []string{
+		`"example.com/shop/pkg/billing/invoice/invoicecontainer"`,
+		`salesinvoicecontainer "example.com/shop/pkg/sales/invoice/invoicecontainer"`,
+		`SalesInvoice *salesinvoicecontainer.Container`,
+		`settingsconfigcontainer "example.com/shop/pkg/settings/config/configcontainer"`,
+	} {
+		if !strings.Contains(container, want) {
+			t.Errorf("cmd/container.go lacks %s", want)
+		}
+	}
+	runGo(t, root, "build", "./...")
+}
+
+// TestNamingConventionsBuild inits a project with each naming convention,
+// generates a domain laid out by it and one overriding it with the next
+// convention, and builds, vets and tests the result.
+func TestNamingConventionsBuild(t *testing.T) {
+	requireGo(t)
+	names := config.NamingNames()
+	for i, name := range names {
+		t.Run(name, func(t *testing.T) {
+			root := initTestProject(t, InitOptions{Naming: name})
+			if got := readFile(t, root, config.ManifestoFile); name != config.DefaultNaming && !strings.Contains(got, "naming: "+name) {
+				t.Errorf("%s doesn't record naming %s", config.ManifestoFile, name)
+			}
+
+			other := names[(i+1)%len(names)]
+			domains := []struct{ path, naming string }{
+				{"pkg/billing/invoice", name},
+				{"pkg/catalog/tag", other},
+			}
+			for _, d := range domains {
+				data := NewDomainData("example.com/shop", d.path).WithNaming(config.NamingRegistry[d.naming])
+				data.WithPolicy = true
+				data.WithTests = true
+				data = ResolveContainerNames(root, data)
+				if err := GenerateDomain(root, data); err != nil {
+					t.Fatalf("generate %s (%s): %v", d.path, d.naming, err)
+				}
+				if want := `"example.com/shop/` + data.ContainerPath + `"`; !strings.Contains(readFile(t, root, "cmd/container.go"), want) {
+					t.Errorf("cmd/container.go doesn't import %s", want)
+				}
+			}
+			runGo(t, root, "build", "./...")
+			runGo(t, root, "vet", "./...")
+			runGo(t, root, "test", "./pkg/billing/...", "./pkg/catalog/...")
+		})
+	}
+}
+
+func TestNameForms(t *testing.T) {
+	type forms struct {
+		words                           string // splitWords, joined by spaces
+		pascal, goName, camel           string
+		snake, kebab, upperSnake, title string
+	}
+	tests := []struct {
+		in   string
+		want forms
+	}{
+		{"api-key", forms{"api key", "ApiKey", "APIKey", "apiKey", "api_key", "api-key", "API_KEY", "API Key"}},
+		{"api_key", forms{"api key", "ApiKey", "APIKey", "apiKey", "api_key", "api-key", "API_KEY", "API Key"}},
+		{"apiKey", forms{"api key", "ApiKey", "APIKey", "apiKey", "api_key", "api-key", "API_KEY", "API Key"}},
+		{"APIKey", forms{"api key", "ApiKey", "APIKey", "apiKey", "api_key", "api-key", "API_KEY", "API Key"}},
+		{"user_id", forms{"user id", "UserId", "UserID", "userID", "user_id", "user-id", "USER_ID", "User ID"}},
+		{"line item", forms{"line item", "LineItem", "LineItem", "lineItem", "line_item", "line-item", "LINE_ITEM", "Line Item"}},
+		{"HTTPServer", forms{"http server", "HttpServer", "HTTPServer", "httpServer", "http_server", "http-server", "HTTP_SERVER", "HTTP Server"}},
+		{"invoice", forms{"invoice", "Invoice", "Invoice", "invoice", "invoice", "invoice", "INVOICE", "Invoice"}},
+		{"", forms{}},
+	}
+	for _, tt := range tests {
+		c := casings(tt.in)
+		got := forms{
+			words:      strings.Join(splitWords(tt.in), " "),
+			pascal:     toPascalCase(tt.in),
+			goName:     toGoName(tt.in),
+			camel:      c.Camel,
+			snake:      c.Snake,
+			kebab:      c.Kebab,
+			upperSnake: toUpperSnake(tt.in),
+			title:      toTitle(tt.in),
+		}
+		if got != tt.want {
+			t.Errorf("%q:\n got %+v\nwant %+v", tt.in, got, tt.want)
+		}
+		if c.Pascal != got.goName {
+			t.Errorf("%q: casings Pascal = %q, want the Go name %q", tt.in, c.Pascal, got.goName)
+		}
+		if got.words != toLabel(tt.in) {
+			t.Errorf("%q: toLabel = %q, want %q", tt.in, toLabel(tt.in), got.words)
+		}
+	}
+}
+
+// TestDomainGoNames checks the Go identifiers derived from a domain's
+// package name keep initialisms upper case.
+func TestDomainGoNames(t *testing.T) {
+	data := NewDomainData("example.com/shop", "pkg/api_key")
+	for _, tt := range []struct{ name, got, want string }{
+		{"EntityName", data.EntityName, "APIKey"},
+		{"ContainerField", data.ContainerField, "APIKey"},
+		{"PluralEntityName", data.PluralEntityName(), "APIKeys"},
+		{"CamelName", data.CamelName, "apiKey"},
+		{"TableName", data.TableName, "api_keys"},
+		{"KebabName", data.KebabName, "api-key"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if got := toPlural(casings("APIKey").Kebab); got != "api-keys" {
+		t.Errorf("plural of kebab APIKey = %q, want api-keys", got)
+	}
+}
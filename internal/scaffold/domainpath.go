@@ -0,0 +1,126 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/token"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// importedPackages are the names generated domain code imports packages
+// under. A domain package of the same name clashes with them in the
+// layers that import both.
+var importedPackages = []string{
+	"bson", "cmp", "codes", "context", "emptypb", "errors", "errx", "fiber", "grpc", "http",
+	"httptest", "io", "json", "kernel", "logx", "mongo", "options", "pq", "slices", "sort", "sql",
+	"sqlx", "status", "strings", "sync", "testing", "time", "timestamppb", "uuid",
+}
+
+// ValidateDomainPath reports whether domainPath can hold a domain laid
+// out by naming: every segment a lowercase Go identifier that isn't a
+// keyword, outside the directories of manifesto modules, with a package
+// name that clashes neither with what the generated code imports nor with
+// the domain's own layer packages. Errors name the offending segment and
+// suggest a fix where there is one.
+func ValidateDomainPath(domainPath string, naming config.Naming) error {
+	if domainPath == "" || path.IsAbs(domainPath) || path.Clean(domainPath) != domainPath {
+		return fmt.Errorf("invalid domain path %q: use a clean relative path like pkg/billing/invoice", domainPath)
+	}
+
+	segs := strings.Split(domainPath, "/")
+	for _, seg := range segs {
+		problem := domainSegmentProblem(seg)
+		if problem == "" {
+			continue
+		}
+		err := fmt.Errorf("invalid domain path %s: segment %q %s", domainPath, seg, problem)
+		fixed := make([]string, len(segs))
+		for i, s := range segs {
+			if fixed[i] = segmentSuggestion(s); fixed[i] == "" {
+				return err
+			}
+		}
+		return fmt.Errorf("%w; try %s", err, strings.Join(fixed, "/"))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.ModuleRegistry)) {
+		for _, p := range config.ModuleRegistry[name].Dirs() {
+			if domainPath == p || strings.HasPrefix(domainPath, p+"/") || strings.HasPrefix(p, domainPath+"/") {
+				return fmt.Errorf("domain path %s overlaps %s, the %s module's directory; pick a path outside it", domainPath, p, name)
+			}
+		}
+	}
+	if seg, _, _ := strings.Cut(domainPath, "/"); seg == "cmd" {
+		return fmt.Errorf("domain path %s is inside cmd/, which holds the project's main package; use pkg/%s", domainPath, strings.TrimPrefix(domainPath, "cmd/"))
+	}
+
+	pkg := path.Base(domainPath)
+	if slices.Contains(importedPackages, pkg) {
+		return fmt.Errorf("domain package %q clashes with the %s package the generated code imports; try %s", pkg, pkg, path.Join(path.Dir(domainPath), toPlural(pkg)))
+	}
+	layout := naming.Layout(pkg)
+	for _, dir := range []string{layout.Service, layout.Infra, layout.API, layout.GRPC, layout.Container, mocksDir} {
+		if dir != "" && layout.PackageName(dir) == pkg {
+			return fmt.Errorf("domain package %q clashes with its own %s/ layer package under %s naming; try %s", pkg, dir, naming.Name, path.Join(path.Dir(domainPath), toPlural(pkg)))
+		}
+	}
+	return nil
+}
+
+// domainSegmentProblem says why seg can't name a domain path directory
+// and package, or returns "" if it can.
+func domainSegmentProblem(seg string) string {
+	switch {
+	case seg == "main":
+		return "can't be a package: main packages can't be imported"
+	case seg == "vendor" || seg == "testdata":
+		return "is a directory the go command ignores"
+	case token.IsKeyword(seg):
+		return "is a Go keyword"
+	case !isLowerIdent(seg):
+		return "is not a lowercase Go identifier"
+	}
+	return ""
+}
+
+// isLowerIdent reports whether s is a lowercase ASCII letter followed by
+// lowercase letters, digits and underscores.
+func isLowerIdent(s string) bool {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return false
+	}
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentSuggestion returns seg, or a valid segment close to it: lowercased,
+// with runs of separators as one underscore, other characters and leading
+// digits dropped, and keywords pluralized. It returns "" when nothing
+// usable is left.
+func segmentSuggestion(seg string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(seg) {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9' && b.Len() > 0:
+			b.WriteRune(r)
+		case (r == '-' || r == '_' || r == ' ' || r == '.') && b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+	fixed := strings.TrimSuffix(b.String(), "_")
+	if token.IsKeyword(fixed) {
+		fixed = toPlural(fixed)
+	}
+	if domainSegmentProblem(fixed) != "" {
+		return ""
+	}
+	return fixed
+}
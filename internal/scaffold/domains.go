@@ -0,0 +1,32 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// DomainStatus is a tracked domain and the generated files it has lost.
+type DomainStatus struct {
+	config.DomainConfig
+	Entity  string
+	Missing []string // Generated files no longer on disk, relative to the project root
+}
+
+// TrackedDomains returns the domains recorded in manifest, sorted by path,
+// each checked for the files GenerateDomain would have created.
+func TrackedDomains(projectRoot string, manifest *config.Manifest) []DomainStatus {
+	statuses := make([]DomainStatus, 0, len(manifest.Domains))
+	for _, d := range manifest.Domains {
+		data := trackedDomainData(manifest, d)
+		status := DomainStatus{DomainConfig: d, Entity: data.EntityName}
+		for _, f := range DomainFiles(data) {
+			if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(f.Path))); err != nil {
+				status.Missing = append(status.Missing, f.Path)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,154 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// Dotenv files wiring writes with the dotenv and both env targets,
+// relative to the project root.
+const (
+	EnvExampleFile = ".env.example"
+	EnvFile        = ".env"
+)
+
+// makefileDotenv makes the Makefile load .env. It goes at the env-config
+// marker, after the defaults the Makefile exports, so .env overrides them.
+const makefileDotenv = `# Settings in .env override the defaults above
+-include .env
+export`
+
+// injectDotenv adds the module's variables to .env.example and those .env
+// doesn't set yet to .env, creating either file when missing, and makes
+// the Makefile load .env. In .env, variables a follow-up asks the user to
+// set are commented out, so the follow-up stays open until they do. It
+// returns the files it changed.
+func injectDotenv(fs FileStore, projectRoot string, spec config.WireableModule) ([]string, error) {
+	vars := spec.EnvVars()
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	var modified []string
+
+	examplePath := filepath.Join(projectRoot, EnvExampleFile)
+	example, err := readOptional(fs, examplePath)
+	if err != nil {
+		return nil, err
+	}
+	if dotenvKeys(example)[vars[0].Name] {
+		fs.Present(examplePath, fmt.Sprintf("%s already set", vars[0].Name))
+	} else {
+		block := dotenvBlock(spec, vars, nil)
+		if err := fs.WriteFile(examplePath, []byte(appendDotenv(example, block)), 0644); err != nil {
+			return nil, err
+		}
+		modified = append(modified, EnvExampleFile)
+	}
+
+	envPath := filepath.Join(projectRoot, EnvFile)
+	env, err := readOptional(fs, envPath)
+	if err != nil {
+		return nil, err
+	}
+	set := dotenvKeys(env)
+	var missing []config.EnvVar
+	for _, v := range vars {
+		if !set[v.Name] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		unset := make(map[string]bool)
+		for _, f := range spec.FollowUps {
+			if f.EnvVar != "" {
+				unset[f.EnvVar] = true
+			}
+		}
+		block := dotenvBlock(spec, missing, unset)
+		if err := fs.WriteFile(envPath, []byte(appendDotenv(env, block)), 0644); err != nil {
+			return nil, err
+		}
+		modified = append(modified, EnvFile)
+	}
+
+	if changed, err := includeDotenv(fs, projectRoot); err != nil {
+		return nil, err
+	} else if changed {
+		modified = append(modified, "Makefile")
+	}
+	return modified, nil
+}
+
+// includeDotenv adds makefileDotenv to the Makefile unless it loads .env
+// already, and reports whether it did.
+func includeDotenv(fs FileStore, projectRoot string) (bool, error) {
+	makefilePath := filepath.Join(projectRoot, "Makefile")
+	content, err := fs.ReadFile(makefilePath)
+	if err != nil {
+		return false, nil // Makefile might not exist
+	}
+	text := string(content)
+	if strings.Contains(text, "include .env") {
+		return false, nil
+	}
+	if !hasMarker(text, "# manifesto:env-config") {
+		fs.Skip(makefilePath, `marker "# manifesto:env-config" not found; add "-include .env" to load .env`)
+		return false, nil
+	}
+	text = replaceMarker(fs, makefilePath, text, "# manifesto:env-config", makefileDotenv+"\n\n# manifesto:env-config")
+	return true, fs.WriteFile(makefilePath, []byte(text), 0644)
+}
+
+// dotenvBlock renders vars under a comment naming the module's section.
+// Those in unset are written commented out.
+func dotenvBlock(spec config.WireableModule, vars []config.EnvVar, unset map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n", spec.Name, spec.EnvSection())
+	for _, v := range vars {
+		if v.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", v.Description)
+		}
+		if unset[v.Name] {
+			b.WriteString("# ")
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Name, v.Default)
+	}
+	return b.String()
+}
+
+// appendDotenv appends block to a dotenv file's content, a blank line
+// apart.
+func appendDotenv(content, block string) string {
+	if content == "" {
+		return block
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + block
+}
+
+// dotenvKeys returns the variables a dotenv file sets, commented-out
+// assignments included, so they aren't added twice.
+func dotenvKeys(content string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+		line = strings.TrimPrefix(line, "export ")
+		if name, _, ok := strings.Cut(line, "="); ok && !strings.ContainsAny(name, " \t") && name != "" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// readOptional returns the file's content, or "" when it doesn't exist.
+func readOptional(fs FileStore, path string) (string, error) {
+	content, err := fs.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	return string(content), err
+}
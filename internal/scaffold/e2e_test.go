@@ -0,0 +1,145 @@
+package scaffold
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+)
+
+// The tests here init projects from testdata/manifesto, a stand-in for
+// the manifesto repository copied in with --local, and build or test the
+// generated code with the go toolchain. go resolves the project's
+// requires through GOPROXY or the module cache like a user's build does.
+
+// requireGo skips t in -short mode or when there is no go on PATH.
+func requireGo(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a generated project; skipped with -short")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("no go toolchain on PATH")
+	}
+}
+
+// fakeManifesto returns the local ref of testdata/manifesto.
+func fakeManifesto(t *testing.T) string {
+	t.Helper()
+	ref, err := remote.LocalRef(filepath.Join("testdata", "manifesto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+// isolateUserConfig points the user config at an empty directory, so the
+// defaults in the developer's own config don't reach the test.
+func isolateUserConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(remote.DefaultRefEnv, "")
+}
+
+// initTestProject inits a project from the fake manifesto and returns its
+// root. Unset options are filled in by testInitOptions.
+func initTestProject(t *testing.T, opts InitOptions) string {
+	t.Helper()
+	isolateUserConfig(t)
+	opts = testInitOptions(t, opts)
+	if err := InitProject(opts); err != nil {
+		t.Fatalf("init %s: %v", opts.ProjectName, err)
+	}
+	return filepath.Join(opts.OutputDir, opts.ProjectName)
+}
+
+// testInitOptions fills in the unset options of an init from the fake
+// manifesto: a project named shop in a temporary directory, an
+// example.com module, the quick profile with its default modules and
+// wiring, and no tidy.
+func testInitOptions(t *testing.T, opts InitOptions) InitOptions {
+	t.Helper()
+	if opts.ProjectName == "" {
+		opts.ProjectName = "shop"
+	}
+	if opts.GoModule == "" {
+		opts.GoModule = "example.com/" + opts.ProjectName
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = t.TempDir()
+	}
+	if opts.Ref == "" {
+		opts.Ref = fakeManifesto(t)
+	}
+	if opts.Profile == "" {
+		opts.Profile = "quick"
+	}
+	opts.SkipTidy = true
+	profile, err := config.LookupProfile(opts.Profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Modules == nil {
+		if opts.Modules, err = profile.InitModules(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if opts.WireModules == nil {
+		opts.WireModules = profile.InitWire(profile.Wire)
+	}
+	return opts
+}
+
+// runGo runs go with args in dir, failing t with its output on error.
+// -mod=mod lets go add the requires the generated code needs.
+func runGo(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %v in %s: %v\n%s", args, dir, err, out)
+	}
+}
+
+// readFile returns the content of the file at path under root.
+func readFile(t *testing.T, root, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestDomainListPagination generates a domain and runs
+// testdata/pagination_test.go in its handler package: List must read
+// ?page=, ?page_size= and ?sort=, count the tenant's rows and query the
+// page, and reject a field it can't sort by before querying.
+func TestDomainListPagination(t *testing.T) {
+	requireGo(t)
+	root := initTestProject(t, InitOptions{})
+
+	data := NewDomainData("example.com/shop", "pkg/invoice")
+	data.WithTests = true
+	fields, err := ParseFields("number:string,amount:int,paid:bool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Fields = fields
+	if err := GenerateDomain(root, data); err != nil {
+		t.Fatal(err)
+	}
+
+	test, err := os.ReadFile(filepath.Join("testdata", "pagination_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "invoice", "invoiceapi", "pagination_test.go"), test, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, root, "test", "-run", "TestE2EListPagination", "-v", "./pkg/invoice/invoiceapi")
+}
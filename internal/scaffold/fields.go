@@ -0,0 +1,299 @@
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FieldSpec describes one entity field passed via --fields.
+type FieldSpec struct {
+	Name    string // Column name, e.g. "due_date"
+	Type    string // --fields type name, e.g. "time"
+	GoName  string // Struct field name, e.g. "DueDate"
+	GoType  string // e.g. "time.Time"
+	SQLType string // e.g. "TIMESTAMPTZ"
+	JSONTag string // e.g. "due_date"
+}
+
+type fieldType struct {
+	goType    string
+	sqlType   string
+	protoType string
+}
+
+// fieldTypes maps --fields type names to Go, Postgres and protobuf types.
+var fieldTypes = map[string]fieldType{
+	"string":  {"string", "TEXT", "string"},
+	"text":    {"string", "TEXT", "string"},
+	"int":     {"int", "INTEGER", "int64"},
+	"int64":   {"int64", "BIGINT", "int64"},
+	"float":   {"float64", "DOUBLE PRECISION", "double"},
+	"decimal": {"float64", "NUMERIC", "double"},
+	"bool":    {"bool", "BOOLEAN", "bool"},
+	"time":    {"time.Time", "TIMESTAMPTZ", "google.protobuf.Timestamp"},
+	"date":    {"time.Time", "DATE", "google.protobuf.Timestamp"},
+	"uuid":    {"string", "UUID", "string"},
+}
+
+// reservedFields are columns every generated entity already has.
+var reservedFields = map[string]bool{
+	"id": true, "tenant_id": true, "created_at": true, "updated_at": true,
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FieldTypeNames returns the supported --fields types, sorted.
+func FieldTypeNames() []string {
+	names := make([]string, 0, len(fieldTypes))
+	for name := range fieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFields parses a --fields value such as "amount:decimal,paid:bool".
+func ParseFields(spec string) ([]FieldSpec, error) {
+	var fields []FieldSpec
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, typ, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q: expected name:type", part)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		typ = strings.ToLower(strings.TrimSpace(typ))
+
+		if !fieldNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid field name %q: use lower_snake_case", name)
+		}
+		if reservedFields[name] {
+			return nil, fmt.Errorf("field %q is generated automatically", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate field %q", name)
+		}
+		seen[name] = true
+
+		ft, ok := fieldTypes[typ]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q for field %q. Supported: %s", typ, name, strings.Join(FieldTypeNames(), ", "))
+		}
+
+		fields = append(fields, FieldSpec{
+			Name:    name,
+			Type:    typ,
+			GoName:  toGoName(name),
+			GoType:  ft.goType,
+			SQLType: ft.sqlType,
+			JSONTag: name,
+		})
+	}
+
+	return fields, nil
+}
+
+// FieldsSpec formats the fields back into a --fields value.
+func (d DomainData) FieldsSpec() string {
+	parts := make([]string, len(d.Fields))
+	for i, f := range d.Fields {
+		parts[i] = f.Name + ":" + f.Type
+	}
+	return strings.Join(parts, ",")
+}
+
+// commonInitialisms are upper-cased in Go field names (customer_id → CustomerID).
+var commonInitialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "api": true, "http": true,
+	"json": true, "sql": true, "uuid": true, "ip": true,
+}
+
+func toGoName(s string) string {
+	var b strings.Builder
+	for _, w := range splitWords(s) {
+		if commonInitialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// ---------------------------------------------------------------------------
+// Protobuf helpers used by the gRPC templates
+// ---------------------------------------------------------------------------
+
+// ProtoType is the field's type in the .proto file.
+func (f FieldSpec) ProtoType() string {
+	return fieldTypes[f.Type].protoType
+}
+
+// ProtoMessage reports whether the field's proto type is a message, which
+// has presence without the optional keyword and is a pointer in Go.
+func (f FieldSpec) ProtoMessage() bool {
+	return strings.HasPrefix(f.ProtoType(), "google.protobuf.")
+}
+
+// PBName is the name protoc-gen-go gives the field (customer_id →
+// CustomerId), by the rules of its GoCamelCase: an underscore before a
+// lowercase letter is dropped and the letter capitalized, as is a letter
+// after a digit.
+func (f FieldSpec) PBName() string {
+	s := f.Name
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case '0' <= c && c <= '9':
+			b.WriteByte(c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b.WriteByte(c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b.WriteByte(s[i+1])
+			}
+		}
+	}
+	return b.String()
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+// ToProto converts the Go value expr of the field to its proto type.
+func (f FieldSpec) ToProto(expr string) string {
+	switch f.GoType {
+	case "int":
+		return "int64(" + expr + ")"
+	case "time.Time":
+		return "timestamppb.New(" + expr + ")"
+	}
+	return expr
+}
+
+// FromProto converts the proto value expr of the field to its Go type.
+func (f FieldSpec) FromProto(expr string) string {
+	switch f.GoType {
+	case "int":
+		return "int(" + expr + ")"
+	case "time.Time":
+		return expr + ".AsTime()"
+	}
+	return expr
+}
+
+// ProtoField is a field of a generated proto message with its number.
+type ProtoField struct {
+	FieldSpec
+	Number int
+}
+
+// ProtoFields numbers the custom fields for a proto message, from first.
+func (d DomainData) ProtoFields(first int) []ProtoField {
+	out := make([]ProtoField, len(d.Fields))
+	for i, f := range d.Fields {
+		out[i] = ProtoField{f, first + i}
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Sorting helpers used by List
+// ---------------------------------------------------------------------------
+
+// SortableFields returns the custom fields List can order by: all but
+// booleans.
+func (d DomainData) SortableFields() []FieldSpec {
+	var out []FieldSpec
+	for _, f := range d.Fields {
+		if f.GoType != "bool" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// SortFieldList is the fields List can order by, for messages, e.g.
+// "created_at, updated_at, amount".
+func (d DomainData) SortFieldList() string {
+	names := []string{"created_at", "updated_at"}
+	for _, f := range d.SortableFields() {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Compare is the Go expression comparing the field of entities a and b,
+// negative when a's sorts first.
+func (f FieldSpec) Compare(a, b string) string {
+	if f.GoType == "time.Time" {
+		return fmt.Sprintf("%s.%s.Compare(%s.%s)", a, f.GoName, b, f.GoName)
+	}
+	return fmt.Sprintf("cmp.Compare(%s.%s, %s.%s)", a, f.GoName, b, f.GoName)
+}
+
+// ---------------------------------------------------------------------------
+// SQL helpers used by the postgres template
+// ---------------------------------------------------------------------------
+
+// columns returns the table columns in the order used by INSERT and SELECT.
+func (d DomainData) columns() []string {
+	cols := []string{"id", "tenant_id"}
+	for _, f := range d.Fields {
+		cols = append(cols, f.Name)
+	}
+	return append(cols, "created_at", "updated_at")
+}
+
+// Columns is the comma-separated column list for INSERT and SELECT.
+func (d DomainData) Columns() string {
+	return strings.Join(d.columns(), ", ")
+}
+
+// InsertPlaceholders is "$1, $2, ..." matching Columns.
+func (d DomainData) InsertPlaceholders() string {
+	n := len(d.columns())
+	params := make([]string, n)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return strings.Join(params, ", ")
+}
+
+// UpdateAssignments is the SET clause of the UPDATE statement: every field
+// followed by updated_at, numbered from $1.
+func (d DomainData) UpdateAssignments() string {
+	var sets []string
+	for i, f := range d.Fields {
+		sets = append(sets, fmt.Sprintf("%s = $%d", f.Name, i+1))
+	}
+	sets = append(sets, fmt.Sprintf("updated_at = $%d", len(d.Fields)+1))
+	return strings.Join(sets, ", ")
+}
+
+// UpdateIDParam is the placeholder for id in the UPDATE's WHERE clause.
+func (d DomainData) UpdateIDParam() string {
+	return fmt.Sprintf("$%d", len(d.Fields)+2)
+}
+
+// MigrationColumns returns the CREATE TABLE lines for the custom fields.
+func (d DomainData) MigrationColumns() []string {
+	var lines []string
+	for _, f := range d.Fields {
+		lines = append(lines, fmt.Sprintf("%-10s %s NOT NULL,", f.Name, f.SQLType))
+	}
+	return lines
+}
@@ -0,0 +1,179 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// FileStore is the file access used by scaffolding and wiring. The disk
+// implementation writes through; Preview buffers writes in memory.
+type FileStore interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+
+	// Skip records that an injection into path could not be applied,
+	// e.g. because its marker is missing.
+	Skip(path, reason string)
+
+	// Present records that an injection into path was not needed because
+	// the code is already there.
+	Present(path, what string)
+}
+
+// diskStore reads and writes the real filesystem.
+type diskStore struct{}
+
+func (diskStore) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (diskStore) WriteFile(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+func (diskStore) Skip(path, reason string) {}
+
+func (diskStore) Present(path, what string) {}
+
+// Preview is an in-memory FileStore used for --dry-run, and by wiring to
+// write its edits all at once. Reads fall back to disk for files that have
+// not been written; nothing is persisted until Commit.
+type Preview struct {
+	root    string
+	pending map[string][]byte
+	skipped []string
+	present []string
+
+	committed []FileChange // Written by the last Commit, for Rollback
+}
+
+// FileChange is a single file a previewed operation would create or modify.
+type FileChange struct {
+	Path    string // Relative to the project root
+	Old     string
+	New     string
+	Created bool
+}
+
+func NewPreview(projectRoot string) *Preview {
+	return &Preview{
+		root:    projectRoot,
+		pending: make(map[string][]byte),
+	}
+}
+
+func (p *Preview) ReadFile(path string) ([]byte, error) {
+	if data, ok := p.pending[path]; ok {
+		return data, nil
+	}
+	return os.ReadFile(path)
+}
+
+func (p *Preview) WriteFile(path string, data []byte, perm os.FileMode) error {
+	p.pending[path] = data
+	return nil
+}
+
+func (p *Preview) Skip(path, reason string) {
+	msg := fmt.Sprintf("%s: %s", p.rel(path), reason)
+	if slices.Contains(p.skipped, msg) {
+		return
+	}
+	p.skipped = append(p.skipped, msg)
+}
+
+// Skipped returns the injections that would not be applied.
+func (p *Preview) Skipped() []string {
+	return p.skipped
+}
+
+func (p *Preview) Present(path, what string) {
+	msg := fmt.Sprintf("%s: %s", p.rel(path), what)
+	if !slices.Contains(p.present, msg) {
+		p.present = append(p.present, msg)
+	}
+}
+
+// AlreadyPresent returns the injections that were not needed because the
+// code is already in place.
+func (p *Preview) AlreadyPresent() []string {
+	return p.present
+}
+
+// Changes returns the files that would differ from disk, sorted by path.
+func (p *Preview) Changes() []FileChange {
+	var changes []FileChange
+	for path, data := range p.pending {
+		old, err := os.ReadFile(path)
+		created := os.IsNotExist(err)
+		if !created && string(old) == string(data) {
+			continue
+		}
+		changes = append(changes, FileChange{
+			Path:    p.rel(path),
+			Old:     string(old),
+			New:     string(data),
+			Created: created,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Commit writes the files that differ from disk and returns their paths,
+// as Changes does. If a write fails, the files already written are restored.
+func (p *Preview) Commit() ([]string, error) {
+	changes := p.Changes()
+	var written []string
+	for i, c := range changes {
+		path := filepath.Join(p.root, filepath.FromSlash(c.Path))
+		if err := (diskStore{}).WriteFile(path, []byte(c.New), 0644); err != nil {
+			err = fmt.Errorf("write %s: %w", c.Path, err)
+			return nil, errors.Join(err, p.restore(changes[:i]))
+		}
+		written = append(written, c.Path)
+	}
+	p.committed = changes
+	return written, nil
+}
+
+// Rollback puts back the files the last Commit wrote, for when a step
+// after it fails.
+func (p *Preview) Rollback() error {
+	err := p.restore(p.committed)
+	p.committed = nil
+	return err
+}
+
+// restore puts back what each of changes replaced: the old content, or no
+// file at all.
+func (p *Preview) restore(changes []FileChange) error {
+	var errs []error
+	for _, c := range changes {
+		path := filepath.Join(p.root, filepath.FromSlash(c.Path))
+		var err error
+		if c.Created {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, []byte(c.Old), 0644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", c.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Preview) rel(path string) string {
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
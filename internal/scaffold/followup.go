@@ -0,0 +1,242 @@
+package scaffold
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// TodoFile is the follow-up checklist, relative to the project root. It is
+// rewritten by every command that changes the project.
+const TodoFile = ".manifesto/TODO.md"
+
+// ChecklistItem is a follow-up step, tagged with the module or domain that
+// left it.
+type ChecklistItem struct {
+	Source string `json:"source"`
+	Text   string `json:"text"`
+	Done   bool   `json:"done"`
+}
+
+// FollowUps returns the steps left to the user after scaffolding d.
+func (d DomainData) FollowUps() []config.FollowUp {
+	var items []config.FollowUp
+	infra := d.Generates(DomainLayerInfra)
+	if len(d.Fields) == 0 {
+		items = append(items, config.FollowUp{Text: fmt.Sprintf("Add fields to %s/%s.go", d.DomainPath, d.PackageName)})
+		if infra && d.Repo.Migration {
+			items = append(items, config.FollowUp{
+				Text: fmt.Sprintf("Update the SQL in %s/%sinfra/%s to match your fields", d.DomainPath, d.PackageName, d.Repo.File),
+			})
+		}
+	}
+	if infra && d.Repo.Migration {
+		items = append(items, config.FollowUp{
+			Text:  fmt.Sprintf("Create a migration for the %s table", d.TableName),
+			Table: d.TableName,
+		})
+	}
+	if infra && d.Repo.NextStep != "" {
+		items = append(items, config.FollowUp{Text: d.Repo.NextStep})
+	}
+	if d.HasGRPC() {
+		items = append(items, config.FollowUp{
+			Text: fmt.Sprintf("Generate the gRPC code from %s with make proto (needs protoc, protoc-gen-go and protoc-gen-go-grpc)", d.ProtoFile()),
+			File: d.ProtoGoFile(),
+		})
+	}
+	return items
+}
+
+// CheckFollowUps ticks off the follow-ups from source that are verifiably
+// done in projectRoot, or were ticked by hand in TodoFile.
+func CheckFollowUps(projectRoot, source string, followUps []config.FollowUp) []ChecklistItem {
+	return newFollowUpChecker(projectRoot).check(source, followUps)
+}
+
+// ProjectChecklist collects the follow-ups of every wired module and
+// tracked domain in manifest, checked against projectRoot.
+func ProjectChecklist(projectRoot string, manifest *config.Manifest) []ChecklistItem {
+	c := newFollowUpChecker(projectRoot)
+
+	var items []ChecklistItem
+	for _, name := range manifest.WiredModules {
+		spec, _ := manifest.WiredSpec(name)
+		items = append(items, c.check(name, spec.FollowUps)...)
+	}
+	for _, d := range manifest.Domains {
+		items = append(items, c.check(d.Path, trackedDomainData(manifest, d).FollowUps())...)
+	}
+	return items
+}
+
+// trackedDomainData rebuilds enough of a tracked domain's data to derive
+// its follow-ups and file list. An unknown backend leaves the zero
+// RepoBackend, and an unknown naming convention the default one.
+func trackedDomainData(manifest *config.Manifest, d config.DomainConfig) DomainData {
+	data := NewDomainData(manifest.Project.GoModule, d.Path)
+	if naming, err := manifest.DomainNaming(d); err == nil {
+		data = data.WithNaming(naming)
+	}
+	data.Fields, _ = ParseFields(d.Fields)
+	repo := d.Repo
+	if repo == "" {
+		repo = DefaultRepoBackend
+	}
+	data.Repo, _ = LookupRepoBackend(repo)
+	data.Kind = d.Kind
+	data.Transport = cmp.Or(d.Transport, TransportHTTP)
+	data.WithPolicy = d.WithPolicy
+	data.WithEvents = d.WithEvents
+	data.HasJobx = manifest.IsWired("jobx")
+	data.WithUploads = d.WithUploads
+	data.HasSwagger = manifest.IsWired("swagger")
+	data.WithTests = !d.NoTests
+	data.WithMocks = !d.NoMocks
+	data.Layers = d.Layers
+	data.TableName = TrackedTable(d)
+	return data
+}
+
+// WriteTodo rewrites TodoFile with items grouped by source. With no items
+// the file is removed.
+func WriteTodo(projectRoot string, items []ChecklistItem) error {
+	path := filepath.Join(projectRoot, filepath.FromSlash(TodoFile))
+	if len(items) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("# Follow-ups\n\n")
+	b.WriteString("Generated by manifesto and refreshed on every command. Items it can\n")
+	b.WriteString("verify are ticked automatically; tick the others yourself and they stay\n")
+	b.WriteString("ticked.\n")
+	source := ""
+	for i, item := range items {
+		if i == 0 || item.Source != source {
+			source = item.Source
+			fmt.Fprintf(&b, "\n## %s\n\n", source)
+		}
+		box := " "
+		if item.Done {
+			box = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] %s\n", box, item.Text)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// followUpChecker holds what follow-ups are checked against, read once.
+type followUpChecker struct {
+	root       string
+	env        map[string]string // Variables set in .env
+	migrations string            // Every migrations/*.sql file, concatenated
+	ticked     map[string]bool   // source + "\x00" + text, ticked in TodoFile
+}
+
+func newFollowUpChecker(projectRoot string) *followUpChecker {
+	c := &followUpChecker{
+		root:   projectRoot,
+		env:    readEnvFile(filepath.Join(projectRoot, ".env")),
+		ticked: readTicked(filepath.Join(projectRoot, filepath.FromSlash(TodoFile))),
+	}
+	files, _ := filepath.Glob(filepath.Join(projectRoot, "migrations", "*.sql"))
+	var sql strings.Builder
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err == nil {
+			sql.Write(data)
+			sql.WriteByte('\n')
+		}
+	}
+	c.migrations = sql.String()
+	return c
+}
+
+func (c *followUpChecker) check(source string, followUps []config.FollowUp) []ChecklistItem {
+	items := make([]ChecklistItem, 0, len(followUps))
+	for _, f := range followUps {
+		items = append(items, ChecklistItem{Source: source, Text: f.Text, Done: c.done(source, f)})
+	}
+	return items
+}
+
+// done verifies f when it can; otherwise it is done once ticked by hand.
+func (c *followUpChecker) done(source string, f config.FollowUp) bool {
+	switch {
+	case f.EnvVar != "":
+		return c.env[f.EnvVar] != ""
+	case f.Table != "":
+		re := regexp.MustCompile(`(?i)create\s+table\s+(if\s+not\s+exists\s+)?("?\w+"?\.)?"?` + regexp.QuoteMeta(f.Table) + `"?[\s(]`)
+		return re.MatchString(c.migrations)
+	case f.File != "":
+		_, err := os.Stat(filepath.Join(c.root, filepath.FromSlash(f.File)))
+		return err == nil
+	}
+	return c.ticked[source+"\x00"+f.Text]
+}
+
+// readEnvFile returns the KEY=value assignments in a dotenv file, with
+// optional "export " prefixes and quotes removed.
+func readEnvFile(path string) map[string]string {
+	env := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return env
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		env[strings.TrimSpace(key)] = value
+	}
+	return env
+}
+
+// readTicked returns the items ticked in an existing TodoFile.
+func readTicked(path string) map[string]bool {
+	ticked := make(map[string]bool)
+	f, err := os.Open(path)
+	if err != nil {
+		return ticked
+	}
+	defer f.Close()
+
+	source := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if s, ok := strings.CutPrefix(line, "## "); ok {
+			source = strings.TrimSpace(s)
+			continue
+		}
+		if text, ok := strings.CutPrefix(line, "- [x] "); ok {
+			ticked[source+"\x00"+strings.TrimSpace(text)] = true
+		} else if text, ok := strings.CutPrefix(line, "- [X] "); ok {
+			ticked[source+"\x00"+strings.TrimSpace(text)] = true
+		}
+	}
+	return ticked
+}
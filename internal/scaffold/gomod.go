@@ -0,0 +1,252 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pruneGoMod drops the requires of the project's go.mod, copied from
+// manifesto's, that none of the project's Go files import, so a project
+// with a few modules doesn't download everything manifesto uses. Indirect
+// requires the remaining ones need are kept.
+func pruneGoMod(projectRoot string) error {
+	path := filepath.Join(projectRoot, "go.mod")
+	goMod, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	imports, err := goImports(projectRoot)
+	if err != nil {
+		return err
+	}
+	pruned, err := pruneRequires(string(goMod), imports, cachedGoMod)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(pruned), 0644)
+}
+
+// providesImport reports whether the Go module module provides the package
+// imp.
+func providesImport(module, imp string) bool {
+	return imp == module || strings.HasPrefix(imp, module+"/")
+}
+
+// goImports returns the import paths of the Go files under root, sorted.
+// Hidden directories, vendor and testdata are skipped.
+func goImports(root string) ([]string, error) {
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, imp := range f.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+				seen[p] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(seen)), nil
+}
+
+// pruneRequires returns goMod with only the requires of modules that
+// provide one of imports, at the versions it has, and the indirect ones
+// those need; see neededModules. A require block left without requires
+// is dropped, and blank lines at the end of one; every other line is kept
+// as it is.
+func pruneRequires(goMod string, imports []string, modFile func(module, version string) ([]byte, error)) (string, error) {
+	reqs, err := requires(goMod)
+	if err != nil {
+		return "", err
+	}
+	needed := neededModules(reqs, imports, modFile)
+	keepRequire := func(text string) bool {
+		req, ok, _ := parseRequire(text)
+		return !ok || needed[req.module]
+	}
+
+	var out, block []string
+	inBlock, blockKept := false, false
+	for _, line := range strings.Split(goMod, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock && trimmed == ")":
+			inBlock = false
+			for len(block) > 0 && strings.TrimSpace(block[len(block)-1]) == "" {
+				block = block[:len(block)-1]
+			}
+			if blockKept {
+				out = append(out, "require (")
+				out = append(out, block...)
+				out = append(out, line)
+			}
+			block, blockKept = nil, false
+		case inBlock:
+			if keepRequire(trimmed) {
+				block = append(block, line)
+				blockKept = blockKept || trimmed != "" && !strings.HasPrefix(trimmed, "//")
+			}
+		case trimmed == "require (":
+			inBlock = true
+		case strings.HasPrefix(trimmed, "require "):
+			if keepRequire(strings.TrimPrefix(trimmed, "require ")) {
+				out = append(out, line)
+			}
+		case trimmed == "" && len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "":
+			// The blank line after a dropped require.
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// neededModules returns the modules of reqs a project importing imports
+// needs: the direct requires that provide one of them, and the requires
+// their go.mod files, read with modFile, list in turn. If one of those
+// go.mod files can't be read, every indirect require is kept, as there is
+// no telling which of them the direct ones need.
+func neededModules(reqs []require, imports []string, modFile func(module, version string) ([]byte, error)) map[string]bool {
+	listed := make(map[string]require, len(reqs))
+	needed := make(map[string]bool)
+	var queue []require
+	for _, req := range reqs {
+		listed[req.module] = req
+		if !req.indirect && slices.ContainsFunc(imports, func(imp string) bool { return providesImport(req.module, imp) }) {
+			needed[req.module] = true
+			queue = append(queue, req)
+		}
+	}
+
+	for len(queue) > 0 {
+		req := queue[0]
+		queue = queue[1:]
+		data, err := modFile(req.module, req.version)
+		var deps []require
+		if err == nil {
+			deps, err = requires(string(data))
+		}
+		if err != nil {
+			for _, r := range reqs {
+				if r.indirect {
+					needed[r.module] = true
+				}
+			}
+			return needed
+		}
+		for _, dep := range deps {
+			if r, ok := listed[dep.module]; ok && !needed[dep.module] {
+				needed[dep.module] = true
+				queue = append(queue, r)
+			}
+		}
+	}
+	return needed
+}
+
+// require is one module a go.mod requires.
+type require struct {
+	module, version string
+	indirect        bool
+}
+
+// requires returns the requires of goMod, on their own line or in blocks.
+func requires(goMod string) ([]require, error) {
+	var reqs []require
+	inBlock := false
+	for _, line := range strings.Split(goMod, "\n") {
+		trimmed := strings.TrimSpace(line)
+		var text string
+		switch {
+		case inBlock && trimmed == ")":
+			inBlock = false
+			continue
+		case inBlock:
+			text = trimmed
+		case trimmed == "require (":
+			inBlock = true
+			continue
+		case strings.HasPrefix(trimmed, "require "):
+			text = strings.TrimPrefix(trimmed, "require ")
+		default:
+			continue
+		}
+		req, ok, err := parseRequire(text)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			reqs = append(reqs, req)
+		}
+	}
+	if inBlock {
+		return nil, fmt.Errorf("go.mod has an unterminated require block")
+	}
+	return reqs, nil
+}
+
+// parseRequire parses the require "module version [// comment]". Blank and
+// comment lines aren't requires.
+func parseRequire(text string) (require, bool, error) {
+	if text == "" || strings.HasPrefix(text, "//") {
+		return require{}, false, nil
+	}
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return require{}, false, fmt.Errorf("go.mod has a malformed require: %q", text)
+	}
+	module, err := strconv.Unquote(fields[0])
+	if err != nil {
+		module = fields[0]
+	}
+	return require{module: module, version: fields[1], indirect: strings.Contains(text, "// indirect")}, true, nil
+}
+
+// cachedGoMod returns the go.mod of module at version from the module
+// cache, where go keeps those of every module it has resolved.
+func cachedGoMod(module, version string) ([]byte, error) {
+	cache := os.Getenv("GOMODCACHE")
+	if cache == "" {
+		gopath, _, _ := strings.Cut(build.Default.GOPATH, string(filepath.ListSeparator))
+		cache = filepath.Join(gopath, "pkg", "mod")
+	}
+	// Upper-case letters are escaped as ! and the lower-case letter.
+	var escaped strings.Builder
+	for _, r := range module {
+		if unicode.IsUpper(r) {
+			escaped.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		escaped.WriteRune(r)
+	}
+	return os.ReadFile(filepath.Join(cache, "cache", "download", filepath.FromSlash(escaped.String()), "@v", version+".mod"))
+}
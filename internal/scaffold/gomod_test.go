@@ -0,0 +1,144 @@
+package scaffold
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, keyed by slash path, under root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestPruneRequires prunes a go.mod with requires on their own line and in
+// blocks, separated by blank lines and comments.
+func TestPruneRequires(t *testing.T) {
+	const goMod = `module github.com/Abraxas-365/manifesto
+
+go 1.24
+
+require github.com/google/uuid v1.6.0
+
+require github.com/spf13/cobra v1.8.1
+
+require (
+	// Storage
+	github.com/aws/aws-sdk-go-v2/service/s3 v1.58.0
+)
+
+require (
+	// HTTP
+	github.com/gofiber/fiber/v2 v2.52.5
+	github.com/lib/pq v1.10.9
+
+	github.com/redis/go-redis/v9 v9.7.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/valyala/fasthttp v1.51.0 // indirect
+)
+`
+	imports := []string{"github.com/google/uuid", "github.com/gofiber/fiber/v2", "github.com/gofiber/fiber/v2/middleware/cors"}
+	goMods := map[string]string{
+		"github.com/google/uuid@v1.6.0":               "module github.com/google/uuid\n",
+		"github.com/gofiber/fiber/v2@v2.52.5":         "module github.com/gofiber/fiber/v2\n\nrequire github.com/valyala/fasthttp v1.50.0\n",
+		"github.com/valyala/fasthttp@v1.51.0":         "module github.com/valyala/fasthttp\n\nrequire (\n\tgithub.com/andybalholm/brotli v1.0.5\n)\n",
+		"github.com/andybalholm/brotli@v1.1.0":        "module github.com/andybalholm/brotli\n",
+		"github.com/spf13/cobra@v1.8.1":               "module github.com/spf13/cobra\n\nrequire github.com/inconshreveable/mousetrap v1.1.0\n",
+		"github.com/redis/go-redis/v9@v9.7.0":         "module github.com/redis/go-redis/v9\n\nrequire github.com/cespare/xxhash/v2 v2.2.0\n",
+		"github.com/cespare/xxhash/v2@v2.3.0":         "module github.com/cespare/xxhash/v2\n",
+		"github.com/lib/pq@v1.10.9":                   "module github.com/lib/pq\n",
+		"github.com/inconshreveable/mousetrap@v1.1.0": "module github.com/inconshreveable/mousetrap\n",
+	}
+	modFile := func(module, version string) ([]byte, error) {
+		data, ok := goMods[module+"@"+version]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(data), nil
+	}
+
+	t.Run("reachable", func(t *testing.T) {
+		got, err := pruneRequires(goMod, imports, modFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `module github.com/Abraxas-365/manifesto
+
+go 1.24
+
+require github.com/google/uuid v1.6.0
+
+require (
+	// HTTP
+	github.com/gofiber/fiber/v2 v2.52.5
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/valyala/fasthttp v1.51.0 // indirect
+)
+`
+		if got != want {
+			t.Errorf("pruned go.mod:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("go.mod missing", func(t *testing.T) {
+		delete(goMods, "github.com/valyala/fasthttp@v1.51.0")
+		got, err := pruneRequires(goMod, imports, modFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, indirect := range []string{"brotli", "xxhash", "mousetrap", "fasthttp"} {
+			if !strings.Contains(got, indirect) {
+				t.Errorf("dropped the indirect require of %s though fasthttp's go.mod is unknown:\n%s", indirect, got)
+			}
+		}
+		for _, direct := range []string{"cobra", "lib/pq", "go-redis", "aws-sdk-go-v2", "Storage"} {
+			if strings.Contains(got, direct) {
+				t.Errorf("kept the require of %s, which nothing imports:\n%s", direct, got)
+			}
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, bad := range []string{"module m\n\nrequire (\n\tgithub.com/lib/pq\n)\n", "module m\n\nrequire (\n\tgithub.com/lib/pq v1.10.9\n"} {
+			if _, err := pruneRequires(bad, imports, modFile); err == nil {
+				t.Errorf("pruned %q", bad)
+			}
+		}
+	})
+}
+
+// TestCachedGoMod reads a go.mod from a module cache, where upper-case
+// letters in module paths are escaped.
+func TestCachedGoMod(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+	writeFiles(t, cache, map[string]string{
+		"cache/download/github.com/!abraxas-365/manifesto/@v/v1.2.0.mod": "module github.com/Abraxas-365/manifesto\n",
+	})
+	data, err := cachedGoMod("github.com/Abraxas-365/manifesto", "v1.2.0")
+	if err != nil || string(data) != "module github.com/Abraxas-365/manifesto\n" {
+		t.Errorf("cachedGoMod = %q, %v", data, err)
+	}
+	if _, err := cachedGoMod("github.com/Abraxas-365/manifesto", "v1.3.0"); err == nil {
+		t.Error("read the go.mod of a version not in the cache")
+	}
+}
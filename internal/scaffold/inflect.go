@@ -0,0 +1,125 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// uncountables are words that are their own plural.
+var uncountables = map[string]bool{
+	"aircraft": true, "analytics": true, "audio": true, "baggage": true, "data": true,
+	"deer": true, "equipment": true, "evidence": true, "feedback": true, "firmware": true,
+	"fish": true, "furniture": true, "hardware": true, "information": true, "knowledge": true,
+	"luggage": true, "metadata": true, "money": true, "moose": true, "music": true,
+	"news": true, "offspring": true, "police": true, "research": true, "rice": true,
+	"series": true, "sheep": true, "software": true, "species": true, "staff": true,
+	"traffic": true, "weather": true,
+}
+
+// irregularPlurals are plurals no suffix rule produces, including the
+// Latin and Greek ones (-us → -i, -um → -a, -on → -a, -ix → -ices) that
+// apply only to some words.
+var irregularPlurals = map[string]string{
+	"alumnus": "alumni", "appendix": "appendices", "cactus": "cacti", "child": "children",
+	"criterion": "criteria", "curriculum": "curricula", "datum": "data", "echo": "echoes",
+	"focus": "foci", "foot": "feet", "fungus": "fungi", "goose": "geese",
+	"hero": "heroes", "man": "men", "matrix": "matrices", "medium": "media",
+	"mouse": "mice", "nucleus": "nuclei", "ox": "oxen", "person": "people",
+	"phenomenon": "phenomena", "potato": "potatoes", "quiz": "quizzes", "radius": "radii",
+	"stimulus": "stimuli", "syllabus": "syllabi", "thief": "thieves", "tomato": "tomatoes",
+	"tooth": "teeth", "vertex": "vertices", "veto": "vetoes", "woman": "women",
+}
+
+// toPlural returns the plural of s. Only the last word of a snake_case,
+// kebab-case or PascalCase name is pluralized (line_item → line_items,
+// SalesPerson → SalesPeople), keeping its case.
+func toPlural(s string) string {
+	i := strings.LastIndexAny(s, "_-") + 1
+	if s != strings.ToUpper(s) {
+		for j := len(s) - 1; j > i; j-- {
+			if unicode.IsUpper(rune(s[j])) {
+				i = j
+				break
+			}
+		}
+	}
+	prefix, word := s[:i], s[i:]
+	if word == "" {
+		return s
+	}
+
+	// Keep the case of the part the plural shares with the word.
+	lower := strings.ToLower(word)
+	plural := pluralWord(lower)
+	n := 0
+	for n < len(lower) && n < len(plural) && lower[n] == plural[n] {
+		n++
+	}
+	return prefix + word[:n] + plural[n:]
+}
+
+// pluralWord returns the plural of a lowercase word.
+func pluralWord(w string) string {
+	if uncountables[w] {
+		return w
+	}
+	if p, ok := irregularPlurals[w]; ok {
+		return p
+	}
+	switch {
+	case strings.HasSuffix(w, "is"):
+		// analysis → analyses, axis → axes
+		return strings.TrimSuffix(w, "is") + "es"
+	case strings.HasSuffix(w, "fe") && w != "cafe" && w != "safe":
+		// knife → knives, life → lives
+		return strings.TrimSuffix(w, "fe") + "ves"
+	case strings.HasSuffix(w, "lf") || strings.HasSuffix(w, "eaf") || strings.HasSuffix(w, "oaf") || strings.HasSuffix(w, "arf"):
+		// shelf → shelves, leaf → leaves; roof, chief and belief take -s
+		return strings.TrimSuffix(w, "f") + "ves"
+	case strings.HasSuffix(w, "y") && len(w) > 1 && !strings.ContainsRune("aeiou", rune(w[len(w)-2])):
+		// category → categories; day → days
+		return strings.TrimSuffix(w, "y") + "ies"
+	case strings.HasSuffix(w, "s") || strings.HasSuffix(w, "x") || strings.HasSuffix(w, "z") ||
+		strings.HasSuffix(w, "ch") || strings.HasSuffix(w, "sh"):
+		// status → statuses, box → boxes, batch → batches
+		return w + "es"
+	}
+	return w + "s"
+}
+
+// legacyPlural is how table names were derived before toPlural: -s gets
+// -es, any -y -ies, and the rest -s. Tracked domains without a recorded
+// table were scaffolded with it, and keep it so their tables and routes
+// don't move.
+func legacyPlural(s string) string {
+	if strings.HasSuffix(s, "s") {
+		return s + "es"
+	}
+	if strings.HasSuffix(s, "y") && len(s) > 1 {
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// TrackedTable returns the table name of a tracked domain: the recorded
+// one, or for domains added before tables were recorded, the one they were
+// scaffolded with.
+func TrackedTable(d config.DomainConfig) string {
+	if d.Table != "" {
+		return d.Table
+	}
+	return legacyPlural(path.Base(d.Path))
+}
+
+// CheckTableName reports whether name can be a domain's table and route
+// name: a lowercase snake_case identifier.
+func CheckTableName(name string) error {
+	if !isLowerIdent(name) {
+		return fmt.Errorf("invalid table name %q: use lowercase letters, digits and underscores, starting with a letter", name)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package scaffold
+
+import "testing"
+
+func TestToPlural(t *testing.T) {
+	tests := []struct{ in, want string }{
+		// Irregulars
+		{"person", "people"},
+		{"child", "children"},
+		{"criterion", "criteria"},
+		{"cactus", "cacti"},
+		{"matrix", "matrices"},
+		{"hero", "heroes"},
+
+		// Uncountables
+		{"equipment", "equipment"},
+		{"metadata", "metadata"},
+		{"series", "series"},
+		{"sheep", "sheep"},
+
+		// -y
+		{"category", "categories"},
+		{"company", "companies"},
+		{"day", "days"},
+		{"key", "keys"},
+
+		// -s, -x, -z, -ch, -sh
+		{"status", "statuses"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"tax", "taxes"},
+		{"batch", "batches"},
+		{"wish", "wishes"},
+
+		// -is
+		{"analysis", "analyses"},
+		{"axis", "axes"},
+
+		// -f, -fe
+		{"knife", "knives"},
+		{"shelf", "shelves"},
+		{"leaf", "leaves"},
+		{"roof", "roofs"},
+		{"safe", "safes"},
+
+		// Regular
+		{"invoice", "invoices"},
+		{"order", "orders"},
+
+		// Only the last word, keeping its case
+		{"line_item", "line_items"},
+		{"sales_person", "sales_people"},
+		{"order-status", "order-statuses"},
+		{"APIKey", "APIKeys"},
+		{"SalesPerson", "SalesPeople"},
+		{"HTTPStatus", "HTTPStatuses"},
+		{"Category", "Categories"},
+		{"Person", "People"},
+		{"API", "APIs"},
+		{"user_API", "user_APIs"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := toPlural(tt.in); got != tt.want {
+			t.Errorf("toPlural(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
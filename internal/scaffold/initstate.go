@@ -0,0 +1,98 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InitStateFile marks a project whose init hasn't finished. It is written
+// right after the project directory is created and removed on success, so
+// an interrupted init can be resumed instead of started over.
+const InitStateFile = ".manifesto-init.yaml"
+
+// Init steps recorded in InitState.Completed. Wiring steps are recorded as
+// "wire:<module>".
+const (
+	stepFetch    = "fetch"
+	stepFiles    = "files"
+	stepGoMod    = "go.mod"
+	stepManifest = "manifest"
+)
+
+// InitState records the options an init was started with and the steps it
+// completed.
+type InitState struct {
+	ProjectName string    `yaml:"project_name"`
+	GoModule    string    `yaml:"go_module"`
+	Ref         string    `yaml:"ref"`
+	RefType     string    `yaml:"ref_type,omitempty"`
+	Channel     string    `yaml:"channel,omitempty"`
+	Repo        string    `yaml:"repo,omitempty"`
+	Profile     string    `yaml:"profile,omitempty"`
+	Naming      string    `yaml:"naming,omitempty"`
+	GRPC        bool      `yaml:"grpc,omitempty"`
+	Tests       bool      `yaml:"tests,omitempty"`
+	EnvTarget   string    `yaml:"env_target,omitempty"`
+	Modules     []string  `yaml:"modules"`
+	WireModules []string  `yaml:"wire_modules,omitempty"`
+	Completed   []string  `yaml:"completed,omitempty"`
+	Existing    bool      `yaml:"existing,omitempty"` // Directory existed before init, with --force or --merge
+	StartedAt   time.Time `yaml:"started_at"`
+
+	root string
+}
+
+// LoadInitState reads the in-progress marker from projectRoot. It returns
+// nil and no error when there is none.
+func LoadInitState(projectRoot string) (*InitState, error) {
+	data, err := os.ReadFile(filepath.Join(projectRoot, InitStateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s InitState
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", InitStateFile, err)
+	}
+	s.root = projectRoot
+	return &s, nil
+}
+
+// LastCompleted returns the most recently completed step, or "" if none.
+func (s *InitState) LastCompleted() string {
+	if len(s.Completed) == 0 {
+		return ""
+	}
+	return s.Completed[len(s.Completed)-1]
+}
+
+func (s *InitState) done(step string) bool {
+	return slices.Contains(s.Completed, step)
+}
+
+// complete records step and persists the state.
+func (s *InitState) complete(step string) error {
+	if !s.done(step) {
+		s.Completed = append(s.Completed, step)
+	}
+	return s.save()
+}
+
+func (s *InitState) save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", InitStateFile, err)
+	}
+	return os.WriteFile(filepath.Join(s.root, InitStateFile), data, 0644)
+}
+
+func (s *InitState) remove() error {
+	return os.Remove(filepath.Join(s.root, InitStateFile))
+}
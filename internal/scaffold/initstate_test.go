@@ -0,0 +1,77 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// TestInitResume interrupts an init right after its first step, by making
+// the second fail on a broken template override, and resumes it: the
+// project must come out complete without the download being redone.
+func TestInitResume(t *testing.T) {
+	requireGo(t)
+	isolateUserConfig(t)
+	dir, err := config.UserTemplatesDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "project", "container.go.tmpl")
+	if err := os.MkdirAll(filepath.Dir(broken), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(broken, []byte("{{ .Broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testInitOptions(t, InitOptions{})
+	root := filepath.Join(opts.OutputDir, opts.ProjectName)
+	if err := InitProject(opts); err == nil {
+		t.Fatal("init succeeded with a broken cmd/container.go template")
+	}
+	state, err := LoadInitState(root)
+	if err != nil || state == nil {
+		t.Fatalf("no %s after the interrupted init: %v", InitStateFile, err)
+	}
+	if !slices.Equal(state.Completed, []string{stepFetch}) {
+		t.Fatalf("completed steps = %v, want [%s]", state.Completed, stepFetch)
+	}
+
+	// A fetch that is redone overwrites the marked file.
+	kernel := filepath.Join(root, "pkg", "kernel", "kernel.go")
+	data, err := os.ReadFile(kernel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kernel, append(data, "\n// fetched once\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(broken); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitProject(InitOptions{ProjectName: opts.ProjectName, OutputDir: opts.OutputDir, SkipTidy: true, Resume: true}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	if !strings.Contains(readFile(t, root, "pkg/kernel/kernel.go"), "// fetched once") {
+		t.Error("resume downloaded the modules again")
+	}
+	if _, err := os.Stat(filepath.Join(root, InitStateFile)); !os.IsNotExist(err) {
+		t.Errorf("%s left behind after the resumed init", InitStateFile)
+	}
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range opts.WireModules {
+		if !manifest.IsWired(name) {
+			t.Errorf("%s not wired by the resumed init", name)
+		}
+	}
+	runGo(t, root, "build", "./...")
+}
@@ -0,0 +1,112 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// JobData is the template context for a jobx job handler.
+type JobData struct {
+	GoModule   string
+	Name       string // As given, e.g. send-invoice
+	TypeName   string // e.g. SendInvoice
+	DomainPath string // Domain the job belongs to, e.g. pkg/billing/invoice
+	Package    string // e.g. invoicejobs
+	JobName    string // Name the job is enqueued under, e.g. invoice:send_invoice
+}
+
+// NewJobData returns the data for the job name in the domain at
+// domainPath, or an error when name isn't lowercase words joined by
+// dashes.
+func NewJobData(goModule, domainPath, name string) (JobData, error) {
+	if !cronJobNameRe.MatchString(name) {
+		return JobData{}, fmt.Errorf("invalid job name %q: use lowercase words joined by -, like send-invoice", name)
+	}
+	if domainPath == "" || path.IsAbs(domainPath) || path.Clean(domainPath) != domainPath {
+		return JobData{}, fmt.Errorf("invalid domain path %q: use a clean relative path like pkg/billing/invoice", domainPath)
+	}
+	pkg := path.Base(domainPath)
+	return JobData{
+		GoModule:   goModule,
+		Name:       name,
+		TypeName:   toGoName(name),
+		DomainPath: domainPath,
+		Package:    pkg + "jobs",
+		JobName:    pkg + ":" + strings.ReplaceAll(name, "-", "_"),
+	}, nil
+}
+
+// Dir is the domain's jobs package, relative to the project root.
+func (d JobData) Dir() string {
+	return path.Join(d.DomainPath, d.Package)
+}
+
+// File is the job's file, relative to the project root.
+func (d JobData) File() string {
+	return path.Join(d.Dir(), strings.ReplaceAll(d.Name, "-", "_")+".go")
+}
+
+// GenerateJob writes the job's handler and registers it with the jobx
+// client at the "// manifesto:module-init" marker of cmd/container.go.
+// Either step is skipped when already done; the result reports whether
+// anything changed.
+func GenerateJob(projectRoot string, data JobData) (bool, error) {
+	return generateJob(diskStore{}, projectRoot, data)
+}
+
+// PreviewJob runs GenerateJob against an in-memory Preview.
+func PreviewJob(projectRoot string, data JobData) (*Preview, error) {
+	preview := NewPreview(projectRoot)
+	_, err := generateJob(preview, projectRoot, data)
+	return preview, err
+}
+
+func generateJob(fs FileStore, projectRoot string, data JobData) (bool, error) {
+	if info, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(data.DomainPath))); err != nil || !info.IsDir() {
+		return false, fmt.Errorf("no domain at %s; scaffold it with 'manifesto add %s' first", data.DomainPath, data.DomainPath)
+	}
+
+	changed := false
+	dest := filepath.Join(projectRoot, filepath.FromSlash(data.File()))
+	if _, err := fs.ReadFile(dest); err != nil {
+		if err := renderTemplate(fs, projectRoot, "domain/job.go.tmpl", dest, data); err != nil {
+			return false, fmt.Errorf("render %s: %w", data.File(), err)
+		}
+		changed = true
+	}
+
+	injected, err := injectJob(fs, projectRoot, data)
+	return changed || injected, err
+}
+
+// injectJob imports the domain's jobs package into cmd/container.go, once,
+// and registers the job's handler unless it already is.
+func injectJob(fs FileStore, projectRoot string, data JobData) (bool, error) {
+	containerFile := filepath.Join(projectRoot, "cmd", "container.go")
+
+	content, err := fs.ReadFile(containerFile)
+	if err != nil {
+		return false, fmt.Errorf("read cmd/container.go: %w", err)
+	}
+
+	text := string(content)
+	registerCall := fmt.Sprintf("c.JobClient.Register(%s.%sJob,", data.Package, data.TypeName)
+	if strings.Contains(text, registerCall) {
+		return false, nil
+	}
+
+	importSpec := strconv.Quote(data.GoModule + "/" + data.Dir())
+	if !strings.Contains(text, importSpec) {
+		importLine := fmt.Sprintf("\t%s\n\t// manifesto:container-imports", importSpec)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:container-imports", importLine)
+	}
+
+	registerLine := fmt.Sprintf("\t%s %s.Handle%s)\n\t// manifesto:module-init", registerCall, data.Package, data.TypeName)
+	text = replaceMarker(fs, containerFile, text, "// manifesto:module-init", registerLine)
+
+	return true, fs.WriteFile(containerFile, []byte(text), 0644)
+}
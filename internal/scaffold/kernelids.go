@@ -0,0 +1,182 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// kernelIDsFile declares every domain's ID type, relative to the project
+// root.
+const kernelIDsFile = "pkg/kernel/proj_ids.go"
+
+// kernelSectionRe matches the header of a domain's section in
+// kernelIDsFile, capturing the entity name.
+var kernelSectionRe = regexp.MustCompile(`(?m)^// --- (\w+) IDs \(generated by manifesto\) ---$`)
+
+// appendKernelIDs adds the section snippet declares to kernelIDsFile,
+// among the other domains' sections in entity name order, and gofmts the
+// result. A section already in place is left alone; an identifier of it
+// declared by anything else, in kernelIDsFile or another file of the
+// kernel package, is a collision and an error.
+func appendKernelIDs(fs FileStore, projectRoot, snippet string) error {
+	idFile := filepath.Join(projectRoot, filepath.FromSlash(kernelIDsFile))
+
+	existing, err := fs.ReadFile(idFile)
+	if os.IsNotExist(err) {
+		existing = []byte("package kernel\n")
+	} else if err != nil {
+		return err
+	}
+
+	names, err := kernelDeclNames([]byte("package kernel\n" + snippet))
+	if err != nil {
+		return fmt.Errorf("parse kernel IDs: %w", err)
+	}
+	elsewhere, err := kernelDeclFiles(fs, projectRoot)
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if file, ok := elsewhere[n]; ok {
+			return fmt.Errorf("%s already declares kernel.%s; give the entity another name or remove that declaration and re-run", file, n)
+		}
+	}
+	declared, err := kernelDeclNames(existing)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", kernelIDsFile, err)
+	}
+	have := make(map[string]bool, len(declared))
+	for _, n := range declared {
+		have[n] = true
+	}
+
+	var present, missing []string
+	for _, n := range names {
+		if have[n] {
+			present = append(present, n)
+		} else {
+			missing = append(missing, n)
+		}
+	}
+	switch {
+	case len(missing) == 0:
+		fs.Present(idFile, "kernel IDs already present")
+		return nil
+	case len(present) > 0:
+		return fmt.Errorf("%s already declares kernel.%s but not kernel.%s; rename or remove the existing declarations and re-run",
+			kernelIDsFile, present[0], missing[0])
+	}
+
+	out, err := format.Source([]byte(insertKernelSection(string(existing), snippet)))
+	if err != nil {
+		return fmt.Errorf("format %s: %w", kernelIDsFile, err)
+	}
+	return fs.WriteFile(idFile, out, 0644)
+}
+
+// kernelDeclFiles returns the top-level identifiers declared by the
+// non-test Go files of the kernel package other than kernelIDsFile, each
+// with the file that declares it.
+func kernelDeclFiles(fs FileStore, projectRoot string) (map[string]string, error) {
+	dir := filepath.Join(projectRoot, filepath.FromSlash(path.Dir(kernelIDsFile)))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, e := range entries {
+		name := e.Name()
+		rel := path.Join(path.Dir(kernelIDsFile), name)
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || rel == kernelIDsFile {
+			continue
+		}
+		src, err := fs.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		names, err := kernelDeclNames(src)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", rel, err)
+		}
+		for _, n := range names {
+			files[n] = rel
+		}
+	}
+	return files, nil
+}
+
+// insertKernelSection places snippet ahead of the first generated section
+// whose entity name sorts after its own, or at the end of text.
+func insertKernelSection(text, snippet string) string {
+	snippet = strings.TrimSpace(snippet) + "\n"
+	var name string
+	if m := kernelSectionRe.FindStringSubmatch(snippet); m != nil {
+		name = m[1]
+	}
+	for _, loc := range kernelSectionRe.FindAllStringSubmatchIndex(text, -1) {
+		if text[loc[2]:loc[3]] > name {
+			return text[:loc[0]] + snippet + "\n" + text[loc[0]:]
+		}
+	}
+	return strings.TrimRight(text, "\n") + "\n\n" + snippet
+}
+
+// kernelDeclNames returns the top-level identifiers src declares, in
+// order. Methods are named Receiver.Method.
+func kernelDeclNames(src []byte) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						names = append(names, n.Name)
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiverTypeName(d.Recv.List[0].Type) + "." + name
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// receiverTypeName returns the name of a method's receiver type, without
+// pointer or type parameters.
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
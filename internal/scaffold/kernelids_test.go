@@ -0,0 +1,62 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAppendKernelIDsCollisions adds the kernel IDs of several entities to
+// a kernel package that declares some of them outside kernelIDsFile.
+func TestAppendKernelIDsCollisions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pkg/kernel/kernel.go":      "package kernel\n\ntype UserID string\n\nfunc NewTenantID(id string) string { return id }\n",
+		"pkg/kernel/kernel_test.go": "package kernel\n\ntype InvoiceID string\n",
+	})
+	order, err := renderToString(root, "domain/kernel_ids.go.tmpl", NewDomainData("example.com/shop", "pkg/order"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{kernelIDsFile: "package kernel\n\n" + order})
+
+	tests := []struct {
+		entity  string
+		wantErr string // Empty when the IDs are added
+		present bool   // The IDs are already in kernelIDsFile
+	}{
+		{entity: "User", wantErr: "pkg/kernel/kernel.go already declares kernel.UserID"},
+		{entity: "Tenant", wantErr: "pkg/kernel/kernel.go already declares kernel.NewTenantID"},
+		{entity: "Invoice"},
+		{entity: "Order", present: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entity, func(t *testing.T) {
+			data := NewDomainData("example.com/shop", "pkg/"+strings.ToLower(tt.entity))
+			snippet, err := renderToString(root, "domain/kernel_ids.go.tmpl", data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preview := NewPreview(root)
+			err = appendKernelIDs(preview, root, snippet)
+			switch {
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+
+			changes := preview.Changes()
+			if added := tt.wantErr == "" && !tt.present; (len(changes) == 1) != added {
+				t.Fatalf("%d files changed; want IDs added = %v", len(changes), added)
+			}
+			if len(changes) == 1 {
+				if changes[0].Path != kernelIDsFile || !strings.Contains(changes[0].New, "type "+tt.entity+"ID string") {
+					t.Errorf("%s IDs not added to %s:\n%s", tt.entity, kernelIDsFile, changes[0].New)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Domain layers selectable with --layers. They group the generated files
+// by what they hold rather than by package: entity and port both live in
+// the domain package.
+const (
+	DomainLayerEntity    = "entity"    // Entity, DTOs, errors and events
+	DomainLayerPort      = "port"      // Repository interface, its fake and mock, policy
+	DomainLayerService   = "service"   // Service and its tests
+	DomainLayerInfra     = "infra"     // Repository implementation
+	DomainLayerAPI       = "api"       // HTTP handlers or gRPC server
+	DomainLayerContainer = "container" // Module container and the cmd/ injections
+)
+
+// DomainLayers lists the values accepted by --layers, in dependency order.
+var DomainLayers = []string{
+	DomainLayerEntity, DomainLayerPort, DomainLayerService,
+	DomainLayerInfra, DomainLayerAPI, DomainLayerContainer,
+}
+
+// domainLayerNeeds lists the layers whose code each layer refers to. A
+// container without infra takes its repository through Deps instead.
+var domainLayerNeeds = map[string][]string{
+	DomainLayerPort:      {DomainLayerEntity},
+	DomainLayerService:   {DomainLayerPort, DomainLayerEntity},
+	DomainLayerInfra:     {DomainLayerPort, DomainLayerEntity},
+	DomainLayerAPI:       {DomainLayerService, DomainLayerEntity},
+	DomainLayerContainer: {DomainLayerService, DomainLayerPort, DomainLayerEntity},
+}
+
+// ParseDomainLayers parses a --layers spec such as "entity,port" into the
+// layers it selects, in DomainLayers order. It returns nil, meaning all
+// layers, for an empty spec or one naming every layer.
+func ParseDomainLayers(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	selected := map[string]bool{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(DomainLayers, name) {
+			return nil, fmt.Errorf("unknown layer %q in --layers. Available: %s", name, strings.Join(DomainLayers, ", "))
+		}
+		selected[name] = true
+	}
+
+	var layers []string
+	for _, layer := range DomainLayers {
+		if !selected[layer] {
+			continue
+		}
+		for _, need := range domainLayerNeeds[layer] {
+			if !selected[need] {
+				return nil, fmt.Errorf("--layers: the %s layer needs the %s layer", layer, need)
+			}
+		}
+		layers = append(layers, layer)
+	}
+	if len(layers) == len(DomainLayers) {
+		return nil, nil
+	}
+	return layers, nil
+}
+
+// Generates reports whether layer, one of DomainLayers, is generated.
+func (d DomainData) Generates(layer string) bool {
+	return len(d.Layers) == 0 || slices.Contains(d.Layers, layer)
+}
+
+// SelectedLayers returns the layers generated, in DomainLayers order.
+func (d DomainData) SelectedLayers() []string {
+	if len(d.Layers) == 0 {
+		return DomainLayers
+	}
+	return d.Layers
+}
+
+// selectedBy returns the --layers layer that generates f.
+func (f domainFile) selectedBy() string {
+	switch f.layer {
+	case layerDomain:
+		switch f.tmpl {
+		case "domain/port.go.tmpl", "domain/port_fake.go.tmpl", "domain/policy.go.tmpl":
+			return DomainLayerPort
+		}
+		return DomainLayerEntity
+	case layerMocks:
+		return DomainLayerPort
+	case layerService:
+		return DomainLayerService
+	case layerInfra:
+		return DomainLayerInfra
+	case layerAPI, layerGRPC, layerProto:
+		return DomainLayerAPI
+	}
+	return DomainLayerContainer
+}
@@ -0,0 +1,93 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+)
+
+// ModifiedPolicy says what a module download does with files edited since
+// they were fetched, according to config.LockFile.
+type ModifiedPolicy int
+
+const (
+	RefuseModified    ModifiedPolicy = iota // Fail, listing the files
+	OverwriteModified                       // Replace them (--force)
+	KeepModified                            // Leave them as they are (--keep-modified)
+)
+
+// Modified applies to every module download. The CLI sets it from --force
+// and --keep-modified.
+var Modified = RefuseModified
+
+// ModifiedError lists the module files a download would overwrite although
+// they were edited since they were fetched.
+type ModifiedError struct {
+	Files []string
+}
+
+func (e *ModifiedError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d module file(s) were edited since they were fetched and would be overwritten:", len(e.Files))
+	for _, f := range e.Files {
+		b.WriteString("\n    " + f)
+	}
+	b.WriteString("\n    hint: pass --force to overwrite them, or --keep-modified to keep them and update the rest")
+	return b.String()
+}
+
+// fetchLocked runs client.FetchModulePaths, applying Modified to the files
+// edited since they were fetched and recording what it writes in the lock.
+func fetchLocked(client *remote.Client, ref string, paths []remote.PathMapping, projectRoot, goModule string) error {
+	lock, err := config.LoadLock(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	dirs := make([]string, len(paths))
+	for i, p := range paths {
+		dirs[i] = p.Dest
+	}
+	keep := make(map[string]bool)
+	if modified := lock.Modified(projectRoot, dirs); len(modified) > 0 {
+		switch Modified {
+		case RefuseModified:
+			return &ModifiedError{Files: modified}
+		case KeepModified:
+			for _, f := range modified {
+				keep[f] = true
+			}
+		}
+	}
+
+	client.OnExtract(func(path string, content []byte) bool {
+		if keep[path] {
+			return false
+		}
+		lock.Record(path, content)
+		return true
+	})
+	defer client.OnExtract(nil)
+
+	// Files written before a failure are recorded too.
+	err = client.FetchModulePaths(ref, paths, projectRoot, ManifestoGoModule, goModule)
+	if saveErr := lock.Save(projectRoot); err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// Relock updates the lock for fetched files the CLI edited itself, given
+// as slash paths relative to projectRoot, so they don't count as modified.
+func Relock(projectRoot string, paths ...string) error {
+	lock, err := config.LoadLock(projectRoot)
+	if err != nil {
+		return err
+	}
+	if !lock.Refresh(projectRoot, paths) {
+		return nil
+	}
+	return lock.Save(projectRoot)
+}
@@ -0,0 +1,165 @@
+package scaffold
+
+import (
+	"regexp"
+	"strings"
+)
+
+// projectMarker is a marker comment that wiring and domain scaffolding
+// inject code at, with the anchor it is (re)inserted at.
+type projectMarker struct {
+	File   string // Relative to the project root, slash-separated
+	Marker string // As matched by replaceMarker
+	Server bool   // Only present in projects that serve HTTP
+
+	// insert adds the marker to text at its anchor. It returns text
+	// unchanged when the anchor can't be found.
+	insert func(text, marker string) string
+}
+
+// projectMarkers lists every marker in the order it appears in its file.
+var projectMarkers = []projectMarker{
+	{File: "cmd/container.go", Marker: "// manifesto:container-imports", insert: beforeClosing("import (", '(', ')')},
+	{File: "cmd/container.go", Marker: "// manifesto:container-fields", insert: beforeClosing("type Container struct {", '{', '}')},
+	{File: "cmd/container.go", Marker: "// manifesto:module-init", insert: beforeClosing("func (c *Container) initModules()", '{', '}')},
+	{File: "cmd/container.go", Marker: "// manifesto:background-start", insert: beforeClosing("func (c *Container) StartBackgroundServices(", '{', '}')},
+	{File: "cmd/container.go", Marker: "// manifesto:container-helpers", insert: atEnd},
+
+	{File: "cmd/server.go", Marker: "// manifesto:server-imports", Server: true, insert: beforeClosing("import (", '(', ')')},
+	{File: "cmd/server.go", Marker: "// manifesto:server-middleware", Server: true, insert: beforeClosing("func setupMiddleware(", '{', '}')},
+	// Public routes must be registered ahead of the protected group.
+	{File: "cmd/server.go", Marker: "// manifesto:public-routes", Server: true, insert: beforeMarkerOr("// manifesto:route-registration", beforeClosing("func registerRoutes(", '{', '}'))},
+	{File: "cmd/server.go", Marker: "// manifesto:route-registration", Server: true, insert: beforeClosing("func registerRoutes(", '{', '}')},
+
+	{File: "pkg/config/config.go", Marker: "// manifesto:config-fields", insert: beforeClosing("type Config struct {", '{', '}')},
+	{File: "pkg/config/config.go", Marker: "// manifesto:config-loads", insert: beforeReturnCfg},
+
+	{File: "Makefile", Marker: "# manifesto:env-config", insert: beforeBanner("# Internal Variables")},
+	{File: "Makefile", Marker: "# manifesto:make-targets", insert: beforeBanner("# Docker - All Services")},
+	{File: "Makefile", Marker: "\t# manifesto:env-display", insert: beforeLine("\t@echo \"Connection:\"")},
+
+	{File: "docker-compose.yml", Marker: "  # manifesto:compose-services", insert: beforeLine("volumes:")},
+	{File: "docker-compose.yml", Marker: "  # manifesto:compose-volumes", insert: atEndOfLastSection("volumes:")},
+}
+
+// hasMarker reports whether text contains marker, ignoring its indentation.
+func hasMarker(text, marker string) bool {
+	return strings.Contains(text, strings.TrimSpace(marker))
+}
+
+// insertMissingMarkers adds each of markers missing from text at its anchor.
+// It returns the new text and the markers it couldn't place.
+func insertMissingMarkers(text string, markers []projectMarker) (string, []string) {
+	var unplaced []string
+	for _, m := range markers {
+		if hasMarker(text, m.Marker) {
+			continue
+		}
+		updated := m.insert(text, m.Marker)
+		if updated == text {
+			unplaced = append(unplaced, strings.TrimSpace(m.Marker))
+			continue
+		}
+		text = updated
+	}
+	return text, unplaced
+}
+
+// beforeClosing inserts the marker before the bracket closing the block
+// that follows opener.
+func beforeClosing(opener string, open, close byte) func(text, marker string) string {
+	return func(text, marker string) string {
+		return insertMarkerBeforeClosing(text, opener, marker, open, close)
+	}
+}
+
+// beforeMarkerOr inserts the marker on its own line above another marker,
+// falling back to fallback when that one is missing too.
+func beforeMarkerOr(other string, fallback func(text, marker string) string) func(text, marker string) string {
+	return func(text, marker string) string {
+		idx := strings.Index(text, other)
+		if idx == -1 {
+			return fallback(text, marker)
+		}
+		return text[:idx] + marker + "\n\n\t" + text[idx:]
+	}
+}
+
+// beforeLine inserts the marker as a line of its own above the first line
+// starting with prefix.
+func beforeLine(prefix string) func(text, marker string) string {
+	return func(text, marker string) string {
+		idx := lineIndex(text, prefix)
+		if idx == -1 {
+			return text
+		}
+		return text[:idx] + marker + "\n" + text[idx:]
+	}
+}
+
+// beforeBanner inserts the marker above the "# ====" banner whose title
+// line starts with title.
+func beforeBanner(title string) func(text, marker string) string {
+	return func(text, marker string) string {
+		idx := lineIndex(text, title)
+		if idx == -1 {
+			return text
+		}
+		// Step back over the banner's top rule.
+		if prev := strings.LastIndex(text[:max(idx-1, 0)], "\n"); prev != -1 && strings.HasPrefix(text[prev+1:], "# ===") {
+			idx = prev + 1
+		}
+		return text[:idx] + marker + "\n\n" + text[idx:]
+	}
+}
+
+// beforeReturnCfg inserts the marker ahead of "return cfg" in config.Load.
+func beforeReturnCfg(text, marker string) string {
+	returnIdx := strings.Index(text, "return cfg")
+	if returnIdx == -1 {
+		return text
+	}
+	lineStart := strings.LastIndex(text[:returnIdx], "\n") + 1
+	return text[:lineStart] + "\t" + marker + "\n\n" + text[lineStart:]
+}
+
+// topLevelKeyRe matches a line starting a top-level YAML key.
+var topLevelKeyRe = regexp.MustCompile(`(?m)^[^\s#]`)
+
+// atEndOfLastSection appends the marker when the top-level key section is
+// the file's last one, so it lands inside that section.
+func atEndOfLastSection(section string) func(text, marker string) string {
+	return func(text, marker string) string {
+		idx := lineIndex(text, section)
+		if idx == -1 || topLevelKeyRe.MatchString(text[idx+len(section):]) {
+			return text
+		}
+		return atEnd(text, marker)
+	}
+}
+
+func atEnd(text, marker string) string {
+	return strings.TrimRight(text, "\n") + "\n\n" + marker + "\n"
+}
+
+// lineIndex returns the offset of the first line starting with prefix.
+func lineIndex(text, prefix string) int {
+	if strings.HasPrefix(text, prefix) {
+		return 0
+	}
+	if idx := strings.Index(text, "\n"+prefix); idx != -1 {
+		return idx + 1
+	}
+	return -1
+}
+
+// markersIn returns the markers that belong in file.
+func markersIn(file string) []projectMarker {
+	var out []projectMarker
+	for _, m := range projectMarkers {
+		if m.File == file {
+			out = append(out, m)
+		}
+	}
+	return out
+}
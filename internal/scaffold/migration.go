@@ -0,0 +1,94 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationsDir holds the project's SQL migrations, relative to the
+// project root.
+const MigrationsDir = "migrations"
+
+// migrationTimestamp is the layout of a timestamp migration prefix.
+const migrationTimestamp = "20060102150405"
+
+// defaultMigrationWidth pads sequence numbers in a directory without
+// migrations to number after, as golang-migrate's -seq does.
+const defaultMigrationWidth = 6
+
+var (
+	migrationNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	migrationFileRe = regexp.MustCompile(`^(\d+)_.+\.(up|down)\.sql$`)
+)
+
+// NewMigration creates the .up.sql and .down.sql stubs of the migration
+// name in MigrationsDir and returns their paths relative to projectRoot.
+// Files are numbered after the existing ones: with the next sequence
+// number, zero-padded to the same width, or with now as a timestamp when
+// the existing ones are timestamped.
+func NewMigration(projectRoot, name string, now time.Time) ([]string, error) {
+	if !migrationNameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q: use lower_snake_case, like add_invoice_status", name)
+	}
+	dir := filepath.Join(projectRoot, MigrationsDir)
+	prefix, err := nextMigrationPrefix(dir, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	for _, direction := range []string{"up", "down"} {
+		rel := path.Join(MigrationsDir, fmt.Sprintf("%s_%s.%s.sql", prefix, name, direction))
+		dest := filepath.Join(projectRoot, filepath.FromSlash(rel))
+		if _, err := os.Stat(dest); err == nil {
+			return created, fmt.Errorf("%s already exists", rel)
+		}
+		stub := fmt.Sprintf("-- %s (%s)\n", strings.ReplaceAll(name, "_", " "), direction)
+		if err := os.WriteFile(dest, []byte(stub), 0644); err != nil {
+			return created, err
+		}
+		created = append(created, rel)
+	}
+	return created, nil
+}
+
+// nextMigrationPrefix returns the prefix of the next migration in dir.
+func nextMigrationPrefix(dir string, now time.Time) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("read %s/: %w", MigrationsDir, err)
+	}
+
+	var last string
+	for _, e := range entries {
+		m := migrationFileRe.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		if len(m[1]) > len(last) || (len(m[1]) == len(last) && m[1] > last) {
+			last = m[1]
+		}
+	}
+
+	switch {
+	case last == "":
+		return fmt.Sprintf("%0*d", defaultMigrationWidth, 1), nil
+	case len(last) == len(migrationTimestamp):
+		prefix := now.UTC().Format(migrationTimestamp)
+		if prefix <= last {
+			return "", fmt.Errorf("%s/ already has a migration at or after %s; check the clock", MigrationsDir, prefix)
+		}
+		return prefix, nil
+	}
+	n, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("migration number %s: %w", last, err)
+	}
+	return fmt.Sprintf("%0*d", len(last), n+1), nil
+}
@@ -0,0 +1,158 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+)
+
+type DiffOptions struct {
+	ProjectRoot string
+	ModuleName  string
+	Ref         string // Default: the version the module was installed from
+}
+
+// ModuleDiff is how an installed module's files differ from manifesto's
+// at a ref.
+type ModuleDiff struct {
+	Ref   string
+	Files []FileDiff // Only the files that differ, by path
+}
+
+// FileDiff is a module file that differs between the project and
+// manifesto, by slash path relative to the project root. Ours is nil for
+// a file only manifesto has, and Theirs for one only the project has.
+type FileDiff struct {
+	Path   string
+	Ours   []byte
+	Theirs []byte
+	Edited bool // Changed since it was fetched, according to config.LockFile
+}
+
+// DiffModule compares an installed module's files with manifesto's at
+// opts.Ref, read from the archive as add would fetch them, so imports are
+// rewritten the same way. Files the module leaves out when fetched, such
+// as tests, aren't compared.
+func DiffModule(opts DiffOptions) (*ModuleDiff, error) {
+	manifest, err := config.LoadManifest(opts.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("not a manifesto project: %w", err)
+	}
+	mod, ok := config.ModuleRegistry[opts.ModuleName]
+	if !ok {
+		return nil, fmt.Errorf("unknown module: '%s'. Run 'manifesto modules' to see available modules", opts.ModuleName)
+	}
+	installed, ok := manifest.Modules[opts.ModuleName]
+	if !ok || len(mod.Dirs()) == 0 {
+		return nil, fmt.Errorf("module '%s' is not installed", opts.ModuleName)
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = installed.Version
+	}
+	if ref == "" {
+		return nil, fmt.Errorf("module '%s' has no recorded version to compare with; pass --ref", opts.ModuleName)
+	}
+	// The project's files are where the installed version put them, and
+	// both sides are keyed by those paths. The version installed is read
+	// from where it was fetched then.
+	was := installedModule(mod, installed)
+	fetched := sourcePaths(was, manifest.Project.Tests)
+	if ref != installed.Version {
+		fetched = sourcePaths(mod, manifest.Project.Tests)
+	}
+	dirs := was.Dirs()
+
+	client := remote.NewClient(manifest.Project.Repo)
+	client.ForceRefType(remote.RefType(manifest.Project.RefType))
+	spin := ui.NewSpinner(fmt.Sprintf("Reading %s from manifesto@%s...", opts.ModuleName, ref))
+	spin.Start()
+	ReportProgress(client, spin)
+	theirs, err := client.ReadModulePaths(ref, fetched, ManifestoGoModule, manifest.Project.GoModule)
+	if err != nil {
+		spin.Stop(false)
+		return nil, fmt.Errorf("read manifesto@%s: %w", ref, err)
+	}
+	spin.Stop(true)
+
+	ours, err := readModuleFiles(opts.ProjectRoot, dirs, was.FetchPaths(manifest.Project.Tests))
+	if err != nil {
+		return nil, err
+	}
+	lock, err := config.LoadLock(opts.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	edited := lock.Modified(opts.ProjectRoot, dirs)
+
+	result := &ModuleDiff{Ref: ref}
+	all := slices.Sorted(maps.Keys(theirs))
+	for p := range ours {
+		if _, ok := theirs[p]; !ok {
+			all = append(all, p)
+		}
+	}
+	slices.Sort(all)
+	for _, p := range all {
+		if bytes.Equal(ours[p], theirs[p]) {
+			continue
+		}
+		result.Files = append(result.Files, FileDiff{
+			Path:   p,
+			Ours:   ours[p],
+			Theirs: theirs[p],
+			Edited: slices.Contains(edited, p),
+		})
+	}
+	return result, nil
+}
+
+// installedModule returns mod as installed: fetched from the archive
+// paths recorded for it.
+func installedModule(mod config.Module, installed config.ModuleConfig) config.Module {
+	mod.Mappings = installed.Mappings
+	return mod
+}
+
+// readModuleFiles returns the files under dirs in the project that
+// patterns select, keyed by slash path.
+func readModuleFiles(projectRoot string, dirs, patterns []string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, dir := range dirs {
+		root := filepath.Join(projectRoot, filepath.FromSlash(dir))
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(projectRoot, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if !remote.Match(rel, patterns) {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[rel] = data
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return files, nil
+}
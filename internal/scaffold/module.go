@@ -2,7 +2,6 @@ package scaffold
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
 	"github.com/Abraxas-365/manifesto-cli/internal/remote"
@@ -15,13 +14,16 @@ type InstallOptions struct {
 	Ref         string
 }
 
+// InstallModule downloads a module and its missing dependencies. With a
+// Ref, an installed module is moved to that ref on its own; the rest of
+// the project stays on the ref recorded for each module.
 func InstallModule(opts InstallOptions) error {
 	manifest, err := config.LoadManifest(opts.ProjectRoot)
 	if err != nil {
 		return fmt.Errorf("not a manifesto project: %w", err)
 	}
 
-	if mc, ok := manifest.Modules[opts.ModuleName]; ok {
+	if mc, ok := manifest.Modules[opts.ModuleName]; ok && (opts.Ref == "" || opts.Ref == mc.Version) {
 		return fmt.Errorf("module '%s' already installed (version: %s)", opts.ModuleName, mc.Version)
 	}
 
@@ -29,57 +31,78 @@ func InstallModule(opts InstallOptions) error {
 		return fmt.Errorf("unknown module: '%s'. Run 'manifesto modules' to see available modules", opts.ModuleName)
 	}
 
-	// Resolve deps, find what's missing.
-	allNeeded := config.ResolveDeps([]string{opts.ModuleName})
+	// Find the missing dependencies.
+	deps, err := config.ResolveDeps([]string{opts.ModuleName})
+	if err != nil {
+		return err
+	}
 	var toInstall []string
-	for _, name := range allNeeded {
-		if _, ok := manifest.Modules[name]; !ok {
+	for _, name := range deps {
+		if _, ok := manifest.Modules[name]; !ok && name != opts.ModuleName {
 			toInstall = append(toInstall, name)
 		}
 	}
 
-	// Collect paths.
-	var allPaths []string
-	for _, name := range toInstall {
-		allPaths = append(allPaths, config.ModuleRegistry[name].Paths...)
-	}
-
-	// Determine ref.
+	client := remote.NewClient(manifest.Project.Repo)
+	client.ForceRefType(remote.RefType(manifest.Project.RefType))
 	ref := opts.Ref
 	if ref == "" {
-		ref = manifest.Project.Version
-	}
-	if ref == "" {
-		client := remote.NewClient("")
-		ref, _ = client.GetLatestVersion()
-		if ref == "" {
-			ref = remote.DefaultRef
-		}
+		ref = ChannelRef(manifest, client)
 	}
 
 	// Fetch.
 	spin := ui.NewSpinner(fmt.Sprintf("Installing %s from manifesto@%s...", opts.ModuleName, ref))
 	spin.Start()
-
-	client := remote.NewClient("")
-	if err := client.FetchModulePaths(ref, allPaths, opts.ProjectRoot, ManifestoGoModule, manifest.Project.GoModule); err != nil {
+	ReportProgress(client, spin)
+	if _, err := PinModules(opts.ProjectRoot, manifest, []string{opts.ModuleName}, client, ref); err != nil {
+		spin.Stop(false)
+		return fmt.Errorf("fetch module: %w", err)
+	}
+	if err := EnsureModulesPresent(opts.ProjectRoot, manifest, []string{opts.ModuleName}, client, ref); err != nil {
 		spin.Stop(false)
 		return fmt.Errorf("fetch module: %w", err)
 	}
 	spin.Stop(true)
 
-	// Update manifest.
-	for _, name := range toInstall {
-		manifest.Modules[name] = config.ModuleConfig{
-			Version:     ref,
-			InstalledAt: time.Now(),
-		}
+	if name := manifest.ArchiveChanged(ref, client.ArchiveChecksum(ref)); name != "" {
+		ui.StepWarn(fmt.Sprintf("manifesto@%s has changed upstream since %s was installed from it; the modules may not match", ref, name))
+	}
+	if mixed := manifest.MixedMajors(); mixed != "" {
+		ui.StepWarn(MixedMajorsWarning(mixed))
 	}
 
 	if err := manifest.Save(opts.ProjectRoot); err != nil {
 		return fmt.Errorf("save manifesto.yaml: %w", err)
 	}
 
-	ui.PrintInstallSuccess(opts.ModuleName, toInstall)
+	ui.PrintInstallSuccess(opts.ModuleName, append([]string{opts.ModuleName}, toInstall...))
 	return nil
 }
+
+// MixedMajorsWarning explains a Manifest.MixedMajors result.
+func MixedMajorsWarning(mixed string) string {
+	return fmt.Sprintf("modules come from different major versions of manifesto: %s; they may not work together", mixed)
+}
+
+// ReportProgress shows client's download and extraction progress on r.
+func ReportProgress(client *remote.Client, r ui.ProgressReporter) {
+	client.OnProgress(func(p remote.Progress) {
+		switch p.Stage {
+		case remote.StageDownload:
+			ui.ShowTransfer(r, p.Bytes, p.Total)
+		case remote.StageExtract:
+			r.Status(fmt.Sprintf("%d files written", p.Files))
+		}
+	})
+}
+
+// sourcePaths returns what to extract from the archive for mod, with its
+// tests and testdata only if tests.
+func sourcePaths(mod config.Module, tests bool) []remote.PathMapping {
+	var paths []remote.PathMapping
+	fetch := mod.FetchPaths(tests)
+	for _, src := range mod.Sources() {
+		paths = append(paths, remote.PathMapping{Src: src.SrcPath, Dest: src.DestPath, Paths: fetch})
+	}
+	return paths
+}
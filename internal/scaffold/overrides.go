@@ -0,0 +1,148 @@
+package scaffold
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/templates"
+)
+
+// TemplateOverrideDir holds a project's own versions of the embedded
+// templates, relative to the project root. A file at the same relative
+// path, e.g. domain/handler.go.tmpl, is rendered in place of the embedded
+// one. config.UserTemplatesDir does the same for every project, with the
+// project's taking precedence.
+const TemplateOverrideDir = ".manifesto/templates"
+
+// TemplateOverride is an embedded template shadowed by a file on disk.
+type TemplateOverride struct {
+	Template string // Embedded path, e.g. domain/handler.go.tmpl
+	Path     string // File rendered in its place
+}
+
+// templateOverrideDirs returns the override directories for projectRoot,
+// in precedence order.
+func templateOverrideDirs(projectRoot string) []string {
+	dirs := []string{filepath.Join(projectRoot, filepath.FromSlash(TemplateOverrideDir))}
+	if user, err := config.UserTemplatesDir(); err == nil {
+		dirs = append(dirs, user)
+	}
+	return dirs
+}
+
+// templateOverride returns the file shadowing tmplPath in projectRoot, or
+// "" when the embedded template applies.
+func templateOverride(projectRoot, tmplPath string) string {
+	for _, dir := range templateOverrideDirs(projectRoot) {
+		p := filepath.Join(dir, filepath.FromSlash(tmplPath))
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// readTemplate returns the content of tmplPath, from its override when
+// projectRoot has one.
+func readTemplate(projectRoot, tmplPath string) ([]byte, error) {
+	if p := templateOverride(projectRoot, tmplPath); p != "" {
+		return os.ReadFile(p)
+	}
+	return templates.FS.ReadFile(tmplPath)
+}
+
+// templateOverrides returns the overrides in effect for tmplPaths.
+func templateOverrides(projectRoot string, tmplPaths []string) []TemplateOverride {
+	var out []TemplateOverride
+	for _, t := range tmplPaths {
+		if p := templateOverride(projectRoot, t); p != "" {
+			out = append(out, TemplateOverride{Template: t, Path: p})
+		}
+	}
+	return out
+}
+
+// DomainTemplateOverrides returns the overrides GenerateDomain renders for
+// data in projectRoot.
+func DomainTemplateOverrides(projectRoot string, data DomainData) []TemplateOverride {
+	var tmpls []string
+	for _, f := range domainFiles(data) {
+		tmpls = append(tmpls, f.tmpl)
+	}
+	return templateOverrides(projectRoot, append(tmpls, "domain/kernel_ids.go.tmpl"))
+}
+
+// checkTemplates reports each override that shadows no embedded template,
+// or that doesn't parse or render against sample data of the type the
+// CLI renders it with. Only the branches the sample takes are checked.
+func checkTemplates(projectRoot string, manifest *config.Manifest) []DoctorCheck {
+	var checks []DoctorCheck
+	seen := map[string]bool{}
+	for _, dir := range templateOverrideDirs(projectRoot) {
+		filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, ".tmpl") {
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, p)
+			tmplPath := filepath.ToSlash(rel)
+			if seen[tmplPath] {
+				// Shadowed by the project's own override.
+				return nil
+			}
+			seen[tmplPath] = true
+
+			check := DoctorCheck{Group: "templates", Name: tmplPath + " override renders", OK: true}
+			if _, err := templates.FS.ReadFile(tmplPath); err != nil {
+				check.OK, check.Warn = false, true
+				check.Detail = p + " shadows no embedded template; it is never rendered"
+			} else if err := renderSample(p, tmplPath, manifest); err != nil {
+				check.OK = false
+				check.Detail = err.Error()
+			}
+			checks = append(checks, check)
+			return nil
+		})
+	}
+	return checks
+}
+
+// renderSample renders the override at p of tmplPath against sample data.
+func renderSample(p, tmplPath string, manifest *config.Manifest) error {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(path.Base(tmplPath)).Funcs(TemplateFuncs).Parse(string(content))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(io.Discard, sampleTemplateData(tmplPath, manifest))
+}
+
+// sampleTemplateData returns data of the type tmplPath is rendered with.
+func sampleTemplateData(tmplPath string, manifest *config.Manifest) any {
+	goModule := manifest.Project.GoModule
+	switch {
+	case tmplPath == "project/cron_job.go.tmpl":
+		data, _ := NewCronJobData(goModule, "nightly-report", DefaultCronSchedule)
+		return data
+	case tmplPath == "domain/job.go.tmpl":
+		data, _ := NewJobData(goModule, "pkg/sample/invoice", "send-invoice")
+		return data
+	case strings.HasPrefix(tmplPath, "project/"):
+		data := NewProjectData(goModule, manifest.Project.Name)
+		data.Postgres, data.Redis, data.GRPC = true, true, manifest.Project.GRPC
+		return data
+	}
+	data := NewDomainData(goModule, "pkg/sample/invoice")
+	data.Fields, _ = ParseFields("number:string,amount:decimal,paid:bool,due_at:time")
+	data.HasIAM = manifest.IsWired("iam")
+	data.in = data.layerDir(layerService)
+	return data
+}
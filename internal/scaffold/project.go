@@ -2,157 +2,373 @@ package scaffold
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
+	"go/format"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"text/template"
-	"time"
 
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
 	"github.com/Abraxas-365/manifesto-cli/internal/remote"
-	"github.com/Abraxas-365/manifesto-cli/internal/templates"
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
 	"github.com/Abraxas-365/manifesto-cli/internal/ui"
 )
 
 const ManifestoGoModule = "github.com/Abraxas-365/manifesto"
 
 type InitOptions struct {
-	ProjectName  string
-	GoModule     string
-	OutputDir    string
-	Modules      []string
-	Ref          string
-	WireModules  []string // Wireable modules to wire after init
+	ProjectName string
+	GoModule    string
+	OutputDir   string
+	Modules     []string
+	Ref         string
+	RefType     remote.RefType // Forces how Ref resolves; RefAuto guesses
+	Channel     remote.Channel // Ref later downloads use; ChannelBranch if Ref falls back to a branch
+	Repo        string         // Manifesto fork to fetch from (owner/name); empty means remote.DefaultRepo
+	Profile     string         // Name of a config.Profile; empty means config.DefaultProfile
+	Naming      string         // Name of a config.Naming; empty means config.DefaultNaming
+	WireModules []string       // Wireable modules to wire after init
+	SkipTidy    bool           // Leave go mod tidy to the user
+	Verify      bool           // Run go build ./... once tidied
+	GRPC        bool           // Serve gRPC next to HTTP from cmd/server.go
+	Tests       bool           // Fetch modules with their tests and testdata
+	EnvTarget   string         // Where wiring writes env variables; empty means config.DefaultEnvTarget
+
+	// Resume continues an interrupted init in an existing directory that
+	// holds an InitStateFile. The recorded options replace the ones above.
+	Resume bool
+
+	// Force inits into an existing directory that is empty or holds only
+	// .git; Merge into any existing directory none of whose files init
+	// would overwrite.
+	Force bool
+	Merge bool
 }
 
 // ProjectData is the template context for project-level templates.
 type ProjectData struct {
 	GoModule    string
 	ProjectName string
+
+	// Other forms of the project name, e.g. MyShop, myShop, my-shop,
+	// my_shop and "my shop".
+	PascalName string
+	CamelName  string
+	KebabName  string
+	SnakeName  string
+	Label      string
+
+	// Taken from the project's profile.
+	Static   bool // Serve ./web from the HTTP server
+	Postgres bool // Connect to postgres and run it in docker-compose
+	Redis    bool // Connect to redis and run it in docker-compose
+
+	GRPC bool // Also serve gRPC, from InitOptions
+}
+
+// NewProjectData returns the data for a project, without the profile's
+// settings.
+func NewProjectData(goModule, projectName string) ProjectData {
+	names := casings(projectName)
+	return ProjectData{
+		GoModule:    goModule,
+		ProjectName: projectName,
+		PascalName:  names.Pascal,
+		CamelName:   names.Camel,
+		KebabName:   names.Kebab,
+		SnakeName:   names.Snake,
+		Label:       toLabel(projectName),
+	}
 }
 
 func InitProject(opts InitOptions) error {
 	projectRoot := filepath.Join(opts.OutputDir, opts.ProjectName)
 
+	var state *InitState
+	existing := false
 	if _, err := os.Stat(projectRoot); !os.IsNotExist(err) {
-		return fmt.Errorf("directory %s already exists", projectRoot)
+		existing = true
+		if opts.Resume {
+			state, err = LoadInitState(projectRoot)
+			if err != nil {
+				return err
+			}
+		}
 	}
-	if err := os.MkdirAll(projectRoot, 0755); err != nil {
-		return fmt.Errorf("create project dir: %w", err)
+	if existing && state == nil {
+		if err := checkExistingDir(projectRoot, opts); err != nil {
+			return err
+		}
 	}
+	if state != nil {
+		existing = state.Existing
+		opts.GoModule = state.GoModule
+		opts.Modules = state.Modules
+		opts.Ref = state.Ref
+		opts.RefType = remote.RefType(state.RefType)
+		opts.Channel = remote.Channel(state.Channel)
+		opts.Repo = state.Repo
+		opts.Profile = state.Profile
+		opts.Naming = state.Naming
+		opts.GRPC = state.GRPC
+		opts.Tests = state.Tests
+		opts.EnvTarget = state.EnvTarget
+		opts.WireModules = state.WireModules
+	}
+	client := remote.NewClient(opts.Repo)
+	client.ForceRefType(opts.RefType)
+	if state == nil {
+		if err := os.MkdirAll(projectRoot, 0755); err != nil {
+			return fmt.Errorf("create project dir: %w", err)
+		}
+		if opts.Ref == "" {
+			ref, err := client.GetLatestVersion()
+			if err != nil || ref == "" {
+				ref = client.DefaultBranch()
+			}
+			if ref == client.DefaultBranch() && opts.Channel != remote.ChannelBranch {
+				reason := "no release found"
+				if err != nil {
+					reason = err.Error()
+				}
+				ui.StepWarn(fmt.Sprintf("Couldn't use the latest release (%s); the project tracks the %s branch instead", reason, ref))
+				opts.Channel = remote.ChannelBranch
+			}
+			opts.Ref = ref
+		}
+		state = &InitState{
+			ProjectName: opts.ProjectName,
+			GoModule:    opts.GoModule,
+			Ref:         opts.Ref,
+			RefType:     string(opts.RefType),
+			Channel:     string(opts.Channel),
+			Repo:        opts.Repo,
+			Profile:     opts.Profile,
+			Naming:      opts.Naming,
+			GRPC:        opts.GRPC,
+			Tests:       opts.Tests,
+			EnvTarget:   opts.EnvTarget,
+			Modules:     opts.Modules,
+			WireModules: opts.WireModules,
+			Existing:    existing,
+			StartedAt:   clock.Now(),
+			root:        projectRoot,
+		}
+		if err := state.save(); err != nil {
+			return fmt.Errorf("write %s: %w", InitStateFile, err)
+		}
+	}
+	ref := opts.Ref
 
-	allModules := config.ResolveDeps(opts.Modules)
+	profile, err := config.LookupProfile(opts.Profile)
+	if err != nil {
+		return err
+	}
+
+	allModules, err := config.ResolveDeps(opts.Modules)
+	if err != nil {
+		return err
+	}
 
 	// Collect remote paths to fetch from GitHub.
-	var allPaths []string
+	var allPaths []remote.PathMapping
 	for _, modName := range allModules {
 		mod, ok := config.ModuleRegistry[modName]
 		if !ok {
 			return fmt.Errorf("unknown module: %s", modName)
 		}
-		allPaths = append(allPaths, mod.Paths...)
+		allPaths = append(allPaths, sourcePaths(mod, opts.Tests)...)
 	}
 
-	client := remote.NewClient("")
-	ref := opts.Ref
-	if ref == "" {
-		var err error
-		ref, err = client.GetLatestVersion()
-		if err != nil {
-			ref = remote.DefaultRef
-		}
+	total := 4 + len(opts.WireModules)
+	if !opts.SkipTidy {
+		total++
 	}
-
-	totalSteps := 4
-	if len(opts.WireModules) > 0 {
-		totalSteps = 4 + len(opts.WireModules)
+	verify := opts.Verify && !opts.SkipTidy
+	if verify {
+		total++
 	}
-	step := 1
+	steps := ui.NewSteps(total)
 
-	// Step 1: Fetch module source from GitHub.
-	if len(allPaths) > 0 {
-		spin := ui.NewStepSpinner(step, totalSteps, fmt.Sprintf("Downloading manifesto@%s...", ref))
-		spin.Start()
-		err := client.FetchModulePaths(ref, allPaths, projectRoot, ManifestoGoModule, opts.GoModule)
+	// Step 1: Fetch module source from GitHub. An unrecorded fetch may have
+	// been cut off mid-extraction, so it is redone in full.
+	if !state.done(stepFetch) && len(allPaths) > 0 {
+		if opts.Channel == remote.ChannelBranch {
+			warnBranch(client, ref)
+		}
+		spin := steps.Start(fmt.Sprintf("Downloading manifesto@%s...", ref))
+		ReportProgress(client, spin)
+		err := fetchLocked(client, ref, allPaths, projectRoot, opts.GoModule)
 		if err != nil {
 			spin.Stop(false)
-			os.RemoveAll(projectRoot)
+			if len(state.Completed) == 0 {
+				removeInitOutputs(projectRoot, opts, existing)
+			}
 			return fmt.Errorf("fetch modules: %w", err)
 		}
 		spin.Stop(true)
+	} else {
+		steps.Skip()
 	}
-	step++
-
-	// Step 2: Generate go.mod.
-	spin := ui.NewStepSpinner(step, totalSteps, "Creating go.mod...")
-	spin.Start()
-	if err := generateGoMod(projectRoot, opts.GoModule, client, ref); err != nil {
-		spin.Stop(false)
-		return fmt.Errorf("generate go.mod: %w", err)
+	if err := state.complete(stepFetch); err != nil {
+		return err
 	}
-	spin.Stop(true)
-	step++
 
-	// Step 3: Generate project files from templates.
-	spin = ui.NewStepSpinner(step, totalSteps, "Generating project files...")
-	spin.Start()
+	// Step 2: Generate project files from templates. Never redone once
+	// recorded: wiring edits these files afterwards.
+	if !state.done(stepFiles) {
+		spin := steps.Start("Generating project files...")
+
+		projData := NewProjectData(opts.GoModule, opts.ProjectName)
+		projData.Static = profile.Static
+		projData.Postgres = profile.Postgres
+		projData.Redis = profile.Redis
+		projData.GRPC = opts.GRPC
+
+		// Profiles without HTTP get a runner main instead of the server.
+		mainTemplate := struct {
+			tmpl string
+			dest string
+		}{"project/server.go.tmpl", filepath.Join(projectRoot, "cmd", "server.go")}
+		if !profile.HTTP {
+			mainTemplate.tmpl = "project/worker.go.tmpl"
+			mainTemplate.dest = filepath.Join(projectRoot, "cmd", "main.go")
+		}
 
-	projData := ProjectData{
-		GoModule:    opts.GoModule,
-		ProjectName: opts.ProjectName,
-	}
+		templateFiles := []struct {
+			tmpl string
+			dest string
+		}{
+			{"project/container.go.tmpl", filepath.Join(projectRoot, "cmd", "container.go")},
+			mainTemplate,
+			{"project/makefile.tmpl", filepath.Join(projectRoot, "Makefile")},
+			{"project/docker-compose.yml.tmpl", filepath.Join(projectRoot, "docker-compose.yml")},
+		}
 
-	templateFiles := []struct {
-		tmpl string
-		dest string
-	}{
-		{"project/container.go.tmpl", filepath.Join(projectRoot, "cmd", "container.go")},
-		{"project/server.go.tmpl", filepath.Join(projectRoot, "cmd", "server.go")},
-		{"project/makefile.tmpl", filepath.Join(projectRoot, "Makefile")},
-		{"project/docker-compose.yml.tmpl", filepath.Join(projectRoot, "docker-compose.yml")},
-	}
+		for _, tf := range templateFiles {
+			if err := renderProjectTemplate(projectRoot, tf.tmpl, tf.dest, projData); err != nil {
+				spin.Stop(false)
+				return fmt.Errorf("generate %s: %w", filepath.Base(tf.dest), err)
+			}
+		}
 
-	for _, tf := range templateFiles {
-		if err := renderProjectTemplate(tf.tmpl, tf.dest, projData); err != nil {
+		if err := generateGitignore(projectRoot); err != nil {
 			spin.Stop(false)
-			return fmt.Errorf("generate %s: %w", filepath.Base(tf.dest), err)
+			return fmt.Errorf("generate .gitignore: %w", err)
 		}
-	}
 
-	if err := generateGitignore(projectRoot); err != nil {
-		spin.Stop(false)
-		return fmt.Errorf("generate .gitignore: %w", err)
-	}
+		if profile.Static {
+			if err := generateWebRoot(projectRoot, opts.ProjectName); err != nil {
+				spin.Stop(false)
+				return fmt.Errorf("generate web/: %w", err)
+			}
+		}
 
-	spin.Stop(true)
+		spin.Stop(true)
 
-	// Post-process config.go to insert wiring markers.
-	if err := PostProcessConfigFile(projectRoot); err != nil {
-		return fmt.Errorf("post-process config.go: %w", err)
-	}
+		var tmpls []string
+		for _, tf := range templateFiles {
+			tmpls = append(tmpls, tf.tmpl)
+		}
+		for _, o := range templateOverrides(projectRoot, tmpls) {
+			ui.StepInfo(fmt.Sprintf("Rendered %s from %s", o.Template, o.Path))
+		}
 
-	step++
+		// Post-process config.go to insert wiring markers.
+		if err := PostProcessConfigFile(projectRoot); err != nil {
+			return fmt.Errorf("post-process config.go: %w", err)
+		}
 
-	// Write manifesto.yaml.
-	spin = ui.NewStepSpinner(step, totalSteps, "Writing manifesto.yaml...")
-	spin.Start()
+		if err := state.complete(stepFiles); err != nil {
+			return err
+		}
+	} else {
+		steps.Skip()
+	}
 
-	manifest := config.NewManifest(opts.ProjectName, opts.GoModule, ref)
-	for _, modName := range allModules {
-		manifest.Modules[modName] = config.ModuleConfig{
-			Version:     ref,
-			InstalledAt: time.Now(),
+	// Step 3: Generate go.mod, pruned to the requires the project's Go
+	// files import now that they're all there.
+	if !state.done(stepGoMod) {
+		spin := steps.Start("Creating go.mod...")
+		if err := generateGoMod(projectRoot, opts.GoModule, client, ref); err != nil {
+			spin.Stop(false)
+			return fmt.Errorf("generate go.mod: %w", err)
+		}
+		pruneErr := pruneGoMod(projectRoot)
+		spin.Stop(true)
+		if pruneErr != nil {
+			ui.StepWarn(fmt.Sprintf("Kept every require of manifesto's go.mod; couldn't tell which the project needs: %v", pruneErr))
 		}
+		if err := state.complete(stepGoMod); err != nil {
+			return err
+		}
+	} else {
+		steps.Skip()
 	}
-	if err := manifest.Save(projectRoot); err != nil {
-		spin.Stop(false)
-		return fmt.Errorf("save manifesto.yaml: %w", err)
+
+	// Write manifesto.yaml. Once written it also tracks wired modules and
+	// is loaded rather than recreated.
+	var manifest *config.Manifest
+	if state.done(stepManifest) {
+		steps.Skip()
+		var err error
+		if manifest, err = config.LoadManifest(projectRoot); err != nil {
+			return err
+		}
+	} else {
+		spin := steps.Start("Writing manifesto.yaml...")
+
+		manifest = config.NewManifest(opts.ProjectName, opts.GoModule, ref, opts.Profile)
+		manifest.Project.Repo = opts.Repo
+		manifest.Project.RefType = string(opts.RefType)
+		if opts.Naming != config.DefaultNaming {
+			manifest.Project.Naming = opts.Naming
+		}
+		manifest.Project.GRPC = opts.GRPC
+		manifest.Project.Tests = opts.Tests
+		if opts.EnvTarget != config.DefaultEnvTarget {
+			manifest.Project.EnvTarget = opts.EnvTarget
+		}
+		if opts.Channel != remote.ChannelPinned {
+			manifest.Project.Channel = string(opts.Channel)
+		}
+		for _, modName := range allModules {
+			manifest.Modules[modName] = config.ModuleConfig{
+				Version:     ref,
+				InstalledAt: clock.Now(),
+				SHA256:      client.ArchiveChecksum(ref),
+				Mappings:    config.ModuleRegistry[modName].Remapped(),
+			}
+		}
+		if err := manifest.Save(projectRoot); err != nil {
+			spin.Stop(false)
+			return fmt.Errorf("save manifesto.yaml: %w", err)
+		}
+		spin.Stop(true)
+		if err := state.complete(stepManifest); err != nil {
+			return err
+		}
 	}
-	spin.Stop(true)
 
-	// Wire requested modules (download required source first).
-	for i, wireMod := range opts.WireModules {
+	// Wire requested modules (download required source first). The
+	// manifest is saved after each one so a resumed init skips both the
+	// wiring and the download.
+	var deferred []string
+	var toolchainErr error
+	for _, wireMod := range opts.WireModules {
+		if manifest.IsWired(wireMod) {
+			steps.Skip()
+			continue
+		}
+
 		spec, ok := config.WireableModuleRegistry[wireMod]
 		if !ok {
 			return fmt.Errorf("unknown wireable module: %s", wireMod)
@@ -163,51 +379,196 @@ func InitProject(opts InitOptions) error {
 			if err := EnsureModulesPresent(projectRoot, manifest, spec.RequiredModules, client, ref); err != nil {
 				return fmt.Errorf("download deps for %s: %w", wireMod, err)
 			}
+			if err := manifest.Save(projectRoot); err != nil {
+				return fmt.Errorf("save manifesto.yaml: %w", err)
+			}
 		}
 
-		wireStep := step + i + 1
-		spin = ui.NewStepSpinner(wireStep, totalSteps, fmt.Sprintf("Wiring %s...", wireMod))
-		spin.Start()
-
 		result, err := WireModule(WireOptions{
-			ProjectRoot:  projectRoot,
-			ModuleName:   wireMod,
-			GoModule:     opts.GoModule,
-			ProjectName:  opts.ProjectName,
-			WiredModules: manifest.WiredModules,
+			ProjectRoot:    projectRoot,
+			ModuleName:     wireMod,
+			GoModule:       opts.GoModule,
+			ProjectName:    opts.ProjectName,
+			WiredModules:   manifest.WiredOrProvided(),
+			WiredProviders: manifest.Providers,
+			NoServer:       !manifest.ServesHTTP(),
+			EnvTarget:      manifest.Project.EnvTarget,
+			Steps:          steps,
 		})
 		if err != nil {
-			spin.Stop(false)
 			return fmt.Errorf("wire %s: %w", wireMod, err)
 		}
-		spin.Stop(true)
 
-		manifest.WiredModules = append(manifest.WiredModules, wireMod)
+		manifest.AddWiredModule(wireMod)
+		manifest.SetGoDeps(wireMod, result.GoDeps)
+		manifest.SetProvider(wireMod, result.Provider)
+		manifest.AddBridges(result.ActivatedBridges...)
+		if err := manifest.Save(projectRoot); err != nil {
+			return fmt.Errorf("save manifesto.yaml after wiring: %w", err)
+		}
+		if err := state.complete("wire:" + wireMod); err != nil {
+			return err
+		}
+		deferred = append(deferred, result.Deferred...)
+		if result.ToolchainErr != nil {
+			toolchainErr = result.ToolchainErr
+		}
 
+		if result.SkippedServer {
+			ui.StepWarn(fmt.Sprintf("%s: skipped its routes and middleware; worker projects have no HTTP server", wireMod))
+		}
 		if len(result.ActivatedBridges) > 0 {
 			for _, b := range result.ActivatedBridges {
-				ui.StepInfo(fmt.Sprintf("Bridge: %s + %s auto-connected", wireMod, b))
+				ui.StepInfo(fmt.Sprintf("Bridge: %s auto-connected", strings.ReplaceAll(b, "+", " + ")))
 			}
 		}
 	}
 
-	// Save manifest again if modules were wired.
-	if len(opts.WireModules) > 0 {
-		if err := manifest.Save(projectRoot); err != nil {
-			return fmt.Errorf("save manifesto.yaml after wiring: %w", err)
+	// Tidy, so the project builds as generated. Without a usable go it is
+	// left as a manual step; a failed tidy fails the init so --resume
+	// retries it.
+	if !opts.SkipTidy {
+		if toolchainErr == nil {
+			toolchainErr = toolchain.Check(toolchain.RequiredVersion(projectRoot))
+		}
+		if toolchainErr != nil {
+			deferred = append(deferred, "go mod tidy")
+		} else {
+			spin := steps.Start("Running go mod tidy...")
+			if err := Tidy(projectRoot); err != nil {
+				spin.Stop(false)
+				return err
+			}
+			spin.Stop(true)
+		}
+	}
+
+	// Build, so templates or wiring that don't compile show up now rather
+	// than at the user's first go build. The project is complete either
+	// way; a failed build isn't retried by --resume.
+	var buildErr error
+	if verify {
+		if toolchainErr != nil {
+			steps.Skip()
+		} else {
+			spin := steps.Start("Running go build ./...")
+			buildErr = Build(projectRoot)
+			spin.Stop(buildErr == nil)
 		}
 	}
 
+	if err := state.remove(); err != nil {
+		return fmt.Errorf("remove %s: %w", InitStateFile, err)
+	}
+
+	if toolchainErr != nil {
+		ui.PrintDeferred(toolchainErr.Error(), toolchain.Guidance(toolchainErr), projectRoot, deferred)
+	}
+	if buildErr != nil {
+		var execErr *execx.Error
+		if !errors.As(buildErr, &execErr) {
+			return buildErr
+		}
+		fmt.Println()
+		ui.PrintBuildErrors(execErr.Output)
+		return fmt.Errorf("%s was created but doesn't build; fix the errors above, or re-run init with --no-verify to skip the check", opts.ProjectName)
+	}
+
 	return nil
 }
 
-func renderProjectTemplate(tmplPath, destPath string, data any) error {
-	content, err := templates.FS.ReadFile(tmplPath)
+// checkExistingDir reports whether init may go ahead in projectRoot, which
+// exists and holds no unfinished init: only with Force when it is empty
+// but for .git, and only with Merge when none of init's outputs are there.
+func checkExistingDir(projectRoot string, opts InitOptions) error {
+	if opts.Merge {
+		var conflicts []string
+		outputs, err := initOutputs(opts)
+		if err != nil {
+			return err
+		}
+		for _, p := range outputs {
+			if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(p))); err == nil {
+				conflicts = append(conflicts, p)
+			}
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%s already has files init would overwrite; move them away or init elsewhere:\n    %s",
+				projectRoot, strings.Join(conflicts, "\n    "))
+		}
+		return nil
+	}
+
+	if opts.Force {
+		entries, err := os.ReadDir(projectRoot)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Name() != ".git" {
+				return fmt.Errorf("directory %s is not empty (has %s); use --merge to scaffold around existing files", projectRoot, e.Name())
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("directory %s already exists", projectRoot)
+}
+
+// initOutputs lists the paths, relative to the project root, that init
+// creates: the directories of the modules it fetches, then the files it
+// generates.
+func initOutputs(opts InitOptions) ([]string, error) {
+	modules := slices.Clone(opts.Modules)
+	for _, name := range opts.WireModules {
+		modules = append(modules, config.WireableModuleRegistry[name].RequiredModules...)
+	}
+	resolved, err := config.ResolveDeps(modules)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []string
+	for _, name := range resolved {
+		outputs = append(outputs, config.ModuleRegistry[name].Dirs()...)
+	}
+
+	outputs = append(outputs, "go.mod", "cmd/container.go")
+	profile, err := config.LookupProfile(opts.Profile)
+	if err == nil && !profile.HTTP {
+		outputs = append(outputs, "cmd/main.go")
+	} else {
+		outputs = append(outputs, "cmd/server.go")
+	}
+	outputs = append(outputs, "Makefile", "docker-compose.yml", ".gitignore", config.ManifestoFile, config.LockFile)
+	if err == nil && profile.Static {
+		outputs = append(outputs, "web/index.html")
+	}
+	return outputs, nil
+}
+
+// removeInitOutputs undoes an init that failed before completing a step:
+// the whole project directory, or in a directory that existed before, only
+// what init added to it.
+func removeInitOutputs(projectRoot string, opts InitOptions, existing bool) {
+	if !existing {
+		os.RemoveAll(projectRoot)
+		return
+	}
+	// Init stops before creating anything if its modules don't resolve.
+	outputs, _ := initOutputs(opts)
+	for _, p := range outputs {
+		os.RemoveAll(filepath.Join(projectRoot, filepath.FromSlash(p)))
+	}
+	os.Remove(filepath.Join(projectRoot, InitStateFile))
+}
+
+func renderProjectTemplate(projectRoot, tmplPath, destPath string, data any) error {
+	content, err := readTemplate(projectRoot, tmplPath)
 	if err != nil {
 		return fmt.Errorf("read template %s: %w", tmplPath, err)
 	}
 
-	tmpl, err := template.New(filepath.Base(tmplPath)).Parse(string(content))
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(TemplateFuncs).Parse(string(content))
 	if err != nil {
 		return fmt.Errorf("parse template: %w", err)
 	}
@@ -217,11 +578,19 @@ func renderProjectTemplate(tmplPath, destPath string, data any) error {
 		return fmt.Errorf("execute template: %w", err)
 	}
 
+	out := buf.Bytes()
+	if strings.HasSuffix(destPath, ".go") {
+		// Profiles drop infrastructure fields; let gofmt realign the rest.
+		if formatted, err := format.Source(out); err == nil {
+			out = formatted
+		}
+	}
+
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(destPath, buf.Bytes(), 0644)
+	return os.WriteFile(destPath, out, 0644)
 }
 
 func generateGoMod(projectRoot, goModule string, client *remote.Client, ref string) error {
@@ -243,44 +612,123 @@ func generateGoMod(projectRoot, goModule string, client *remote.Client, ref stri
 	return os.WriteFile(filepath.Join(projectRoot, "go.mod"), buf.Bytes(), 0644)
 }
 
-// EnsureModulesPresent downloads any required source modules that aren't already installed.
-// It updates the manifest's Modules map for each newly downloaded module.
+// EnsureModulesPresent downloads the required source modules that are
+// missing from the project: new ones at ref, and installed ones whose files
+// are gone at the ref recorded for them, so they don't move. It records
+// each download in the manifest's Modules map.
 func EnsureModulesPresent(projectRoot string, manifest *config.Manifest, requiredModules []string, client *remote.Client, ref string) error {
-	var toDownload []string
-	var allPaths []string
-
-	resolved := config.ResolveDeps(requiredModules)
+	modules, err := config.ResolveDeps(requiredModules)
+	if err != nil {
+		return err
+	}
+	refs := make(map[string]string)
+	for _, modName := range modules {
+		mod, ok := config.ModuleRegistry[modName]
+		if !ok || len(mod.Dirs()) == 0 {
+			continue
+		}
+		installed, exists := manifest.Modules[modName]
+		switch {
+		case !exists:
+			refs[modName] = ref
+		case !modulePresent(projectRoot, mod):
+			refs[modName] = cmp.Or(installed.Version, ref)
+		}
+	}
+	return fetchModules(projectRoot, manifest, refs, client)
+}
 
-	for _, modName := range resolved {
-		if _, exists := manifest.Modules[modName]; exists {
+// PinModules downloads the named modules at ref, replacing their files, and
+// records ref for them. Their dependencies and every other module stay on
+// the ref they have; modules already on ref are left alone, unless it is
+// a local checkout, which may have changed since.
+func PinModules(projectRoot string, manifest *config.Manifest, names []string, client *remote.Client, ref string) ([]string, error) {
+	refs := make(map[string]string)
+	var pinned []string
+	for _, name := range names {
+		mod, ok := config.ModuleRegistry[name]
+		if !ok || len(mod.Dirs()) == 0 {
 			continue
 		}
-		mod, ok := config.ModuleRegistry[modName]
-		if !ok || len(mod.Paths) == 0 {
+		_, local := remote.LocalPath(ref)
+		if installed, exists := manifest.Modules[name]; exists && installed.Version == ref && !local && modulePresent(projectRoot, mod) {
 			continue
 		}
-		toDownload = append(toDownload, modName)
-		allPaths = append(allPaths, mod.Paths...)
+		refs[name] = ref
+		pinned = append(pinned, name)
 	}
+	return pinned, fetchModules(projectRoot, manifest, refs, client)
+}
 
-	if len(toDownload) == 0 {
-		return nil
+// fetchModules downloads each module at its ref, one archive per ref, and
+// records it in the manifest. Archives for several refs download
+// concurrently, each on a progress line of its own; the files are then
+// written one ref at a time.
+func fetchModules(projectRoot string, manifest *config.Manifest, refs map[string]string, client *remote.Client) error {
+	byRef := make(map[string][]string)
+	for name, ref := range refs {
+		byRef[ref] = append(byRef[ref], name)
 	}
-
-	if err := client.FetchModulePaths(ref, allPaths, projectRoot, ManifestoGoModule, manifest.Project.GoModule); err != nil {
-		return fmt.Errorf("download modules: %w", err)
+	sorted := slices.Sorted(maps.Keys(byRef))
+	if len(sorted) > 1 {
+		if err := prefetch(client, sorted); err != nil {
+			return fmt.Errorf("download modules: %w", err)
+		}
 	}
+	for _, ref := range sorted {
+		names := byRef[ref]
+		slices.Sort(names)
 
-	for _, modName := range toDownload {
-		manifest.Modules[modName] = config.ModuleConfig{
-			Version:     ref,
-			InstalledAt: time.Now(),
+		var paths []remote.PathMapping
+		for _, name := range names {
+			paths = append(paths, sourcePaths(config.ModuleRegistry[name], manifest.Project.Tests)...)
+		}
+		if err := fetchLocked(client, ref, paths, projectRoot, manifest.Project.GoModule); err != nil {
+			return fmt.Errorf("download modules: %w", err)
 		}
-	}
 
+		for _, name := range names {
+			manifest.Modules[name] = config.ModuleConfig{
+				Version:     ref,
+				InstalledAt: clock.Now(),
+				SHA256:      client.ArchiveChecksum(ref),
+				Mappings:    config.ModuleRegistry[name].Remapped(),
+			}
+		}
+	}
 	return nil
 }
 
+// prefetch downloads the archives for refs concurrently, each reporting on
+// a task of its own. The first error is returned once all have ended.
+func prefetch(client *remote.Client, refs []string) error {
+	errs := make([]error, len(refs))
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		task := ui.AddTask(fmt.Sprintf("manifesto@%s", ref))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = client.Prefetch(ref, func(p remote.Progress) {
+				ui.ShowTransfer(task, p.Bytes, p.Total)
+			})
+			task.Stop(errs[i] == nil)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// modulePresent reports whether all of mod's paths exist in the project.
+func modulePresent(projectRoot string, mod config.Module) bool {
+	for _, p := range mod.Dirs() {
+		if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(p))); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func generateGitignore(projectRoot string) error {
 	content := `.env
 *.exe
@@ -301,3 +749,24 @@ backups/
 `
 	return os.WriteFile(filepath.Join(projectRoot, ".gitignore"), []byte(content), 0644)
 }
+
+// generateWebRoot creates the ./web directory served by fullstack profiles.
+func generateWebRoot(projectRoot, projectName string) error {
+	webDir := filepath.Join(projectRoot, "web")
+	if err := os.MkdirAll(webDir, 0755); err != nil {
+		return err
+	}
+	content := fmt.Sprintf(`<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+</head>
+<body>
+  <h1>%s</h1>
+  <p>Served from ./web. The API lives under /api/v1.</p>
+</body>
+</html>
+`, projectName, projectName)
+	return os.WriteFile(filepath.Join(webDir, "index.html"), []byte(content), 0644)
+}
@@ -0,0 +1,78 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// TestProfilesBuild inits a project with each profile's default modules and
+// wiring and builds it.
+func TestProfilesBuild(t *testing.T) {
+	requireGo(t)
+	for _, name := range config.ProfileNames() {
+		t.Run(name, func(t *testing.T) {
+			profile := config.ProfileRegistry[name]
+			root := initTestProject(t, InitOptions{Profile: name})
+
+			main := "cmd/main.go"
+			if profile.HTTP {
+				main = "cmd/server.go"
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(main))); err != nil {
+				t.Errorf("%s profile has no %s", name, main)
+			}
+			if _, err := os.Stat(filepath.Join(root, "web")); (err == nil) != profile.Static {
+				t.Errorf("%s profile: web/ present = %v, want %v", name, err == nil, profile.Static)
+			}
+			runGo(t, root, "build", "./...")
+			runGo(t, root, "vet", "./...")
+		})
+	}
+}
+
+// TestInitManifestDeterministic inits the same project twice with the
+// clock pinned by SOURCE_DATE_EPOCH, the second time with its modules and
+// wiring listed in reverse: manifesto.yaml must come out byte for byte the
+// same.
+func TestInitManifestDeterministic(t *testing.T) {
+	now := clock.Now
+	t.Cleanup(func() { clock.Now = now })
+	t.Setenv(clock.EpochEnv, "1769904000")
+	if err := clock.FromEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := config.LookupProfile("fullstack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules, err := profile.InitModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wire := profile.InitWire(profile.Wire)
+	modules = append(modules, "fsx", "notifx")
+
+	var manifests []string
+	for _, reverse := range []bool{false, true} {
+		opts := InitOptions{Profile: "fullstack", Modules: slices.Clone(modules), WireModules: slices.Clone(wire)}
+		if reverse {
+			slices.Reverse(opts.Modules)
+			slices.Reverse(opts.WireModules)
+		}
+		root := initTestProject(t, opts)
+		manifests = append(manifests, readFile(t, root, config.ManifestoFile))
+	}
+	if !strings.Contains(manifests[0], "2026-02-01T00:00:00Z") {
+		t.Errorf("%s isn't stamped with SOURCE_DATE_EPOCH:\n%s", config.ManifestoFile, manifests[0])
+	}
+	if manifests[0] != manifests[1] {
+		t.Errorf("%s differs between runs:\n%s\n---\n%s", config.ManifestoFile, manifests[0], manifests[1])
+	}
+}
@@ -0,0 +1,45 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+)
+
+// Route groups a domain registers on in cmd/server.go. Public domains go
+// on app at the public routes marker; the rest on a group variable in
+// registerRoutes, protected unless --group names another.
+const (
+	PublicGroup    = "public"
+	ProtectedGroup = "protected"
+)
+
+// RouteGroup names the group the domain's routes are registered on.
+func (d DomainData) RouteGroup() string {
+	switch {
+	case d.Public:
+		return PublicGroup
+	case d.Group != "":
+		return d.Group
+	}
+	return ProtectedGroup
+}
+
+// CheckRouteGroup reports whether name can be given to --group: a Go
+// identifier other than the ones cmd/server.go uses for something else.
+func CheckRouteGroup(name string) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("invalid route group %q: use the name of a group variable in cmd/server.go's registerRoutes", name)
+	}
+	if name == "app" || name == "container" || name == PublicGroup {
+		return fmt.Errorf("invalid route group %q: use --public for routes outside the protected group", name)
+	}
+	return nil
+}
+
+// declaresRouteGroup reports whether cmd/server.go's text declares the
+// group variable name.
+func declaresRouteGroup(text, name string) bool {
+	q := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`\b` + q + `\s*:=|\bvar\s+` + q + `\b`).MatchString(text)
+}
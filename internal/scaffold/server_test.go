@@ -0,0 +1,149 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// basePathTest is cmd/basepath_test.go for a generated project: it mounts
+// a few routes the way main does and requests them under each BASE_PATH.
+const basePathTest = `package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBasePath(t *testing.T) {
+	tests := []struct {
+		basePath, healthUnprefixed string
+		want                       map[string]int
+	}{
+		{"/svc/billing/", "", map[string]int{
+			"/svc/billing/health":      200,
+			"/svc/billing/api/v1/ping": 200,
+			"/svc/billing":             200,
+			"/health":                  404,
+			"/api/v1/ping":             404,
+			"/svc/billingx/health":     404,
+		}},
+		{"svc/billing", "true", map[string]int{
+			"/svc/billing/health": 200,
+			"/health":             200,
+			"/api/v1/ping":        404,
+		}},
+		{"/", "", map[string]int{
+			"/health":      200,
+			"/api/v1/ping": 200,
+			"/":            200,
+			"/svc/health":  404,
+		}},
+		{"", "", map[string]int{
+			"/health":      200,
+			"/api/v1/ping": 200,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.basePath, func(t *testing.T) {
+			t.Setenv("BASE_PATH", tt.basePath)
+			t.Setenv("HEALTH_UNPREFIXED", tt.healthUnprefixed)
+			app := fiber.New()
+			mountBasePath(app)
+			ok := func(c *fiber.Ctx) error { return c.SendString(c.Path()) }
+			app.Get("/health", ok)
+			app.Get("/", ok)
+			app.Group("/api/v1").Get("/ping", ok)
+			app.Use(notFoundHandler)
+
+			for path, want := range tt.want {
+				resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.StatusCode != want {
+					t.Errorf("GET %s = %d, want %d", path, resp.StatusCode, want)
+				}
+			}
+		})
+	}
+}
+`
+
+// TestServerBasePath runs basePathTest in a generated project, so the
+// server serves its routes under a BASE_PATH and at the root alike.
+func TestServerBasePath(t *testing.T) {
+	requireGo(t)
+	root := initTestProject(t, InitOptions{})
+	if err := os.WriteFile(filepath.Join(root, "cmd", "basepath_test.go"), []byte(basePathTest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, root, "test", "./cmd/")
+}
+
+// TestSwaggerRoutesRelative checks a domain's swag annotations leave the
+// API prefix to @BasePath, so a BASE_PATH isn't added to them twice.
+func TestSwaggerRoutesRelative(t *testing.T) {
+	root := initTestProject(t, InitOptions{})
+	data := NewDomainData("example.com/shop", "pkg/invoice")
+	data.HasSwagger = true
+	if err := GenerateDomain(root, data); err != nil {
+		t.Fatal(err)
+	}
+	handler := readFile(t, root, "pkg/invoice/invoiceapi/handler.go")
+	for _, want := range []string{"@Router   /invoices [post]", "@Router   /invoices/{id} [get]"} {
+		if !strings.Contains(handler, want) {
+			t.Errorf("handler.go lacks %q", want)
+		}
+	}
+	if strings.Contains(handler, "/api/v1") {
+		t.Error("handler.go hardcodes /api/v1")
+	}
+}
+
+// TestDomainRoutesServerShapes adds a protected and a public domain to a
+// server.go without a protected group (quick) and one with iam's: the
+// protected group must be declared exactly once, and the project build.
+func TestDomainRoutesServerShapes(t *testing.T) {
+	requireGo(t)
+	for _, tt := range []struct {
+		profile   string
+		protected bool // server.go starts with a protected group
+	}{
+		{"quick", false},
+		{"api", true},
+	} {
+		t.Run(tt.profile, func(t *testing.T) {
+			root := initTestProject(t, InitOptions{Profile: tt.profile})
+			if got := hasProtectedGroup(readFile(t, root, "cmd/server.go")); got != tt.protected {
+				t.Fatalf("server.go has a protected group = %v, want %v", got, tt.protected)
+			}
+
+			invoice := NewDomainData("example.com/shop", "pkg/invoice")
+			tag := NewDomainData("example.com/shop", "pkg/catalog/tag")
+			tag.Public = true
+			for _, data := range []DomainData{invoice, tag} {
+				if err := GenerateDomain(root, data); err != nil {
+					t.Fatalf("generate %s: %v", data.DomainPath, err)
+				}
+			}
+
+			server := readFile(t, root, "cmd/server.go")
+			if n := strings.Count(server, "protected :="); n != 1 {
+				t.Errorf("server.go declares the protected group %d times", n)
+			}
+			for _, want := range []string{
+				"container.Invoice.RegisterRoutes(protected)",
+				`container.Tag.RegisterRoutes(app.Group("/api/v1"))`,
+			} {
+				if !strings.Contains(server, want) {
+					t.Errorf("server.go lacks %s", want)
+				}
+			}
+			runGo(t, root, "build", "./...")
+		})
+	}
+}
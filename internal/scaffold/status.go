@@ -0,0 +1,143 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// StatusEntry is a module, wired module or domain `manifesto status`
+// compares between manifesto.yaml and the project.
+type StatusEntry struct {
+	Kind   string // "module", "wiring" or "domain"
+	Name   string
+	Detail string // What is missing, or where an untracked one was found
+}
+
+// ProjectStatus sorts what manifesto.yaml records against what is on disk.
+type ProjectStatus struct {
+	Installed []StatusEntry // Recorded and present
+	Missing   []StatusEntry // Recorded but gone from the project
+	Untracked []StatusEntry // In the project but not recorded
+}
+
+// Drifted reports whether manifesto.yaml and the project disagree.
+func (s ProjectStatus) Drifted() bool {
+	return len(s.Missing) > 0 || len(s.Untracked) > 0
+}
+
+// Status compares manifest against the project at projectRoot: installed
+// modules against their directories, wired modules against their guard
+// strings in cmd/container.go, and tracked domains against the files
+// GenerateDomain creates for them.
+func Status(projectRoot string, manifest *config.Manifest) ProjectStatus {
+	var s ProjectStatus
+	s.addModules(projectRoot, manifest)
+	s.addWiring(projectRoot, manifest)
+	s.addDomains(projectRoot, manifest)
+	return s
+}
+
+func (s *ProjectStatus) add(ok bool, e StatusEntry) {
+	if ok {
+		s.Installed = append(s.Installed, e)
+		return
+	}
+	s.Missing = append(s.Missing, e)
+}
+
+// addModules checks each installed module's directories, and looks for
+// the directories of modules manifesto.yaml doesn't list.
+func (s *ProjectStatus) addModules(projectRoot string, manifest *config.Manifest) {
+	names := make([]string, 0, len(config.ModuleRegistry))
+	for name := range config.ModuleRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		paths := config.ModuleRegistry[name].Dirs()
+		var present, absent []string
+		for _, p := range paths {
+			if info, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(p))); err == nil && info.IsDir() {
+				present = append(present, p+"/")
+			} else {
+				absent = append(absent, p+"/")
+			}
+		}
+
+		entry := StatusEntry{Kind: "module", Name: name}
+		if _, ok := manifest.Modules[name]; ok {
+			if len(absent) > 0 {
+				entry.Detail = strings.Join(absent, ", ") + " not found"
+			}
+			s.add(len(absent) == 0, entry)
+		} else if len(paths) > 0 && len(absent) == 0 {
+			entry.Detail = strings.Join(present, ", ") + " exists"
+			s.Untracked = append(s.Untracked, entry)
+		}
+	}
+
+	// Modules the registry no longer knows can't be checked.
+	for name := range manifest.Modules {
+		if _, ok := config.ModuleRegistry[name]; !ok {
+			s.Missing = append(s.Missing, StatusEntry{Kind: "module", Name: name, Detail: "not a known module"})
+		}
+	}
+}
+
+// addWiring looks for each wired module's guard strings in
+// cmd/container.go, and for the guard of each module that isn't wired.
+// Modules the profile provides are left out: their code comes with the
+// templates.
+func (s *ProjectStatus) addWiring(projectRoot string, manifest *config.Manifest) {
+	content, _ := os.ReadFile(filepath.Join(projectRoot, "cmd", "container.go"))
+	container := string(content)
+
+	for _, name := range config.WireableModuleNames() {
+		entry := StatusEntry{Kind: "wiring", Name: name}
+		if !config.HasModule(manifest.WiredModules, name) {
+			if manifest.IsWired(name) {
+				continue
+			}
+			spec := replacePlaceholders(config.WireableModuleRegistry[name], manifest.Project.GoModule, manifest.Project.Name)
+			if guard := wireGuardString(spec); guard != "" && strings.Contains(container, guard) {
+				entry.Detail = fmt.Sprintf("%q found in cmd/container.go", guard)
+				s.Untracked = append(s.Untracked, entry)
+			}
+			continue
+		}
+
+		guards, err := wiringGuards(manifest, name)
+		if err != nil {
+			entry.Detail = err.Error()
+		} else if guard := missingGuard(container, guards); guard != "" {
+			entry.Detail = fmt.Sprintf("%q not found in cmd/container.go", guard)
+		}
+		s.add(entry.Detail == "", entry)
+	}
+
+	for _, name := range manifest.WiredModules {
+		if !config.IsWireableModule(name) {
+			s.Missing = append(s.Missing, StatusEntry{Kind: "wiring", Name: name, Detail: "not a wireable module"})
+		}
+	}
+}
+
+// addDomains checks each tracked domain's generated files.
+func (s *ProjectStatus) addDomains(projectRoot string, manifest *config.Manifest) {
+	for _, d := range TrackedDomains(projectRoot, manifest) {
+		entry := StatusEntry{Kind: "domain", Name: d.Path}
+		if len(d.Missing) > 0 {
+			entry.Detail = fmt.Sprintf("%s not found", d.Missing[0])
+			if len(d.Missing) > 1 {
+				entry.Detail = fmt.Sprintf("%s and %d more file(s) not found", d.Missing[0], len(d.Missing)-1)
+			}
+		}
+		s.add(len(d.Missing) == 0, entry)
+	}
+}
@@ -0,0 +1 @@
+CREATE TABLE IF NOT EXISTS tenants (id TEXT PRIMARY KEY);
@@ -0,0 +1,9 @@
+// Package ai wraps LLM, embedding and OCR providers.
+package ai
+
+import "context"
+
+// Completer returns a model's completion of prompt.
+type Completer interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
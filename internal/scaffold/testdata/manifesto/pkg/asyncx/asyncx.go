@@ -0,0 +1,15 @@
+// Package asyncx has concurrency helpers: futures, fan-out and retries.
+package asyncx
+
+import "context"
+
+// Retry calls fn until it succeeds, ctx is done or attempts run out.
+func Retry(ctx context.Context, attempts int, fn func(context.Context) error) error {
+	var err error
+	for range attempts {
+		if err = fn(ctx); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+// Package config loads the service configuration from the environment.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Server   ServerConfig
+	Database DatabaseConfig
+	Redis    RedisConfig
+}
+
+type ServerConfig struct {
+	Port        int
+	Environment string
+	LogLevel    string
+	CORSOrigins []string
+}
+
+type DatabaseConfig struct {
+	Host            string
+	Port            int
+	User            string
+	Password        string
+	Name            string
+	SSLMode         string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+type RedisConfig struct {
+	Host     string
+	Port     int
+	Password string
+	DB       int
+}
+
+func (r RedisConfig) Address() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+type JobxConfig struct {
+	Concurrency       int
+	Queues            []string
+	PollInterval      time.Duration
+	ShutdownTimeout   time.Duration
+	DequeueTimeout    time.Duration
+	DefaultRetryDelay time.Duration
+}
+
+type NotifxConfig struct {
+	Provider    string
+	AWSRegion   string
+	FromAddress string
+}
+
+func Load() (*Config, error) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port:        getInt("SERVER_PORT", 8080),
+			Environment: getEnv("ENVIRONMENT", "development"),
+			LogLevel:    getEnv("LOG_LEVEL", "info"),
+			CORSOrigins: strings.Split(getEnv("CORS_ORIGINS", ""), ","),
+		},
+		Database: DatabaseConfig{
+			Host:            getEnv("POSTGRES_HOST", "localhost"),
+			Port:            getInt("POSTGRES_PORT", 5432),
+			User:            getEnv("POSTGRES_USER", "postgres"),
+			Password:        getEnv("POSTGRES_PASSWORD", ""),
+			Name:            getEnv("POSTGRES_DB", "app"),
+			SSLMode:         getEnv("POSTGRES_SSLMODE", "disable"),
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
+		},
+		Redis: RedisConfig{
+			Host: getEnv("REDIS_HOST", "localhost"),
+			Port: getInt("REDIS_PORT", 6379),
+		},
+	}
+	return cfg, nil
+}
+
+func (c *Config) IsDevelopment() bool {
+	return c.Server.Environment == "development"
+}
+
+func loadJobxConfig() JobxConfig {
+	return JobxConfig{
+		Concurrency:       getInt("JOBX_CONCURRENCY", 4),
+		Queues:            strings.Split(getEnv("JOBX_QUEUES", "default"), ","),
+		PollInterval:      time.Second,
+		ShutdownTimeout:   30 * time.Second,
+		DequeueTimeout:    5 * time.Second,
+		DefaultRetryDelay: 30 * time.Second,
+	}
+}
+
+func loadNotifxConfig() NotifxConfig {
+	return NotifxConfig{
+		Provider:    getEnv("NOTIFX_PROVIDER", "console"),
+		AWSRegion:   getEnv("AWS_REGION", "us-east-1"),
+		FromAddress: getEnv("NOTIFX_FROM_ADDRESS", "noreply@example.com"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return n
+	}
+	return fallback
+}
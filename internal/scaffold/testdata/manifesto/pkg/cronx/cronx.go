@@ -0,0 +1,41 @@
+// Package cronx runs jobs on cron schedules.
+package cronx
+
+import (
+	"context"
+	"time"
+)
+
+// Job is run on each tick of the schedule it is registered with.
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type Scheduler struct {
+	loc  *time.Location
+	jobs map[string]Job
+}
+
+type Option func(*Scheduler)
+
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) { s.loc = loc }
+}
+
+func NewScheduler(opts ...Option) *Scheduler {
+	s := &Scheduler{loc: time.UTC, jobs: map[string]Job{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Scheduler) Register(schedule string, job Job) error {
+	s.jobs[schedule+" "+job.Name()] = job
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	<-ctx.Done()
+}
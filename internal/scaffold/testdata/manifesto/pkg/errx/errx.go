@@ -0,0 +1,55 @@
+// Package errx is structured error handling with HTTP mapping.
+package errx
+
+import "fmt"
+
+type Type string
+
+const (
+	TypeInternal      Type = "INTERNAL"
+	TypeNotFound      Type = "NOT_FOUND"
+	TypeBusiness      Type = "BUSINESS"
+	TypeValidation    Type = "VALIDATION"
+	TypeAuthorization Type = "AUTHORIZATION"
+)
+
+type Error struct {
+	Code       string
+	Type       Type
+	HTTPStatus int
+	Message    string
+	Details    map[string]any
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func Wrap(err error, message string, t Type) *Error {
+	return &Error{Code: string(t), Type: t, HTTPStatus: 500, Message: message, Err: err}
+}
+
+type Registry struct {
+	prefix string
+	codes  map[string]*Error
+}
+
+func NewRegistry(prefix string) *Registry {
+	return &Registry{prefix: prefix, codes: make(map[string]*Error)}
+}
+
+func (r *Registry) Register(code string, t Type, status int, message string) string {
+	r.codes[code] = &Error{Code: code, Type: t, HTTPStatus: status, Message: message}
+	return code
+}
+
+func (r *Registry) New(code string) *Error {
+	e := *r.codes[code]
+	return &e
+}
@@ -0,0 +1,13 @@
+// Package fsx abstracts file storage over local disk and S3.
+package fsx
+
+import (
+	"context"
+	"io"
+)
+
+type FileSystem interface {
+	WriteFileStream(ctx context.Context, path string, r io.Reader) error
+	ReadFileStream(ctx context.Context, path string) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, path string) error
+}
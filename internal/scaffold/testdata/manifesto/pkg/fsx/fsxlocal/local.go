@@ -0,0 +1,46 @@
+// Package fsxlocal stores files on local disk.
+package fsxlocal
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type LocalFileSystem struct {
+	basePath string
+}
+
+func NewLocalFileSystem(basePath string) (*LocalFileSystem, error) {
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalFileSystem{basePath: basePath}, nil
+}
+
+func (fs *LocalFileSystem) GetBasePath() string {
+	return fs.basePath
+}
+
+func (fs *LocalFileSystem) WriteFileStream(ctx context.Context, path string, r io.Reader) error {
+	full := filepath.Join(fs.basePath, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(full)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (fs *LocalFileSystem) ReadFileStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(fs.basePath, path))
+}
+
+func (fs *LocalFileSystem) DeleteFile(ctx context.Context, path string) error {
+	return os.Remove(filepath.Join(fs.basePath, path))
+}
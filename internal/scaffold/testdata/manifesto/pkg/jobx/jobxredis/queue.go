@@ -0,0 +1,24 @@
+// Package jobxredis is a jobx.Queue on redis lists.
+package jobxredis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisQueue struct {
+	client *redis.Client
+}
+
+func NewRedisQueue(client *redis.Client) *RedisQueue {
+	return &RedisQueue{client: client}
+}
+
+func (q *RedisQueue) Push(ctx context.Context, queue string, payload []byte) error {
+	return q.client.LPush(ctx, "jobx:"+queue, payload).Err()
+}
+
+func (q *RedisQueue) Size(ctx context.Context, queue string) (int64, error) {
+	return q.client.LLen(ctx, "jobx:"+queue).Result()
+}
@@ -0,0 +1,17 @@
+package kernel
+
+import "slices"
+
+// AuthContext is the authenticated caller iam's middleware stores on a
+// request.
+type AuthContext struct {
+	UserID   *UserID  `json:"user_id,omitempty"`
+	TenantID TenantID `json:"tenant_id"`
+	Email    string   `json:"email"`
+	Scopes   []string `json:"scopes"`
+	IsAPIKey bool     `json:"is_api_key"`
+}
+
+func (a *AuthContext) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
@@ -0,0 +1,12 @@
+// Package kernel holds the domain primitives shared by every module.
+package kernel
+
+type UserID string
+
+func NewUserID(id string) UserID { return UserID(id) }
+func (id UserID) String() string { return string(id) }
+
+type TenantID string
+
+func NewTenantID(id string) TenantID { return TenantID(id) }
+func (id TenantID) String() string   { return string(id) }
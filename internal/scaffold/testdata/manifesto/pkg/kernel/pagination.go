@@ -0,0 +1,22 @@
+package kernel
+
+type PaginationOptions struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+type Paginated[T any] struct {
+	Items      []T `json:"items"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+func NewPaginated[T any](items []T, page, pageSize, total int) Paginated[T] {
+	pages := 0
+	if pageSize > 0 {
+		pages = (total + pageSize - 1) / pageSize
+	}
+	return Paginated[T]{Items: items, Page: page, PageSize: pageSize, Total: total, TotalPages: pages}
+}
@@ -0,0 +1,60 @@
+// Package logx is structured logging.
+package logx
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var level = LevelInfo
+
+func SetLevel(l Level) { level = l }
+
+type Fields map[string]any
+
+type Entry struct{ fields Fields }
+
+func WithFields(f Fields) *Entry { return &Entry{fields: f} }
+
+func (e *Entry) Errorf(format string, args ...any) {
+	Errorf("%v "+format, append([]any{e.fields}, args...)...)
+}
+func (e *Entry) Infof(format string, args ...any) {
+	Infof("%v "+format, append([]any{e.fields}, args...)...)
+}
+
+func Debug(args ...any) { logAt(LevelDebug, fmt.Sprint(args...)) }
+func Info(args ...any)  { logAt(LevelInfo, fmt.Sprint(args...)) }
+func Warn(args ...any)  { logAt(LevelWarn, fmt.Sprint(args...)) }
+func Error(args ...any) { logAt(LevelError, fmt.Sprint(args...)) }
+
+func Debugf(format string, args ...any) { logAt(LevelDebug, fmt.Sprintf(format, args...)) }
+func Infof(format string, args ...any)  { logAt(LevelInfo, fmt.Sprintf(format, args...)) }
+func Warnf(format string, args ...any)  { logAt(LevelWarn, fmt.Sprintf(format, args...)) }
+func Errorf(format string, args ...any) { logAt(LevelError, fmt.Sprintf(format, args...)) }
+
+func Fatal(args ...any) {
+	logAt(LevelError, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func Fatalf(format string, args ...any) {
+	logAt(LevelError, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func logAt(l Level, msg string) {
+	if l >= level {
+		log.Print(msg)
+	}
+}
@@ -0,0 +1,28 @@
+// Package notifx sends email notifications.
+package notifx
+
+import "context"
+
+type EmailMessage struct {
+	To       []string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// EmailSender delivers email through a provider.
+type EmailSender interface {
+	SendEmail(ctx context.Context, msg EmailMessage) error
+}
+
+type Client struct {
+	sender EmailSender
+}
+
+func NewClient(sender EmailSender) *Client {
+	return &Client{sender: sender}
+}
+
+func (c *Client) SendEmail(ctx context.Context, msg EmailMessage) error {
+	return c.sender.SendEmail(ctx, msg)
+}
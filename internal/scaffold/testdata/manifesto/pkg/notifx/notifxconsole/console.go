@@ -0,0 +1,20 @@
+// Package notifxconsole logs email instead of sending it.
+package notifxconsole
+
+import (
+	"context"
+
+	"github.com/Abraxas-365/manifesto/pkg/logx"
+	"github.com/Abraxas-365/manifesto/pkg/notifx"
+)
+
+type ConsoleProvider struct{}
+
+func NewConsoleProvider() *ConsoleProvider {
+	return &ConsoleProvider{}
+}
+
+func (p *ConsoleProvider) SendEmail(ctx context.Context, msg notifx.EmailMessage) error {
+	logx.Infof("email to %v: %s", msg.To, msg.Subject)
+	return nil
+}
@@ -0,0 +1,3 @@
+package ptrx
+
+func To[T any](v T) *T { return &v }
@@ -0,0 +1,159 @@
+package invoiceapi
+
+// TestE2EListPagination is copied into an invoice domain generated with
+// number:string,amount:int,paid:bool fields by TestDomainListPagination.
+// It serves List from the Postgres repository over a database/sql driver
+// that records the queries it is sent.
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/shop/pkg/invoice"
+	"example.com/shop/pkg/invoice/invoiceinfra"
+	"example.com/shop/pkg/invoice/invoicesrv"
+	"example.com/shop/pkg/kernel"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+)
+
+type query struct {
+	sql  string
+	args []driver.Value
+}
+
+// recorder is a database holding 3 invoices of tenant-a, of which every
+// SELECT but the count returns the first.
+type recorder struct{ queries []query }
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return r, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+func (r *recorder) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (r *recorder) Close() error                                 { return nil }
+func (r *recorder) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+func (r *recorder) QueryContext(_ context.Context, sql string, named []driver.NamedValue) (driver.Rows, error) {
+	q := query{sql: sql}
+	for _, arg := range named {
+		q.args = append(q.args, arg.Value)
+	}
+	r.queries = append(r.queries, q)
+	if strings.Contains(sql, "COUNT(*)") {
+		return &rows{columns: []string{"count"}, values: [][]driver.Value{{int64(3)}}}, nil
+	}
+	now := time.Now()
+	return &rows{
+		columns: []string{"id", "tenant_id", "number", "amount", "paid", "created_at", "updated_at"},
+		values:  [][]driver.Value{{"1", "tenant-a", "INV-1", int64(10), false, now, now}},
+	}, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestE2EListPagination(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		order  string         // ORDER BY of the page's query
+		args   []driver.Value // Its LIMIT and OFFSET
+		page   kernel.Paginated[invoice.Invoice]
+	}{
+		{
+			name:   "second page by amount descending",
+			query:  "page=2&page_size=2&sort=-amount",
+			status: fiber.StatusOK,
+			order:  "ORDER BY amount DESC, id LIMIT $2 OFFSET $3",
+			args:   []driver.Value{int64(2), int64(2)},
+			page:   kernel.Paginated[invoice.Invoice]{Page: 2, PageSize: 2, Total: 3, TotalPages: 2},
+		},
+		{
+			name:   "defaults",
+			query:  "",
+			status: fiber.StatusOK,
+			order:  "ORDER BY created_at DESC, id LIMIT $2 OFFSET $3",
+			args:   []driver.Value{int64(20), int64(0)},
+			page:   kernel.Paginated[invoice.Invoice]{Page: 1, PageSize: 20, Total: 3, TotalPages: 1},
+		},
+		{
+			name:   "out of range",
+			query:  "page=0&page_size=1000&sort=number",
+			status: fiber.StatusOK,
+			order:  "ORDER BY number, id LIMIT $2 OFFSET $3",
+			args:   []driver.Value{int64(100), int64(0)},
+			page:   kernel.Paginated[invoice.Invoice]{Page: 1, PageSize: 100, Total: 3, TotalPages: 1},
+		},
+		{name: "unknown sort field", query: "sort=nosuch", status: fiber.StatusBadRequest},
+		{name: "bool sort field", query: "sort=-paid", status: fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &recorder{}
+			repo := invoiceinfra.NewPostgresInvoiceRepository(sqlx.NewDb(sql.OpenDB(db), "postgres"))
+			app := fiber.New(fiber.Config{ErrorHandler: testErrorHandler})
+			NewInvoiceHandlers(invoicesrv.NewInvoiceService(repo)).RegisterRoutes(app)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/invoices?tenant_id=tenant-a&"+tt.query, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != fiber.StatusOK {
+				if len(db.queries) > 0 {
+					t.Errorf("a rejected sort queried the database: %v", db.queries)
+				}
+				return
+			}
+
+			if len(db.queries) != 2 {
+				t.Fatalf("ran %d queries, want a count and a page: %v", len(db.queries), db.queries)
+			}
+			count, page := db.queries[0], db.queries[1]
+			if count.sql != "SELECT COUNT(*) FROM invoices WHERE tenant_id = $1" || !slices.Equal(count.args, []driver.Value{"tenant-a"}) {
+				t.Errorf("count query = %q %v", count.sql, count.args)
+			}
+			if !strings.HasSuffix(page.sql, tt.order) || !slices.Equal(page.args, append([]driver.Value{"tenant-a"}, tt.args...)) {
+				t.Errorf("page query = %q %v, want one ending in %q with LIMIT and OFFSET %v", page.sql, page.args, tt.order, tt.args)
+			}
+
+			var got kernel.Paginated[invoice.Invoice]
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Items) != 1 || got.Items[0].Number != "INV-1" {
+				t.Errorf("items = %+v, want INV-1", got.Items)
+			}
+			got.Items = nil
+			if !reflect.DeepEqual(got, tt.page) {
+				t.Errorf("envelope = %+v, want %+v", got, tt.page)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// CheckUninstall returns an error when name isn't an installed module the
+// project can do without: core modules, dependencies of other installed
+// modules and modules a wired module requires stay.
+func CheckUninstall(manifest *config.Manifest, name string) error {
+	mod, ok := config.ModuleRegistry[name]
+	if !ok {
+		return fmt.Errorf("unknown module: '%s'. Run 'manifesto modules' to see available modules", name)
+	}
+	if _, ok := manifest.Modules[name]; !ok {
+		return fmt.Errorf("module '%s' is not installed", name)
+	}
+	if mod.Core {
+		return fmt.Errorf("module '%s' is a core module; every project needs it", name)
+	}
+
+	var dependents []string
+	for other := range manifest.Modules {
+		if slices.Contains(config.ModuleRegistry[other].Deps, name) {
+			dependents = append(dependents, other)
+		}
+	}
+	if len(dependents) > 0 {
+		slices.Sort(dependents)
+		return fmt.Errorf("module '%s' is a dependency of %s; uninstall those first", name, strings.Join(dependents, ", "))
+	}
+
+	var wired []string
+	for _, w := range manifest.WiredModules {
+		if slices.Contains(config.WireableModuleRegistry[w].RequiredModules, name) {
+			wired = append(wired, w)
+		}
+	}
+	if len(wired) > 0 {
+		return fmt.Errorf("module '%s' is required by the wired module(s) %s", name, strings.Join(wired, ", "))
+	}
+	return nil
+}
+
+// ModuleImporters returns the project's Go files outside mod that import
+// one of its packages, as slash paths relative to projectRoot, sorted.
+// vendor/, testdata/ and nested modules aren't searched, and files that
+// don't parse are skipped.
+func ModuleImporters(projectRoot, goModule string, mod config.Module) ([]string, error) {
+	var prefixes []string
+	for _, p := range mod.Dirs() {
+		prefixes = append(prefixes, goModule+"/"+p)
+	}
+
+	var importers []string
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(projectRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(projectRoot, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if p == projectRoot {
+				return nil
+			}
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || slices.Contains(mod.Dirs(), rel) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, spec := range f.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if slices.ContainsFunc(prefixes, func(prefix string) bool {
+				return imp == prefix || strings.HasPrefix(imp, prefix+"/")
+			}) {
+				importers = append(importers, rel)
+				break
+			}
+		}
+		return nil
+	})
+	return importers, err
+}
+
+// UninstallModule deletes the module's directories, drops its files from
+// config.LockFile and removes it from the manifest. CheckUninstall says
+// whether the project can do without it.
+func UninstallModule(projectRoot string, manifest *config.Manifest, name string) error {
+	mod := config.ModuleRegistry[name]
+	for _, p := range mod.Dirs() {
+		if err := os.RemoveAll(filepath.Join(projectRoot, filepath.FromSlash(p))); err != nil {
+			return err
+		}
+	}
+
+	lock, err := config.LoadLock(projectRoot)
+	if err != nil {
+		return err
+	}
+	if lock.Forget(mod.Dirs()) {
+		if err := lock.Save(projectRoot); err != nil {
+			return err
+		}
+	}
+
+	delete(manifest.Modules, name)
+	if err := manifest.Save(projectRoot); err != nil {
+		return fmt.Errorf("save manifesto.yaml: %w", err)
+	}
+	return nil
+}
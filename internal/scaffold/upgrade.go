@@ -0,0 +1,203 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/clock"
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/diff"
+	"github.com/Abraxas-365/manifesto-cli/internal/remote"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
+)
+
+type UpgradeOptions struct {
+	ProjectRoot string
+	ModuleName  string
+	Ref         string          // Default: the project's channel, as for add
+	Resolve     diff.Resolution // How hunks both sides changed are settled
+}
+
+// UpgradeResult says what UpgradeModule did with each of the module's
+// files, by slash path relative to the project root.
+type UpgradeResult struct {
+	From, To  string
+	Added     []string // Files new in the new version
+	Replaced  []string // Unedited files now at the new version
+	Removed   []string // Unedited files the new version no longer has
+	Kept      []string // Files edited locally that upstream didn't change or removed
+	Merged    []string // Files changed on both sides, merged without conflicts
+	Conflicts []string // Files changed on both sides with conflicting hunks
+}
+
+// UpgradeModule moves an installed module to another manifesto version
+// with a three-way merge against the version it was installed from.
+// Files unedited since they were fetched, according to config.LockFile,
+// are replaced; files only edited locally are kept; files changed on both
+// sides are merged, with opts.Resolve settling conflicting hunks. Like
+// add --ref, the module moves on its own; its dependencies stay where
+// they are.
+func UpgradeModule(opts UpgradeOptions) (*UpgradeResult, error) {
+	manifest, err := config.LoadManifest(opts.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("not a manifesto project: %w", err)
+	}
+	mod, ok := config.ModuleRegistry[opts.ModuleName]
+	if !ok {
+		return nil, fmt.Errorf("unknown module: '%s'. Run 'manifesto modules' to see available modules", opts.ModuleName)
+	}
+	installed, ok := manifest.Modules[opts.ModuleName]
+	if !ok || len(mod.Dirs()) == 0 {
+		return nil, fmt.Errorf("module '%s' is not installed", opts.ModuleName)
+	}
+	if installed.Version == "" {
+		return nil, fmt.Errorf("module '%s' has no recorded version to merge against; move it with 'manifesto add --ref' instead", opts.ModuleName)
+	}
+
+	client := remote.NewClient(manifest.Project.Repo)
+	client.ForceRefType(remote.RefType(manifest.Project.RefType))
+	ref := opts.Ref
+	if ref == "" {
+		ref = ChannelRef(manifest, client)
+	}
+	if ref == installed.Version {
+		return nil, fmt.Errorf("module '%s' is already at manifesto@%s", opts.ModuleName, ref)
+	}
+
+	spin := ui.NewSpinner(fmt.Sprintf("Upgrading %s from manifesto@%s to manifesto@%s...", opts.ModuleName, installed.Version, ref))
+	spin.Start()
+	ReportProgress(client, spin)
+
+	// The version installed is read from where it was fetched then.
+	was := installedModule(mod, installed)
+	base, err := client.ReadModulePaths(installed.Version, sourcePaths(was, manifest.Project.Tests), ManifestoGoModule, manifest.Project.GoModule)
+	if err != nil {
+		spin.Stop(false)
+		return nil, fmt.Errorf("read manifesto@%s, which %s was installed from: %w", installed.Version, opts.ModuleName, err)
+	}
+	theirs, err := client.ReadModulePaths(ref, sourcePaths(mod, manifest.Project.Tests), ManifestoGoModule, manifest.Project.GoModule)
+	if err != nil {
+		spin.Stop(false)
+		return nil, fmt.Errorf("read manifesto@%s: %w", ref, err)
+	}
+
+	result, err := mergeModule(opts.ProjectRoot, mod.Dirs(), base, theirs, opts.Resolve, "manifesto@"+ref)
+	if err != nil {
+		spin.Stop(false)
+		return nil, err
+	}
+	spin.Stop(true)
+	result.From, result.To = installed.Version, ref
+
+	manifest.Modules[opts.ModuleName] = config.ModuleConfig{
+		Version:     ref,
+		InstalledAt: clock.Now(),
+		SHA256:      client.ArchiveChecksum(ref),
+		Mappings:    mod.Remapped(),
+	}
+	if mixed := manifest.MixedMajors(); mixed != "" {
+		ui.StepWarn(MixedMajorsWarning(mixed))
+	}
+	if err := manifest.Save(opts.ProjectRoot); err != nil {
+		return nil, fmt.Errorf("save manifesto.yaml: %w", err)
+	}
+	return result, nil
+}
+
+// mergeModule applies the change from base to theirs, both keyed by slash
+// path, to the module's files under dirs on disk, and records theirs in
+// the lock.
+func mergeModule(projectRoot string, dirs []string, base, theirs map[string][]byte, res diff.Resolution, theirsLabel string) (*UpgradeResult, error) {
+	lock, err := config.LoadLock(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	edited := make(map[string]bool)
+	for _, p := range lock.Modified(projectRoot, dirs) {
+		edited[p] = true
+	}
+
+	result := &UpgradeResult{}
+	paths := slices.Sorted(maps.Keys(base))
+	for p := range theirs {
+		if _, ok := base[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+
+	for _, p := range paths {
+		path := filepath.Join(projectRoot, filepath.FromSlash(p))
+		ours, err := os.ReadFile(path)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		b, inBase := base[p]
+		t, inTheirs := theirs[p]
+
+		// Files the lock doesn't know are compared with the version
+		// installed instead.
+		changed := exists && edited[p]
+		if _, locked := lock.Files[p]; exists && !locked {
+			changed = !inBase || !bytes.Equal(ours, b)
+		}
+
+		switch {
+		case !exists && inBase:
+			// Deleted locally; it stays deleted.
+			result.Kept = append(result.Kept, p)
+		case !exists, !changed:
+			if !inTheirs {
+				if exists {
+					if err := os.Remove(path); err != nil {
+						return nil, err
+					}
+				}
+				result.Removed = append(result.Removed, p)
+				break
+			}
+			if bytes.Equal(ours, t) && exists {
+				break
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, t, 0644); err != nil {
+				return nil, err
+			}
+			if inBase {
+				result.Replaced = append(result.Replaced, p)
+			} else {
+				result.Added = append(result.Added, p)
+			}
+		case !inTheirs, inBase && bytes.Equal(b, t):
+			result.Kept = append(result.Kept, p)
+		case bytes.Equal(ours, t):
+			// Edited locally into what upstream now has.
+		default:
+			merged, conflicts := diff.Merge3(string(b), string(ours), string(t), res, "local", theirsLabel)
+			if err := os.WriteFile(path, []byte(merged), 0644); err != nil {
+				return nil, err
+			}
+			if conflicts > 0 {
+				result.Conflicts = append(result.Conflicts, p)
+			} else {
+				result.Merged = append(result.Merged, p)
+			}
+		}
+
+		if inTheirs {
+			lock.Record(p, t)
+		} else {
+			delete(lock.Files, p)
+		}
+	}
+	return result, lock.Save(projectRoot)
+}
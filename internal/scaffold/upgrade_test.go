@@ -0,0 +1,145 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/diff"
+)
+
+// TestMergeModule upgrades a module's files in every state the project can
+// hold them in against what upstream did to each.
+func TestMergeModule(t *testing.T) {
+	const (
+		base    = "package errx\n\nconst A = 1\n\nconst B = 2\n"
+		local   = "package errx\n\nconst A = 10\n\nconst B = 2\n" // A edited
+		clash   = "package errx\n\nconst A = 11\n\nconst B = 2\n" // A edited locally, differently
+		upgrade = "package errx\n\nconst A = 1\n\nconst B = 20\n" // B edited
+		both    = "package errx\n\nconst A = 10\n\nconst B = 20\n"
+		rival   = "package errx\n\nconst A = 2\n\nconst B = 2\n" // A edited upstream
+	)
+	type file struct {
+		base, theirs *string // nil when the version lacks the file
+		disk         *string // nil when the project lacks it
+		locked       bool    // The lock has the hash of base
+	}
+	s := func(v string) *string { return &v }
+	files := map[string]file{
+		"pkg/errx/unedited.go":     {base: s(base), theirs: s(upgrade), disk: s(base), locked: true},
+		"pkg/errx/edited.go":       {base: s(base), theirs: s(base), disk: s(local), locked: true},
+		"pkg/errx/merged.go":       {base: s(base), theirs: s(upgrade), disk: s(local), locked: true},
+		"pkg/errx/conflict.go":     {base: s(base), theirs: s(rival), disk: s(clash), locked: true},
+		"pkg/errx/added.go":        {theirs: s(upgrade)},
+		"pkg/errx/deleted.go":      {base: s(base), theirs: s(upgrade), locked: true},
+		"pkg/errx/removed.go":      {base: s(base), disk: s(base), locked: true},
+		"pkg/errx/removededit.go":  {base: s(base), disk: s(local), locked: true},
+		"pkg/errx/unlocked.go":     {base: s(base), theirs: s(upgrade), disk: s(base)},
+		"pkg/errx/unlockededit.go": {base: s(base), theirs: s(upgrade), disk: s(local)},
+	}
+
+	tests := []struct {
+		res      diff.Resolution
+		conflict string // What conflict.go ends up as
+	}{
+		{diff.Mark, "package errx\n\n<<<<<<< local\nconst A = 11\n=======\nconst A = 2\n>>>>>>> manifesto@v2\n\nconst B = 2\n"},
+		{diff.Ours, clash},
+		{diff.Theirs, rival},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		baseFiles, theirsFiles := make(map[string][]byte), make(map[string][]byte)
+		lock := &config.Lock{Files: make(map[string]string)}
+		for p, f := range files {
+			if f.base != nil {
+				baseFiles[p] = []byte(*f.base)
+			}
+			if f.theirs != nil {
+				theirsFiles[p] = []byte(*f.theirs)
+			}
+			if f.disk != nil {
+				writeFiles(t, root, map[string]string{p: *f.disk})
+			}
+			if f.locked {
+				lock.Record(p, []byte(*f.base))
+			}
+		}
+		if err := lock.Save(root); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := mergeModule(root, []string{"pkg/errx"}, baseFiles, theirsFiles, tt.res, "manifesto@v2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &UpgradeResult{
+			Added:     []string{"pkg/errx/added.go"},
+			Replaced:  []string{"pkg/errx/unedited.go", "pkg/errx/unlocked.go"},
+			Removed:   []string{"pkg/errx/removed.go"},
+			Kept:      []string{"pkg/errx/deleted.go", "pkg/errx/edited.go", "pkg/errx/removededit.go"},
+			Merged:    []string{"pkg/errx/merged.go", "pkg/errx/unlockededit.go"},
+			Conflicts: []string{"pkg/errx/conflict.go"},
+		}
+		for _, field := range []struct {
+			name      string
+			got, want []string
+		}{
+			{"Added", result.Added, want.Added},
+			{"Replaced", result.Replaced, want.Replaced},
+			{"Removed", result.Removed, want.Removed},
+			{"Kept", result.Kept, want.Kept},
+			{"Merged", result.Merged, want.Merged},
+			{"Conflicts", result.Conflicts, want.Conflicts},
+		} {
+			if !slices.Equal(field.got, field.want) {
+				t.Errorf("resolution %d: %s = %q, want %q", tt.res, field.name, field.got, field.want)
+			}
+		}
+
+		wantDisk := map[string]*string{
+			"pkg/errx/unedited.go":     s(upgrade),
+			"pkg/errx/edited.go":       s(local),
+			"pkg/errx/merged.go":       s(both),
+			"pkg/errx/conflict.go":     s(tt.conflict),
+			"pkg/errx/added.go":        s(upgrade),
+			"pkg/errx/deleted.go":      nil,
+			"pkg/errx/removed.go":      nil,
+			"pkg/errx/removededit.go":  s(local),
+			"pkg/errx/unlocked.go":     s(upgrade),
+			"pkg/errx/unlockededit.go": s(both),
+		}
+		for p, want := range wantDisk {
+			data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(p)))
+			switch {
+			case want == nil && err == nil:
+				t.Errorf("resolution %d: %s exists", tt.res, p)
+			case want != nil && err != nil:
+				t.Errorf("resolution %d: %v", tt.res, err)
+			case want != nil && string(data) != *want:
+				t.Errorf("resolution %d: %s =\n%s\nwant:\n%s", tt.res, p, data, *want)
+			}
+		}
+
+		// The lock follows upstream: its files at the new version, and
+		// nothing for the ones it dropped.
+		saved, err := config.LoadLock(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		upstream := &config.Lock{Files: make(map[string]string)}
+		for p, content := range theirsFiles {
+			upstream.Record(p, content)
+		}
+		if len(saved.Files) != len(upstream.Files) {
+			t.Errorf("resolution %d: lock has %d files, want %d", tt.res, len(saved.Files), len(upstream.Files))
+		}
+		for p, sum := range upstream.Files {
+			if saved.Files[p] != sum {
+				t.Errorf("resolution %d: lock doesn't have %s at upstream's version", tt.res, p)
+			}
+		}
+	}
+}
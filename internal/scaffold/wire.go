@@ -1,94 +1,259 @@
 package scaffold
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Abraxas-365/manifesto-cli/internal/config"
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
+	"github.com/Abraxas-365/manifesto-cli/internal/toolchain"
+	"github.com/Abraxas-365/manifesto-cli/internal/ui"
 )
 
 // WireOptions configures a module wiring operation.
 type WireOptions struct {
-	ProjectRoot  string
-	ModuleName   string
-	GoModule     string   // From manifest
-	ProjectName  string   // From manifest
-	WiredModules []string // Already wired modules (for bridge detection)
+	ProjectRoot    string
+	ModuleName     string
+	GoModule       string            // From manifest
+	ProjectName    string            // From manifest
+	WiredModules   []string          // Already wired or profile-provided modules (for bridge detection)
+	WiredProviders map[string]string // Provider each wired module was wired with (for bridges on a provider)
+	Provider       string            // For modules with providers; empty means the default one
+	EnvTarget      string            // Where env variables go (config.EnvTargetMakefile, ...); empty means the default
+	NoServer       bool              // The project has no HTTP server (worker profile); server injections are skipped
+
+	// Steps renders the wiring as its next step. Nil renders nothing.
+	Steps *ui.Steps
 }
 
 // WireResult holds the outcome of a wire operation.
 type WireResult struct {
-	ModifiedFiles   []string
-	ActivatedBridges []string
+	ModifiedFiles    []string
+	ActivatedBridges []string // Bridges injected, by config.BridgeName
+	GoDeps           []string // External Go dependencies required by the module
+	Provider         string   // Provider the module was wired with; empty if it has none
+	SkippedServer    bool     // The module's server injections were skipped: the project has no HTTP server
+
+	// Deferred lists commands that could not run (e.g. go is missing or too
+	// old) and must be run manually. ToolchainErr explains why.
+	Deferred     []string
+	ToolchainErr error
 }
 
 // WireModule wires a module into the project by injecting code at marker points
 // in config.go, container.go, server.go, and Makefile. Returns the result.
+//
+// The edits are made in memory and written only once every one of them
+// applies, so a missing marker or a failed injection leaves the project as
+// it was. The files are put back too when installing the module's Go
+// dependencies fails afterwards. ModifiedFiles lists the files that
+// changed.
 func WireModule(opts WireOptions) (*WireResult, error) {
+	var spin *ui.Spinner
+	if opts.Steps != nil {
+		spin = opts.Steps.Start(fmt.Sprintf("Wiring %s...", opts.ModuleName))
+	}
+	preview := NewPreview(opts.ProjectRoot)
+	result, err := wireModule(preview, opts)
+	if err == nil && len(preview.Skipped()) > 0 {
+		err = fmt.Errorf("%s can't be wired cleanly, nothing was changed: %s; run 'manifesto doctor --fix'", opts.ModuleName, strings.Join(preview.Skipped(), "; "))
+	}
+	if err == nil {
+		result.ModifiedFiles, err = preview.Commit()
+		if err == nil {
+			err = installAndRelock(opts.ProjectRoot, result)
+			if err != nil {
+				// Don't leave code behind that needs what failed to install.
+				err = errors.Join(err, preview.Rollback())
+			}
+		}
+	}
+	if spin != nil {
+		spin.Stop(err == nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// installAndRelock installs the Go dependencies of the wiring in result,
+// whose files are written, and records the files in the lock.
+func installAndRelock(projectRoot string, result *WireResult) error {
+	if err := installWireDeps(projectRoot, result); err != nil {
+		return err
+	}
+	// config.go comes from manifesto; wiring edits don't count as local ones.
+	if err := Relock(projectRoot, result.ModifiedFiles...); err != nil {
+		return fmt.Errorf("update %s: %w", config.LockFile, err)
+	}
+	return nil
+}
+
+// PreviewWire runs WireModule against an in-memory Preview. Go dependencies
+// are reported in the result but not installed.
+func PreviewWire(opts WireOptions) (*Preview, *WireResult, error) {
+	preview := NewPreview(opts.ProjectRoot)
+	result, err := wireModule(preview, opts)
+	return preview, result, err
+}
+
+func wireModule(fs FileStore, opts WireOptions) (*WireResult, error) {
 	spec, ok := config.WireableModuleRegistry[opts.ModuleName]
 	if !ok {
 		return nil, fmt.Errorf("unknown wireable module: %s", opts.ModuleName)
 	}
 
+	result := &WireResult{}
+	if len(spec.Providers) > 0 {
+		result.Provider = cmp.Or(opts.Provider, spec.DefaultProvider)
+	}
+	spec, err := spec.WithProvider(opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+
 	// Replace placeholders with actual project values.
 	spec = replacePlaceholders(spec, opts.GoModule, opts.ProjectName)
 
-	result := &WireResult{}
-
 	// 1. Inject into pkg/config/config.go
 	if spec.ConfigFields != "" || spec.ConfigLoads != "" {
-		if err := injectWireConfig(opts.ProjectRoot, spec); err != nil {
+		if err := injectWireConfig(fs, opts.ProjectRoot, spec); err != nil {
 			return nil, fmt.Errorf("wire config: %w", err)
 		}
 		result.ModifiedFiles = append(result.ModifiedFiles, "pkg/config/config.go")
 	}
 
 	// 2. Inject into cmd/container.go
-	if err := injectWireContainer(opts.ProjectRoot, spec); err != nil {
+	if err := injectWireContainer(fs, opts.ProjectRoot, spec); err != nil {
 		return nil, fmt.Errorf("wire container: %w", err)
 	}
 	result.ModifiedFiles = append(result.ModifiedFiles, "cmd/container.go")
 
-	// 3. Inject into cmd/server.go (if module has server injections)
-	if spec.PublicRoutes != "" || spec.RouteRegistration != "" || spec.AuthMiddleware != "" || spec.ServerImports != "" {
-		if err := injectWireServer(opts.ProjectRoot, spec); err != nil {
-			return nil, fmt.Errorf("wire server: %w", err)
+	// 3. Inject into cmd/server.go (if module has server injections and the
+	// project serves HTTP; worker projects have no server.go)
+	if spec.ServerMiddleware != "" || spec.PublicRoutes != "" || spec.RouteRegistration != "" || spec.AuthMiddleware != "" || spec.ServerImports != "" {
+		if opts.NoServer {
+			result.SkippedServer = true
+		} else {
+			if err := injectWireServer(fs, opts.ProjectRoot, spec); err != nil {
+				return nil, fmt.Errorf("wire server: %w", err)
+			}
+			result.ModifiedFiles = append(result.ModifiedFiles, "cmd/server.go")
 		}
-		result.ModifiedFiles = append(result.ModifiedFiles, "cmd/server.go")
 	}
 
-	// 4. Inject into Makefile
-	if spec.MakefileEnv != "" || spec.MakefileEnvDisplay != "" {
-		if err := injectIntoMakefile(opts.ProjectRoot, spec); err != nil {
+	// 4. Inject into Makefile, and into .env.example and .env for the
+	// dotenv targets. The make env display lines go in either way.
+	envTarget, err := config.LookupEnvTarget(opts.EnvTarget)
+	if err != nil {
+		return nil, err
+	}
+	makeSpec := spec
+	if envTarget == config.EnvTargetDotenv {
+		makeSpec.MakefileEnv = ""
+	}
+	if makeSpec.MakefileEnv != "" || makeSpec.MakefileEnvDisplay != "" || makeSpec.MakefileTargets != "" {
+		if err := injectIntoMakefile(fs, opts.ProjectRoot, makeSpec); err != nil {
 			return nil, fmt.Errorf("wire makefile: %w", err)
 		}
 		result.ModifiedFiles = append(result.ModifiedFiles, "Makefile")
 	}
+	if envTarget != config.EnvTargetMakefile {
+		modified, err := injectDotenv(fs, opts.ProjectRoot, spec)
+		if err != nil {
+			return nil, fmt.Errorf("wire .env: %w", err)
+		}
+		for _, f := range modified {
+			if !slices.Contains(result.ModifiedFiles, f) {
+				result.ModifiedFiles = append(result.ModifiedFiles, f)
+			}
+		}
+	}
+
+	// 5. Inject into docker-compose.yml
+	if spec.ComposeServices != "" || spec.ComposeVolumes != "" {
+		if err := injectIntoCompose(fs, opts.ProjectRoot, spec); err != nil {
+			return nil, fmt.Errorf("wire docker-compose: %w", err)
+		}
+		result.ModifiedFiles = append(result.ModifiedFiles, "docker-compose.yml")
+	}
+
+	// 6. Create the module's files
+	for _, rel := range slices.Sorted(maps.Keys(spec.Files)) {
+		path := filepath.Join(opts.ProjectRoot, filepath.FromSlash(rel))
+		if _, err := fs.ReadFile(path); err == nil {
+			fs.Present(path, "file already exists")
+			continue
+		}
+		if err := fs.WriteFile(path, []byte(spec.Files[rel]), 0644); err != nil {
+			return nil, fmt.Errorf("create %s: %w", rel, err)
+		}
+		result.ModifiedFiles = append(result.ModifiedFiles, rel)
+	}
 
-	// 5. Check cross-module bridges
+	// 7. Check cross-module bridges: the module's own, then those wired
+	// modules declare with it, which couldn't fire when they were wired.
+	var bridgeDeps []string
 	for _, bridge := range spec.Bridges {
-		if hasWiredModule(opts.WiredModules, bridge.RequiresModule) {
-			bridgeSpec := replaceBridgePlaceholders(bridge, opts.GoModule, opts.ProjectName)
-			if err := injectBridge(opts.ProjectRoot, bridgeSpec); err != nil {
-				return nil, fmt.Errorf("wire bridge (%s+%s): %w", opts.ModuleName, bridge.RequiresModule, err)
+		if bridgeApplies(opts, bridge) {
+			if err := applyBridge(fs, opts, opts.ModuleName, bridge, result); err != nil {
+				return nil, err
 			}
-			result.ActivatedBridges = append(result.ActivatedBridges, bridge.RequiresModule)
+			bridgeDeps = append(bridgeDeps, bridge.GoDeps...)
 		}
 	}
-
-	// 6. Install external Go dependencies
-	if len(spec.GoDeps) > 0 {
-		if err := installGoDeps(opts.ProjectRoot, spec.GoDeps); err != nil {
-			return nil, fmt.Errorf("install deps: %w", err)
+	for _, other := range slices.Sorted(slices.Values(opts.WiredModules)) {
+		if other == opts.ModuleName {
+			continue
+		}
+		for _, bridge := range config.WireableModuleRegistry[other].Bridges {
+			if bridge.RequiresModule != opts.ModuleName {
+				continue
+			}
+			if len(bridge.RequiresProviders) > 0 && !slices.Contains(bridge.RequiresProviders, result.Provider) {
+				continue
+			}
+			if err := applyBridge(fs, opts, other, bridge, result); err != nil {
+				return nil, err
+			}
+			bridgeDeps = append(bridgeDeps, bridge.GoDeps...)
 		}
 	}
 
+	// 8. External Go dependencies, installed by WireModule
+	result.GoDeps = append(slices.Clip(spec.GoDeps), bridgeDeps...)
+
 	return result, nil
 }
 
+// installWireDeps installs the Go dependencies of a wired module, or
+// records them as deferred when the toolchain can't.
+func installWireDeps(projectRoot string, result *WireResult) error {
+	if len(result.GoDeps) == 0 {
+		return nil
+	}
+	if err := toolchain.Check(toolchain.RequiredVersion(projectRoot)); err != nil {
+		result.ToolchainErr = err
+		for _, dep := range result.GoDeps {
+			result.Deferred = append(result.Deferred, "go get "+dep)
+		}
+		return nil
+	}
+	if err := InstallGoDeps(projectRoot, result.GoDeps); err != nil {
+		return fmt.Errorf("install deps: %w", err)
+	}
+	return nil
+}
+
 // PostProcessConfigFile inserts wiring markers into the fetched config.go file.
 // Called once after init to prepare the file for future module wiring.
 func PostProcessConfigFile(projectRoot string) error {
@@ -99,34 +264,27 @@ func PostProcessConfigFile(projectRoot string) error {
 		return nil // config.go might not exist yet
 	}
 
-	text := string(content)
-
-	// Already processed?
-	if strings.Contains(text, "// manifesto:config-fields") {
+	// Insert config-fields before the closing brace of type Config struct
+	// and config-loads before "return cfg" in the Load function.
+	text, _ := insertMissingMarkers(string(content), markersIn("pkg/config/config.go"))
+	if text == string(content) {
 		return nil
 	}
 
-	// Insert config-fields marker before closing brace of type Config struct { ... }
-	text = insertMarkerBeforeClosingBrace(text, "type Config struct {", "// manifesto:config-fields")
-
-	// Insert config-loads marker before "return cfg" in the Load function
-	returnIdx := strings.Index(text, "return cfg")
-	if returnIdx != -1 {
-		indent := "\t"
-		text = text[:returnIdx] + indent + "// manifesto:config-loads\n\n\t" + text[returnIdx:]
+	if err := os.WriteFile(configFile, []byte(text), 0644); err != nil {
+		return err
 	}
-
-	return os.WriteFile(configFile, []byte(text), 0644)
+	return Relock(projectRoot, "pkg/config/config.go")
 }
 
 // ---------------------------------------------------------------------------
 // Config injection
 // ---------------------------------------------------------------------------
 
-func injectWireConfig(projectRoot string, spec config.WireableModule) error {
+func injectWireConfig(fs FileStore, projectRoot string, spec config.WireableModule) error {
 	configFile := filepath.Join(projectRoot, "pkg", "config", "config.go")
 
-	content, err := os.ReadFile(configFile)
+	content, err := fs.ReadFile(configFile)
 	if err != nil {
 		return fmt.Errorf("read config.go: %w", err)
 	}
@@ -137,6 +295,7 @@ func injectWireConfig(projectRoot string, spec config.WireableModule) error {
 	if spec.ConfigFields != "" {
 		firstLine := strings.Split(strings.TrimSpace(spec.ConfigFields), "\n")[0]
 		if strings.Contains(text, strings.TrimSpace(firstLine)) {
+			fs.Present(configFile, "config fields already present")
 			return nil
 		}
 	}
@@ -144,26 +303,26 @@ func injectWireConfig(projectRoot string, spec config.WireableModule) error {
 	// Inject config fields
 	if spec.ConfigFields != "" {
 		fieldLine := spec.ConfigFields + "\n\t// manifesto:config-fields"
-		text = strings.Replace(text, "// manifesto:config-fields", fieldLine, 1)
+		text = replaceMarker(fs, configFile, text, "// manifesto:config-fields", fieldLine)
 	}
 
 	// Inject config loads
 	if spec.ConfigLoads != "" {
 		loadLine := spec.ConfigLoads + "\n\t// manifesto:config-loads"
-		text = strings.Replace(text, "// manifesto:config-loads", loadLine, 1)
+		text = replaceMarker(fs, configFile, text, "// manifesto:config-loads", loadLine)
 	}
 
-	return os.WriteFile(configFile, []byte(text), 0644)
+	return fs.WriteFile(configFile, []byte(text), 0644)
 }
 
 // ---------------------------------------------------------------------------
 // Container injection
 // ---------------------------------------------------------------------------
 
-func injectWireContainer(projectRoot string, spec config.WireableModule) error {
+func injectWireContainer(fs FileStore, projectRoot string, spec config.WireableModule) error {
 	containerFile := filepath.Join(projectRoot, "cmd", "container.go")
 
-	content, err := os.ReadFile(containerFile)
+	content, err := fs.ReadFile(containerFile)
 	if err != nil {
 		return fmt.Errorf("read container.go: %w", err)
 	}
@@ -173,6 +332,7 @@ func injectWireContainer(projectRoot string, spec config.WireableModule) error {
 	// Guard: use first import line as idempotency check
 	guardStr := wireGuardString(spec)
 	if guardStr != "" && strings.Contains(text, guardStr) {
+		fs.Present(containerFile, fmt.Sprintf("already wired (%s)", guardStr))
 		return nil
 	}
 
@@ -192,55 +352,62 @@ func injectWireContainer(projectRoot string, spec config.WireableModule) error {
 				continue
 			}
 			importLine := "\t" + trimmed + "\n\t// manifesto:container-imports"
-			text = strings.Replace(text, "// manifesto:container-imports", importLine, 1)
+			text = replaceMarker(fs, containerFile, text, "// manifesto:container-imports", importLine)
 		}
 	}
 
 	// Inject fields
 	if spec.ContainerFields != "" {
 		fieldLine := spec.ContainerFields + "\n\t// manifesto:container-fields"
-		text = strings.Replace(text, "// manifesto:container-fields", fieldLine, 1)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:container-fields", fieldLine)
 	}
 
 	// Inject module init
 	if spec.ModuleInit != "" {
 		initLine := spec.ModuleInit + "\n\n\t// manifesto:module-init"
-		text = strings.Replace(text, "// manifesto:module-init", initLine, 1)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:module-init", initLine)
 	}
 
 	// Inject background start
 	if spec.BackgroundStart != "" {
 		bgLine := spec.BackgroundStart + "\n\t// manifesto:background-start"
-		text = strings.Replace(text, "// manifesto:background-start", bgLine, 1)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:background-start", bgLine)
 	}
 
 	// Inject helpers
 	if spec.ContainerHelpers != "" {
 		helperLine := spec.ContainerHelpers + "\n\n// manifesto:container-helpers"
-		text = strings.Replace(text, "// manifesto:container-helpers", helperLine, 1)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:container-helpers", helperLine)
 	}
 
-	return os.WriteFile(containerFile, []byte(text), 0644)
+	return fs.WriteFile(containerFile, []byte(text), 0644)
 }
 
 // ---------------------------------------------------------------------------
 // Server injection
 // ---------------------------------------------------------------------------
 
-func injectWireServer(projectRoot string, spec config.WireableModule) error {
+func injectWireServer(fs FileStore, projectRoot string, spec config.WireableModule) error {
 	serverFile := filepath.Join(projectRoot, "cmd", "server.go")
 
-	content, err := os.ReadFile(serverFile)
+	content, err := fs.ReadFile(serverFile)
 	if err != nil {
 		return fmt.Errorf("read server.go: %w", err)
 	}
 
 	text := string(content)
 
-	// Guard: check if public routes already injected
+	// Guard: check if public routes or middleware already injected
 	if spec.PublicRoutes != "" {
 		firstLine := strings.Split(strings.TrimSpace(spec.PublicRoutes), "\n")[0]
 		if strings.Contains(text, strings.TrimSpace(firstLine)) {
+			fs.Present(serverFile, "routes already registered")
+			return nil
+		}
+	} else if spec.ServerMiddleware != "" {
+		firstLine := strings.Split(strings.TrimSpace(spec.ServerMiddleware), "\n")[0]
+		if strings.Contains(text, strings.TrimSpace(firstLine)) {
+			fs.Present(serverFile, "middleware already registered")
 			return nil
 		}
 	}
@@ -248,26 +415,25 @@ func injectWireServer(projectRoot string, spec config.WireableModule) error {
 	// Inject server imports
 	if spec.ServerImports != "" {
 		importLine := spec.ServerImports + "\n\t// manifesto:server-imports"
-		text = strings.Replace(text, "// manifesto:server-imports", importLine, 1)
+		text = replaceMarker(fs, serverFile, text, "// manifesto:server-imports", importLine)
+	}
+
+	// Inject global middleware
+	if spec.ServerMiddleware != "" {
+		middlewareLine := spec.ServerMiddleware + "\n\n\t// manifesto:server-middleware"
+		text = replaceMarker(fs, serverFile, text, "// manifesto:server-middleware", middlewareLine)
 	}
 
 	// Inject public routes
 	if spec.PublicRoutes != "" {
 		routeLine := spec.PublicRoutes + "\n\n\t// manifesto:public-routes"
-		text = strings.Replace(text, "// manifesto:public-routes", routeLine, 1)
+		text = replaceMarker(fs, serverFile, text, "// manifesto:public-routes", routeLine)
 	}
 
 	// Ensure protected group exists if this module needs routes
 	if spec.RouteRegistration != "" || spec.AuthMiddleware != "" {
-		if !strings.Contains(text, "protected :=") {
-			// Create the protected group (with auth middleware if present)
-			if spec.AuthMiddleware != "" {
-				groupCode := fmt.Sprintf("\tprotected := app.Group(\"/api/v1\",\n\t\t%s,\n\t)\n\n\t// manifesto:route-registration", spec.AuthMiddleware)
-				text = strings.Replace(text, "// manifesto:route-registration", groupCode, 1)
-			} else {
-				groupCode := "\tprotected := app.Group(\"/api/v1\")\n\n\t// manifesto:route-registration"
-				text = strings.Replace(text, "// manifesto:route-registration", groupCode, 1)
-			}
+		if !hasProtectedGroup(text) {
+			text = addProtectedGroup(fs, serverFile, text, spec.AuthMiddleware)
 		} else if spec.AuthMiddleware != "" {
 			// Protected group already exists — add middleware
 			oldGroup := `protected := app.Group("/api/v1")`
@@ -281,47 +447,156 @@ func injectWireServer(projectRoot string, spec config.WireableModule) error {
 	// Inject route registration
 	if spec.RouteRegistration != "" {
 		regLine := spec.RouteRegistration + "\n\n\t// manifesto:route-registration"
-		text = strings.Replace(text, "// manifesto:route-registration", regLine, 1)
+		text = replaceMarker(fs, serverFile, text, "// manifesto:route-registration", regLine)
 	}
 
-	return os.WriteFile(serverFile, []byte(text), 0644)
+	return fs.WriteFile(serverFile, []byte(text), 0644)
+}
+
+// hasProtectedGroup reports whether cmd/server.go declares the protected
+// route group. Quick projects start without one.
+func hasProtectedGroup(text string) bool {
+	return declaresRouteGroup(text, ProtectedGroup)
+}
+
+// addProtectedGroup declares the protected group at the route
+// registration marker, behind authMiddleware when there is one.
+func addProtectedGroup(fs FileStore, serverFile, text, authMiddleware string) string {
+	groupCode := "\tprotected := app.Group(\"/api/v1\")\n\n\t// manifesto:route-registration"
+	if authMiddleware != "" {
+		groupCode = fmt.Sprintf("\tprotected := app.Group(\"/api/v1\",\n\t\t%s,\n\t)\n\n\t// manifesto:route-registration", authMiddleware)
+	}
+	return replaceMarker(fs, serverFile, text, "// manifesto:route-registration", groupCode)
 }
 
 // ---------------------------------------------------------------------------
 // Makefile injection
 // ---------------------------------------------------------------------------
 
-func injectIntoMakefile(projectRoot string, spec config.WireableModule) error {
+func injectIntoMakefile(fs FileStore, projectRoot string, spec config.WireableModule) error {
 	makefilePath := filepath.Join(projectRoot, "Makefile")
 
-	content, err := os.ReadFile(makefilePath)
+	content, err := fs.ReadFile(makefilePath)
 	if err != nil {
 		return nil // Makefile might not exist
 	}
 
 	text := string(content)
 
-	// Guard: check if already injected
+	// Guard: check if already injected. Skip the "# ===" banner comments,
+	// which every env block shares. Targets are guarded one by one below.
+	envPresent := false
 	if spec.MakefileEnv != "" {
-		firstLine := strings.Split(strings.TrimSpace(spec.MakefileEnv), "\n")[0]
-		if strings.Contains(text, strings.TrimSpace(firstLine)) {
-			return nil
+		if firstLine := firstCodeLine(spec.MakefileEnv, "#"); firstLine != "" && strings.Contains(text, firstLine) {
+			fs.Present(makefilePath, "environment block already present")
+			envPresent = true
+		}
+	} else if spec.MakefileEnvDisplay != "" {
+		if firstLine := firstCodeLine(spec.MakefileEnvDisplay, "#"); firstLine != "" && strings.Contains(text, "\t"+firstLine) {
+			fs.Present(makefilePath, "env display lines already present")
+			envPresent = true
 		}
 	}
 
 	// Inject env config block (top-level, no tab prefix)
-	if spec.MakefileEnv != "" {
+	if spec.MakefileEnv != "" && !envPresent {
 		envBlock := spec.MakefileEnv + "\n\n# manifesto:env-config"
-		text = strings.Replace(text, "# manifesto:env-config", envBlock, 1)
+		text = replaceMarker(fs, makefilePath, text, "# manifesto:env-config", envBlock)
 	}
 
 	// Inject env display lines (inside make recipe, needs tab prefix)
-	if spec.MakefileEnvDisplay != "" {
+	if spec.MakefileEnvDisplay != "" && !envPresent {
 		displayBlock := tabPrefixLines(spec.MakefileEnvDisplay) + "\n\t# manifesto:env-display"
-		text = strings.Replace(text, "\t# manifesto:env-display", displayBlock, 1)
+		text = replaceMarker(fs, makefilePath, text, "\t# manifesto:env-display", displayBlock)
+	}
+
+	// Inject the targets the Makefile doesn't define yet (top-level;
+	// recipe lines are tab-indented)
+	if spec.MakefileTargets != "" {
+		var missing []string
+		for _, block := range makefileTargetBlocks(spec.MakefileTargets) {
+			if name := makefileTargetName(block); name != "" && makefileDefines(text, name) {
+				fs.Present(makefilePath, fmt.Sprintf("target already present (%s)", name))
+				continue
+			}
+			missing = append(missing, block)
+		}
+		if len(missing) > 0 {
+			targetBlock := strings.Join(missing, "\n\n") + "\n\n# manifesto:make-targets"
+			text = replaceMarker(fs, makefilePath, text, "# manifesto:make-targets", targetBlock)
+		}
+	}
+
+	return fs.WriteFile(makefilePath, []byte(text), 0644)
+}
+
+var makefileRuleRe = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*:([^=]|$)`)
+
+// makefileTargetBlocks splits targets at blank lines into one block per
+// target, with each recipe line indented by a tab, as make requires, even
+// when the spec indents it with spaces.
+func makefileTargetBlocks(targets string) []string {
+	var blocks []string
+	for _, block := range strings.Split(strings.TrimSpace(targets), "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		inRule := false
+		for i, line := range lines {
+			switch {
+			case makefileRuleRe.MatchString(line):
+				inRule = true
+			case inRule && strings.HasPrefix(line, " "):
+				lines[i] = "\t" + strings.TrimLeft(line, " ")
+			}
+		}
+		if block := strings.Join(lines, "\n"); strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// makefileTargetName returns the name of the first rule in block, or ""
+// when it has none.
+func makefileTargetName(block string) string {
+	for _, line := range strings.Split(block, "\n") {
+		if m := makefileRuleRe.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// makefileDefines reports whether the Makefile has a rule for target.
+func makefileDefines(text, target string) bool {
+	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(target) + `\s*:([^=]|$)`)
+	return re.MatchString(text)
+}
+
+// injectIntoCompose adds the module's services and volumes to
+// docker-compose.yml. A service already defined there is left alone.
+func injectIntoCompose(fs FileStore, projectRoot string, spec config.WireableModule) error {
+	composePath := filepath.Join(projectRoot, "docker-compose.yml")
+
+	content, err := fs.ReadFile(composePath)
+	if err != nil {
+		return nil // docker-compose.yml might not exist
+	}
+
+	text := string(content)
+
+	// Guard: the first service's key line, e.g. "  redis:".
+	if spec.ComposeServices != "" {
+		if first := strings.SplitN(spec.ComposeServices, "\n", 2)[0]; lineIndex(text, first+"\n") != -1 {
+			fs.Present(composePath, fmt.Sprintf("service already present (%s)", strings.TrimSpace(first)))
+			return nil
+		}
+		text = replaceMarker(fs, composePath, text, "  # manifesto:compose-services", spec.ComposeServices+"\n\n  # manifesto:compose-services")
+	}
+	if spec.ComposeVolumes != "" {
+		text = replaceMarker(fs, composePath, text, "  # manifesto:compose-volumes", spec.ComposeVolumes+"\n  # manifesto:compose-volumes")
 	}
 
-	return os.WriteFile(makefilePath, []byte(text), 0644)
+	return fs.WriteFile(composePath, []byte(text), 0644)
 }
 
 // tabPrefixLines adds a leading tab to every non-empty line.
@@ -339,10 +614,20 @@ func tabPrefixLines(s string) string {
 // Bridge injection
 // ---------------------------------------------------------------------------
 
-func injectBridge(projectRoot string, bridge config.Bridge) error {
+// applyBridge injects the bridge module declares and records it in result.
+func applyBridge(fs FileStore, opts WireOptions, module string, bridge config.Bridge, result *WireResult) error {
+	bridgeSpec := replaceBridgePlaceholders(bridge, opts.GoModule, opts.ProjectName)
+	if err := injectBridge(fs, opts.ProjectRoot, bridgeSpec); err != nil {
+		return fmt.Errorf("wire bridge (%s): %w", config.BridgeName(module, bridge.RequiresModule), err)
+	}
+	result.ActivatedBridges = append(result.ActivatedBridges, config.BridgeName(module, bridge.RequiresModule))
+	return nil
+}
+
+func injectBridge(fs FileStore, projectRoot string, bridge config.Bridge) error {
 	containerFile := filepath.Join(projectRoot, "cmd", "container.go")
 
-	content, err := os.ReadFile(containerFile)
+	content, err := fs.ReadFile(containerFile)
 	if err != nil {
 		return fmt.Errorf("read container.go for bridge: %w", err)
 	}
@@ -352,6 +637,7 @@ func injectBridge(projectRoot string, bridge config.Bridge) error {
 	// Guard: check if bridge code already present
 	firstLine := strings.Split(strings.TrimSpace(bridge.ContainerInit), "\n")[0]
 	if strings.Contains(text, strings.TrimSpace(firstLine)) {
+		fs.Present(containerFile, "bridge already present")
 		return nil
 	}
 
@@ -361,7 +647,7 @@ func injectBridge(projectRoot string, bridge config.Bridge) error {
 			line = strings.TrimSpace(line)
 			if line != "" && !strings.Contains(text, line) {
 				importLine := "\t" + line + "\n\t// manifesto:container-imports"
-				text = strings.Replace(text, "// manifesto:container-imports", importLine, 1)
+				text = replaceMarker(fs, containerFile, text, "// manifesto:container-imports", importLine)
 			}
 		}
 	}
@@ -369,45 +655,74 @@ func injectBridge(projectRoot string, bridge config.Bridge) error {
 	// Inject bridge init code
 	if bridge.ContainerInit != "" {
 		initLine := bridge.ContainerInit + "\n\n\t// manifesto:module-init"
-		text = strings.Replace(text, "// manifesto:module-init", initLine, 1)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:module-init", initLine)
 	}
 
 	// Inject bridge helpers
 	if bridge.ContainerHelpers != "" {
 		helperLine := bridge.ContainerHelpers + "\n\n// manifesto:container-helpers"
-		text = strings.Replace(text, "// manifesto:container-helpers", helperLine, 1)
+		text = replaceMarker(fs, containerFile, text, "// manifesto:container-helpers", helperLine)
 	}
 
-	return os.WriteFile(containerFile, []byte(text), 0644)
+	return fs.WriteFile(containerFile, []byte(text), 0644)
 }
 
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
 
-// insertMarkerBeforeClosingBrace finds a pattern like "type Config struct {"
-// and inserts a marker comment before the matching closing brace.
-func insertMarkerBeforeClosingBrace(text, opener, marker string) string {
+// firstCodeLine returns the first non-empty line of s that is not a comment.
+func firstCodeLine(s, commentPrefix string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, commentPrefix) {
+			return line
+		}
+	}
+	return ""
+}
+
+// replaceMarker replaces the first occurrence of marker with replacement,
+// recording a skip when the marker is missing from the file. Replacements
+// are written indented as whole lines, so the indentation the marker's line
+// already has is dropped from the start of replacement.
+func replaceMarker(fs FileStore, path, text, marker, replacement string) string {
+	i := strings.Index(text, marker)
+	if i == -1 {
+		fs.Skip(path, fmt.Sprintf("marker %q not found", strings.TrimSpace(marker)))
+		return text
+	}
+	lineStart := strings.LastIndex(text[:i], "\n") + 1
+	if indent := text[lineStart:i]; strings.TrimSpace(indent) == "" {
+		replacement = strings.TrimPrefix(replacement, indent)
+	}
+	return text[:i] + replacement + text[i+len(marker):]
+}
+
+// insertMarkerBeforeClosing finds a pattern like "type Config struct {"
+// and inserts a marker comment before the bracket (close) matching the
+// first open after it.
+func insertMarkerBeforeClosing(text, opener, marker string, open, close byte) string {
 	idx := strings.Index(text, opener)
 	if idx == -1 {
 		return text
 	}
 
-	// Find the opening brace
-	braceIdx := strings.Index(text[idx:], "{")
+	// Find the opening bracket
+	braceIdx := strings.IndexByte(text[idx:], open)
 	if braceIdx == -1 {
 		return text
 	}
 	braceIdx += idx
 
-	// Count braces to find matching close
+	// Count brackets to find matching close
 	depth := 1
 	pos := braceIdx + 1
 
 	for pos < len(text) && depth > 0 {
-		if text[pos] == '{' {
+		if text[pos] == open {
 			depth++
-		} else if text[pos] == '}' {
+		} else if text[pos] == close {
 			depth--
 		}
 		if depth > 0 {
@@ -416,10 +731,10 @@ func insertMarkerBeforeClosingBrace(text, opener, marker string) string {
 	}
 
 	if depth != 0 {
-		return text // unmatched braces, don't modify
+		return text // unmatched brackets, don't modify
 	}
 
-	// pos is at the closing brace — insert marker before it
+	// pos is at the closing bracket — insert marker before it
 	return text[:pos] + "\t" + marker + "\n" + text[pos:]
 }
 
@@ -437,10 +752,21 @@ func replacePlaceholders(spec config.WireableModule, goModule, projectName strin
 	spec.BackgroundStart = r(spec.BackgroundStart)
 	spec.ContainerHelpers = r(spec.ContainerHelpers)
 	spec.ServerImports = r(spec.ServerImports)
+	spec.ServerMiddleware = r(spec.ServerMiddleware)
 	spec.PublicRoutes = r(spec.PublicRoutes)
 	spec.RouteRegistration = r(spec.RouteRegistration)
 	spec.MakefileEnv = r(spec.MakefileEnv)
 	spec.MakefileEnvDisplay = r(spec.MakefileEnvDisplay)
+	spec.MakefileTargets = r(spec.MakefileTargets)
+	spec.ComposeServices = r(spec.ComposeServices)
+	spec.ComposeVolumes = r(spec.ComposeVolumes)
+	if spec.Files != nil {
+		files := make(map[string]string, len(spec.Files))
+		for path, content := range spec.Files {
+			files[path] = r(content)
+		}
+		spec.Files = files
+	}
 
 	for i, bridge := range spec.Bridges {
 		spec.Bridges[i].ContainerImports = r(bridge.ContainerImports)
@@ -487,6 +813,20 @@ func wireGuardString(spec config.WireableModule) string {
 	return ""
 }
 
+// bridgeApplies reports whether the module bridge requires is wired, with
+// one of the providers it names if it names any. A module wired before its
+// provider was recorded has the default one.
+func bridgeApplies(opts WireOptions, bridge config.Bridge) bool {
+	if !hasWiredModule(opts.WiredModules, bridge.RequiresModule) {
+		return false
+	}
+	if len(bridge.RequiresProviders) == 0 {
+		return true
+	}
+	provider := cmp.Or(opts.WiredProviders[bridge.RequiresModule], config.WireableModuleRegistry[bridge.RequiresModule].DefaultProvider)
+	return slices.Contains(bridge.RequiresProviders, provider)
+}
+
 func hasWiredModule(wired []string, name string) bool {
 	for _, m := range wired {
 		if m == name {
@@ -496,15 +836,28 @@ func hasWiredModule(wired []string, name string) bool {
 	return false
 }
 
-func installGoDeps(projectRoot string, deps []string) error {
+// InstallGoDeps runs go get for each dependency in the project root. Its
+// output is only shown when it fails, or with --verbose.
+func InstallGoDeps(projectRoot string, deps []string) error {
 	for _, dep := range deps {
-		cmd := exec.Command("go", "get", dep)
-		cmd.Dir = projectRoot
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("go get %s: %w", dep, err)
+		if _, err := execx.Go(context.Background(), projectRoot, "get", dep); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// Build runs go build ./... in the project root, to check that what was
+// generated or wired compiles. Binaries are discarded. The compiler's
+// errors are the *execx.Error's Output.
+func Build(projectRoot string) error {
+	_, err := execx.Go(context.Background(), projectRoot, "build", "-o", os.DevNull, "./...")
+	return err
+}
+
+// Tidy runs go mod tidy in the project root. Like InstallGoDeps, its
+// output is only shown when it fails, or with --verbose.
+func Tidy(projectRoot string) error {
+	_, err := execx.Go(context.Background(), projectRoot, "mod", "tidy")
+	return err
+}
@@ -0,0 +1,169 @@
+package scaffold
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/config"
+)
+
+// wireWithoutDeps wires name into the project at root the way WireModule
+// does, leaving out the go get of its dependencies.
+func wireWithoutDeps(t *testing.T, root, name string) {
+	t.Helper()
+	manifest, err := config.LoadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview, _, err := PreviewWire(WireOptions{
+		ProjectRoot:  root,
+		ModuleName:   name,
+		GoModule:     manifest.Project.GoModule,
+		ProjectName:  manifest.Project.Name,
+		WiredModules: manifest.WiredOrProvided(),
+	})
+	if err != nil {
+		t.Fatalf("wire %s: %v", name, err)
+	}
+	if _, err := preview.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMakefileTargets wires modules with Makefile targets, twice, and has
+// make parse the result: every target they add must run under make -n,
+// and none may be defined twice.
+func TestMakefileTargets(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("no make on PATH")
+	}
+	root := initTestProject(t, InitOptions{Profile: "api"})
+	modules := []string{"iam", "swagger"}
+
+	var targets []string
+	for _, name := range modules {
+		for _, block := range makefileTargetBlocks(config.WireableModuleRegistry[name].MakefileTargets) {
+			targets = append(targets, makefileTargetName(block))
+		}
+	}
+	if len(targets) == 0 {
+		t.Fatal("no module defines Makefile targets")
+	}
+
+	for round := 1; round <= 2; round++ {
+		wireWithoutDeps(t, root, "swagger")
+		if round == 2 {
+			wireWithoutDeps(t, root, "iam")
+		}
+
+		makefile := readFile(t, root, "Makefile")
+		for _, target := range append(targets, "help", "env") {
+			if n := strings.Count(makefile, "\n"+target+":"); n != 1 {
+				t.Errorf("round %d: Makefile defines %s %d times", round, target, n)
+			}
+			cmd := exec.Command("make", "-n", target)
+			cmd.Dir = root
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Errorf("round %d: make -n %s: %v\n%s", round, target, err, out)
+			} else if strings.Contains(string(out), "warning:") {
+				t.Errorf("round %d: make -n %s warns:\n%s", round, target, out)
+			}
+		}
+	}
+}
+
+// projectFiles returns the content of every file under root, by slash
+// path.
+func projectFiles(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// assertUntouched fails t if the files under root differ from before.
+func assertUntouched(t *testing.T, root string, before map[string]string) {
+	t.Helper()
+	after := projectFiles(t, root)
+	for path, data := range after {
+		if old, ok := before[path]; !ok {
+			t.Errorf("%s was created", path)
+		} else if data != old {
+			t.Errorf("%s was modified", path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			t.Errorf("%s was removed", path)
+		}
+	}
+}
+
+// TestWireMissingMarker wires iam into a server.go whose template has no
+// route registration marker: wiring must fail before touching any file,
+// though config.go and container.go, edited first, have their markers.
+func TestWireMissingMarker(t *testing.T) {
+	root := initTestProject(t, InitOptions{})
+	server := readFile(t, root, "cmd/server.go")
+	writeFiles(t, root, map[string]string{
+		"cmd/server.go": strings.Replace(server, "// manifesto:route-registration", "", 1),
+	})
+	before := projectFiles(t, root)
+
+	_, err := WireModule(WireOptions{
+		ProjectRoot: root,
+		ModuleName:  "iam",
+		GoModule:    "example.com/shop",
+		ProjectName: "shop",
+	})
+	if err == nil || !strings.Contains(err.Error(), "manifesto:route-registration") {
+		t.Fatalf("got %v, want an error about the missing marker", err)
+	}
+	assertUntouched(t, root, before)
+}
+
+// TestWireRollsBackFailedDeps wires a module whose Go dependency can't be
+// installed: the files written before go get ran must be put back.
+func TestWireRollsBackFailedDeps(t *testing.T) {
+	requireGo(t)
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	config.WireableModuleRegistry["unreachable"] = config.WireableModule{
+		Name:             "unreachable",
+		ContainerHelpers: "// unreachableHelper needs a module go can't get.\nfunc unreachableHelper() {}",
+		GoDeps:           []string{"example.invalid/unreachable@v1.0.0"},
+	}
+	t.Cleanup(func() { delete(config.WireableModuleRegistry, "unreachable") })
+
+	root := initTestProject(t, InitOptions{})
+	before := projectFiles(t, root)
+	_, err := WireModule(WireOptions{
+		ProjectRoot: root,
+		ModuleName:  "unreachable",
+		GoModule:    "example.com/shop",
+		ProjectName: "shop",
+	})
+	if err == nil {
+		t.Fatal("wired a module whose dependency can't be installed")
+	}
+	assertUntouched(t, root, before)
+}
@@ -0,0 +1,193 @@
+// Package toolchain detects the local Go toolchain and checks it against the
+// version a project requires.
+package toolchain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abraxas-365/manifesto-cli/internal/execx"
+)
+
+// Info describes the go binary found on PATH.
+type Info struct {
+	Path        string // Absolute path to go, empty when not found
+	Version     string // e.g. "1.24.1"
+	GOTOOLCHAIN string // Effective GOTOOLCHAIN setting
+	Err         error  // Set when go is missing or `go version` failed
+}
+
+var (
+	detectOnce sync.Once
+	detected   Info
+)
+
+// probeTimeout bounds the go version and go env calls Detect makes.
+const probeTimeout = 30 * time.Second
+
+// Detect locates go on PATH and parses `go version`. The result is cached
+// for the rest of the invocation.
+func Detect() Info {
+	detectOnce.Do(func() {
+		detected = detect()
+	})
+	return detected
+}
+
+func detect() Info {
+	path, err := exec.LookPath("go")
+	if err != nil {
+		return Info{Err: fmt.Errorf("go not found on PATH")}
+	}
+	info := Info{Path: path}
+
+	// Run outside any module with GOTOOLCHAIN=local so go reports itself
+	// instead of trying to switch to the toolchain a go.mod asks for.
+	out, err := execx.Run(context.Background(), execx.Command{
+		Name:    path,
+		Args:    []string{"version"},
+		Dir:     os.TempDir(),
+		Env:     []string{"GOTOOLCHAIN=local"},
+		Timeout: probeTimeout,
+	})
+	if err != nil {
+		info.Err = err
+		return info
+	}
+	// "go version go1.24.1 linux/amd64"
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "go") {
+		info.Err = fmt.Errorf("unrecognized go version output: %q", strings.TrimSpace(string(out)))
+		return info
+	}
+	info.Version = strings.TrimPrefix(fields[2], "go")
+
+	out, err = execx.Run(context.Background(), execx.Command{
+		Name:    path,
+		Args:    []string{"env", "GOTOOLCHAIN"},
+		Dir:     os.TempDir(),
+		Timeout: probeTimeout,
+	})
+	if err == nil {
+		info.GOTOOLCHAIN = strings.TrimSpace(string(out))
+	}
+	return info
+}
+
+// RequiredVersion returns the go directive of projectRoot/go.mod, or "" if
+// there is none.
+func RequiredVersion(projectRoot string) string {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// Error explains why the toolchain can't run a project's go commands.
+type Error struct {
+	Required string // go directive, may be empty
+	Info     Info
+}
+
+func (e *Error) Error() string {
+	if e.Info.Path == "" {
+		return "go is not installed (not found on PATH)"
+	}
+	if e.Info.Err != nil {
+		return e.Info.Err.Error()
+	}
+	return fmt.Sprintf("go %s is too old (project requires go %s)", e.Info.Version, e.Required)
+}
+
+// Guidance returns the steps to fix the problem.
+func (e *Error) Guidance() []string {
+	want := "a recent Go release"
+	if e.Required != "" {
+		want = "Go " + e.Required + " or newer"
+	}
+
+	if e.Info.Path == "" || e.Info.Err != nil {
+		return []string{fmt.Sprintf("Install %s from https://go.dev/dl/ and make sure `go` is on your PATH", want)}
+	}
+
+	if Compare(e.Info.Version, "1.21") < 0 {
+		return []string{
+			fmt.Sprintf("Go %s cannot download newer toolchains; install %s from https://go.dev/dl/", e.Info.Version, want),
+		}
+	}
+	return []string{
+		fmt.Sprintf("Install %s from https://go.dev/dl/, or", want),
+		fmt.Sprintf("let go fetch it automatically: go env -w GOTOOLCHAIN=go%s+auto", e.Required),
+	}
+}
+
+// Check verifies go is installed and can build a module with the given go
+// directive. Go 1.21+ switches toolchains on its own unless GOTOOLCHAIN is
+// "local", so an older-but-capable go passes.
+func Check(required string) error {
+	info := Detect()
+	if info.Err != nil {
+		return &Error{Required: required, Info: info}
+	}
+	if required == "" || Compare(info.Version, required) >= 0 {
+		return nil
+	}
+	if Compare(info.Version, "1.21") >= 0 && !strings.HasPrefix(info.GOTOOLCHAIN, "local") {
+		return nil
+	}
+	return &Error{Required: required, Info: info}
+}
+
+// Guidance returns the remediation steps for err if it is a toolchain
+// Error, or nil otherwise.
+func Guidance(err error) []string {
+	if te, ok := err.(*Error); ok {
+		return te.Guidance()
+	}
+	return nil
+}
+
+// Compare compares two Go versions ("1.23", "1.24.1", "1.22rc1") and returns
+// -1, 0 or +1. Pre-release suffixes are ignored.
+func Compare(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) [3]int {
+	var out [3]int
+	v = strings.TrimPrefix(v, "go")
+	for i, part := range strings.SplitN(v, ".", 3) {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		out[i], _ = strconv.Atoi(part[:end])
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// AssumeYes skips every prompt in favor of its default answer, for CI and
+// scripts. The CLI sets it from --yes.
+var AssumeYes bool
+
+// Interactive reports whether prompts like Confirm and MultiSelect are
+// shown: stdin is a terminal and AssumeYes isn't set.
+func Interactive() bool {
+	return !AssumeYes && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Confirm asks a yes/no question and returns the answer. Empty input picks
+// def, as does AssumeYes. It returns false without prompting when stdin
+// isn't a terminal.
+func Confirm(question string, def bool) bool {
+	if AssumeYes {
+		return def
+	}
+	if !Interactive() {
+		return false
+	}
+
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(Output, "  %s %s ", question, Dim.Sprintf("(%s)", hint))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "":
+		return def
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// Ask prompts for a line of text and returns it trimmed. Empty input picks
+// def, as does AssumeYes. It returns def without prompting when stdin
+// isn't a terminal.
+func Ask(question, def string) string {
+	if !Interactive() {
+		return def
+	}
+
+	if def != "" {
+		fmt.Fprintf(Output, "  %s %s ", question, Dim.Sprintf("(%s)", def))
+	} else {
+		fmt.Fprintf(Output, "  %s ", question)
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return def
+	}
+	return cmp.Or(strings.TrimSpace(line), def)
+}
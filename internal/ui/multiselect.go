@@ -17,23 +17,29 @@ type SelectableItem struct {
 
 // MultiSelect displays an interactive checkbox menu and returns selected items.
 // Navigation: up/down arrows, space to toggle, 'a' to toggle all, enter to confirm.
+// Without a prompt (see Interactive) it returns the preselected items.
 func MultiSelect(title string, items []SelectableItem) ([]string, error) {
 	if len(items) == 0 {
 		return nil, nil
 	}
+	if !Interactive() {
+		return selectedItemNames(items), nil
+	}
 
 	// Switch terminal to raw mode.
 	fd := int(os.Stdin.Fd())
-	if !term.IsTerminal(fd) {
-		// Non-interactive: skip selection.
-		return nil, nil
-	}
 	oldState, err := term.MakeRaw(fd)
 	if err != nil {
 		return nil, nil // Fallback: skip selection if raw mode fails.
 	}
 	defer term.Restore(fd, oldState)
 
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	nameWidth := widest(8, names)
+
 	cursor := 0
 
 	render := func() {
@@ -53,16 +59,16 @@ func MultiSelect(title string, items []SelectableItem) ([]string, error) {
 			}
 
 			if i == cursor {
-				buf.WriteString(fmt.Sprintf("  %s %s  %-8s  %s\r\n",
+				buf.WriteString(fmt.Sprintf("  %s %s  %s  %s\r\n",
 					Cyan.Sprint("❯"),
 					check,
-					Bold.Sprint(item.Name),
+					Bold.Sprint(pad(item.Name, nameWidth)),
 					Dim.Sprint(item.Description),
 				))
 			} else {
-				buf.WriteString(fmt.Sprintf("    %s  %-8s  %s\r\n",
+				buf.WriteString(fmt.Sprintf("    %s  %s  %s\r\n",
 					check,
-					item.Name,
+					pad(item.Name, nameWidth),
 					Dim.Sprint(item.Description),
 				))
 			}
@@ -0,0 +1,338 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ProgressReporter reports the progress of one unit of work. Both Spinner and
+// the tasks of a MultiProgress implement it, so callers don't need to know
+// whether they render alone or alongside concurrent tasks.
+type ProgressReporter interface {
+	Status(msg string)
+	Percent(pct int)
+	Stop(success bool)
+}
+
+var (
+	_ ProgressReporter = (*Spinner)(nil)
+	_ ProgressReporter = (*progressTask)(nil)
+)
+
+type taskState int
+
+const (
+	taskRunning taskState = iota
+	taskSucceeded
+	taskFailed
+)
+
+type progressEvent struct {
+	id      int
+	add     bool
+	message string
+	status  string
+	percent int // -1 when unchanged
+	state   taskState
+	done    chan struct{} // Closed once the event is rendered, if set
+}
+
+type taskLine struct {
+	id      int
+	message string
+	status  string
+	percent int // -1 when unknown
+	state   taskState
+}
+
+// MultiProgress renders several concurrent tasks, one line each, updated in
+// place. All terminal writes happen on a single goroutine fed by a channel,
+// so tasks may report from any goroutine. When stdout is not a terminal it
+// falls back to logging a line when each task starts and finishes.
+//
+// Spinner and Steps render through the process-wide MultiProgress, so
+// tasks added while a step runs are drawn below it rather than over it.
+type MultiProgress struct {
+	out    io.Writer
+	tty    bool
+	events chan progressEvent
+	done   chan struct{}
+	nextID atomic.Int64
+
+	mu      sync.Mutex
+	closed  bool
+	sending sync.WaitGroup // Sends in flight, which Wait lets finish
+}
+
+var (
+	stdoutProgress     *MultiProgress
+	stdoutProgressOnce sync.Once
+)
+
+// progress returns the MultiProgress writing to stdout that Spinner, Steps
+// and AddTask share. It runs for the life of the process.
+func progress() *MultiProgress {
+	stdoutProgressOnce.Do(func() {
+		stdoutProgress = NewMultiProgress()
+	})
+	return stdoutProgress
+}
+
+// AddTask registers a task on the stdout renderer and returns its
+// reporter, for work that runs alongside a step, such as one of several
+// concurrent downloads.
+func AddTask(message string) ProgressReporter {
+	return progress().Add(message)
+}
+
+// NewMultiProgress starts a renderer writing to stdout. Call Wait when all
+// tasks are added and finished.
+func NewMultiProgress() *MultiProgress {
+	return newMultiProgress(stdout{}, term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// stdout writes to os.Stdout as it is when written to, not when the
+// renderer started, so output redirected later is followed.
+type stdout struct{}
+
+func (stdout) Write(b []byte) (int, error) { return os.Stdout.Write(b) }
+
+func newMultiProgress(out io.Writer, tty bool) *MultiProgress {
+	p := &MultiProgress{
+		out:    out,
+		tty:    tty,
+		events: make(chan progressEvent, 64),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Add registers a task and returns its reporter.
+func (p *MultiProgress) Add(message string) ProgressReporter {
+	t := &progressTask{id: int(p.nextID.Add(1)), p: p}
+	p.send(progressEvent{id: t.id, add: true, message: message, percent: -1})
+	return t
+}
+
+// Wait stops accepting events and blocks until everything sent so far has
+// been rendered. Tasks still running are shown as failed.
+func (p *MultiProgress) Wait() {
+	p.mu.Lock()
+	closed := p.closed
+	p.closed = true
+	p.mu.Unlock()
+	if !closed {
+		p.sending.Wait()
+		close(p.events)
+	}
+	<-p.done
+}
+
+// send queues ev for rendering and reports whether it was accepted. The
+// lock only guards the closed check: the channel send happens outside it,
+// so a slow renderer blocks the sender but never Wait or other tasks'
+// bookkeeping.
+func (p *MultiProgress) send(ev progressEvent) bool {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return false
+	}
+	p.sending.Add(1)
+	p.mu.Unlock()
+
+	p.events <- ev
+	p.sending.Done()
+	return true
+}
+
+func (p *MultiProgress) run() {
+	defer close(p.done)
+
+	var (
+		active []*taskLine
+		drawn  int
+		frame  int
+		ticker *time.Ticker
+	)
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	// redraw repaints the live region. Finished tasks are printed once as
+	// ✓/✗ lines above it and dropped from the region.
+	redraw := func(finished []*taskLine) {
+		var b strings.Builder
+		if drawn > 0 {
+			fmt.Fprintf(&b, "\033[%dA", drawn)
+		}
+		for _, t := range finished {
+			b.WriteString("\r\033[2K" + finishedLine(t) + "\n")
+		}
+		for _, t := range active {
+			b.WriteString("\r\033[2K" + runningLine(t, frames[frame%len(frames)]) + "\n")
+		}
+		b.WriteString("\033[J")
+		drawn = len(active)
+		fmt.Fprint(p.out, b.String())
+	}
+
+	apply := func(ev progressEvent) (finished *taskLine) {
+		if ev.add {
+			t := &taskLine{id: ev.id, message: ev.message, percent: -1}
+			active = append(active, t)
+			if !p.tty {
+				fmt.Fprintf(p.out, "  %s\n", t.message)
+			}
+			return nil
+		}
+
+		for i, t := range active {
+			if t.id != ev.id {
+				continue
+			}
+			if ev.status != "" {
+				t.status = ev.status
+			}
+			if ev.percent >= 0 {
+				t.percent = ev.percent
+			}
+			if ev.state != taskRunning {
+				t.state = ev.state
+				active = append(active[:i], active[i+1:]...)
+				if !p.tty {
+					fmt.Fprintln(p.out, finishedLine(t))
+				}
+				return t
+			}
+			return nil
+		}
+		return nil
+	}
+
+	for {
+		// Animate only while something is running, so an idle renderer
+		// doesn't wake up.
+		var tick <-chan time.Time
+		switch {
+		case p.tty && len(active) > 0:
+			if ticker == nil {
+				ticker = time.NewTicker(80 * time.Millisecond)
+			}
+			tick = ticker.C
+		case ticker != nil:
+			ticker.Stop()
+			ticker = nil
+		}
+
+		select {
+		case ev, ok := <-p.events:
+			if !ok {
+				for _, t := range active {
+					t.state = taskFailed
+				}
+				finished := active
+				active = nil
+				if p.tty {
+					redraw(finished)
+				} else {
+					for _, t := range finished {
+						fmt.Fprintln(p.out, finishedLine(t))
+					}
+				}
+				return
+			}
+			if t := apply(ev); p.tty {
+				var finished []*taskLine
+				if t != nil {
+					finished = append(finished, t)
+				}
+				redraw(finished)
+			}
+			if ev.done != nil {
+				close(ev.done)
+			}
+		case <-tick:
+			frame++
+			redraw(nil)
+		}
+	}
+}
+
+func runningLine(t *taskLine, frame string) string {
+	line := Cyan.Sprintf("  %s %s", frame, t.message)
+	if t.percent >= 0 {
+		line += Dim.Sprintf(" %3d%%", t.percent)
+	}
+	if t.status != "" {
+		line += Dim.Sprint("  " + t.status)
+	}
+	return line
+}
+
+func finishedLine(t *taskLine) string {
+	if t.state == taskSucceeded {
+		return Green.Sprintf("  ✓ %s", t.message)
+	}
+	return Red.Sprintf("  ✗ %s", t.message)
+}
+
+// progressTask is the ProgressReporter handed out by MultiProgress.Add.
+type progressTask struct {
+	id   int
+	p    *MultiProgress
+	once sync.Once
+}
+
+func (t *progressTask) Status(msg string) {
+	t.p.send(progressEvent{id: t.id, status: msg, percent: -1})
+}
+
+func (t *progressTask) Percent(pct int) {
+	t.p.send(progressEvent{id: t.id, percent: max(0, min(pct, 100))})
+}
+
+// Stop returns once the task's ✓/✗ line is written, so output printed
+// after it appears below it.
+func (t *progressTask) Stop(success bool) {
+	t.once.Do(func() {
+		state := taskFailed
+		if success {
+			state = taskSucceeded
+		}
+		done := make(chan struct{})
+		if t.p.send(progressEvent{id: t.id, percent: -1, state: state, done: done}) {
+			<-done
+		}
+	})
+}
+
+// ShowTransfer reports n of total bytes transferred on r: as a percentage
+// when total is known, otherwise as the byte count so far.
+func ShowTransfer(r ProgressReporter, n, total int64) {
+	if total > 0 {
+		r.Percent(int(n * 100 / total))
+		return
+	}
+	r.Status(formatBytes(n))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from the renderer while the
+// test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// slowWriter delays every write, like a terminal that can't keep up.
+type slowWriter struct {
+	syncBuffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	return w.syncBuffer.Write(p)
+}
+
+// runTasks runs n tasks on p from goroutines of their own, each reporting
+// status and percent before stopping; odd tasks fail.
+func runTasks(p *MultiProgress, n int) {
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task := p.Add(fmt.Sprintf("task %d", i))
+			for pct := 0; pct <= 100; pct += 10 {
+				task.Status(fmt.Sprintf("step %d", pct))
+				task.Percent(pct)
+			}
+			task.Stop(i%2 == 0)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMultiProgressConcurrentTasks(t *testing.T) {
+	for _, tty := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tty=%v", tty), func(t *testing.T) {
+			var out syncBuffer
+			p := newMultiProgress(&out, tty)
+			runTasks(p, 10)
+			p.Wait()
+
+			got := out.String()
+			for i := range 10 {
+				mark := "✓"
+				if i%2 == 1 {
+					mark = "✗"
+				}
+				line := fmt.Sprintf("%s task %d\n", mark, i)
+				if n := strings.Count(got, line); n != 1 {
+					t.Errorf("%q written %d times, want once:\n%s", line, n, got)
+				}
+			}
+			if !tty && strings.Contains(got, "\r") {
+				t.Errorf("non-terminal output has carriage returns:\n%q", got)
+			}
+		})
+	}
+}
+
+// TestMultiProgressSlowWriter checks tasks reporting from many goroutines
+// don't deadlock Wait while the renderer is stuck writing.
+func TestMultiProgressSlowWriter(t *testing.T) {
+	p := newMultiProgress(&slowWriter{}, true)
+	done := make(chan struct{})
+	go func() {
+		runTasks(p, 10)
+		p.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("tasks and Wait deadlocked with a slow renderer")
+	}
+}
+
+// TestMultiProgressAfterWait checks reporting on a closed renderer is
+// dropped rather than blocking or panicking.
+func TestMultiProgressAfterWait(t *testing.T) {
+	var out syncBuffer
+	p := newMultiProgress(&out, false)
+	task := p.Add("late")
+	p.Wait()
+
+	task.Status("ignored")
+	task.Stop(true)
+	if got := out.String(); !strings.Contains(got, "✗ late") {
+		t.Errorf("task running at Wait not shown as failed:\n%s", got)
+	}
+}
+
+// TestSpinnerStopWritesFirst checks Stop returns only once its line is
+// written, so output printed after it can't land above it.
+func TestSpinnerStopWritesFirst(t *testing.T) {
+	var out syncBuffer
+	p := newMultiProgress(&out, false)
+	s := newSpinner(p, "spin")
+	s.Start()
+	s.Status("half way")
+	s.Stop(true)
+	if got := out.String(); !strings.HasSuffix(got, "✓ spin\n") {
+		t.Errorf("Stop returned before writing its line:\n%q", got)
+	}
+
+	stopped := newSpinner(p, "never started")
+	stopped.Stop(false)
+	stopped.Start()
+	if got := out.String(); !strings.HasSuffix(got, "✗ never started\n") {
+		t.Errorf("Stop without Start didn't write the status line:\n%q", got)
+	}
+	p.Wait()
+}
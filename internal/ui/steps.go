@@ -0,0 +1,42 @@
+package ui
+
+// Steps renders an ordered sequence of steps as "[n/total] message"
+// spinners with one active at a time: starting a step stops the one
+// before it. Work a step runs concurrently reports on tasks of its own,
+// added with AddTask, which render below it.
+type Steps struct {
+	p     *MultiProgress
+	total int
+	n     int
+	cur   *Spinner
+}
+
+// NewSteps returns a renderer for total steps writing to stdout.
+func NewSteps(total int) *Steps {
+	return &Steps{p: progress(), total: total}
+}
+
+// Start stops the running step, as failed, and starts the next one.
+func (s *Steps) Start(message string) *Spinner {
+	s.Stop(false)
+	s.n++
+	s.cur = newSpinner(s.p, Dim.Sprintf("[%d/%d]", s.n, s.total)+" "+message)
+	s.cur.Start()
+	return s.cur
+}
+
+// Skip counts the next step without rendering it, such as a step a
+// resumed init already completed.
+func (s *Steps) Skip() {
+	s.Stop(false)
+	s.n++
+}
+
+// Stop ends the running step, if any.
+func (s *Steps) Stop(success bool) {
+	if s.cur == nil {
+		return
+	}
+	s.cur.Stop(success)
+	s.cur = nil
+}
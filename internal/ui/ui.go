@@ -2,24 +2,77 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"sync"
-	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 )
 
+// Output is where the package prints: color.Output, stdout with colors
+// translated for the Windows console, unless redirected.
+var Output io.Writer = colorOutput{}
+
+// colorOutput writes to color.Output as it is when written to.
+type colorOutput struct{}
+
+func (colorOutput) Write(b []byte) (int, error) { return color.Output.Write(b) }
+
+// Style prints text in one color. It wraps *color.Color so that printing
+// goes to Output, and follows color.NoColor: it prints plain text when
+// NO_COLOR is set, stdout isn't a terminal, or DisableColor was called.
+type Style struct {
+	c *color.Color
+}
+
+func newStyle(attrs ...color.Attribute) Style {
+	return Style{c: color.New(attrs...)}
+}
+
+// Sprint formats a as fmt.Sprint does, in s.
+func (s Style) Sprint(a ...any) string {
+	return s.c.Sprint(a...)
+}
+
+// Sprintf formats as fmt.Sprintf does, in s.
+func (s Style) Sprintf(format string, a ...any) string {
+	return s.c.Sprintf(format, a...)
+}
+
+// Print writes a to Output as fmt.Print does, in s.
+func (s Style) Print(a ...any) {
+	fmt.Fprint(Output, s.Sprint(a...))
+}
+
+// Printf writes to Output as fmt.Printf does, in s.
+func (s Style) Printf(format string, a ...any) {
+	fmt.Fprint(Output, s.Sprintf(format, a...))
+}
+
+// Println writes a to Output as fmt.Println does, in s but for the
+// newline.
+func (s Style) Println(a ...any) {
+	fmt.Fprintln(Output, s.Sprint(strings.TrimSuffix(fmt.Sprintln(a...), "\n")))
+}
+
 var (
-	Bold    = color.New(color.Bold)
-	Green   = color.New(color.FgGreen, color.Bold)
-	Cyan    = color.New(color.FgCyan)
-	Yellow  = color.New(color.FgYellow)
-	Red     = color.New(color.FgRed, color.Bold)
-	Dim     = color.New(color.Faint)
-	White   = color.New(color.FgWhite, color.Bold)
-	Magenta = color.New(color.FgMagenta, color.Bold)
+	Bold    = newStyle(color.Bold)
+	Green   = newStyle(color.FgGreen, color.Bold)
+	Cyan    = newStyle(color.FgCyan)
+	Yellow  = newStyle(color.FgYellow)
+	Red     = newStyle(color.FgRed, color.Bold)
+	Dim     = newStyle(color.Faint)
+	White   = newStyle(color.FgWhite, color.Bold)
+	Magenta = newStyle(color.FgMagenta, color.Bold)
 )
 
+// DisableColor makes every style print plain text, as --no-color does.
+func DisableColor() {
+	color.NoColor = true
+}
+
 const banner = `
                         _  __          _
   _ __ ___   __ _ _ __ (_)/ _| ___ ___| |_ ___
@@ -33,78 +86,111 @@ func PrintBanner() {
 }
 
 func PrintCreateHeader(projectName, goModule string) {
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Magenta.Println("  Creating a new Manifesto app in", Bold.Sprint("./"+projectName))
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Dim.Printf("  module:  %s\n", goModule)
-	fmt.Println()
+	fmt.Fprintln(Output)
 }
 
 func PrintCreateHeaderQuick(projectName, goModule string) {
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Magenta.Println("  Creating a new Manifesto", Yellow.Sprint("quick"), "app in", Bold.Sprint("./"+projectName))
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Dim.Printf("  module:  %s\n", goModule)
 	Dim.Println("  mode:    quick (no IAM, no migrations)")
-	fmt.Println()
+	fmt.Fprintln(Output)
 }
 
-// Spinner provides a CRA-style animated spinner.
+func PrintCreateHeaderProfile(projectName, goModule, profile, description string) {
+	fmt.Fprintln(Output)
+	Magenta.Println("  Creating a new Manifesto", Yellow.Sprint(profile), "app in", Bold.Sprint("./"+projectName))
+	fmt.Fprintln(Output)
+	Dim.Printf("  module:  %s\n", goModule)
+	Dim.Printf("  profile: %s (%s)\n", profile, description)
+	fmt.Fprintln(Output)
+}
+
+// Spinner provides a CRA-style animated spinner, drawn as a task of the
+// process-wide MultiProgress. When stdout is not a terminal it prints its
+// message once when started and a ✓/✗ line when stopped, without carriage
+// returns.
+//
+// Start and Stop may be called in any order and any number of times: Stop
+// without Start only prints the status line, and Start after Stop does
+// nothing. Stop returns once the status line is written.
 type Spinner struct {
 	message string
-	done    chan bool
+	p       *MultiProgress
+
 	mu      sync.Mutex
+	task    ProgressReporter // Set by Start
+	status  string           // Last Status before Start
+	percent int              // Last Percent before Start, or -1
 	stopped bool
 }
 
 var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 func NewSpinner(message string) *Spinner {
-	return &Spinner{
-		message: message,
-		done:    make(chan bool),
-	}
+	return newSpinner(progress(), message)
 }
 
-// NewStepSpinner creates a spinner with a step counter prefix (e.g. "[1/6] Downloading...")
-func NewStepSpinner(step, total int, message string) *Spinner {
-	return NewSpinner(Dim.Sprintf("[%d/%d]", step, total) + " " + message)
+func newSpinner(p *MultiProgress, message string) *Spinner {
+	return &Spinner{message: message, p: p, percent: -1}
 }
 
 func (s *Spinner) Start() {
-	go func() {
-		i := 0
-		for {
-			select {
-			case <-s.done:
-				return
-			default:
-				frame := frames[i%len(frames)]
-				Cyan.Printf("\r  %s %s", frame, s.message)
-				time.Sleep(80 * time.Millisecond)
-				i++
-			}
-		}
-	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.task != nil || s.stopped {
+		return
+	}
+	s.task = s.p.Add(s.message)
+	if s.percent >= 0 {
+		s.task.Percent(s.percent)
+	}
+	if s.status != "" {
+		s.task.Status(s.status)
+	}
 }
 
 func (s *Spinner) Stop(success bool) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	if s.stopped {
+		s.mu.Unlock()
 		return
 	}
 	s.stopped = true
-	s.done <- true
+	task := s.task
+	if task == nil {
+		task = s.p.Add(s.message)
+	}
+	s.mu.Unlock()
 
-	// Clear the line.
-	fmt.Printf("\r%s\r", strings.Repeat(" ", len(s.message)+10))
+	task.Stop(success)
+}
 
-	if success {
-		Green.Printf("  ✓ %s\n", s.message)
-	} else {
-		Red.Printf("  ✗ %s\n", s.message)
+// Status shows msg after the spinner message.
+func (s *Spinner) Status(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.task == nil {
+		s.status = msg
+		return
 	}
+	s.task.Status(msg)
+}
+
+// Percent shows a completion percentage after the spinner message.
+func (s *Spinner) Percent(pct int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.task == nil {
+		s.percent = pct
+		return
+	}
+	s.task.Percent(pct)
 }
 
 func StepDone(msg string) {
@@ -119,105 +205,414 @@ func StepWarn(msg string) {
 	Yellow.Printf("  ⚠ %s\n", msg)
 }
 
-func PrintSuccess(projectName string, wiredModules []string) {
-	fmt.Println()
+// PrintSuccess reports a created project, followed by the follow-ups its
+// wired modules left. untidy lists go mod tidy among the first steps, for
+// a project init didn't tidy.
+func PrintSuccess(projectName string, wiredModules []string, followUps []ChecklistItem, todoFile string, untidy bool) {
+	fmt.Fprintln(Output)
 	Green.Println("  Success!", White.Sprintf(" Created %s", projectName))
-	fmt.Println()
-
-	hasIAM := false
-	for _, m := range wiredModules {
-		if m == "iam" {
-			hasIAM = true
-			break
-		}
-	}
+	fmt.Fprintln(Output)
 
 	Dim.Println("  Get started:")
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Cyan.Printf("    cd %s\n", projectName)
-	Cyan.Println("    go mod tidy")
-	if hasIAM {
-		Cyan.Println("    make up         # start postgres + redis")
-		Cyan.Println("    make migrate    # run database migrations")
-	} else {
-		Cyan.Println("    make up         # start postgres + redis")
+	if untidy {
+		Cyan.Println("    go mod tidy")
 	}
+	Cyan.Println("    make up         # start postgres + redis")
 	Cyan.Println("    make dev        # start with hot reload")
-	fmt.Println()
+	fmt.Fprintln(Output)
 
 	Dim.Println("  Add your first domain:")
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Cyan.Println("    manifesto add pkg/mymodule/entity")
-	fmt.Println()
+	fmt.Fprintln(Output)
 
 	if len(wiredModules) == 0 {
 		Dim.Println("  Wire modules anytime:")
-		fmt.Println()
+		fmt.Fprintln(Output)
 		Cyan.Println("    manifesto add iam       # auth, users, tenants")
 		Cyan.Println("    manifesto add jobx      # background jobs")
 		Cyan.Println("    manifesto modules       # see all available")
-		fmt.Println()
+		fmt.Fprintln(Output)
 	}
 
+	PrintChecklist(followUps, todoFile)
+
 	Dim.Println("  Happy hacking!")
-	fmt.Println()
+	fmt.Fprintln(Output)
+}
+
+// FileDisplay is a generated file shown in success output.
+type FileDisplay struct {
+	Path        string
+	Description string
+}
+
+// AddSummary describes a scaffolded domain for PrintAddSuccess.
+type AddSummary struct {
+	EntityName string
+	TableName  string
+	Files      []FileDisplay
+	Migration  bool     // Backend is SQL; sketch a CREATE TABLE migration
+	Columns    []string // CREATE TABLE lines for the fields given with --fields
+	Uploads    string   // Attachments table of a domain with uploads, sketched too
+	UploadsKey string   // Its column referencing the entity, e.g. report_id
+	Injected   bool     // Domain has a container injected into cmd/container.go
+	Routes     bool     // Domain has HTTP handlers registered in cmd/server.go
+	RouteGroup string   // Group they are registered on: public, protected or a --group
+	GRPC       bool     // Domain has a gRPC service registered in cmd/server.go
 }
 
-func PrintAddSuccess(entityName, domainPath, pkgName, tableName string) {
-	fmt.Println()
-	Green.Println("  Success!", White.Sprintf(" Created domain %s", entityName))
-	fmt.Println()
+// PrintAddSuccess reports a scaffolded domain. Follow-up steps are printed
+// separately with PrintChecklist.
+func PrintAddSuccess(s AddSummary) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Created domain %s", s.EntityName))
+	fmt.Fprintln(Output)
 	Dim.Println("  Generated files:")
-	fmt.Println()
-	printFile(domainPath+"/"+pkgName+".go", "Entity + DTOs")
-	printFile(domainPath+"/port.go", "Repository interface")
-	printFile(domainPath+"/errors.go", "Error registry")
-	printFile(domainPath+"/"+pkgName+"srv/service.go", "Service layer")
-	printFile(domainPath+"/"+pkgName+"infra/postgres.go", "Postgres repository")
-	printFile(domainPath+"/"+pkgName+"api/handler.go", "HTTP handlers (CRUD ready)")
-	printFile(domainPath+"/"+pkgName+"container/container.go", "Module container (DI wiring)")
-	fmt.Println()
-	Dim.Printf("  + kernel.%sID added to pkg/kernel/proj_ids.go\n", entityName)
-	Dim.Printf("  + %s injected into cmd/container.go\n", entityName)
-	Dim.Printf("  + %s routes registered at /api/v1/%s\n", entityName, tableName)
-	fmt.Println()
-	Dim.Println("  Next steps:")
-	fmt.Println()
-	fmt.Printf("    %s Add fields to %s\n", Cyan.Sprint("1."), Bold.Sprint(domainPath+"/"+pkgName+".go"))
-	fmt.Printf("    %s Update the SQL in %s to match your fields\n", Cyan.Sprint("2."), Bold.Sprint(domainPath+"/"+pkgName+"infra/postgres.go"))
-	fmt.Printf("    %s Create a migration:\n", Cyan.Sprint("3."))
-	fmt.Println()
-	Dim.Printf("       CREATE TABLE %s (\n", tableName)
-	Dim.Println("           id         TEXT PRIMARY KEY,")
-	Dim.Println("           tenant_id  TEXT NOT NULL REFERENCES tenants(id),")
-	Dim.Println("           -- add your fields here")
-	Dim.Println("           created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),")
-	Dim.Println("           updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()")
-	Dim.Println("       );")
-	fmt.Println()
-}
-
-func PrintWireSuccess(moduleName string, modifiedFiles []string, bridges []string) {
-	fmt.Println()
-	Green.Println("  Success!", White.Sprintf(" Wired %s", moduleName))
-	fmt.Println()
+	fmt.Fprintln(Output)
+	for _, f := range s.Files {
+		printFile(f.Path, f.Description)
+	}
+	fmt.Fprintln(Output)
+	Dim.Printf("  + kernel.%sID added to pkg/kernel/proj_ids.go\n", s.EntityName)
+	if s.Injected {
+		Dim.Printf("  + %s injected into cmd/container.go\n", s.EntityName)
+	}
+	if s.Routes {
+		switch s.RouteGroup {
+		case "public", "protected":
+			Dim.Printf("  + %s routes registered at /api/v1/%s (%s)\n", s.EntityName, s.TableName, s.RouteGroup)
+		default:
+			Dim.Printf("  + %s routes registered on the %s group in cmd/server.go\n", s.EntityName, s.RouteGroup)
+		}
+	}
+	if s.GRPC {
+		Dim.Printf("  + %sService registered in cmd/server.go's registerGRPC\n", s.EntityName)
+	}
+	fmt.Fprintln(Output)
+
+	if !s.Migration {
+		return
+	}
+	Dim.Println("  Migration sketch:")
+	fmt.Fprintln(Output)
+	Dim.Printf("    CREATE TABLE %s (\n", s.TableName)
+	Dim.Println("        id         TEXT PRIMARY KEY,")
+	Dim.Println("        tenant_id  TEXT NOT NULL REFERENCES tenants(id),")
+	if len(s.Columns) == 0 {
+		Dim.Println("        -- add your fields here")
+	}
+	for _, col := range s.Columns {
+		Dim.Printf("        %s\n", col)
+	}
+	Dim.Println("        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),")
+	Dim.Println("        updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()")
+	Dim.Println("    );")
+	fmt.Fprintln(Output)
+
+	if s.Uploads == "" {
+		return
+	}
+	width := max(len(s.UploadsKey), len("content_type"))
+	Dim.Printf("    CREATE TABLE %s (\n", s.Uploads)
+	Dim.Printf("        %-*s TEXT PRIMARY KEY,\n", width, "id")
+	Dim.Printf("        %-*s TEXT NOT NULL REFERENCES %s(id) ON DELETE CASCADE,\n", width, s.UploadsKey, s.TableName)
+	Dim.Printf("        %-*s TEXT NOT NULL,\n", width, "file_name")
+	Dim.Printf("        %-*s TEXT NOT NULL,\n", width, "content_type")
+	Dim.Printf("        %-*s BIGINT NOT NULL,\n", width, "size")
+	Dim.Printf("        %-*s TEXT NOT NULL,\n", width, "path")
+	Dim.Printf("        %-*s TIMESTAMPTZ NOT NULL DEFAULT NOW()\n", width, "created_at")
+	Dim.Println("    );")
+	Dim.Printf("    CREATE INDEX ON %s (%s);\n", s.Uploads, s.UploadsKey)
+	fmt.Fprintln(Output)
+}
+
+// PrintCronSuccess reports a scaffolded cron job.
+func PrintCronSuccess(name, file, schedule string) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Created cron job %s", name))
+	fmt.Fprintln(Output)
+	printFile(file, "runs at "+schedule)
+	fmt.Fprintln(Output)
+	Dim.Printf("  + %s registered in cmd/container.go's registerCronJobs\n", name)
+	fmt.Fprintln(Output)
+}
+
+// PrintJobSuccess reports a scaffolded jobx job handler.
+func PrintJobSuccess(name, file string) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Created job %s", name))
+	fmt.Fprintln(Output)
+	printFile(file, "payload and handler")
+	fmt.Fprintln(Output)
+	Dim.Printf("  + %s registered with the job client in cmd/container.go\n", name)
+	fmt.Fprintln(Output)
+}
+
+// ChecklistItem is a follow-up step shown by PrintChecklist.
+type ChecklistItem struct {
+	Source string // Module or domain that left the step
+	Text   string
+	Done   bool
+}
+
+// PrintMigrationSuccess reports the files of a new migration.
+func PrintMigrationSuccess(name string, files []string) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Created migration %s", name))
+	fmt.Fprintln(Output)
+	for _, f := range files {
+		desc := "applies the change"
+		if strings.HasSuffix(f, ".down.sql") {
+			desc = "reverts it"
+		}
+		printFile(f, desc)
+	}
+	fmt.Fprintln(Output)
+}
+
+// MigrationDisplay is one migration in migrate's output.
+type MigrationDisplay struct {
+	Version string
+	Name    string
+	Applied bool
+}
+
+// PrintMigrations lists the migrations a dry run would apply or revert,
+// in the order it would.
+func PrintMigrations(title string, migrations []MigrationDisplay) {
+	PrintSection(title)
+	for _, m := range migrations {
+		fmt.Fprintf(Output, "    %s %s\n", Cyan.Sprint(m.Version), m.Name)
+	}
+	fmt.Fprintln(Output)
+}
+
+// PrintMigrationStatus lists every migration, oldest first, with whether
+// it is applied.
+func PrintMigrationStatus(migrations []MigrationDisplay) {
+	PrintSection("Migrations")
+	if len(migrations) == 0 {
+		Dim.Println("    none")
+		fmt.Fprintln(Output)
+		return
+	}
+	pending := 0
+	for _, m := range migrations {
+		if m.Applied {
+			fmt.Fprintf(Output, "    %s %s %s  %s\n", Green.Sprint("✓"), Cyan.Sprint(m.Version), m.Name, Dim.Sprint("applied"))
+		} else {
+			pending++
+			fmt.Fprintf(Output, "    %s %s %s  %s\n", Yellow.Sprint("•"), Cyan.Sprint(m.Version), m.Name, Yellow.Sprint("pending"))
+		}
+	}
+	fmt.Fprintln(Output)
+	Dim.Printf("  %d applied, %d pending\n", len(migrations)-pending, pending)
+	fmt.Fprintln(Output)
+}
+
+// StatusDisplay is one module, wired module or domain in `manifesto status`.
+type StatusDisplay struct {
+	Kind   string // "module", "wiring" or "domain"
+	Name   string
+	Detail string
+}
+
+// PrintStatus prints what manifesto.yaml records and the project has, what
+// it records but the project lacks, and what the project has unrecorded.
+func PrintStatus(installed, missing, untracked []StatusDisplay) {
+	// One set of columns for all three sections.
+	var kinds, names []string
+	for _, e := range slices.Concat(installed, missing, untracked) {
+		kinds = append(kinds, e.Kind)
+		names = append(names, e.Name)
+	}
+	kindWidth, nameWidth := widest(8, kinds), widest(20, names)
+	printStatusSection("Installed", Green.Sprint("●"), installed, kindWidth, nameWidth)
+	printStatusSection("Missing", Red.Sprint("✗"), missing, kindWidth, nameWidth)
+	printStatusSection("Untracked", Yellow.Sprint("?"), untracked, kindWidth, nameWidth)
+	fmt.Fprintln(Output)
+	Dim.Printf("  %d installed, %d missing, %d untracked\n", len(installed), len(missing), len(untracked))
+	fmt.Fprintln(Output)
+}
+
+func printStatusSection(title, mark string, entries []StatusDisplay, kindWidth, nameWidth int) {
+	PrintSection(title)
+	if len(entries) == 0 {
+		Dim.Println("    none")
+		return
+	}
+	for _, e := range entries {
+		if e.Detail == "" {
+			fmt.Fprintf(Output, "    %s %s %s\n", mark, Dim.Sprint(pad(e.Kind, kindWidth)), Cyan.Sprint(e.Name))
+			continue
+		}
+		fmt.Fprintf(Output, "    %s %s %s %s\n", mark, Dim.Sprint(pad(e.Kind, kindWidth)), Cyan.Sprint(pad(e.Name, nameWidth)), Dim.Sprint(e.Detail))
+	}
+}
+
+// PrintChecklist prints follow-up steps with the module or domain each
+// came from, and the file they were saved to. It prints nothing when
+// there are no items.
+func PrintChecklist(items []ChecklistItem, file string) {
+	if len(items) == 0 {
+		return
+	}
+	Dim.Println("  Follow-ups:")
+	fmt.Fprintln(Output)
+	for _, item := range items {
+		if item.Done {
+			fmt.Fprintf(Output, "    %s %s  %s\n", Green.Sprint("[x]"), Dim.Sprint(item.Text), Dim.Sprint(item.Source))
+			continue
+		}
+		fmt.Fprintf(Output, "    %s %s  %s\n", Cyan.Sprint("[ ]"), item.Text, Dim.Sprint(item.Source))
+	}
+	fmt.Fprintln(Output)
+	if file != "" {
+		Dim.Printf("  Saved to %s\n", file)
+		fmt.Fprintln(Output)
+	}
+}
+
+func PrintWireSuccess(modules []string, modifiedFiles []string, bridges []string) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Wired %s", strings.Join(modules, ", ")))
+	fmt.Fprintln(Output)
 	if len(modifiedFiles) > 0 {
 		Dim.Println("  Modified files:")
 		for _, f := range modifiedFiles {
-			fmt.Printf("    %s %s\n", Green.Sprint("~"), Cyan.Sprint(f))
+			fmt.Fprintf(Output, "    %s %s\n", Green.Sprint("~"), Cyan.Sprint(f))
 		}
-		fmt.Println()
+		fmt.Fprintln(Output)
 	}
 	if len(bridges) > 0 {
 		for _, b := range bridges {
-			fmt.Printf("    %s Bridge: %s + %s auto-connected\n", Magenta.Sprint("⚡"), moduleName, b)
+			fmt.Fprintf(Output, "    %s Bridge: %s auto-connected\n", Magenta.Sprint("⚡"), strings.ReplaceAll(b, "+", " + "))
+		}
+		fmt.Fprintln(Output)
+	}
+}
+
+// PrintBuildErrors prints the output of a failed go build, with the
+// file:line:col of each compiler error highlighted.
+func PrintBuildErrors(output string) {
+	Red.Println("  ✗ The project doesn't build:")
+	fmt.Fprintln(Output)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if pos, msg, ok := strings.Cut(line, ": "); ok && strings.Contains(pos, ".go:") {
+			fmt.Fprintf(Output, "    %s: %s\n", Cyan.Sprint(pos), msg)
+		} else {
+			Dim.Printf("    %s\n", line)
 		}
-		fmt.Println()
+	}
+	fmt.Fprintln(Output)
+}
+
+// PrintDeferred explains why some steps were skipped and lists the commands
+// the user must run manually from dir once the problem is fixed.
+func PrintDeferred(problem string, guidance []string, dir string, steps []string) {
+	Yellow.Printf("  ⚠ %s\n", problem)
+	for _, g := range guidance {
+		Dim.Printf("    %s\n", g)
+	}
+	fmt.Fprintln(Output)
+	if len(steps) == 0 {
+		return
+	}
+	Dim.Println("  Then run these steps manually:")
+	fmt.Fprintln(Output)
+	Cyan.Printf("    cd %s\n", dir)
+	for _, step := range steps {
+		Cyan.Printf("    %s\n", step)
+	}
+	fmt.Fprintln(Output)
+}
+
+// PrintSection prints a bold section heading preceded by a blank line.
+func PrintSection(title string) {
+	fmt.Fprintln(Output)
+	if title != "" {
+		Bold.Printf("  %s\n", title)
+		fmt.Fprintln(Output)
+	}
+}
+
+// fieldWidth is the narrowest key column of PrintField and PrintFields.
+const fieldWidth = 14
+
+// Field is a "key  value" line of a section.
+type Field struct {
+	Key, Value string
+}
+
+// PrintField prints an aligned "key  value" line within a section.
+func PrintField(key, value string) {
+	printField(Field{key, value}, fieldWidth)
+}
+
+// PrintFields prints fields as PrintField does, with the values aligned
+// past the longest key.
+func PrintFields(fields []Field) {
+	width := fieldWidth
+	for _, f := range fields {
+		width = max(width, utf8.RuneCountInString(f.Key))
+	}
+	for _, f := range fields {
+		printField(f, width)
+	}
+}
+
+func printField(f Field, width int) {
+	if f.Value == "" {
+		fmt.Fprintf(Output, "    %s\n", Dim.Sprint(f.Key))
+		return
+	}
+	fmt.Fprintf(Output, "    %s %s\n", Dim.Sprint(pad(f.Key, width)), f.Value)
+}
+
+// pad returns s followed by spaces up to width runes. Pad text before
+// coloring it: fmt's widths would count a colored string's escape codes.
+func pad(s string, width int) string {
+	return fmt.Sprintf("%-*s", width, s)
+}
+
+// widest returns the length in runes of the longest of strs, or width if
+// none is longer.
+func widest(width int, strs []string) int {
+	for _, s := range strs {
+		width = max(width, utf8.RuneCountInString(s))
+	}
+	return width
+}
+
+// PrintCheck prints a pass/fail line for a doctor check, with the reason
+// for a failure dimmed underneath.
+func PrintCheck(ok bool, name, detail string) {
+	if ok {
+		fmt.Fprintf(Output, "    %s %s\n", Green.Sprint("✓"), name)
+		return
+	}
+	fmt.Fprintf(Output, "    %s %s\n", Red.Sprint("✗"), name)
+	if detail != "" {
+		fmt.Fprintf(Output, "      %s\n", Dim.Sprint(detail))
+	}
+}
+
+// PrintCheckWarn prints a failed check that is only a warning.
+func PrintCheckWarn(name, detail string) {
+	fmt.Fprintf(Output, "    %s %s\n", Yellow.Sprint("⚠"), name)
+	if detail != "" {
+		fmt.Fprintf(Output, "      %s\n", Dim.Sprint(detail))
 	}
 }
 
 func printFile(path, desc string) {
-	fmt.Printf("    %s %s  %s\n", Green.Sprint("✓"), Cyan.Sprint(path), Dim.Sprint(desc))
+	fmt.Fprintf(Output, "    %s %s  %s\n", Green.Sprint("✓"), Cyan.Sprint(path), Dim.Sprint(desc))
 }
 
 type ModuleDisplay struct {
@@ -226,18 +621,34 @@ type ModuleDisplay struct {
 	Installed   bool
 	Core        bool
 	Deps        string
+	Version     string // Manifesto ref the module was installed from
 }
 
 type WireableModuleDisplay struct {
 	Name        string
 	Description string
 	Wired       bool
+	Version     string // Manifesto ref of its source module, if installed
+}
+
+// moduleVersion formats a module's ref for the modules listing.
+func moduleVersion(v string) string {
+	if v == "" {
+		return ""
+	}
+	return Dim.Sprintf("  @%s", v)
 }
 
 func PrintModulesWithSections(libraries []ModuleDisplay, wireables []WireableModuleDisplay) {
-	fmt.Println()
+	var names []string
+	for _, m := range libraries {
+		names = append(names, m.Name)
+	}
+	nameWidth := widest(12, names)
+
+	fmt.Fprintln(Output)
 	Bold.Println("  Core Libraries")
-	fmt.Println()
+	fmt.Fprintln(Output)
 
 	for _, m := range libraries {
 		status := Dim.Sprint("○")
@@ -250,43 +661,344 @@ func PrintModulesWithSections(libraries []ModuleDisplay, wireables []WireableMod
 			deps = Dim.Sprintf(" → %s", m.Deps)
 		}
 
-		fmt.Printf("    %s  %-12s %s%s\n",
+		fmt.Fprintf(Output, "    %s  %s %s%s%s\n",
 			status,
-			Bold.Sprint(m.Name),
+			Bold.Sprint(pad(m.Name, nameWidth)),
 			m.Description,
+			moduleVersion(m.Version),
 			deps,
 		)
 	}
 
-	fmt.Println()
+	names = names[:0]
+	for _, m := range wireables {
+		names = append(names, m.Name)
+	}
+	nameWidth = widest(8, names)
+
+	fmt.Fprintln(Output)
 	Bold.Println("  Wireable Modules")
-	fmt.Println()
+	fmt.Fprintln(Output)
 
+	const notWired = "○ not wired"
 	for _, m := range wireables {
-		status := Dim.Sprint("○ not wired")
+		status := Dim.Sprint(notWired)
 		if m.Wired {
-			status = Green.Sprint("● wired")
+			status = Green.Sprint(pad("● wired", utf8.RuneCountInString(notWired)))
 		}
 
-		fmt.Printf("    %s  %-8s  %s\n",
+		fmt.Fprintf(Output, "    %s  %s  %s%s\n",
 			status,
-			Bold.Sprint(m.Name),
+			Bold.Sprint(pad(m.Name, nameWidth)),
 			m.Description,
+			moduleVersion(m.Version),
 		)
 	}
 
-	fmt.Println()
-	fmt.Printf("    %s installed/wired   %s available\n", Green.Sprint("●"), Dim.Sprint("○"))
-	fmt.Println()
+	fmt.Fprintln(Output)
+	fmt.Fprintf(Output, "    %s installed/wired   %s available\n", Green.Sprint("●"), Dim.Sprint("○"))
+	fmt.Fprintln(Output)
+}
+
+// RefDisplay is a manifesto ref in the versions listing.
+type RefDisplay struct {
+	Name    string
+	Release string // Release title, if any
+	Latest  bool
+	Current bool // The ref the project was created with
+}
+
+// PrintVersions lists the tags and branches of repo. current is the
+// project's ref, noted separately when it isn't in the listing, e.g. a
+// commit SHA.
+func PrintVersions(repo string, tags, branches []RefDisplay, current string) {
+	var names []string
+	for _, r := range slices.Concat(tags, branches) {
+		names = append(names, r.Name)
+	}
+	nameWidth := widest(16, names)
+
+	listed := false
+	printRefs := func(title string, refs []RefDisplay) {
+		fmt.Fprintln(Output)
+		Bold.Println("  " + title)
+		fmt.Fprintln(Output)
+		if len(refs) == 0 {
+			Dim.Println("    (none)")
+		}
+		for _, r := range refs {
+			status := Dim.Sprint("○")
+			if r.Current {
+				status = Green.Sprint("●")
+				listed = true
+			}
+			var notes []string
+			if r.Latest {
+				notes = append(notes, Green.Sprint("latest"))
+			}
+			if r.Current {
+				notes = append(notes, Green.Sprint("this project"))
+			}
+			line := fmt.Sprintf("    %s  %s", status, Bold.Sprint(pad(r.Name, nameWidth)))
+			if r.Release != "" && r.Release != r.Name {
+				line += " " + r.Release
+			}
+			if len(notes) > 0 {
+				line += Dim.Sprint("  (") + strings.Join(notes, Dim.Sprint(", ")) + Dim.Sprint(")")
+			}
+			fmt.Fprintln(Output, strings.TrimRight(line, " "))
+		}
+	}
+
+	Dim.Printf("\n  %s\n", repo)
+	printRefs("Tags", tags)
+	printRefs("Branches", branches)
+
+	fmt.Fprintln(Output)
+	if current != "" && !listed {
+		fmt.Fprintf(Output, "    This project uses manifesto@%s\n\n", Bold.Sprint(current))
+	}
+	Dim.Println("    Pass any of these to manifesto init --ref")
+	fmt.Fprintln(Output)
+}
+
+// DomainDisplay is a tracked domain in the domains listing.
+type DomainDisplay struct {
+	Path    string
+	Entity  string
+	Repo    string   // Repository backend, e.g. "postgres"
+	Kind    string   // Scaffold kind, e.g. "crud"
+	Layers  string   // Layers generated when not all, e.g. "entity,port"
+	Created string   // Date it was first scaffolded, if recorded
+	Missing []string // Generated files no longer on disk
+}
+
+func PrintDomains(domains []DomainDisplay) {
+	fmt.Fprintln(Output)
+	Bold.Println("  Domains")
+	fmt.Fprintln(Output)
+	if len(domains) == 0 {
+		Dim.Println("    (none)")
+		fmt.Fprintln(Output)
+		Dim.Println("    Scaffold one with manifesto add pkg/<context>/<entity>")
+		fmt.Fprintln(Output)
+		return
+	}
+
+	var paths []string
+	for _, d := range domains {
+		paths = append(paths, d.Path)
+	}
+	pathWidth := widest(28, paths)
+
+	incomplete := 0
+	for _, d := range domains {
+		status := Green.Sprint("●")
+		if len(d.Missing) > 0 {
+			status = Yellow.Sprint("◐")
+			incomplete++
+		}
+		details := []string{d.Entity, d.Repo}
+		if d.Kind != "" {
+			details = append(details, d.Kind)
+		}
+		if d.Layers != "" {
+			details = append(details, d.Layers)
+		}
+		if d.Created != "" {
+			details = append(details, d.Created)
+		}
+		fmt.Fprintf(Output, "    %s  %s %s\n", status, Cyan.Sprint(pad(d.Path, pathWidth)), Dim.Sprint(strings.Join(details, " · ")))
+		for _, f := range d.Missing {
+			fmt.Fprintf(Output, "         %s %s\n", Yellow.Sprint("missing"), f)
+		}
+	}
+
+	fmt.Fprintln(Output)
+	fmt.Fprintf(Output, "    %s all files present   %s files missing\n", Green.Sprint("●"), Yellow.Sprint("◐"))
+	if incomplete > 0 {
+		Dim.Println("    Regenerate a domain with manifesto add <path> to restore its files")
+	}
+	fmt.Fprintln(Output)
 }
 
 func PrintInstallSuccess(moduleName string, installed []string) {
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Green.Println("  Success!", White.Sprintf(" Installed %s", moduleName))
 	if len(installed) > 1 {
 		Dim.Printf("  (with dependencies: %s)\n", strings.Join(installed, ", "))
 	}
-	fmt.Println()
+	fmt.Fprintln(Output)
 	Dim.Println("  Run 'go mod tidy' to sync dependencies.")
-	fmt.Println()
+	fmt.Fprintln(Output)
+}
+
+func PrintUninstallSuccess(moduleName string, paths []string) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Uninstalled %s", moduleName))
+	fmt.Fprintln(Output)
+	for _, p := range paths {
+		fmt.Fprintf(Output, "    %s %s\n", Red.Sprint("-"), Cyan.Sprint(p))
+	}
+	fmt.Fprintln(Output)
+	Dim.Println("  Run 'go mod tidy' to drop the dependencies only it used.")
+	fmt.Fprintln(Output)
+}
+
+// UpgradeSummary lists what an upgrade did with the module's files.
+type UpgradeSummary struct {
+	Module, From, To string
+	Added            []string
+	Replaced         []string
+	Removed          []string
+	Kept             []string
+	Merged           []string
+	Conflicts        []string
+	Resolved         string // "ours" or "theirs" when conflicts were settled by a flag
+}
+
+// PrintUpgradeSuccess reports an upgraded module, one line per file it
+// touched or left alone, and explains any conflicts left to resolve.
+func PrintUpgradeSuccess(s UpgradeSummary) {
+	fmt.Fprintln(Output)
+	Green.Println("  Success!", White.Sprintf(" Upgraded %s from %s to %s", s.Module, s.From, s.To))
+	fmt.Fprintln(Output)
+	for _, f := range s.Added {
+		fmt.Fprintf(Output, "    %s %s  %s\n", Green.Sprint("+"), Cyan.Sprint(f), Dim.Sprint("new upstream"))
+	}
+	for _, f := range s.Replaced {
+		fmt.Fprintf(Output, "    %s %s  %s\n", Green.Sprint("✓"), Cyan.Sprint(f), Dim.Sprint("updated"))
+	}
+	for _, f := range s.Removed {
+		fmt.Fprintf(Output, "    %s %s  %s\n", Red.Sprint("-"), Cyan.Sprint(f), Dim.Sprint("removed upstream"))
+	}
+	for _, f := range s.Merged {
+		fmt.Fprintf(Output, "    %s %s  %s\n", Green.Sprint("~"), Cyan.Sprint(f), Dim.Sprint("merged with your changes"))
+	}
+	for _, f := range s.Kept {
+		fmt.Fprintf(Output, "    %s %s  %s\n", Yellow.Sprint("="), Cyan.Sprint(f), Dim.Sprint("kept: edited locally only"))
+	}
+	for _, f := range s.Conflicts {
+		if s.Resolved != "" {
+			fmt.Fprintf(Output, "    %s %s  %s\n", Yellow.Sprint("!"), Cyan.Sprint(f), Dim.Sprintf("conflicts settled with --%s", s.Resolved))
+			continue
+		}
+		fmt.Fprintf(Output, "    %s %s  %s\n", Red.Sprint("✗"), Cyan.Sprint(f), Dim.Sprint("conflicts"))
+	}
+	if len(s.Added)+len(s.Replaced)+len(s.Removed)+len(s.Merged)+len(s.Kept)+len(s.Conflicts) == 0 {
+		Dim.Println("    No file changed")
+	}
+	fmt.Fprintln(Output)
+	if len(s.Conflicts) > 0 && s.Resolved == "" {
+		Yellow.Println("  ⚠ Resolve the conflicts between the <<<<<<< and >>>>>>> markers in the files above")
+		fmt.Fprintln(Output)
+	}
+}
+
+// PrintDryRun shows what a --dry-run operation would do: files it would
+// create, unified diffs of files it would modify, other actions it would
+// take, and injections that would not apply.
+func PrintDryRun(newFiles, diffs, actions, skipped []string) {
+	fmt.Fprintln(Output)
+	Yellow.Println("  Dry run — no files were written")
+	fmt.Fprintln(Output)
+
+	if len(newFiles) > 0 {
+		Dim.Println("  New files:")
+		for _, f := range newFiles {
+			fmt.Fprintf(Output, "    %s %s\n", Green.Sprint("+"), Cyan.Sprint(f))
+		}
+		fmt.Fprintln(Output)
+	}
+
+	for _, d := range diffs {
+		PrintDiff(d)
+		fmt.Fprintln(Output)
+	}
+
+	if len(actions) > 0 {
+		Dim.Println("  Would also:")
+		for _, a := range actions {
+			fmt.Fprintf(Output, "    %s %s\n", Cyan.Sprint("→"), a)
+		}
+		fmt.Fprintln(Output)
+	}
+
+	if len(skipped) > 0 {
+		Red.Println("  Would not apply cleanly:")
+		for _, s := range skipped {
+			fmt.Fprintf(Output, "    %s %s\n", Red.Sprint("✗"), s)
+		}
+		fmt.Fprintln(Output)
+	}
+}
+
+// PrintPending prints the changes a --check run found pending: one line per
+// file or action, without diffs.
+func PrintPending(newFiles, modified, actions, skipped []string) {
+	fmt.Fprintln(Output)
+	for _, f := range newFiles {
+		fmt.Fprintf(Output, "    %s %s\n", Green.Sprint("+"), Cyan.Sprint(f))
+	}
+	for _, f := range modified {
+		fmt.Fprintf(Output, "    %s %s\n", Yellow.Sprint("~"), Cyan.Sprint(f))
+	}
+	for _, a := range actions {
+		fmt.Fprintf(Output, "    %s %s\n", Cyan.Sprint("→"), a)
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(Output, "    %s %s\n", Red.Sprint("✗"), s)
+	}
+	fmt.Fprintln(Output)
+}
+
+// PrintDiff prints a unified diff with added and removed lines colored.
+func PrintDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			Bold.Println(line)
+		case strings.HasPrefix(line, "@@"):
+			Cyan.Println(line)
+		case strings.HasPrefix(line, "+"):
+			Green.Println(line)
+		case strings.HasPrefix(line, "-"):
+			Red.Println(line)
+		default:
+			fmt.Fprintln(Output, line)
+		}
+	}
+}
+
+// DiffStat is one file of a diff --stat summary.
+type DiffStat struct {
+	Path           string
+	Added, Removed int    // Lines
+	Side           string // "ours" or "theirs" for a file only one side has
+	Edited         bool   // Changed locally since it was fetched
+}
+
+// PrintDiffStat prints one line per differing file with the lines added
+// and removed, then the totals.
+func PrintDiffStat(stats []DiffStat, theirs string) {
+	fmt.Fprintln(Output)
+	added, removed := 0, 0
+	for _, s := range stats {
+		mark, note := Yellow.Sprint("~"), ""
+		switch s.Side {
+		case "theirs":
+			mark, note = Green.Sprint("+"), "only in "+theirs
+		case "ours":
+			mark, note = Red.Sprint("-"), "only in the project"
+		}
+		if s.Edited {
+			note = strings.TrimPrefix(note+", edited locally", ", ")
+		}
+		fmt.Fprintf(Output, "    %s %s  %s %s  %s\n", mark, Cyan.Sprint(s.Path), Green.Sprintf("+%d", s.Added), Red.Sprintf("-%d", s.Removed), Dim.Sprint(note))
+		added += s.Added
+		removed += s.Removed
+	}
+	fmt.Fprintln(Output)
+	Dim.Printf("    %d file(s) differ, %d line(s) added, %d removed\n", len(stats), added, removed)
+	fmt.Fprintln(Output)
 }
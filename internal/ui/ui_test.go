@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// printAll prints every kind of output the package has: headers, steps,
+// a spinner on a renderer writing to out, listings and summaries.
+func printAll(out *syncBuffer) {
+	PrintBanner()
+	PrintCreateHeaderProfile("shop", "example.com/shop", "api", "HTTP API")
+	s := newSpinner(newMultiProgress(out, false), "Downloading manifesto")
+	s.Start()
+	s.Status("42 files")
+	s.Stop(true)
+	StepDone("done")
+	StepInfo("info")
+	StepWarn("warn")
+	PrintSuccess("shop", nil, []ChecklistItem{{Source: "jobx", Text: "run a worker"}}, "TODO.md", true)
+	PrintAddSuccess(AddSummary{EntityName: "Invoice", TableName: "invoices", Files: []FileDisplay{{Path: "pkg/invoice/invoice.go", Description: "entity"}}, Migration: true, Routes: true})
+	PrintWireSuccess([]string{"jobx"}, []string{"cmd/container.go"}, nil)
+	PrintStatus([]StatusDisplay{{Kind: "module", Name: "errx"}}, []StatusDisplay{{Kind: "wiring", Name: "jobx", Detail: "gone"}}, nil)
+	PrintSection("Project")
+	PrintField("module", "example.com/shop")
+	PrintCheck(true, "markers", "")
+	PrintCheck(false, "wiring", "jobx missing")
+	PrintCheckWarn("go.mod", "untidy")
+	PrintModulesWithSections([]ModuleDisplay{{Name: "errx", Installed: true, Version: "v1.0.0"}}, []WireableModuleDisplay{{Name: "jobx", Wired: true}})
+	PrintVersions("acme/manifesto", []RefDisplay{{Name: "v1.0.0", Latest: true, Current: true}}, nil, "v1.0.0")
+	PrintDomains([]DomainDisplay{{Path: "pkg/invoice", Entity: "Invoice", Repo: "postgres", Missing: []string{"pkg/invoice/port.go"}}})
+	PrintUpgradeSuccess(UpgradeSummary{Module: "errx", From: "v1.0.0", To: "v1.1.0", Merged: []string{"pkg/errx/errx.go"}, Conflicts: []string{"pkg/errx/http.go"}})
+	PrintDryRun([]string{"pkg/a.go"}, []string{"--- a\n+++ b\n-x\n+y\n"}, []string{"go get x"}, nil)
+	PrintDiff("--- a\n+++ b\n@@ -1 +1 @@\n-x\n+y\n")
+	PrintDiffStat([]DiffStat{{Path: "pkg/errx/errx.go", Added: 1, Removed: 2, Edited: true}}, "v1.1.0")
+	PrintBuildErrors("./main.go:1: undefined: x")
+}
+
+// capture runs fn with Output redirected to the returned buffer, and color
+// on or off.
+func capture(t *testing.T, colored bool, fn func(out *syncBuffer)) string {
+	t.Helper()
+	oldOutput, oldNoColor := Output, color.NoColor
+	t.Cleanup(func() { Output, color.NoColor = oldOutput, oldNoColor })
+	var out syncBuffer
+	Output, color.NoColor = &out, !colored
+	fn(&out)
+	return out.String()
+}
+
+// TestPlainOutput prints everything with color off, as without a terminal
+// or with NO_COLOR or --no-color: there must be no escape codes or
+// carriage returns, and the spinner must print its message and one ✓ line.
+func TestPlainOutput(t *testing.T) {
+	got := capture(t, false, printAll)
+	if strings.ContainsAny(got, "\x1b\r") {
+		t.Errorf("plain output has escape codes or carriage returns:\n%q", got)
+	}
+	for _, want := range []string{"  Downloading manifesto\n", "  ✓ Downloading manifesto\n"} {
+		if n := strings.Count("\n"+got, "\n"+want); n != 1 {
+			t.Errorf("%q printed %d times, want once:\n%s", want, n, got)
+		}
+	}
+}
+
+// TestColoredOutput checks styles color their text when color is on, so
+// TestPlainOutput passing means color was turned off.
+func TestColoredOutput(t *testing.T) {
+	got := capture(t, true, func(*syncBuffer) { StepDone("done") })
+	if !strings.Contains(got, "\x1b[") || !strings.Contains(got, "✓ done") {
+		t.Errorf("colored output = %q, want escape codes around ✓ done", got)
+	}
+}
+
+// TestStylePrintln checks Println spaces its operands as fmt.Println does,
+// colored or not.
+func TestStylePrintln(t *testing.T) {
+	for _, colored := range []bool{false, true} {
+		var want string
+		got := capture(t, colored, func(*syncBuffer) {
+			want = Green.Sprint("  Success! Created shop") + "\n"
+			Green.Println("  Success!", "Created", "shop")
+		})
+		if got != want {
+			t.Errorf("colored = %v: Println printed %q, want %q", colored, got, want)
+		}
+	}
+}
+
+var escape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// TestAlignedColumns prints listings with a key or name past the narrowest
+// column, colored and not: each value must start in the same column,
+// counted without escape codes.
+func TestAlignedColumns(t *testing.T) {
+	tests := []struct {
+		name  string
+		print func()
+		value string // Text starting the aligned column of every line
+	}{
+		{"fields", func() {
+			PrintFields([]Field{{"JOBX_CONCURRENCY", "10"}, {"REDIS_URL", "10"}, {"", "10"}})
+		}, "10"},
+		{"status", func() {
+			PrintStatus([]StatusDisplay{{Kind: "module", Name: "errx", Detail: "ok"}},
+				[]StatusDisplay{{Kind: "wiring", Name: "a-very-long-module-name", Detail: "ok"}}, nil)
+		}, "ok"},
+		{"domains", func() {
+			PrintDomains([]DomainDisplay{
+				{Path: "pkg/invoice", Entity: "Invoice", Repo: "postgres"},
+				{Path: "pkg/billing/invoicing/lineitems", Entity: "Invoice", Repo: "postgres"},
+			})
+		}, "Invoice"},
+		{"modules", func() {
+			PrintModulesWithSections(nil, []WireableModuleDisplay{{Name: "jobx", Wired: true, Description: "ok"}, {Name: "ai", Description: "ok"}})
+		}, "ok"},
+	}
+	for _, tt := range tests {
+		for _, colored := range []bool{false, true} {
+			got := escape.ReplaceAllString(capture(t, colored, func(*syncBuffer) { tt.print() }), "")
+			column := -1
+			for _, line := range strings.Split(got, "\n") {
+				i := strings.Index(line, tt.value)
+				if i < 0 {
+					continue
+				}
+				i = len([]rune(line[:i]))
+				if column >= 0 && i != column {
+					t.Errorf("%s, colored = %v: columns at %d and %d:\n%s", tt.name, colored, column, i, got)
+					break
+				}
+				column = i
+			}
+			if column < 0 {
+				t.Errorf("%s, colored = %v: no %q printed:\n%s", tt.name, colored, tt.value, got)
+			}
+		}
+	}
+}